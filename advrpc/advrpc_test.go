@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/sanjit-bhat/pav/safemarshal"
+	"github.com/sanjit-bhat/pav/safemarshal/stream"
 	"github.com/tchajed/marshal"
 )
 
@@ -85,6 +86,35 @@ func TestRPC(t *testing.T) {
 	}
 }
 
+func TestCallStream(t *testing.T) {
+	h := map[uint64]func([]byte, *[]byte){
+		2: servStub,
+	}
+	s := NewServer(h)
+	addr := makeUniqueAddr()
+	s.Serve(addr)
+
+	c := Dial(addr)
+	args := encArgs(&Args{A: 7, B: 8})
+	var reply uint64
+	err := c.CallStream(2, args, 8, func(r *stream.Reader) bool {
+		var err bool
+		reply, err = r.Int()
+		return err
+	})
+	if err || reply != 7*8 {
+		t.Fatal()
+	}
+
+	// the budget bounds the reply.
+	err = c.CallStream(2, args, 7, func(r *stream.Reader) bool {
+		return false
+	})
+	if !err {
+		t.Fatal()
+	}
+}
+
 func makeUniqueAddr() uint64 {
 	port := uint64(rand.IntN(4000)) + 6000
 	// left shift to make IP 0.0.0.0.
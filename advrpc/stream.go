@@ -0,0 +1,29 @@
+//go:build !goose
+
+package advrpc
+
+import (
+	"io"
+
+	"github.com/sanjit-bhat/pav/safemarshal/stream"
+	"github.com/tchajed/marshal"
+)
+
+// CallStream does an rpc, passing the reply to decode as a reader,
+// instead of buffering it.
+// it errors if the reply is over budget or decode errors.
+func (c *Client) CallStream(rpcId uint64, args []byte, budget uint64, decode func(r *stream.Reader) (err bool)) (err bool) {
+	req0 := make([]byte, 0, 8+len(args))
+	req1 := marshal.WriteInt(req0, rpcId)
+	req2 := marshal.WriteBytes(req1, args)
+	if c.conn.Send(req2) {
+		return true
+	}
+
+	return c.conn.ReceiveStream(func(r io.Reader, length uint64) bool {
+		if length > budget {
+			return true
+		}
+		return decode(stream.NewReader(r, length))
+	})
+}
@@ -16,15 +16,16 @@ import (
 const (
 	aliceUid uint64 = iota
 	bobUid
+	carolUid
 )
 
 func init() {
 	server.EpochTime = time.Millisecond
 }
 
-func testAliceBob(servAddr uint64, adtrAddr uint64) (err ktcore.Blame, evid *ktcore.Evid) {
+func testAliceBob(vrfSuite, hashSuite, chainMode byte, servAddr uint64, adtrAddr uint64) (err ktcore.Blame, evid *ktcore.Evid) {
 	// setup server and auditor.
-	serv, servSigPk := server.New()
+	serv, servSigPk := server.New(vrfSuite, hashSuite, chainMode)
 	servRpc := server.NewRpcServer(serv)
 	servRpc.Serve(servAddr)
 	time.Sleep(time.Millisecond)
@@ -49,6 +50,11 @@ func testAliceBob(servAddr uint64, adtrAddr uint64) (err ktcore.Blame, evid *ktc
 	if err != ktcore.BlameNone {
 		return
 	}
+	// carol is a light client, who only follows auditor checkpoints.
+	carol, _, err := client.New(carolUid, servAddr, servSigPk)
+	if err != ktcore.BlameNone {
+		return
+	}
 
 	// run alice and bob.
 	var aliceHist []*optPk
@@ -98,6 +104,14 @@ func testAliceBob(servAddr uint64, adtrAddr uint64) (err ktcore.Blame, evid *ktc
 		return
 	}
 	primitive.Assume(adtrStartEp1 == 0)
+	carolEp, err, evid := carol.Checkpoint(adtrAddr, adtrPk)
+	if err != ktcore.BlameNone {
+		return
+	}
+	// alice is at the checkpoint, so she just checks it.
+	if _, err, evid = alice.Checkpoint(adtrAddr, adtrPk); err != ktcore.BlameNone {
+		return
+	}
 
 	// Assume alice monitored bob's Get epoch.
 	primitive.Assume(bobEp < uint64(len(aliceHist)))
@@ -109,6 +123,36 @@ func testAliceBob(servAddr uint64, adtrAddr uint64) (err ktcore.Blame, evid *ktc
 		err = ktcore.BlameAdtrSig
 		return
 	}
+
+	// carol sees alice's key as of the checkpoint.
+	primitive.Assume(carolEp < uint64(len(aliceHist)))
+	isReg, pk, err := carol.GetAtEpoch(aliceUid, carolEp)
+	if err != ktcore.BlameNone {
+		return
+	}
+	if !equal(aliceHist[carolEp], &optPk{opt: isReg, pk: pk}) {
+		err = ktcore.BlameAdtrSig
+		return
+	}
+
+	// bob can also look up alice's past keys,
+	// once he's seen all of alice's epochs.
+	bobLastEp, _, _, err := bob.Get(aliceUid)
+	if err != ktcore.BlameNone {
+		return
+	}
+	primitive.Assume(uint64(len(aliceHist)) <= bobLastEp+1)
+	for ep, alicePk := range aliceHist {
+		isReg, pk, err0 := bob.GetAtEpoch(aliceUid, uint64(ep))
+		if err0 != ktcore.BlameNone {
+			err = err0
+			return
+		}
+		if !equal(alicePk, &optPk{opt: isReg, pk: pk}) {
+			err = ktcore.BlameServFull
+			return
+		}
+	}
 	return
 }
 
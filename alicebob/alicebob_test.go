@@ -6,6 +6,9 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/sanjit-bhat/pav/auditor"
+	"github.com/sanjit-bhat/pav/cryptoffi"
+	"github.com/sanjit-bhat/pav/hashchain"
 	"github.com/sanjit-bhat/pav/ktcore"
 )
 
@@ -15,7 +18,37 @@ type blameInterp struct {
 }
 
 func TestAliceBob(t *testing.T) {
-	if err, evid := testAliceBob(makeUniqueAddr(), makeUniqueAddr()); err != ktcore.BlameNone {
+	if err, evid := testAliceBob(cryptoffi.VrfSuiteTai, cryptoffi.HashSuiteSha256, hashchain.ModeLinear, makeUniqueAddr(), makeUniqueAddr()); err != ktcore.BlameNone {
+		t.Error()
+		alertUser(t, err, evid)
+	}
+}
+
+func TestAliceBobR255(t *testing.T) {
+	if err, evid := testAliceBob(cryptoffi.VrfSuiteR255, cryptoffi.HashSuiteSha256, hashchain.ModeLinear, makeUniqueAddr(), makeUniqueAddr()); err != ktcore.BlameNone {
+		t.Error()
+		alertUser(t, err, evid)
+	}
+}
+
+func TestAliceBobBlake3(t *testing.T) {
+	if err, evid := testAliceBob(cryptoffi.VrfSuiteTai, cryptoffi.HashSuiteBlake3, hashchain.ModeLinear, makeUniqueAddr(), makeUniqueAddr()); err != ktcore.BlameNone {
+		t.Error()
+		alertUser(t, err, evid)
+	}
+}
+
+func TestAliceBobLog(t *testing.T) {
+	if err, evid := testAliceBob(cryptoffi.VrfSuiteTai, cryptoffi.HashSuiteSha256, hashchain.ModeLog, makeUniqueAddr(), makeUniqueAddr()); err != ktcore.BlameNone {
+		t.Error()
+		alertUser(t, err, evid)
+	}
+}
+
+func TestAliceBobShard(t *testing.T) {
+	defer func(bits uint64) { auditor.ShardBits = bits }(auditor.ShardBits)
+	auditor.ShardBits = 2
+	if err, evid := testAliceBob(cryptoffi.VrfSuiteTai, cryptoffi.HashSuiteSha256, hashchain.ModeLinear, makeUniqueAddr(), makeUniqueAddr()); err != ktcore.BlameNone {
 		t.Error()
 		alertUser(t, err, evid)
 	}
@@ -13,6 +13,16 @@ import (
 	"github.com/sanjit-bhat/pav/server"
 )
 
+// performance params.
+var (
+	// ShardBits splits each epoch update into 2^ShardBits label prefixes,
+	// which the auditor checks with [CheckShard] instead of the
+	// update proofs.
+	// 0 checks the update proofs in order.
+	// it's at most 64.
+	ShardBits uint64 = 0
+)
+
 type Auditor struct {
 	sk   *cryptoffi.SigPrivateKey
 	serv *serv
@@ -20,10 +30,21 @@ type Auditor struct {
 
 	mu   *sync.RWMutex
 	hist *history
+	// store is nil for in-memory auditors.
+	store linkStore
+}
+
+// linkStore persists checked epochs, e.g., for [Open].
+// it's trusted, and should panic if it can't do an op.
+type linkStore interface {
+	// Append durably adds an epoch's dig and server sig.
+	Append(dig, servSig []byte)
 }
 
 type history struct {
 	lastDig []byte
+	// tail extends the server's hashchain.
+	tail *hashchain.Tail
 	// the epoch of our first hist entry.
 	startEp uint64
 	// epochs that the auditor checked update proofs for.
@@ -31,8 +52,9 @@ type history struct {
 }
 
 type serv struct {
-	cli   *advrpc.Client
-	sigPk cryptoffi.SigPublicKey
+	cli       *advrpc.Client
+	sigPk     cryptoffi.SigPublicKey
+	hashSuite byte
 }
 
 // Update queries server for a new epoch update and applies it.
@@ -54,20 +76,63 @@ func (a *Auditor) Update() (err ktcore.Blame) {
 }
 
 func (a *Auditor) updOnce(p *ktcore.AuditProof) (err ktcore.Blame) {
-	sigPk := a.serv.sigPk
 	hist := a.hist
-	prevEp := hist.startEp + uint64(len(hist.epochs)) - 1
-	prevLink := hist.epochs[len(hist.epochs)-1].Link
-	ep, dig, link, errb := getNextLink(sigPk, prevEp, hist.lastDig, prevLink, p)
+	ep, errb := hist.nextEp()
 	if errb {
 		err = ktcore.BlameServFull
 		return
 	}
+	var dig []byte
+	if ShardBits == 0 {
+		dig0, errb := getNextDig(a.serv.hashSuite, hist.lastDig, p.Updates)
+		if errb {
+			err = ktcore.BlameServFull
+			return
+		}
+		dig = dig0
+	} else {
+		dig0, err0 := a.getShardedDig(ep, p.Updates)
+		if err0 != ktcore.BlameNone {
+			err = err0
+			return
+		}
+		dig = dig0
+	}
+	if a.apply(ep, dig, p.LinkSig) {
+		err = ktcore.BlameServFull
+	}
+	return
+}
+
+func (h *history) nextEp() (ep uint64, err bool) {
+	prevEp := h.startEp + uint64(len(h.epochs)) - 1
+	if !std.SumNoOverflow(prevEp, 1) {
+		err = true
+		return
+	}
+	ep = prevEp + 1
+	return
+}
+
+// apply extends the chain with ep's dig, if the server signed it.
+func (a *Auditor) apply(ep uint64, dig, linkSig []byte) (err bool) {
+	hist := a.hist
+	tail := hist.tail.Append(dig)
+	if ktcore.VerifyLinkSig(a.serv.sigPk, ep, tail.Link(), linkSig) {
+		err = true
+		return
+	}
 
+	// persist before anyone can see the counter-sig.
+	if a.store != nil {
+		a.store.Append(dig, linkSig)
+	}
 	// counter-sign and apply update.
+	link := tail.Link()
 	sig := ktcore.SignLink(a.sk, ep, link)
 	hist.lastDig = dig
-	info := &SignedLink{Link: link, ServSig: p.LinkSig, AdtrSig: sig}
+	hist.tail = tail
+	info := &SignedLink{Link: link, ServSig: linkSig, AdtrSig: sig}
 	hist.epochs = append(hist.epochs, info)
 	return
 }
@@ -97,57 +162,62 @@ func (a *Auditor) Get(epoch uint64) (startEp uint64, startLink, currLink *Signed
 	return
 }
 
+// Checkpoint returns the latest epoch that the auditor checked.
+// light clients can skip to it with [server.Server.Skip].
+func (a *Auditor) Checkpoint() (cp *Checkpoint) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	hist := a.hist
+	lastIdx := uint64(len(hist.epochs)) - 1
+	return &Checkpoint{Epoch: hist.startEp + lastIdx, Link: hist.epochs[lastIdx]}
+}
+
 func New(servAddr uint64, servPk cryptoffi.SigPublicKey) (a *Auditor, sigPk cryptoffi.SigPublicKey, err ktcore.Blame) {
 	cli := advrpc.Dial(servAddr)
 	chain, vrf, err := server.CallStart(cli)
 	if err != ktcore.BlameNone {
 		return
 	}
-	startEp, startDig, startLink, errb := CheckStartChain(servPk, chain)
-	if errb {
-		err = ktcore.BlameServFull
-		return
-	}
-	_, errb = CheckStartVrf(servPk, vrf)
+	sigPk, sk := cryptoffi.SigGenerateKey()
+	a, errb := newAuditor(cli, servPk, sk, chain, vrf)
 	if errb {
 		err = ktcore.BlameServFull
 		return
 	}
-
-	mu := new(sync.RWMutex)
-	sigPk, sk := cryptoffi.SigGenerateKey()
-	linkSig := ktcore.SignLink(sk, startEp, startLink)
-	info := &SignedLink{Link: startLink, ServSig: chain.LinkSig, AdtrSig: linkSig}
-	hist := &history{lastDig: startDig, startEp: startEp, epochs: []*SignedLink{info}}
-	vrfSig := ktcore.SignVrf(sk, vrf.VrfPk)
-	serv := &serv{cli: cli, sigPk: servPk}
-	signedVrf := &SignedVrf{VrfPk: vrf.VrfPk, ServSig: vrf.VrfSig, AdtrSig: vrfSig}
-	a = &Auditor{sk: sk, serv: serv, vrf: signedVrf, mu: mu, hist: hist}
 	return
 }
 
-func getNextLink(sigPk cryptoffi.SigPublicKey, prevEp uint64, prevDig, prevLink []byte, p *ktcore.AuditProof) (ep uint64, dig, link []byte, err bool) {
-	if !std.SumNoOverflow(prevEp, 1) {
+// newAuditor starts an auditor at the server's start.
+// it errors if the start is invalid.
+func newAuditor(cli *advrpc.Client, servPk cryptoffi.SigPublicKey, sk *cryptoffi.SigPrivateKey, chain *server.StartChain, vrf *server.StartVrf) (a *Auditor, err bool) {
+	_, errb := CheckStartVrf(servPk, vrf)
+	if errb {
 		err = true
 		return
 	}
-	ep = prevEp + 1
-	if dig, err = getNextDig(prevDig, p.Updates); err {
-		return
-	}
-	link = hashchain.GetNextLink(prevLink, dig)
-	if ktcore.VerifyLinkSig(sigPk, ep, link, p.LinkSig) {
+	startEp, startDig, startTail, errb := CheckStartChain(servPk, vrf.HashSuite, chain)
+	if errb {
 		err = true
 		return
 	}
+	startLink := startTail.Link()
+
+	mu := new(sync.RWMutex)
+	linkSig := ktcore.SignLink(sk, startEp, startLink)
+	info := &SignedLink{Link: startLink, ServSig: chain.LinkSig, AdtrSig: linkSig}
+	hist := &history{lastDig: startDig, tail: startTail, startEp: startEp, epochs: []*SignedLink{info}}
+	vrfSig := ktcore.SignVrf(sk, vrf.VrfSuite, vrf.HashSuite, vrf.VrfPk)
+	serv := &serv{cli: cli, sigPk: servPk, hashSuite: vrf.HashSuite}
+	signedVrf := &SignedVrf{VrfSuite: vrf.VrfSuite, HashSuite: vrf.HashSuite, VrfPk: vrf.VrfPk, ServSig: vrf.VrfSig, AdtrSig: vrfSig}
+	a = &Auditor{sk: sk, serv: serv, vrf: signedVrf, mu: mu, hist: hist}
 	return
 }
 
-func getNextDig(prevDig []byte, updates []*ktcore.UpdateProof) (dig []byte, err bool) {
+func getNextDig(hashSuite byte, prevDig []byte, updates []*ktcore.UpdateProof) (dig []byte, err bool) {
 	dig = prevDig
 	for _, u := range updates {
 		var prev, next []byte
-		prev, next, err = merkle.VerifyUpdate(u.MapLabel, u.MapVal, u.NonMembProof)
+		prev, next, err = merkle.VerifyUpdate(hashSuite, u.MapLabel, u.MapVal, u.NonMembProof)
 		if err {
 			return
 		}
@@ -160,27 +230,25 @@ func getNextDig(prevDig []byte, updates []*ktcore.UpdateProof) (dig []byte, err
 	return
 }
 
-func CheckStartChain(servPk cryptoffi.SigPublicKey, chain *server.StartChain) (ep uint64, dig, link []byte, err bool) {
-	if uint64(len(chain.PrevLink)) != cryptoffi.HashLen {
-		err = true
-		return
-	}
-	extLen, dig, link, errb := hashchain.Verify(chain.PrevLink, chain.ChainProof)
-	if errb {
+// CheckStartChain expects a valid hashSuite, e.g., from [CheckStartVrf].
+// the tail has the chain up to ep, and the chain's mode.
+func CheckStartChain(servPk cryptoffi.SigPublicKey, hashSuite byte, chain *server.StartChain) (ep uint64, dig []byte, tail *hashchain.Tail, err bool) {
+	if hashchain.CheckMode(chain.ChainMode) {
 		err = true
 		return
 	}
-	// want a starting dig.
-	if extLen == 0 {
+	if uint64(len(chain.PrevLink)) != cryptoffi.HashLen {
 		err = true
 		return
 	}
-	if !std.SumNoOverflow(chain.PrevEpochLen, extLen-1) {
+	// want a starting dig, which VerifyTail guarantees.
+	tail, dig, errb := hashchain.VerifyTail(chain.ChainMode, hashSuite, chain.PrevEpochLen, chain.PrevLink, chain.ChainProof)
+	if errb {
 		err = true
 		return
 	}
-	ep = chain.PrevEpochLen + extLen - 1
-	if ktcore.VerifyLinkSig(servPk, ep, link, chain.LinkSig) {
+	ep = tail.Len() - 1
+	if ktcore.VerifyLinkSig(servPk, ep, tail.Link(), chain.LinkSig) {
 		err = true
 		return
 	}
@@ -188,12 +256,16 @@ func CheckStartChain(servPk cryptoffi.SigPublicKey, chain *server.StartChain) (e
 }
 
 func CheckStartVrf(servPk cryptoffi.SigPublicKey, vrf *server.StartVrf) (vrfPk *cryptoffi.VrfPublicKey, err bool) {
-	vrfPk, errb := cryptoffi.VrfPublicKeyDecode(vrf.VrfPk)
+	if cryptoffi.CheckHashSuite(vrf.HashSuite) {
+		err = true
+		return
+	}
+	vrfPk, errb := cryptoffi.VrfPublicKeyDecode(vrf.VrfSuite, vrf.VrfPk)
 	if errb {
 		err = true
 		return
 	}
-	if ktcore.VerifyVrfSig(servPk, vrf.VrfPk, vrf.VrfSig) {
+	if ktcore.VerifyVrfSig(servPk, vrf.VrfSuite, vrf.HashSuite, vrf.VrfPk, vrf.VrfSig) {
 		err = true
 		return
 	}
@@ -0,0 +1,179 @@
+//go:build !goose
+
+package auditor
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/goose-lang/std"
+	"github.com/sanjit-bhat/pav/advrpc"
+	"github.com/sanjit-bhat/pav/cryptoffi"
+	"github.com/sanjit-bhat/pav/hashchain"
+	"github.com/sanjit-bhat/pav/hashchain/diskchain"
+	"github.com/sanjit-bhat/pav/ktcore"
+	"github.com/sanjit-bhat/pav/safemarshal"
+	"github.com/sanjit-bhat/pav/server"
+)
+
+// Open restores the auditor in dir, or starts a new one there,
+// as in [New].
+// kek has length [cryptoffi.KekLen], and seals the auditor's sig key.
+//
+// the start file has the sealed sig key and the server's start.
+// each checked epoch's dig goes in a [diskchain.Chain],
+// with the server sig as its aux record.
+// the chain's links are local to the file; the server's links come
+// from re-checking each epoch on top of the start.
+// an epoch is synced before it's counter-signed.
+func Open(dir string, kek []byte, servAddr uint64, servPk cryptoffi.SigPublicKey) (a *Auditor, sigPk cryptoffi.SigPublicKey, err ktcore.Blame) {
+	std.Assert(uint64(len(kek)) == cryptoffi.KekLen)
+	cli := advrpc.Dial(servAddr)
+	startPath := filepath.Join(dir, "start")
+	b, err0 := os.ReadFile(startPath)
+	if err0 != nil && !errors.Is(err0, fs.ErrNotExist) {
+		err = ktcore.BlameUnknown
+		return
+	}
+	var sk *cryptoffi.SigPrivateKey
+	var start *server.StartReply
+	if err0 != nil {
+		chain, vrf, err1 := server.CallStart(cli)
+		if err1 != ktcore.BlameNone {
+			err = err1
+			return
+		}
+		sigPk, sk = cryptoffi.SigGenerateKey()
+		start = &server.StartReply{Chain: chain, Vrf: vrf}
+		if writeStart(startPath, kek, sk, start) {
+			err = ktcore.BlameUnknown
+			return
+		}
+	} else {
+		var errb bool
+		if sigPk, sk, start, errb = decodeStart(kek, b); errb {
+			err = ktcore.BlameUnknown
+			return
+		}
+	}
+
+	a, errb := newAuditor(cli, servPk, sk, start.Chain, start.Vrf)
+	if errb {
+		err = ktcore.BlameServFull
+		return
+	}
+	c, _, errb := diskchain.Open(filepath.Join(dir, "chain"), start.Vrf.HashSuite, hashchain.ModeLinear)
+	if errb {
+		err = ktcore.BlameUnknown
+		return
+	}
+	if a.replay(c) {
+		c.Close()
+		err = ktcore.BlameUnknown
+		return
+	}
+	a.store = &diskStore{c: c}
+	return
+}
+
+// replay re-checks the stored epochs.
+func (a *Auditor) replay(c *diskchain.Chain) (err bool) {
+	for i := uint64(0); i < c.Len(); i++ {
+		dig, err0 := c.Val(i)
+		if err0 {
+			err = true
+			return
+		}
+		servSig, err0 := c.Aux(i)
+		if err0 {
+			err = true
+			return
+		}
+		ep, err0 := a.hist.nextEp()
+		if err0 {
+			err = true
+			return
+		}
+		if a.apply(ep, dig, servSig) {
+			err = true
+			return
+		}
+	}
+	return
+}
+
+// diskStore is a [linkStore] on a [diskchain.Chain].
+type diskStore struct {
+	c *diskchain.Chain
+}
+
+func (d *diskStore) Append(dig, servSig []byte) {
+	d.c.AppendAux(dig, servSig)
+	if d.c.SetHead(servSig) {
+		panic("auditor: set head")
+	}
+}
+
+// writeStart atomically writes the start file.
+// the start is the aad for the sealed sk.
+func writeStart(path string, kek []byte, sk *cryptoffi.SigPrivateKey, start *server.StartReply) (err bool) {
+	startb := server.StartReplyEncode(nil, start)
+	pt := cryptoffi.SigPrivateKeyEncode(sk)
+	ct := cryptoffi.Seal(kek, startb, pt)
+	zero(pt)
+	b := safemarshal.WriteSlice1D(nil, ct)
+	b = append(b, startb...)
+	tmp := path + ".tmp"
+	if os.WriteFile(tmp, b, 0o600) != nil {
+		return true
+	}
+	f, err0 := os.Open(tmp)
+	if err0 != nil {
+		return true
+	}
+	err0 = f.Sync()
+	if f.Close() != nil || err0 != nil {
+		return true
+	}
+	if os.Rename(tmp, path) != nil {
+		return true
+	}
+	// sync the dir to persist the rename.
+	d, err0 := os.Open(filepath.Dir(path))
+	if err0 != nil {
+		return true
+	}
+	defer d.Close()
+	return d.Sync() != nil
+}
+
+func decodeStart(kek, b []byte) (sigPk cryptoffi.SigPublicKey, sk *cryptoffi.SigPrivateKey, start *server.StartReply, err bool) {
+	ct, startb, err := safemarshal.ReadSlice1D(b)
+	if err {
+		return
+	}
+	start, rem, err := server.StartReplyDecode(startb)
+	if err {
+		return
+	}
+	if len(rem) != 0 {
+		err = true
+		return
+	}
+	pt, err := cryptoffi.Open(kek, startb, ct)
+	if err {
+		return
+	}
+	defer zero(pt)
+	sigPk, sk, err = cryptoffi.SigPrivateKeyDecode(pt)
+	return
+}
+
+// zero clears the plaintext sk once we're done with it.
+func zero(b []byte) {
+	for i := range b {
+		b[i] = 0
+	}
+}
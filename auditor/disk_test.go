@@ -0,0 +1,100 @@
+package auditor
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/sanjit-bhat/pav/cryptoffi"
+	"github.com/sanjit-bhat/pav/hashchain"
+	"github.com/sanjit-bhat/pav/ktcore"
+	"github.com/sanjit-bhat/pav/server"
+)
+
+func TestRestart(t *testing.T) {
+	serv, servPk := server.New(cryptoffi.VrfSuiteTai, cryptoffi.HashSuiteSha256, hashchain.ModeLog)
+	servKek := cryptoffi.RandBytes(cryptoffi.KekLen)
+	bundle := serv.ExportSecrets(servKek)
+	adtrKek := cryptoffi.RandBytes(cryptoffi.KekLen)
+	servDir := t.TempDir()
+	adtrDir := t.TempDir()
+
+	// run both, then drop them, as if killed.
+	s0, _, errb := server.Open(servDir, bundle, servKek)
+	if errb {
+		t.Fatal()
+	}
+	s0.SetEpochTime(time.Millisecond)
+	addr0 := makeUniqueAddr(t)
+	server.NewRpcServer(s0).Serve(addr0)
+	time.Sleep(time.Millisecond)
+	a0, adtrPk, err := Open(adtrDir, adtrKek, addr0, servPk)
+	if err != ktcore.BlameNone {
+		t.Fatal(err)
+	}
+	// one put per epoch, so the server stops at epoch 3.
+	for uid := uint64(0); uid < 3; uid++ {
+		s0.Put(uid, 0, []byte{byte(uid)})
+		waitUpdates(t, s0, int(uid)+1)
+	}
+	if err = a0.Update(); err != ktcore.BlameNone {
+		t.Fatal(err)
+	}
+	cp0 := a0.Checkpoint()
+	if cp0.Epoch != 3 {
+		t.Fatal()
+	}
+
+	// reopen both from disk.
+	s1, _, errb := server.Open(servDir, bundle, servKek)
+	if errb {
+		t.Fatal()
+	}
+	s1.SetEpochTime(time.Millisecond)
+	addr1 := makeUniqueAddr(t)
+	server.NewRpcServer(s1).Serve(addr1)
+	time.Sleep(time.Millisecond)
+	a1, adtrPk1, err := Open(adtrDir, adtrKek, addr1, servPk)
+	if err != ktcore.BlameNone {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(adtrPk, adtrPk1) {
+		t.Fatal()
+	}
+	cp1 := a1.Checkpoint()
+	if cp1.Epoch != cp0.Epoch || !bytes.Equal(cp1.Link.Link, cp0.Link.Link) ||
+		!bytes.Equal(cp1.Link.AdtrSig, cp0.Link.AdtrSig) {
+		t.Fatal()
+	}
+
+	// the reopened server extends the same chain.
+	s1.Put(0, 1, []byte{3})
+	waitUpdates(t, s1, 4)
+	if err = a1.Update(); err != ktcore.BlameNone {
+		t.Fatal(err)
+	}
+	if a1.Checkpoint().Epoch != cp0.Epoch+1 {
+		t.Fatal()
+	}
+
+	// the sig key needs the right kek.
+	if _, _, err = Open(adtrDir, servKek, addr1, servPk); err == ktcore.BlameNone {
+		t.Fatal()
+	}
+}
+
+// waitUpdates waits for serv to commit n puts.
+func waitUpdates(t *testing.T, serv *server.Server, n int) {
+	for range 1000 {
+		proofs, _ := serv.Audit(0)
+		var num int
+		for _, p := range proofs {
+			num += len(p.Updates)
+		}
+		if num >= n {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatal("server didn't commit puts")
+}
@@ -7,6 +7,7 @@ import (
 
 const (
 	GetRpc uint64 = iota
+	CheckpointRpc
 )
 
 func NewRpcAuditor(adtr *Auditor) *advrpc.Server {
@@ -22,6 +23,9 @@ func NewRpcAuditor(adtr *Auditor) *advrpc.Server {
 		r := &GetReply{StartEp: r0, StartLink: r1, CurrLink: r2, Vrf: r3, Err: r4}
 		*reply = GetReplyEncode(*reply, r)
 	}
+	h[CheckpointRpc] = func(arg []byte, reply *[]byte) {
+		*reply = CheckpointEncode(*reply, adtr.Checkpoint())
+	}
 	return advrpc.NewServer(h)
 }
 
@@ -34,10 +38,6 @@ func CallGet(c *advrpc.Client, epoch uint64) (startEp uint64, startLink, currLin
 		return
 	}
 	r, _, errb := GetReplyDecode(*rb)
-	startEp = r.StartEp
-	startLink = r.StartLink
-	currLink = r.CurrLink
-	vrf = r.Vrf
 	if errb {
 		err = ktcore.BlameAdtrFull
 		return
@@ -47,5 +47,24 @@ func CallGet(c *advrpc.Client, epoch uint64) (startEp uint64, startLink, currLin
 		err = ktcore.BlameUnknown
 		return
 	}
+	// the links and vrf are only optional for err replies.
+	if r.StartLink == nil || r.CurrLink == nil || r.Vrf == nil {
+		err = ktcore.BlameAdtrFull
+		return
+	}
+	return r.StartEp, r.StartLink, r.CurrLink, r.Vrf, ktcore.BlameNone
+}
+
+func CallCheckpoint(c *advrpc.Client) (cp *Checkpoint, err ktcore.Blame) {
+	rb := new([]byte)
+	if c.Call(CheckpointRpc, nil, rb) {
+		err = ktcore.BlameUnknown
+		return
+	}
+	cp, _, errb := CheckpointDecode(*rb)
+	if errb {
+		err = ktcore.BlameAdtrFull
+		return
+	}
 	return
 }
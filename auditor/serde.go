@@ -5,21 +5,38 @@ type GetArg struct {
 }
 
 type SignedLink struct {
-	Link    []byte
+	// serde: len 32.
+	Link []byte
+	// serde: len 64.
 	ServSig []byte
+	// serde: len 64.
 	AdtrSig []byte
 }
 
 type SignedVrf struct {
-	VrfPk   []byte
+	VrfSuite  byte
+	HashSuite byte
+	VrfPk     []byte
+	// serde: len 64.
 	ServSig []byte
+	// serde: len 64.
 	AdtrSig []byte
 }
 
 type GetReply struct {
-	StartEp   uint64
+	StartEp uint64
+	// serde: optional.
 	StartLink *SignedLink
-	CurrLink  *SignedLink
-	Vrf       *SignedVrf
-	Err       bool
+	// serde: optional.
+	CurrLink *SignedLink
+	// serde: optional.
+	Vrf *SignedVrf
+	Err bool
+}
+
+// Checkpoint is the auditor's latest epoch and its link,
+// which light clients can skip to.
+type Checkpoint struct {
+	Epoch uint64
+	Link  *SignedLink
 }
@@ -31,29 +31,40 @@ func SignedLinkDecode(b0 []byte) (*SignedLink, []byte, bool) {
 	if err1 {
 		return nil, nil, true
 	}
+	if uint64(len(a1)) != 32 {
+		return nil, nil, true
+	}
 	a2, b2, err2 := safemarshal.ReadSlice1D(b1)
 	if err2 {
 		return nil, nil, true
 	}
+	if uint64(len(a2)) != 64 {
+		return nil, nil, true
+	}
 	a3, b3, err3 := safemarshal.ReadSlice1D(b2)
 	if err3 {
 		return nil, nil, true
 	}
+	if uint64(len(a3)) != 64 {
+		return nil, nil, true
+	}
 	return &SignedLink{Link: a1, ServSig: a2, AdtrSig: a3}, b3, false
 }
 func SignedVrfEncode(b0 []byte, o *SignedVrf) []byte {
 	var b = b0
+	b = safemarshal.WriteByte(b, o.VrfSuite)
+	b = safemarshal.WriteByte(b, o.HashSuite)
 	b = safemarshal.WriteSlice1D(b, o.VrfPk)
 	b = safemarshal.WriteSlice1D(b, o.ServSig)
 	b = safemarshal.WriteSlice1D(b, o.AdtrSig)
 	return b
 }
 func SignedVrfDecode(b0 []byte) (*SignedVrf, []byte, bool) {
-	a1, b1, err1 := safemarshal.ReadSlice1D(b0)
+	a1, b1, err1 := safemarshal.ReadByte(b0)
 	if err1 {
 		return nil, nil, true
 	}
-	a2, b2, err2 := safemarshal.ReadSlice1D(b1)
+	a2, b2, err2 := safemarshal.ReadByte(b1)
 	if err2 {
 		return nil, nil, true
 	}
@@ -61,14 +72,37 @@ func SignedVrfDecode(b0 []byte) (*SignedVrf, []byte, bool) {
 	if err3 {
 		return nil, nil, true
 	}
-	return &SignedVrf{VrfPk: a1, ServSig: a2, AdtrSig: a3}, b3, false
+	a4, b4, err4 := safemarshal.ReadSlice1D(b3)
+	if err4 {
+		return nil, nil, true
+	}
+	if uint64(len(a4)) != 64 {
+		return nil, nil, true
+	}
+	a5, b5, err5 := safemarshal.ReadSlice1D(b4)
+	if err5 {
+		return nil, nil, true
+	}
+	if uint64(len(a5)) != 64 {
+		return nil, nil, true
+	}
+	return &SignedVrf{VrfSuite: a1, HashSuite: a2, VrfPk: a3, ServSig: a4, AdtrSig: a5}, b5, false
 }
 func GetReplyEncode(b0 []byte, o *GetReply) []byte {
 	var b = b0
 	b = marshal.WriteInt(b, o.StartEp)
-	b = SignedLinkEncode(b, o.StartLink)
-	b = SignedLinkEncode(b, o.CurrLink)
-	b = SignedVrfEncode(b, o.Vrf)
+	b = marshal.WriteBool(b, o.StartLink != nil)
+	if o.StartLink != nil {
+		b = SignedLinkEncode(b, o.StartLink)
+	}
+	b = marshal.WriteBool(b, o.CurrLink != nil)
+	if o.CurrLink != nil {
+		b = SignedLinkEncode(b, o.CurrLink)
+	}
+	b = marshal.WriteBool(b, o.Vrf != nil)
+	if o.Vrf != nil {
+		b = SignedVrfEncode(b, o.Vrf)
+	}
 	b = marshal.WriteBool(b, o.Err)
 	return b
 }
@@ -77,21 +111,65 @@ func GetReplyDecode(b0 []byte) (*GetReply, []byte, bool) {
 	if err1 {
 		return nil, nil, true
 	}
-	a2, b2, err2 := SignedLinkDecode(b1)
+	var a2 *SignedLink
+	isSet2, b2, err2 := safemarshal.ReadBool(b1)
 	if err2 {
 		return nil, nil, true
 	}
-	a3, b3, err3 := SignedLinkDecode(b2)
+	if isSet2 {
+		x, rem, err := SignedLinkDecode(b2)
+		if err {
+			return nil, nil, true
+		}
+		a2 = x
+		b2 = rem
+	}
+	var a3 *SignedLink
+	isSet3, b3, err3 := safemarshal.ReadBool(b2)
 	if err3 {
 		return nil, nil, true
 	}
-	a4, b4, err4 := SignedVrfDecode(b3)
+	if isSet3 {
+		x, rem, err := SignedLinkDecode(b3)
+		if err {
+			return nil, nil, true
+		}
+		a3 = x
+		b3 = rem
+	}
+	var a4 *SignedVrf
+	isSet4, b4, err4 := safemarshal.ReadBool(b3)
 	if err4 {
 		return nil, nil, true
 	}
+	if isSet4 {
+		x, rem, err := SignedVrfDecode(b4)
+		if err {
+			return nil, nil, true
+		}
+		a4 = x
+		b4 = rem
+	}
 	a5, b5, err5 := safemarshal.ReadBool(b4)
 	if err5 {
 		return nil, nil, true
 	}
 	return &GetReply{StartEp: a1, StartLink: a2, CurrLink: a3, Vrf: a4, Err: a5}, b5, false
 }
+func CheckpointEncode(b0 []byte, o *Checkpoint) []byte {
+	var b = b0
+	b = marshal.WriteInt(b, o.Epoch)
+	b = SignedLinkEncode(b, o.Link)
+	return b
+}
+func CheckpointDecode(b0 []byte) (*Checkpoint, []byte, bool) {
+	a1, b1, err1 := safemarshal.ReadInt(b0)
+	if err1 {
+		return nil, nil, true
+	}
+	a2, b2, err2 := SignedLinkDecode(b1)
+	if err2 {
+		return nil, nil, true
+	}
+	return &Checkpoint{Epoch: a1, Link: a2}, b2, false
+}
@@ -0,0 +1,293 @@
+// Auto-generated from spec "github.com/sanjit-bhat/pav/auditor/serde.go"
+// using compiler "github.com/sanjit-bhat/pav/serde".
+package auditor
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"math/rand/v2"
+	"os"
+	"testing"
+)
+
+func FuzzGetArgDecode(f *testing.F) {
+	rnd := rand.New(rand.NewPCG(0, 0))
+	for i := 0; i < 4; i++ {
+		f.Add(GetArgEncode(nil, randGetArg(rnd)))
+	}
+	f.Fuzz(func(t *testing.T, b []byte) {
+		x, _, err := GetArgDecode(b)
+		if err {
+			return
+		}
+		b0 := GetArgEncode(nil, x)
+		y, rem, err := GetArgDecode(b0)
+		if err || len(rem) != 0 {
+			t.Fatal()
+		}
+		if !bytes.Equal(b0, GetArgEncode(nil, y)) {
+			t.Fatal()
+		}
+	})
+}
+func TestGetArgRoundTrip(t *testing.T) {
+	rnd := rand.New(rand.NewPCG(0, 0))
+	for i := 0; i < 100; i++ {
+		b := GetArgEncode(nil, randGetArg(rnd))
+		x, rem, err := GetArgDecode(b)
+		if err || len(rem) != 0 {
+			t.Fatal()
+		}
+		if !bytes.Equal(b, GetArgEncode(nil, x)) {
+			t.Fatal()
+		}
+	}
+}
+func FuzzSignedLinkDecode(f *testing.F) {
+	rnd := rand.New(rand.NewPCG(0, 0))
+	for i := 0; i < 4; i++ {
+		f.Add(SignedLinkEncode(nil, randSignedLink(rnd)))
+	}
+	f.Fuzz(func(t *testing.T, b []byte) {
+		x, _, err := SignedLinkDecode(b)
+		if err {
+			return
+		}
+		b0 := SignedLinkEncode(nil, x)
+		y, rem, err := SignedLinkDecode(b0)
+		if err || len(rem) != 0 {
+			t.Fatal()
+		}
+		if !bytes.Equal(b0, SignedLinkEncode(nil, y)) {
+			t.Fatal()
+		}
+	})
+}
+func TestSignedLinkRoundTrip(t *testing.T) {
+	rnd := rand.New(rand.NewPCG(0, 0))
+	for i := 0; i < 100; i++ {
+		b := SignedLinkEncode(nil, randSignedLink(rnd))
+		x, rem, err := SignedLinkDecode(b)
+		if err || len(rem) != 0 {
+			t.Fatal()
+		}
+		if !bytes.Equal(b, SignedLinkEncode(nil, x)) {
+			t.Fatal()
+		}
+	}
+}
+func FuzzSignedVrfDecode(f *testing.F) {
+	rnd := rand.New(rand.NewPCG(0, 0))
+	for i := 0; i < 4; i++ {
+		f.Add(SignedVrfEncode(nil, randSignedVrf(rnd)))
+	}
+	f.Fuzz(func(t *testing.T, b []byte) {
+		x, _, err := SignedVrfDecode(b)
+		if err {
+			return
+		}
+		b0 := SignedVrfEncode(nil, x)
+		y, rem, err := SignedVrfDecode(b0)
+		if err || len(rem) != 0 {
+			t.Fatal()
+		}
+		if !bytes.Equal(b0, SignedVrfEncode(nil, y)) {
+			t.Fatal()
+		}
+	})
+}
+func TestSignedVrfRoundTrip(t *testing.T) {
+	rnd := rand.New(rand.NewPCG(0, 0))
+	for i := 0; i < 100; i++ {
+		b := SignedVrfEncode(nil, randSignedVrf(rnd))
+		x, rem, err := SignedVrfDecode(b)
+		if err || len(rem) != 0 {
+			t.Fatal()
+		}
+		if !bytes.Equal(b, SignedVrfEncode(nil, x)) {
+			t.Fatal()
+		}
+	}
+}
+func FuzzGetReplyDecode(f *testing.F) {
+	rnd := rand.New(rand.NewPCG(0, 0))
+	for i := 0; i < 4; i++ {
+		f.Add(GetReplyEncode(nil, randGetReply(rnd)))
+	}
+	f.Fuzz(func(t *testing.T, b []byte) {
+		x, _, err := GetReplyDecode(b)
+		if err {
+			return
+		}
+		b0 := GetReplyEncode(nil, x)
+		y, rem, err := GetReplyDecode(b0)
+		if err || len(rem) != 0 {
+			t.Fatal()
+		}
+		if !bytes.Equal(b0, GetReplyEncode(nil, y)) {
+			t.Fatal()
+		}
+	})
+}
+func TestGetReplyRoundTrip(t *testing.T) {
+	rnd := rand.New(rand.NewPCG(0, 0))
+	for i := 0; i < 100; i++ {
+		b := GetReplyEncode(nil, randGetReply(rnd))
+		x, rem, err := GetReplyDecode(b)
+		if err || len(rem) != 0 {
+			t.Fatal()
+		}
+		if !bytes.Equal(b, GetReplyEncode(nil, x)) {
+			t.Fatal()
+		}
+	}
+}
+func FuzzCheckpointDecode(f *testing.F) {
+	rnd := rand.New(rand.NewPCG(0, 0))
+	for i := 0; i < 4; i++ {
+		f.Add(CheckpointEncode(nil, randCheckpoint(rnd)))
+	}
+	f.Fuzz(func(t *testing.T, b []byte) {
+		x, _, err := CheckpointDecode(b)
+		if err {
+			return
+		}
+		b0 := CheckpointEncode(nil, x)
+		y, rem, err := CheckpointDecode(b0)
+		if err || len(rem) != 0 {
+			t.Fatal()
+		}
+		if !bytes.Equal(b0, CheckpointEncode(nil, y)) {
+			t.Fatal()
+		}
+	})
+}
+func TestCheckpointRoundTrip(t *testing.T) {
+	rnd := rand.New(rand.NewPCG(0, 0))
+	for i := 0; i < 100; i++ {
+		b := CheckpointEncode(nil, randCheckpoint(rnd))
+		x, rem, err := CheckpointDecode(b)
+		if err || len(rem) != 0 {
+			t.Fatal()
+		}
+		if !bytes.Equal(b, CheckpointEncode(nil, x)) {
+			t.Fatal()
+		}
+	}
+}
+func randGetArg(rnd *rand.Rand) *GetArg {
+	o := &GetArg{}
+	o.Epoch = rnd.Uint64()
+	return o
+}
+func randSignedLink(rnd *rand.Rand) *SignedLink {
+	o := &SignedLink{}
+	o.Link = randBytes(rnd, 32)
+	o.ServSig = randBytes(rnd, 64)
+	o.AdtrSig = randBytes(rnd, 64)
+	return o
+}
+func randSignedVrf(rnd *rand.Rand) *SignedVrf {
+	o := &SignedVrf{}
+	o.VrfSuite = byte(rnd.Uint32())
+	o.HashSuite = byte(rnd.Uint32())
+	o.VrfPk = randBytes(rnd, rnd.IntN(4))
+	o.ServSig = randBytes(rnd, 64)
+	o.AdtrSig = randBytes(rnd, 64)
+	return o
+}
+func randGetReply(rnd *rand.Rand) *GetReply {
+	o := &GetReply{}
+	o.StartEp = rnd.Uint64()
+	if rnd.IntN(2) == 0 {
+		o.StartLink = randSignedLink(rnd)
+	}
+	if rnd.IntN(2) == 0 {
+		o.CurrLink = randSignedLink(rnd)
+	}
+	if rnd.IntN(2) == 0 {
+		o.Vrf = randSignedVrf(rnd)
+	}
+	o.Err = rnd.IntN(2) == 0
+	return o
+}
+func randCheckpoint(rnd *rand.Rand) *Checkpoint {
+	o := &Checkpoint{}
+	o.Epoch = rnd.Uint64()
+	o.Link = randSignedLink(rnd)
+	return o
+}
+func randBytes(rnd *rand.Rand, n int) []byte {
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = byte(rnd.Uint32())
+	}
+	return b
+}
+func vectorCodec(name string, b []byte) (enc []byte, err bool) {
+	switch name {
+	case "GetArg":
+		x, rem, errb := GetArgDecode(b)
+		if errb || len(rem) != 0 {
+			return nil, true
+		}
+		return GetArgEncode(nil, x), false
+	case "SignedLink":
+		x, rem, errb := SignedLinkDecode(b)
+		if errb || len(rem) != 0 {
+			return nil, true
+		}
+		return SignedLinkEncode(nil, x), false
+	case "SignedVrf":
+		x, rem, errb := SignedVrfDecode(b)
+		if errb || len(rem) != 0 {
+			return nil, true
+		}
+		return SignedVrfEncode(nil, x), false
+	case "GetReply":
+		x, rem, errb := GetReplyDecode(b)
+		if errb || len(rem) != 0 {
+			return nil, true
+		}
+		return GetReplyEncode(nil, x), false
+	case "Checkpoint":
+		x, rem, errb := CheckpointDecode(b)
+		if errb || len(rem) != 0 {
+			return nil, true
+		}
+		return CheckpointEncode(nil, x), false
+	}
+	return nil, true
+}
+func TestVectors(t *testing.T) {
+	data, err := os.ReadFile("serde.vectors.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var vs struct {
+		Vectors []struct {
+			Name, Struct, Hex string
+		}
+	}
+	err = json.Unmarshal(data, &vs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(vs.Vectors) == 0 {
+		t.Fatal("no vectors")
+	}
+	for _, v := range vs.Vectors {
+		b, err := hex.DecodeString(v.Hex)
+		if err != nil {
+			t.Fatal(err)
+		}
+		enc, errb := vectorCodec(v.Struct, b)
+		if errb {
+			t.Fatalf("%s: decode failed", v.Name)
+		}
+		if !bytes.Equal(b, enc) {
+			t.Fatalf("%s: encoding differs", v.Name)
+		}
+	}
+}
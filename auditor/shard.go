@@ -0,0 +1,108 @@
+package auditor
+
+import (
+	"bytes"
+
+	"github.com/sanjit-bhat/pav/ktcore"
+	"github.com/sanjit-bhat/pav/merkle"
+	"github.com/sanjit-bhat/pav/server"
+	"github.com/tchajed/marshal"
+)
+
+// Shard has a prefix sub-tree of the maps before and after an epoch update,
+// from [merkle.Map.ProvePrefix].
+type Shard struct {
+	Prefix    []byte
+	PrefixLen uint64
+	// PrevEntries and PrevProof are for the map before the update.
+	PrevEntries []*merkle.Entry
+	PrevProof   []byte
+	// NextEntries and NextProof are for the map after the update.
+	NextEntries []*merkle.Entry
+	NextProof   []byte
+}
+
+// CheckShard checks the updates under a shard's prefix, which lets
+// auditors split up a large epoch update.
+// if shards with prefixes covering all labels pass, then dig
+// has exactly the entries of prevDig and updates.
+func CheckShard(hashSuite byte, prevDig, dig []byte, updates []*ktcore.UpdateProof, s *Shard) (err bool) {
+	prevHash, err := merkle.VerifyPrefix(hashSuite, s.Prefix, s.PrefixLen, s.PrevEntries, s.PrevProof)
+	if err {
+		return
+	}
+	if !bytes.Equal(prevDig, prevHash) {
+		err = true
+		return
+	}
+	nextHash, err := merkle.VerifyPrefix(hashSuite, s.Prefix, s.PrefixLen, s.NextEntries, s.NextProof)
+	if err {
+		return
+	}
+	if !bytes.Equal(dig, nextHash) {
+		err = true
+		return
+	}
+
+	// next must be prev with the shard's updates added.
+	exp := make(map[string][]byte)
+	for _, e := range s.PrevEntries {
+		exp[string(e.Label)] = e.Val
+	}
+	for _, u := range updates {
+		if !merkle.HasPrefix(u.MapLabel, s.Prefix, s.PrefixLen) {
+			continue
+		}
+		// updates only add new labels.
+		if _, ok := exp[string(u.MapLabel)]; ok {
+			err = true
+			return
+		}
+		exp[string(u.MapLabel)] = u.MapVal
+	}
+	if len(exp) != len(s.NextEntries) {
+		err = true
+		return
+	}
+	for _, e := range s.NextEntries {
+		val, ok := exp[string(e.Label)]
+		if !ok || !bytes.Equal(val, e.Val) {
+			err = true
+			return
+		}
+	}
+	return
+}
+
+// getShardedDig gets ep's dig by checking updates against a shard
+// from the server for each of the 2^[ShardBits] label prefixes.
+func (a *Auditor) getShardedDig(ep uint64, updates []*ktcore.UpdateProof) (dig []byte, err ktcore.Blame) {
+	hashSuite := a.serv.hashSuite
+	prevDig := a.hist.lastDig
+	numShards := uint64(1) << ShardBits
+	for i := uint64(0); i < numShards; i++ {
+		// labels start with the low bits of each byte, so the
+		// little-endian i has the first ShardBits label bits.
+		prefix := marshal.WriteInt(nil, i)
+		prevEntries, prevProof, nextEntries, nextProof, err0 := server.CallShard(a.serv.cli, ep, prefix, ShardBits)
+		if err0 != ktcore.BlameNone {
+			err = err0
+			return
+		}
+		s := &Shard{Prefix: prefix, PrefixLen: ShardBits, PrevEntries: prevEntries, PrevProof: prevProof, NextEntries: nextEntries, NextProof: nextProof}
+		// every shard proves the whole dig, so take it from the first.
+		if i == 0 {
+			dig0, errb := merkle.VerifyPrefix(hashSuite, prefix, ShardBits, nextEntries, nextProof)
+			if errb {
+				err = ktcore.BlameServFull
+				return
+			}
+			dig = dig0
+		}
+		if CheckShard(hashSuite, prevDig, dig, updates, s) {
+			err = ktcore.BlameServFull
+			return
+		}
+	}
+	return
+}
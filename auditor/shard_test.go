@@ -0,0 +1,67 @@
+package auditor
+
+import (
+	"math/rand/v2"
+	"testing"
+
+	"github.com/sanjit-bhat/pav/cryptoffi"
+	"github.com/sanjit-bhat/pav/ktcore"
+	"github.com/sanjit-bhat/pav/merkle"
+)
+
+func TestCheckShard(t *testing.T) {
+	var seed [32]byte
+	rnd := rand.NewChaCha8(seed)
+	mkLabel := func() []byte {
+		l := make([]byte, cryptoffi.HashLen)
+		rnd.Read(l)
+		return l
+	}
+	suite := cryptoffi.HashSuiteSha256
+	m := merkle.New(suite)
+	for i := 0; i < 100; i++ {
+		m.Put(mkLabel(), []byte{1})
+	}
+	prev := m.Snapshot()
+	var entries []*merkle.Entry
+	for i := 0; i < 20; i++ {
+		entries = append(entries, &merkle.Entry{Label: mkLabel(), Val: []byte{2}})
+	}
+	sorted, proofs := m.PutBatch(entries)
+	var updates []*ktcore.UpdateProof
+	for i, e := range sorted {
+		updates = append(updates, &ktcore.UpdateProof{MapLabel: e.Label, MapVal: e.Val, NonMembProof: proofs[i]})
+	}
+
+	// 4 shards cover all labels.
+	const prefixLen = 2
+	var shards []*Shard
+	for b := byte(0); b < 1<<prefixLen; b++ {
+		prefix := []byte{b}
+		s := &Shard{Prefix: prefix, PrefixLen: prefixLen}
+		s.PrevEntries, s.PrevProof = prev.ProvePrefix(prefix, prefixLen)
+		s.NextEntries, s.NextProof = m.ProvePrefix(prefix, prefixLen)
+		if CheckShard(suite, prev.Hash(), m.Hash(), updates, s) {
+			t.Fatal()
+		}
+		shards = append(shards, s)
+	}
+
+	// a missing update.
+	var n int
+	for _, s := range shards {
+		if CheckShard(suite, prev.Hash(), m.Hash(), updates[1:], s) {
+			n++
+		}
+	}
+	if n != 1 {
+		t.Fatal()
+	}
+	// the wrong digs.
+	if !CheckShard(suite, m.Hash(), m.Hash(), updates, shards[0]) {
+		t.Fatal()
+	}
+	if !CheckShard(suite, prev.Hash(), prev.Hash(), updates, shards[0]) {
+		t.Fatal()
+	}
+}
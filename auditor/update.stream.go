@@ -0,0 +1,50 @@
+//go:build !goose
+
+package auditor
+
+import (
+	"bytes"
+
+	"github.com/sanjit-bhat/pav/ktcore"
+	"github.com/sanjit-bhat/pav/merkle"
+	"github.com/sanjit-bhat/pav/server"
+)
+
+// AuditBudget bounds the bytes of each audit reply in [Auditor.UpdateStream].
+var AuditBudget uint64 = 1 << 30
+
+// UpdateStream is [Auditor.Update], except that it checks each update
+// as it arrives, instead of buffering the reply.
+// it checks update proofs in order, ignoring [ShardBits],
+// since shard rpcs can't share the conn with the reply.
+func (a *Auditor) UpdateStream() (err ktcore.Blame) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	hist := a.hist
+	prevEp := hist.startEp + uint64(len(hist.epochs)) - 1
+	// proofs only get applied after their link sig checks out,
+	// so a later err leaves the hist consistent.
+	dig := hist.lastDig
+	eachUpd := func(u *ktcore.UpdateProof) bool {
+		prev, next, errb := merkle.VerifyUpdate(a.serv.hashSuite, u.MapLabel, u.MapVal, u.NonMembProof)
+		if errb || !bytes.Equal(dig, prev) {
+			err = ktcore.BlameServFull
+			return true
+		}
+		dig = next
+		return false
+	}
+	eachP := func(p *ktcore.AuditProof) bool {
+		ep, errb := hist.nextEp()
+		if errb || a.apply(ep, dig, p.LinkSig) {
+			err = ktcore.BlameServFull
+			return true
+		}
+		return false
+	}
+	err0 := server.CallAuditStream(a.serv.cli, prevEp, AuditBudget, eachP, eachUpd)
+	if err == ktcore.BlameNone {
+		err = err0
+	}
+	return
+}
@@ -0,0 +1,68 @@
+package auditor
+
+import (
+	"bytes"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/sanjit-bhat/pav/cryptoffi"
+	"github.com/sanjit-bhat/pav/hashchain"
+	"github.com/sanjit-bhat/pav/ktcore"
+	"github.com/sanjit-bhat/pav/server"
+)
+
+func TestUpdateStream(t *testing.T) {
+	serv, servPk := server.New(cryptoffi.VrfSuiteTai, cryptoffi.HashSuiteSha256, hashchain.ModeLinear)
+	serv.SetEpochTime(time.Millisecond)
+	addr := makeUniqueAddr(t)
+	server.NewRpcServer(serv).Serve(addr)
+	time.Sleep(time.Millisecond)
+
+	a0, _, err := New(addr, servPk)
+	if err != ktcore.BlameNone {
+		t.Fatal(err)
+	}
+	a1, _, err := New(addr, servPk)
+	if err != ktcore.BlameNone {
+		t.Fatal(err)
+	}
+	for uid := uint64(0); uid < 3; uid++ {
+		serv.Put(uid, 0, []byte{byte(uid)})
+	}
+	waitUpdates(t, serv, 3)
+
+	if err = a0.Update(); err != ktcore.BlameNone {
+		t.Fatal(err)
+	}
+	if err = a1.UpdateStream(); err != ktcore.BlameNone {
+		t.Fatal(err)
+	}
+	cp0 := a0.Checkpoint()
+	cp1 := a1.Checkpoint()
+	if cp0.Epoch == 0 || cp1.Epoch != cp0.Epoch || !bytes.Equal(cp0.Link.Link, cp1.Link.Link) {
+		t.Fatal()
+	}
+
+	// the budget bounds the reply.
+	defer func(n uint64) { AuditBudget = n }(AuditBudget)
+	AuditBudget = 8
+	serv.Put(3, 0, []byte{3})
+	waitUpdates(t, serv, 4)
+	if err = a1.UpdateStream(); err == ktcore.BlameNone {
+		t.Fatal()
+	}
+	if a1.Checkpoint().Epoch != cp1.Epoch {
+		t.Fatal()
+	}
+}
+
+func makeUniqueAddr(t *testing.T) uint64 {
+	l, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	// left shift to make IP 0.0.0.0.
+	return uint64(l.Addr().(*net.TCPAddr).Port) << 32
+}
@@ -34,10 +34,13 @@ type epoch struct {
 }
 
 type serv struct {
-	cli    *advrpc.Client
-	sigPk  cryptoffi.SigPublicKey
-	vrfPk  *cryptoffi.VrfPublicKey
-	vrfSig []byte
+	cli       *advrpc.Client
+	sigPk     cryptoffi.SigPublicKey
+	vrfSuite  byte
+	hashSuite byte
+	chainMode byte
+	vrfPk     *cryptoffi.VrfPublicKey
+	vrfSig    []byte
 }
 
 // Put queues pk for insertion.
@@ -59,17 +62,17 @@ func (c *Client) Get(uid uint64) (ep uint64, isReg bool, pk []byte, err ktcore.B
 		return
 	}
 	// check.
-	next, errb := getNextEp(c.last, c.serv.sigPk, chainProof, sig)
+	next, errb := getNextEp(c.last, c.serv.sigPk, c.serv.hashSuite, c.serv.chainMode, chainProof, sig)
 	if errb {
 		err = ktcore.BlameServFull
 		return
 	}
-	if checkHist(c.serv.vrfPk, uid, 0, next.dig, hist) {
+	if checkHist(c.serv.vrfPk, c.serv.hashSuite, uid, 0, next.dig, hist) {
 		err = ktcore.BlameServFull
 		return
 	}
 	boundVer := uint64(len(hist))
-	if checkNonMemb(c.serv.vrfPk, uid, boundVer, next.dig, bound) {
+	if checkNonMemb(c.serv.vrfPk, c.serv.hashSuite, uid, boundVer, next.dig, bound) {
 		err = ktcore.BlameServFull
 		return
 	}
@@ -84,6 +87,43 @@ func (c *Client) Get(uid uint64) (ep uint64, isReg bool, pk []byte, err ktcore.B
 	return
 }
 
+// GetAtEpoch gets a uid's pk as of a past epoch,
+// e.g., to check an old signed message.
+// epoch must be at most the epoch of the last Get or SelfMon,
+// else it errs with [ktcore.BlameUnknown].
+func (c *Client) GetAtEpoch(uid, epoch uint64) (isReg bool, pk []byte, err ktcore.Blame) {
+	last := c.last
+	if epoch > last.epoch {
+		err = ktcore.BlameUnknown
+		return
+	}
+	inclProof, hist, bound, err := server.CallGetAtEpoch(c.serv.cli, uid, epoch, last.epoch)
+	if err != ktcore.BlameNone {
+		return
+	}
+	// check.
+	dig, errb := checkIncl(c.serv.hashSuite, c.serv.chainMode, last, epoch, inclProof)
+	if errb {
+		err = ktcore.BlameServFull
+		return
+	}
+	if checkHist(c.serv.vrfPk, c.serv.hashSuite, uid, 0, dig, hist) {
+		err = ktcore.BlameServFull
+		return
+	}
+	boundVer := uint64(len(hist))
+	if checkNonMemb(c.serv.vrfPk, c.serv.hashSuite, uid, boundVer, dig, bound) {
+		err = ktcore.BlameServFull
+		return
+	}
+
+	if boundVer != 0 {
+		isReg = true
+		pk = hist[boundVer-1].PkOpen.Val
+	}
+	return
+}
+
 // SelfMon a client's own uid.
 // if isChanged, the key was added sometime from the last SelfMon.
 func (c *Client) SelfMon() (ep uint64, isChanged bool, err ktcore.Blame) {
@@ -92,7 +132,7 @@ func (c *Client) SelfMon() (ep uint64, isChanged bool, err ktcore.Blame) {
 		return
 	}
 	// check.
-	next, errb := getNextEp(c.last, c.serv.sigPk, chainProof, sig)
+	next, errb := getNextEp(c.last, c.serv.sigPk, c.serv.hashSuite, c.serv.chainMode, chainProof, sig)
 	if errb {
 		err = ktcore.BlameServFull
 		return
@@ -103,11 +143,11 @@ func (c *Client) SelfMon() (ep uint64, isChanged bool, err ktcore.Blame) {
 		err = ktcore.BlameServFull
 		return
 	}
-	if checkHist(c.serv.vrfPk, c.uid, c.pend.ver, next.dig, hist) {
+	if checkHist(c.serv.vrfPk, c.serv.hashSuite, c.uid, c.pend.ver, next.dig, hist) {
 		err = ktcore.BlameServFull
 		return
 	}
-	if checkNonMemb(c.serv.vrfPk, c.uid, boundVer, next.dig, bound) {
+	if checkNonMemb(c.serv.vrfPk, c.serv.hashSuite, c.uid, boundVer, next.dig, bound) {
 		err = ktcore.BlameServFull
 		return
 	}
@@ -181,8 +221,8 @@ func (c *Client) Audit(adtrAddr uint64, adtrPk cryptoffi.SigPublicKey) (startEp
 
 	// vrf evidence.
 	vrfPkB := cryptoffi.VrfPublicKeyEncode(c.serv.vrfPk)
-	if !bytes.Equal(vrfPkB, vrf.VrfPk) {
-		evid = &ktcore.Evid{Vrf: &ktcore.EvidVrf{VrfPk0: vrfPkB, Sig0: c.serv.vrfSig, VrfPk1: vrf.VrfPk, Sig1: vrf.ServSig}}
+	if c.serv.vrfSuite != vrf.VrfSuite || c.serv.hashSuite != vrf.HashSuite || !bytes.Equal(vrfPkB, vrf.VrfPk) {
+		evid = &ktcore.Evid{Vrf: &ktcore.EvidVrf{VrfSuite0: c.serv.vrfSuite, HashSuite0: c.serv.hashSuite, VrfPk0: vrfPkB, Sig0: c.serv.vrfSig, VrfSuite1: vrf.VrfSuite, HashSuite1: vrf.HashSuite, VrfPk1: vrf.VrfPk, Sig1: vrf.ServSig}}
 		err = ktcore.BlameServSig
 		return
 	}
@@ -195,33 +235,120 @@ func (c *Client) Audit(adtrAddr uint64, adtrPk cryptoffi.SigPublicKey) (startEp
 	return
 }
 
+// Checkpoint skips ahead to the auditor's latest checkpoint.
+// only [hashchain.ModeLog] has compact skips: its skip proof is
+// logarithmic, which suits light clients.
+// for [hashchain.ModeLinear], the skip proof has every dig
+// along the way, so light clients should use a ModeLog server.
+// if the checkpoint conflicts with our epoch, it returns evidence.
+func (c *Client) Checkpoint(adtrAddr uint64, adtrPk cryptoffi.SigPublicKey) (ep uint64, err ktcore.Blame, evid *ktcore.Evid) {
+	cli := advrpc.Dial(adtrAddr)
+	last := c.last
+	cp, err := auditor.CallCheckpoint(cli)
+	if err != ktcore.BlameNone {
+		return
+	}
+	if checkAuditLink(c.serv.sigPk, adtrPk, cp.Epoch, cp.Link) {
+		err = ktcore.BlameAdtrFull
+		return
+	}
+	ep = last.epoch
+
+	// link evidence.
+	if cp.Epoch == last.epoch {
+		if !bytes.Equal(last.link, cp.Link.Link) {
+			evid = &ktcore.Evid{Link: &ktcore.EvidLink{Epoch: last.epoch, Link0: last.link, Sig0: last.sig, Link1: cp.Link.Link, Sig1: cp.Link.ServSig}}
+			err = ktcore.BlameServSig
+		}
+		return
+	}
+	// auditor is lagging, but our epoch should still extend it.
+	if cp.Epoch < last.epoch {
+		proof, err0 := server.CallSkip(c.serv.cli, cp.Epoch, last.epoch)
+		if err0 != ktcore.BlameNone {
+			err = err0
+			return
+		}
+		_, link, errb := checkSkip(c.serv.hashSuite, c.serv.chainMode, cp.Epoch, cp.Link.Link, last.epoch, proof)
+		if errb || !bytes.Equal(link, last.link) {
+			err, evid = c.getLinkEvid(cli, adtrPk)
+			return
+		}
+		return
+	}
+
+	proof, err := server.CallSkip(c.serv.cli, last.epoch, cp.Epoch)
+	if err != ktcore.BlameNone {
+		return
+	}
+	dig, link, errb := checkSkip(c.serv.hashSuite, c.serv.chainMode, last.epoch, last.link, cp.Epoch, proof)
+	if errb || !bytes.Equal(link, cp.Link.Link) {
+		err, evid = c.getLinkEvid(cli, adtrPk)
+		return
+	}
+	c.last = &epoch{epoch: cp.Epoch, dig: dig, link: link, sig: cp.Link.ServSig}
+	ep = cp.Epoch
+	return
+}
+
+// getLinkEvid is for when the server's skip proof doesn't connect
+// our epoch and the auditor's checkpoint.
+// that might be a bad proof, or two signed chains.
+// to tell, it gets the auditor's link at our epoch, like [Client.Audit].
+// if the server signed a different link there, it returns evidence.
+func (c *Client) getLinkEvid(cli *advrpc.Client, adtrPk cryptoffi.SigPublicKey) (err ktcore.Blame, evid *ktcore.Evid) {
+	last := c.last
+	// the auditor might not have our epoch yet,
+	// so a failed get doesn't tell us who's at fault.
+	_, _, currLink, _, err0 := auditor.CallGet(cli, last.epoch)
+	if err0 != ktcore.BlameNone {
+		err = ktcore.BlameUnknown
+		return
+	}
+	if checkAuditLink(c.serv.sigPk, adtrPk, last.epoch, currLink) {
+		err = ktcore.BlameAdtrFull
+		return
+	}
+	if !bytes.Equal(last.link, currLink.Link) {
+		evid = &ktcore.Evid{Link: &ktcore.EvidLink{Epoch: last.epoch, Link0: last.link, Sig0: last.sig, Link1: currLink.Link, Sig1: currLink.ServSig}}
+		err = ktcore.BlameServSig
+		return
+	}
+	err = ktcore.BlameServFull
+	return
+}
+
 func New(uid, servAddr uint64, servPk cryptoffi.SigPublicKey) (c *Client, ep uint64, err ktcore.Blame) {
 	cli := advrpc.Dial(servAddr)
 	chain, vrf, err := server.CallStart(cli)
 	if err != ktcore.BlameNone {
 		return
 	}
-	startEp, startDig, startLink, errb := auditor.CheckStartChain(servPk, chain)
+	vrfPk, errb := auditor.CheckStartVrf(servPk, vrf)
 	if errb {
 		err = ktcore.BlameServFull
 		return
 	}
-	vrfPk, errb := auditor.CheckStartVrf(servPk, vrf)
+	startEp, startDig, startTail, errb := auditor.CheckStartChain(servPk, vrf.HashSuite, chain)
 	if errb {
 		err = ktcore.BlameServFull
 		return
 	}
 
 	pendingPut := &nextVer{}
-	last := &epoch{epoch: startEp, dig: startDig, link: startLink, sig: chain.LinkSig}
-	serv := &serv{cli: cli, sigPk: servPk, vrfPk: vrfPk, vrfSig: vrf.VrfSig}
+	last := &epoch{epoch: startEp, dig: startDig, link: startTail.Link(), sig: chain.LinkSig}
+	serv := &serv{cli: cli, sigPk: servPk, vrfSuite: vrf.VrfSuite, hashSuite: vrf.HashSuite, chainMode: chain.ChainMode, vrfPk: vrfPk, vrfSig: vrf.VrfSig}
 	c = &Client{uid: uid, pend: pendingPut, last: last, serv: serv}
 	ep, _, err = c.SelfMon()
 	return
 }
 
-func getNextEp(prev *epoch, sigPk cryptoffi.SigPublicKey, chainProof, sig []byte) (next *epoch, err bool) {
-	extLen, nextDig, nextLink, err := hashchain.Verify(prev.link, chainProof)
+func getNextEp(prev *epoch, sigPk cryptoffi.SigPublicKey, hashSuite, chainMode byte, chainProof, sig []byte) (next *epoch, err bool) {
+	if !std.SumNoOverflow(prev.epoch, 1) {
+		err = true
+		return
+	}
+	extLen, nextDig, nextLink, err := hashchain.Verify(chainMode, hashSuite, prev.epoch+1, prev.link, chainProof)
 	if err {
 		return
 	}
@@ -241,13 +368,36 @@ func getNextEp(prev *epoch, sigPk cryptoffi.SigPublicKey, chainProof, sig []byte
 	return
 }
 
-func checkMemb(vrfPk *cryptoffi.VrfPublicKey, uid, ver uint64, dig []byte, memb *ktcore.Memb) (err bool) {
-	label, err := ktcore.CheckMapLabel(vrfPk, uid, ver, memb.LabelProof)
+// checkSkip returns the dig and link at ep, given the link at prevEp.
+// it expects prevEp < ep.
+func checkSkip(hashSuite, chainMode byte, prevEp uint64, prevLink []byte, ep uint64, proof []byte) (dig, link []byte, err bool) {
+	extLen, dig, link, err := hashchain.Verify(chainMode, hashSuite, prevEp+1, prevLink, proof)
 	if err {
 		return
 	}
-	mapVal := ktcore.GetMapVal(memb.PkOpen.Val, memb.PkOpen.Rand)
-	dig0, err := merkle.VerifyMemb(label, mapVal, memb.MerkleProof)
+	if extLen != ep-prevEp {
+		err = true
+		return
+	}
+	return
+}
+
+// checkIncl returns the dig at epoch, which is at or before last.
+func checkIncl(hashSuite, chainMode byte, last *epoch, ep uint64, inclProof []byte) (dig []byte, err bool) {
+	dig, link, err := hashchain.VerifyIncl(chainMode, hashSuite, ep, last.epoch+1, inclProof)
+	if err {
+		return
+	}
+	if !bytes.Equal(link, last.link) {
+		err = true
+		return
+	}
+	return
+}
+
+func checkMemb(hashSuite byte, label, dig []byte, memb *ktcore.Memb) (err bool) {
+	mapVal := ktcore.GetMapVal(hashSuite, memb.PkOpen.Val, memb.PkOpen.Rand)
+	dig0, err := merkle.VerifyMemb(hashSuite, label, mapVal, memb.MerkleProof)
 	if err {
 		return
 	}
@@ -258,21 +408,31 @@ func checkMemb(vrfPk *cryptoffi.VrfPublicKey, uid, ver uint64, dig []byte, memb
 	return
 }
 
-func checkHist(vrfPk *cryptoffi.VrfPublicKey, uid, prefixLen uint64, dig []byte, hist []*ktcore.Memb) (err bool) {
-	for ver, memb := range hist {
-		if err = checkMemb(vrfPk, uid, prefixLen+uint64(ver), dig, memb); err {
+func checkHist(vrfPk *cryptoffi.VrfPublicKey, hashSuite byte, uid, prefixLen uint64, dig []byte, hist []*ktcore.Memb) (err bool) {
+	// check the VRF proofs together, which spreads them across cores.
+	// they dominate verification time.
+	labelProofs := make([][]byte, 0, len(hist))
+	for _, memb := range hist {
+		labelProofs = append(labelProofs, memb.LabelProof)
+	}
+	labels, err := ktcore.CheckMapLabels(vrfPk, uid, prefixLen, labelProofs)
+	if err {
+		return
+	}
+	for i, memb := range hist {
+		if err = checkMemb(hashSuite, labels[i], dig, memb); err {
 			return
 		}
 	}
 	return
 }
 
-func checkNonMemb(vrfPk *cryptoffi.VrfPublicKey, uid, ver uint64, dig []byte, nonMemb *ktcore.NonMemb) (err bool) {
+func checkNonMemb(vrfPk *cryptoffi.VrfPublicKey, hashSuite byte, uid, ver uint64, dig []byte, nonMemb *ktcore.NonMemb) (err bool) {
 	label, err := ktcore.CheckMapLabel(vrfPk, uid, ver, nonMemb.LabelProof)
 	if err {
 		return
 	}
-	dig0, err := merkle.VerifyNonMemb(label, nonMemb.MerkleProof)
+	dig0, err := merkle.VerifyNonMemb(hashSuite, label, nonMemb.MerkleProof)
 	if err {
 		return
 	}
@@ -294,10 +454,10 @@ func checkAuditLink(servPk, adtrPk cryptoffi.SigPublicKey, ep uint64, link *audi
 }
 
 func checkAuditVrf(servPk, adtrPk cryptoffi.SigPublicKey, vrf *auditor.SignedVrf) (err bool) {
-	if ktcore.VerifyVrfSig(adtrPk, vrf.VrfPk, vrf.AdtrSig) {
+	if ktcore.VerifyVrfSig(adtrPk, vrf.VrfSuite, vrf.HashSuite, vrf.VrfPk, vrf.AdtrSig) {
 		return true
 	}
-	if ktcore.VerifyVrfSig(servPk, vrf.VrfPk, vrf.ServSig) {
+	if ktcore.VerifyVrfSig(servPk, vrf.VrfSuite, vrf.HashSuite, vrf.VrfPk, vrf.ServSig) {
 		return true
 	}
 	return
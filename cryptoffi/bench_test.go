@@ -71,12 +71,24 @@ func TestBenchHash(t *testing.T) {
 }
 
 func TestBenchVrfProve(t *testing.T) {
-	sk := VrfGenerateKey()
+	benchutil.Report(benchVrfProve(VrfSuiteTai))
+}
+
+func TestBenchVrfProveR255(t *testing.T) {
+	benchutil.Report(benchVrfProve(VrfSuiteR255))
+}
+
+func TestBenchVrfProveEll2(t *testing.T) {
+	benchutil.Report(benchVrfProve(VrfSuiteEll2))
+}
+
+func benchVrfProve(suite byte) (nOps int, ms []*benchutil.Metric) {
+	sk := VrfGenerateKey(suite)
 	var seed [32]byte
 	rnd := rand.NewChaCha8(seed)
 	data := make([]byte, 16)
 
-	nOps := 50_000
+	nOps = 50_000
 	start := time.Now()
 	for i := 0; i < nOps; i++ {
 		rnd.Read(data)
@@ -86,16 +98,29 @@ func TestBenchVrfProve(t *testing.T) {
 
 	m0 := float64(total.Microseconds()) / float64(nOps)
 	m1 := float64(total.Milliseconds())
-	benchutil.Report(nOps, []*benchutil.Metric{
+	ms = []*benchutil.Metric{
 		{N: m0, Unit: "us/op"},
 		{N: m1, Unit: "total(ms)"},
-	})
+	}
+	return
 }
 
 func TestBenchVrfVerify(t *testing.T) {
-	sk := VrfGenerateKey()
+	benchutil.Report(benchVrfVerify(t, VrfSuiteTai))
+}
+
+func TestBenchVrfVerifyR255(t *testing.T) {
+	benchutil.Report(benchVrfVerify(t, VrfSuiteR255))
+}
+
+func TestBenchVrfVerifyEll2(t *testing.T) {
+	benchutil.Report(benchVrfVerify(t, VrfSuiteEll2))
+}
+
+func benchVrfVerify(t *testing.T, suite byte) (nOps int, ms []*benchutil.Metric) {
+	sk := VrfGenerateKey(suite)
 	pkB := sk.PublicKey()
-	pk, err := VrfPublicKeyDecode(pkB)
+	pk, err := VrfPublicKeyDecode(suite, pkB)
 	if err {
 		t.Fatal()
 	}
@@ -103,7 +128,7 @@ func TestBenchVrfVerify(t *testing.T) {
 	rnd := rand.NewChaCha8(seed)
 	data := make([]byte, 16)
 
-	nOps := 50_000
+	nOps = 50_000
 	var total time.Duration
 	for i := 0; i < nOps; i++ {
 		rnd.Read(data)
@@ -116,19 +141,88 @@ func TestBenchVrfVerify(t *testing.T) {
 
 	m0 := float64(total.Microseconds()) / float64(nOps)
 	m1 := float64(total.Milliseconds())
-	benchutil.Report(nOps, []*benchutil.Metric{
+	ms = []*benchutil.Metric{
 		{N: m0, Unit: "us/op"},
 		{N: m1, Unit: "total(ms)"},
-	})
+	}
+	return
+}
+
+func TestBenchVrfVerifyMany(t *testing.T) {
+	benchutil.Report(benchVrfVerifyMany(t, VrfSuiteTai))
+}
+
+func TestBenchVrfVerifyManyR255(t *testing.T) {
+	benchutil.Report(benchVrfVerifyMany(t, VrfSuiteR255))
+}
+
+func TestBenchVrfVerifyManyEll2(t *testing.T) {
+	benchutil.Report(benchVrfVerifyMany(t, VrfSuiteEll2))
+}
+
+func benchVrfVerifyMany(t *testing.T, suite byte) (nOps int, ms []*benchutil.Metric) {
+	sk := VrfGenerateKey(suite)
+	pkB := sk.PublicKey()
+	pk, err := VrfPublicKeyDecode(suite, pkB)
+	if err {
+		t.Fatal()
+	}
+	var seed [32]byte
+	rnd := rand.NewChaCha8(seed)
+	// roughly the size of a long key history.
+	batchSz := 64
+	nBatches := 1_000
+
+	var total time.Duration
+	for i := 0; i < nBatches; i++ {
+		data := make([][]byte, 0, batchSz)
+		proofs := make([][]byte, 0, batchSz)
+		for j := 0; j < batchSz; j++ {
+			d := make([]byte, 16)
+			rnd.Read(d)
+			_, p := sk.Prove(d)
+			data = append(data, d)
+			proofs = append(proofs, p)
+		}
+
+		t0 := time.Now()
+		if _, err = pk.VerifyMany(data, proofs); err {
+			t.Fatal()
+		}
+		total += time.Since(t0)
+	}
+
+	nOps = batchSz * nBatches
+	m0 := float64(total.Microseconds()) / float64(nOps)
+	m1 := float64(total.Milliseconds())
+	ms = []*benchutil.Metric{
+		{N: m0, Unit: "us/op"},
+		{N: m1, Unit: "total(ms)"},
+	}
+	return
 }
 
 func TestBenchVrfSize(t *testing.T) {
-	sk := VrfGenerateKey()
+	benchutil.Report(benchVrfSize(VrfSuiteTai))
+}
+
+func TestBenchVrfSizeR255(t *testing.T) {
+	benchutil.Report(benchVrfSize(VrfSuiteR255))
+}
+
+func TestBenchVrfSizeEll2(t *testing.T) {
+	benchutil.Report(benchVrfSize(VrfSuiteEll2))
+}
+
+func benchVrfSize(suite byte) (nOps int, ms []*benchutil.Metric) {
+	sk := VrfGenerateKey(suite)
 	data := make([]byte, 16)
 	_, p := sk.Prove(data)
-	benchutil.Report(0, []*benchutil.Metric{
+	ms = []*benchutil.Metric{
 		{N: float64(len(p)), Unit: "B"},
-	})
+		{N: float64(len(sk.PublicKey())), Unit: "B(pk)"},
+	}
+	return
 }
 
 func TestBenchSigGenVer(t *testing.T) {
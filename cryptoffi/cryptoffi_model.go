@@ -3,23 +3,48 @@
 package cryptoffi
 
 import (
+	"bytes"
 	"crypto/ed25519"
 	"crypto/rand"
 
 	"github.com/sanjit-bhat/pav/cryptoffi/ffi"
 	"github.com/sanjit-bhat/pav/cryptoffi/vrf"
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/chacha20poly1305"
 )
 
 const (
 	HashLen uint64 = 32
 )
 
+// hash suites, chosen at directory creation.
+// all suites have [HashLen] outputs.
+const (
+	// HashSuiteSha256 is SHA-256.
+	HashSuiteSha256 byte = iota
+	// HashSuiteSha512_256 is SHA-512/256.
+	// it's faster than SHA-256 on 64-bit machines without SHA extensions.
+	HashSuiteSha512_256
+	// HashSuiteBlake3 is BLAKE3.
+	HashSuiteBlake3
+)
+
 type Hasher struct {
-	b []byte
+	suite byte
+	b     []byte
+}
+
+// NewHasher expects a valid suite.
+func NewHasher(suite byte) *Hasher {
+	if CheckHashSuite(suite) {
+		panic("cryptoffi: NewHasher suite")
+	}
+	return &Hasher{suite: suite}
 }
 
-func NewHasher() *Hasher {
-	return &Hasher{}
+// CheckHashSuite errors if suite is unknown.
+func CheckHashSuite(suite byte) (err bool) {
+	return suite > HashSuiteBlake3
 }
 
 func (hr *Hasher) Write(b []byte) {
@@ -27,11 +52,16 @@ func (hr *Hasher) Write(b []byte) {
 }
 
 func (hr *Hasher) Sum(b []byte) (hash []byte) {
-	return append(b, []byte(ffi.TrustedHash(string(hr.b)))...)
+	return append(b, []byte(ffi.TrustedHash(hr.suite, string(hr.b)))...)
 }
 
 // # Signature
 
+const (
+	// SigLen is the length of a signature.
+	SigLen uint64 = ed25519.SignatureSize
+)
+
 // SigPrivateKey has an unexported sk, which can't be accessed outside
 // the package, without reflection or unsafe.
 type SigPrivateKey struct {
@@ -59,30 +89,70 @@ func (pk SigPublicKey) Verify(data []byte, sig []byte) (err bool) {
 	return !ed25519.Verify(ed25519.PublicKey(pk), data, sig)
 }
 
+// SigPrivateKeyEncode exports the seed of sk.
+// callers should [Seal] it before it leaves memory.
+func SigPrivateKeyEncode(sk *SigPrivateKey) []byte {
+	return sk.sk.Seed()
+}
+
+// SigPrivateKeyDecode imports a seed from [SigPrivateKeyEncode].
+func SigPrivateKeyDecode(b []byte) (pk SigPublicKey, sk *SigPrivateKey, err bool) {
+	if len(b) != ed25519.SeedSize {
+		err = true
+		return
+	}
+	sk0 := ed25519.NewKeyFromSeed(b)
+	pk = SigPublicKey(bytes.Clone(sk0[ed25519.SeedSize:]))
+	sk = &SigPrivateKey{sk: sk0}
+	return
+}
+
 // # VRF
 
+// VRF suites, chosen at key generation.
+// all suites have the same output and proof lengths.
+const (
+	// VrfSuiteTai is ECVRF-EDWARDS25519-SHA512-TAI from [RFC 9381].
+	VrfSuiteTai byte = iota
+	// VrfSuiteR255 is the same ECVRF construction over ristretto255.
+	// it has constant-time hash-to-curve and a canonical point encoding.
+	VrfSuiteR255
+	// VrfSuiteEll2 is ECVRF-EDWARDS25519-SHA512-ELL2 from [RFC 9381].
+	// it has constant-time hash-to-curve, and shares keys with [VrfSuiteTai].
+	VrfSuiteEll2
+)
+
+// VrfPkLen is the length of a VRF pk, in all suites.
+const VrfPkLen uint64 = 32
+
 // VrfPrivateKey has an unexported sk, which can't be accessed outside
 // the package, without reflection or unsafe.
 // we use a fork of ProtonMail's vrf, which implements
-// ECVRF-EDWARDS25519-SHA512-TAI from [RFC 9381].
-// TODO: maybe switch to https://pkg.go.dev/filippo.io/mostly-harmless/vrf-r255.
+// ECVRF-EDWARDS25519-SHA512-TAI from [RFC 9381],
+// extended with a ristretto255 suite.
 // ecvrf satisfies full uniqueness, i.e., determinism under adversarial pks.
 // this is the only property that pav requires.
 // [RFC 9381]: https://datatracker.ietf.org/doc/rfc9381/
 type VrfPrivateKey struct {
-	sk *vrf.PrivateKey
+	sk    *vrf.PrivateKey
+	suite byte
 }
 
 type VrfPublicKey struct {
 	pk *vrf.PublicKey
 }
 
-func VrfGenerateKey() *VrfPrivateKey {
-	sk, err := vrf.GenerateKey(nil)
-	if err != nil {
+// VrfGenerateKey expects a valid suite.
+func VrfGenerateKey(suite byte) *VrfPrivateKey {
+	s, err := getVrfSuite(suite)
+	if err {
+		panic("cryptoffi: VrfGenerateKey suite")
+	}
+	sk, errg := s.GenerateKey(nil)
+	if errg != nil {
 		panic("cryptoffi: VrfGenerateKey")
 	}
-	return &VrfPrivateKey{sk: sk}
+	return &VrfPrivateKey{sk: sk, suite: suite}
 }
 
 // Prove evaluates the VRF on data.
@@ -123,19 +193,76 @@ func (pk *VrfPublicKey) Verify(data, proof []byte) (out []byte, err bool) {
 	return
 }
 
+// VerifyMany verifies each data[i] against proofs[i].
+// it's equivalent to calling [VrfPublicKey.Verify] on each pair.
+// it isn't a batch verification: each proof is checked on its own,
+// with variable-time scalar mults, and pairs are spread across cores.
+// the RFC proof format can't be batched, see [vrf.PublicKey.VerifyMany].
+// it errors if any pair fails to verify.
+func (pk *VrfPublicKey) VerifyMany(data, proofs [][]byte) (outs [][]byte, err bool) {
+	ok, outs, errg := pk.pk.VerifyMany(data, proofs)
+	if errg != nil {
+		err = true
+		return
+	}
+	if !ok {
+		err = true
+		return
+	}
+	for i := range outs {
+		outs[i] = outs[i][:HashLen]
+	}
+	return
+}
+
 func (sk *VrfPrivateKey) PublicKey() []byte {
 	return sk.sk.PublicKey()
 }
 
+// Suite returns the VRF suite of sk.
+func (sk *VrfPrivateKey) Suite() byte {
+	return sk.suite
+}
+
+// VrfPrivateKeyEncode exports the seed of sk.
+// callers should [Seal] it before it leaves memory.
+func VrfPrivateKeyEncode(sk *VrfPrivateKey) []byte {
+	return sk.sk.Seed()
+}
+
+// VrfPrivateKeyDecode imports a seed from [VrfPrivateKeyEncode],
+// re-deriving the sk for suite.
+func VrfPrivateKeyDecode(suite byte, b []byte) (sk *VrfPrivateKey, err bool) {
+	s, err := getVrfSuite(suite)
+	if err {
+		return
+	}
+	if len(b) != ed25519.SeedSize {
+		err = true
+		return
+	}
+	sk0, errg := s.GenerateKey(bytes.NewReader(b))
+	if errg != nil {
+		err = true
+		return
+	}
+	sk = &VrfPrivateKey{sk: sk0, suite: suite}
+	return
+}
+
 // VrfPublicKeyEncodes encodes a valid pk as bytes.
 func VrfPublicKeyEncode(pk *VrfPublicKey) []byte {
 	return pk.pk.Bytes()
 }
 
-// VrfPublicKeyDecode decodes b.
+// VrfPublicKeyDecode decodes b as a pk from suite.
 // it performs the ECVRF_validate_key checks to run even on adversarial pks.
-func VrfPublicKeyDecode(b []byte) (pk *VrfPublicKey, err bool) {
-	pk0, errg := vrf.NewPublicKey(b)
+func VrfPublicKeyDecode(suite byte, b []byte) (pk *VrfPublicKey, err bool) {
+	s, err := getVrfSuite(suite)
+	if err {
+		return
+	}
+	pk0, errg := s.NewPublicKey(b)
 	if errg != nil {
 		err = true
 		return
@@ -144,6 +271,20 @@ func VrfPublicKeyDecode(b []byte) (pk *VrfPublicKey, err bool) {
 	return
 }
 
+func getVrfSuite(suite byte) (s *vrf.Suite, err bool) {
+	switch suite {
+	case VrfSuiteTai:
+		s = vrf.Edwards25519TAI
+	case VrfSuiteR255:
+		s = vrf.Ristretto255
+	case VrfSuiteEll2:
+		s = vrf.Edwards25519ELL2
+	default:
+		err = true
+	}
+	return
+}
+
 // # Random
 
 // RandBytes returns n random bytes.
@@ -152,3 +293,52 @@ func RandBytes(n uint64) []byte {
 	rand.Read(b)
 	return b
 }
+
+// # Secret box
+
+const (
+	// KekLen is the length of a key-encryption key.
+	KekLen uint64 = chacha20poly1305.KeySize
+	// KdfSaltLen is the length of a [KekFromPassword] salt.
+	KdfSaltLen uint64 = 16
+)
+
+// Seal encrypts msg and authenticates it, along with aad, under kek.
+// it uses XChaCha20-Poly1305 with a random nonce, prepended to ct.
+// it expects a [KekLen] kek.
+func Seal(kek, aad, msg []byte) (ct []byte) {
+	aead, err := chacha20poly1305.NewX(kek)
+	if err != nil {
+		panic("cryptoffi: Seal kek")
+	}
+	nonce := RandBytes(chacha20poly1305.NonceSizeX)
+	return aead.Seal(nonce, nonce, msg, aad)
+}
+
+// Open undoes [Seal].
+// it errors if ct or aad was tampered with, or if kek is wrong.
+func Open(kek, aad, ct []byte) (msg []byte, err bool) {
+	aead, errg := chacha20poly1305.NewX(kek)
+	if errg != nil {
+		err = true
+		return
+	}
+	if len(ct) < chacha20poly1305.NonceSizeX {
+		err = true
+		return
+	}
+	nonce := ct[:chacha20poly1305.NonceSizeX]
+	msg, errg = aead.Open(nil, nonce, ct[chacha20poly1305.NonceSizeX:], aad)
+	if errg != nil {
+		err = true
+		return
+	}
+	return
+}
+
+// KekFromPassword derives a [KekLen] key from password with Argon2id,
+// using the RFC 9106 second recommended params (t=3, m=64MiB, p=4).
+// salt should be [KdfSaltLen] random bytes, stored alongside the ct.
+func KekFromPassword(password, salt []byte) (kek []byte) {
+	return argon2.IDKey(password, salt, 3, 64*1024, 4, uint32(KekLen))
+}
@@ -2,16 +2,27 @@ package cryptoffi
 
 import (
 	"bytes"
+	"encoding/hex"
 	"testing"
+
+	"github.com/sanjit-bhat/pav/cryptoffi/ffi"
 )
 
+var hashSuites = []byte{HashSuiteSha256, HashSuiteSha512_256, HashSuiteBlake3}
+
 func TestHash(t *testing.T) {
+	for _, suite := range hashSuites {
+		testHash(t, suite)
+	}
+}
+
+func testHash(t *testing.T, suite byte) {
 	// same hashes for same input.
 	d1 := []byte("d1")
-	hr1 := NewHasher()
+	hr1 := NewHasher(suite)
 	hr1.Write(d1)
 	h1 := hr1.Sum(nil)
-	hr2 := NewHasher()
+	hr2 := NewHasher(suite)
 	hr2.Write(d1)
 	h2 := hr2.Sum(nil)
 	if !bytes.Equal(h1, h2) {
@@ -23,7 +34,7 @@ func TestHash(t *testing.T) {
 
 	// diff hashes for diff inputs.
 	d2 := []byte("d2")
-	hr3 := NewHasher()
+	hr3 := NewHasher(suite)
 	hr3.Write(d2)
 	h3 := hr3.Sum(nil)
 	if bytes.Equal(h1, h3) {
@@ -31,6 +42,45 @@ func TestHash(t *testing.T) {
 	}
 }
 
+func TestHashSuites(t *testing.T) {
+	// known answers for the empty string.
+	exp := []string{
+		"e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855",
+		"c672b8d1ef56ed28ab87c3622c5114069bdd3ad7b8f9737498d0c01ecef0967a",
+		"af1349b9f5f9a1a6a0404dea36dcc9499bcb25c9adc112b7cc9a93cae41f3262",
+	}
+	for i, suite := range hashSuites {
+		if CheckHashSuite(suite) {
+			t.Fatal()
+		}
+		h := NewHasher(suite).Sum(nil)
+		if hex.EncodeToString(h) != exp[i] {
+			t.Fatal(suite)
+		}
+	}
+	if !CheckHashSuite(HashSuiteBlake3 + 1) {
+		t.Fatal()
+	}
+}
+
+// TestTrustedHash ties the ffi suite numbers to the suite consts.
+func TestTrustedHash(t *testing.T) {
+	d := []byte("d")
+	for _, suite := range hashSuites {
+		hr := NewHasher(suite)
+		hr.Write(d)
+		if ffi.TrustedHash(suite, string(d)) != string(hr.Sum(nil)) {
+			t.Fatal(suite)
+		}
+	}
+	defer func() {
+		if recover() == nil {
+			t.Fatal()
+		}
+	}()
+	ffi.TrustedHash(HashSuiteBlake3+1, string(d))
+}
+
 func TestSig(t *testing.T) {
 	// verify true.
 	d := []byte("d")
@@ -59,10 +109,18 @@ func TestSig(t *testing.T) {
 	}
 }
 
+var vrfSuites = []byte{VrfSuiteTai, VrfSuiteR255, VrfSuiteEll2}
+
 func TestVRF(t *testing.T) {
-	sk0 := VrfGenerateKey()
+	for _, suite := range vrfSuites {
+		testVRF(t, suite)
+	}
+}
+
+func testVRF(t *testing.T, suite byte) {
+	sk0 := VrfGenerateKey(suite)
 	pkB0 := sk0.PublicKey()
-	pk0, err := VrfPublicKeyDecode(pkB0)
+	pk0, err := VrfPublicKeyDecode(suite, pkB0)
 	if err {
 		t.Fatal()
 	}
@@ -95,9 +153,9 @@ func TestVRF(t *testing.T) {
 	}
 
 	// verify false for bad pk.
-	sk1 := VrfGenerateKey()
+	sk1 := VrfGenerateKey(suite)
 	pkB1 := sk1.PublicKey()
-	pk1, err := VrfPublicKeyDecode(pkB1)
+	pk1, err := VrfPublicKeyDecode(suite, pkB1)
 	if err {
 		t.Fatal()
 	}
@@ -112,3 +170,180 @@ func TestVRF(t *testing.T) {
 		t.Fatal()
 	}
 }
+
+func TestVRFBatch(t *testing.T) {
+	for _, suite := range vrfSuites {
+		testVRFBatch(t, suite)
+	}
+}
+
+func testVRFBatch(t *testing.T, suite byte) {
+	sk0 := VrfGenerateKey(suite)
+	pk0, err := VrfPublicKeyDecode(suite, sk0.PublicKey())
+	if err {
+		t.Fatal()
+	}
+	var ds, ps, outs [][]byte
+	for i := 0; i < 10; i++ {
+		d := []byte{byte(i)}
+		o, p := sk0.Prove(d)
+		ds = append(ds, d)
+		ps = append(ps, p)
+		outs = append(outs, o)
+	}
+
+	// verify true, with same outputs as Prove.
+	outs0, err := pk0.VerifyMany(ds, ps)
+	if err {
+		t.Fatal()
+	}
+	if len(outs0) != len(outs) {
+		t.Fatal()
+	}
+	for i := range outs {
+		if !bytes.Equal(outs[i], outs0[i]) {
+			t.Fatal()
+		}
+	}
+
+	// verify false for bad pk.
+	sk1 := VrfGenerateKey(suite)
+	pk1, err := VrfPublicKeyDecode(suite, sk1.PublicKey())
+	if err {
+		t.Fatal()
+	}
+	if _, err = pk1.VerifyMany(ds, ps); !err {
+		t.Fatal()
+	}
+
+	// verify false for one bad proof.
+	ps[5] = bytes.Clone(ps[5])
+	ps[5][0] = ^ps[5][0]
+	if _, err = pk0.VerifyMany(ds, ps); !err {
+		t.Fatal()
+	}
+}
+
+func TestVRFSuites(t *testing.T) {
+	sk0 := VrfGenerateKey(VrfSuiteR255)
+	if sk0.Suite() != VrfSuiteR255 {
+		t.Fatal()
+	}
+	pkB0 := sk0.PublicKey()
+	for _, suite := range []byte{VrfSuiteTai, VrfSuiteR255, VrfSuiteEll2} {
+		if uint64(len(VrfGenerateKey(suite).PublicKey())) != VrfPkLen {
+			t.Fatal()
+		}
+	}
+
+	// decode false for unknown suite.
+	if _, err := VrfPublicKeyDecode(VrfSuiteEll2+1, pkB0); !err {
+		t.Fatal()
+	}
+
+	// proofs don't verify across suites, even for pks that decode in both.
+	d := []byte("d")
+	_, p := sk0.Prove(d)
+	sk1 := VrfGenerateKey(VrfSuiteTai)
+	pk1, err := VrfPublicKeyDecode(VrfSuiteTai, sk1.PublicKey())
+	if err {
+		t.Fatal()
+	}
+	if _, err = pk1.Verify(d, p); !err {
+		t.Fatal()
+	}
+
+	// TAI and ELL2 share pks, but not proofs.
+	pk2, err := VrfPublicKeyDecode(VrfSuiteEll2, sk1.PublicKey())
+	if err {
+		t.Fatal()
+	}
+	_, p1 := sk1.Prove(d)
+	if _, err = pk2.Verify(d, p1); !err {
+		t.Fatal()
+	}
+}
+
+func TestKeyExport(t *testing.T) {
+	d := []byte("d")
+	pk, sk := SigGenerateKey()
+	pk0, sk0, err := SigPrivateKeyDecode(SigPrivateKeyEncode(sk))
+	if err {
+		t.Fatal()
+	}
+	if !bytes.Equal(pk, pk0) {
+		t.Fatal()
+	}
+	if pk.Verify(d, sk0.Sign(d)) {
+		t.Fatal()
+	}
+	if _, _, err = SigPrivateKeyDecode(d); !err {
+		t.Fatal()
+	}
+
+	for _, suite := range vrfSuites {
+		vsk := VrfGenerateKey(suite)
+		vsk0, err := VrfPrivateKeyDecode(suite, VrfPrivateKeyEncode(vsk))
+		if err {
+			t.Fatal()
+		}
+		if vsk0.Suite() != suite {
+			t.Fatal()
+		}
+		if !bytes.Equal(vsk.PublicKey(), vsk0.PublicKey()) {
+			t.Fatal()
+		}
+		o, p := vsk.Prove(d)
+		o0, p0 := vsk0.Prove(d)
+		if !bytes.Equal(o, o0) || !bytes.Equal(p, p0) {
+			t.Fatal()
+		}
+	}
+	if _, err := VrfPrivateKeyDecode(VrfSuiteEll2+1, make([]byte, 32)); !err {
+		t.Fatal()
+	}
+}
+
+func TestSecretBox(t *testing.T) {
+	kek := RandBytes(KekLen)
+	aad := []byte("aad")
+	msg := []byte("msg")
+	ct := Seal(kek, aad, msg)
+	if bytes.Contains(ct, msg) {
+		t.Fatal()
+	}
+	msg0, err := Open(kek, aad, ct)
+	if err {
+		t.Fatal()
+	}
+	if !bytes.Equal(msg, msg0) {
+		t.Fatal()
+	}
+
+	// open false for bad kek, aad, and ct.
+	if _, err = Open(RandBytes(KekLen), aad, ct); !err {
+		t.Fatal()
+	}
+	if _, err = Open(kek, []byte("aad1"), ct); !err {
+		t.Fatal()
+	}
+	ct0 := bytes.Clone(ct)
+	ct0[len(ct0)-1] ^= 1
+	if _, err = Open(kek, aad, ct0); !err {
+		t.Fatal()
+	}
+	if _, err = Open(kek, aad, ct[:4]); !err {
+		t.Fatal()
+	}
+
+	// same password and salt give same kek.
+	salt := RandBytes(KdfSaltLen)
+	k0 := KekFromPassword([]byte("pw"), salt)
+	k1 := KekFromPassword([]byte("pw"), salt)
+	if !bytes.Equal(k0, k1) || uint64(len(k0)) != KekLen {
+		t.Fatal()
+	}
+	if bytes.Equal(k0, KekFromPassword([]byte("pw1"), salt)) {
+		t.Fatal()
+	}
+}
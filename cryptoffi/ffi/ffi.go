@@ -2,9 +2,27 @@ package ffi
 
 import (
 	"crypto/sha256"
+	"crypto/sha512"
+
+	"lukechampine.com/blake3"
 )
 
-func TrustedHash(data string) string {
-	h := sha256.Sum256([]byte(data))
-	return string(h[:])
+// TrustedHash hashes data with a [cryptoffi] hash suite.
+// the goose model of cryptoffi imports ffi, so ffi can't import the
+// cryptoffi.HashSuite* consts. the cases use their values, and
+// cryptoffi's TestTrustedHash checks that they match.
+func TrustedHash(suite byte, data string) string {
+	switch suite {
+	case 0: // HashSuiteSha256.
+		h := sha256.Sum256([]byte(data))
+		return string(h[:])
+	case 1: // HashSuiteSha512_256.
+		h := sha512.Sum512_256([]byte(data))
+		return string(h[:])
+	case 2: // HashSuiteBlake3.
+		h := blake3.Sum256([]byte(data))
+		return string(h[:])
+	default:
+		panic("ffi: TrustedHash suite")
+	}
 }
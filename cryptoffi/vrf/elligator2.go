@@ -0,0 +1,189 @@
+package vrf
+
+import (
+	"crypto/sha512"
+	"errors"
+
+	"filippo.io/edwards25519"
+	"filippo.io/edwards25519/field"
+)
+
+// Edwards25519ELL2 is ECVRF-EDWARDS25519-SHA512-ELL2 from RFC 9381.
+// it only differs from [Edwards25519TAI] in its suite_string and hash-to-curve,
+// which is the constant-time edwards25519_XMD:SHA-512_ELL2_NU_ encoding
+// from [RFC 9380], instead of a variable number of try-and-increment attempts.
+// keys are interchangeable with [Edwards25519TAI] keys.
+//
+// [RFC 9380]: https://datatracker.ietf.org/doc/rfc9380/
+var Edwards25519ELL2 = &Suite{
+	id:            suiteIDELL2,
+	hashToCurve:   hashToCurveELL2,
+	secretScalar:  clampedScalar,
+	encodePoint:   (*edwards25519.Point).Bytes,
+	decodePoint:   decodeEdwards,
+	clearCofactor: multByCofactor,
+}
+
+const (
+	suiteIDELL2 = 0x04
+	// h2cSuiteELL2 is the h2c_suite_ID_string of the RFC 9380 suite.
+	h2cSuiteELL2 = "edwards25519_XMD:SHA-512_ELL2_NU_"
+	// ell2FieldLen is L from Section 5 of RFC 9380, ceil((255 + 128) / 8).
+	ell2FieldLen = 48
+	// curve25519J is the Montgomery A coefficient of curve25519.
+	curve25519J = 486662
+)
+
+// ell2SqrtMinusA is sqrt(-486664), with sgn0 equal to 0,
+// from the rational map in Appendix D.2 of RFC 9380.
+var ell2SqrtMinusA = ell2SqrtMinusA0()
+
+// hashToCurveELL2 implements section 5.4.1.2 of RFC 9381,
+// ECVRF_encode_to_curve_h2c_suite, with PK as the encode_to_curve_salt.
+func hashToCurveELL2(pk, alpha []byte) (*edwards25519.Point, error) {
+	dst := make([]byte, 0, 6+len(h2cSuiteELL2)+1)
+	dst = append(dst, "ECVRF_"...)
+	dst = append(dst, h2cSuiteELL2...)
+	dst = append(dst, suiteIDELL2)
+	msg := make([]byte, 0, len(pk)+len(alpha))
+	msg = append(msg, pk...)
+	msg = append(msg, alpha...)
+	return encodeToCurveELL2(msg, dst)
+}
+
+// encodeToCurveELL2 is encode_to_curve from Section 3 of RFC 9380,
+// for the edwards25519_XMD:SHA-512_ELL2_NU_ suite.
+func encodeToCurveELL2(msg, dst []byte) (*edwards25519.Point, error) {
+	uniform, err := expandMessageXMD(msg, dst, ell2FieldLen)
+	if err != nil {
+		return nil, err
+	}
+	u, err := feFromBigEndian(uniform)
+	if err != nil {
+		return nil, err
+	}
+	q, err := mapToCurveELL2(u)
+	if err != nil {
+		return nil, err
+	}
+	return (&edwards25519.Point{}).MultByCofactor(q), nil
+}
+
+// expandMessageXMD implements Section 5.3.1 of RFC 9380 with SHA-512.
+func expandMessageXMD(msg, dst []byte, lenInBytes int) ([]byte, error) {
+	const bInBytes = sha512.Size
+	const sInBytes = sha512.BlockSize
+	ell := (lenInBytes + bInBytes - 1) / bInBytes
+	if ell > 255 || lenInBytes > 65535 || len(dst) > 255 {
+		return nil, errors.New("ecvrf: bad expand_message_xmd params")
+	}
+	dstPrime := make([]byte, 0, len(dst)+1)
+	dstPrime = append(dstPrime, dst...)
+	dstPrime = append(dstPrime, byte(len(dst)))
+
+	h := sha512.New()
+	h.Write(make([]byte, sInBytes))
+	h.Write(msg)
+	h.Write([]byte{byte(lenInBytes >> 8), byte(lenInBytes), 0x00})
+	h.Write(dstPrime)
+	b0 := h.Sum(nil)
+
+	out := make([]byte, 0, ell*bInBytes)
+	bi := make([]byte, bInBytes)
+	for i := 1; i <= ell; i++ {
+		// b_i = H(strxor(b_0, b_(i-1)) || I2OSP(i, 1) || DST_prime),
+		// where b_0 stands in for the all-zero strxor input when i = 1.
+		for j := range bi {
+			bi[j] ^= b0[j]
+		}
+		h.Reset()
+		h.Write(bi)
+		h.Write([]byte{byte(i)})
+		h.Write(dstPrime)
+		bi = h.Sum(bi[:0])
+		out = append(out, bi...)
+	}
+	return out[:lenInBytes], nil
+}
+
+// mapToCurveELL2 maps u to edwards25519 using the Elligator 2 method
+// from Section 6.7.1 of RFC 9380 on curve25519,
+// followed by the rational map from Appendix D.2.
+func mapToCurveELL2(u *field.Element) (*edwards25519.Point, error) {
+	one := new(field.Element).One()
+	j := new(field.Element).Mult32(one, curve25519J)
+	negJ := new(field.Element).Negate(j)
+
+	// x1 = -J / (1 + 2 * u^2).
+	// the denominator is never 0, since -1/2 is not square.
+	tv1 := new(field.Element).Square(u)
+	tv1.Add(tv1, tv1)
+	xd := new(field.Element).Add(tv1, one)
+	x1 := new(field.Element).Invert(xd)
+	x1.Multiply(x1, negJ)
+
+	// gx1 = x1^3 + J * x1^2 + x1
+	gx1 := montgomeryRHS(x1, j)
+	// x2 = -x1 - J
+	x2 := new(field.Element).Subtract(negJ, x1)
+	gx2 := montgomeryRHS(x2, j)
+
+	// if is_square(gx1), x = x1, y = sqrt(gx1) with sgn0(y) == 1.
+	// else x = x2, y = sqrt(gx2) with sgn0(y) == 0.
+	// SqrtRatio returns the non-negative root.
+	y1, isSq := new(field.Element).SqrtRatio(gx1, one)
+	y2, _ := new(field.Element).SqrtRatio(gx2, one)
+	y1.Negate(y1)
+	s := new(field.Element).Select(x1, x2, isSq)
+	t := new(field.Element).Select(y1, y2, isSq)
+
+	// v = sqrt(-486664) * s / t
+	// w = (s - 1) / (s + 1)
+	// if t == 0 or s == -1, (v, w) = (0, 1).
+	// Invert maps 0 to 0, which gives v = 0, but not w = 1.
+	sPlusOne := new(field.Element).Add(s, one)
+	v := new(field.Element).Invert(t)
+	v.Multiply(v, s)
+	v.Multiply(v, ell2SqrtMinusA)
+	w := new(field.Element).Invert(sPlusOne)
+	w.Multiply(w, new(field.Element).Subtract(s, one))
+	zero := new(field.Element).Zero()
+	exc := new(field.Element).Multiply(t, sPlusOne).Equal(zero)
+	v.Select(zero, v, exc)
+	w.Select(one, w, exc)
+
+	return (&edwards25519.Point{}).SetExtendedCoordinates(
+		v, w, one, new(field.Element).Multiply(v, w))
+}
+
+// montgomeryRHS returns x^3 + j*x^2 + x.
+func montgomeryRHS(x, j *field.Element) *field.Element {
+	// x * (x * (x + j) + 1)
+	r := new(field.Element).Add(x, j)
+	r.Multiply(r, x)
+	r.Add(r, new(field.Element).One())
+	return r.Multiply(r, x)
+}
+
+// feFromBigEndian is OS2IP(b) mod p, for len(b) <= 64.
+func feFromBigEndian(b []byte) (*field.Element, error) {
+	if len(b) > 64 {
+		return nil, errors.New("ecvrf: field input too long")
+	}
+	le := make([]byte, 64)
+	for i := range b {
+		le[i] = b[len(b)-1-i]
+	}
+	return new(field.Element).SetWideBytes(le)
+}
+
+func ell2SqrtMinusA0() *field.Element {
+	one := new(field.Element).One()
+	negA := new(field.Element).Mult32(one, curve25519J+2)
+	negA.Negate(negA)
+	r, isSq := new(field.Element).SqrtRatio(negA, one)
+	if isSq != 1 {
+		panic("ecvrf: -486664 not square")
+	}
+	return r
+}
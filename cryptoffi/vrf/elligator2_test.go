@@ -0,0 +1,197 @@
+package vrf
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+
+	"filippo.io/edwards25519/field"
+	"github.com/stretchr/testify/assert"
+)
+
+// A.4 Example 10, ECVRF-EDWARDS25519-SHA512-ELL2.
+var ell2Vector = testVector{
+	sk:    decodeHex("9d61b19deffd5a60ba844af492ec2cc44449c5697b326919703bac031cae7f60"),
+	pk:    decodeHex("d75a980182b10ab7d54bfed3c964073a0ee172f3daa62325af021a68f707511a"),
+	h:     decodeHex("b8066ebbb706c72b64390324e4a3276f129569eab100c26b9f05011200c1bad9"),
+	pi:    decodeHex("7d9c633ffeee27349264cf5c667579fc583b4bda63ab71d001f89c10003ab46f25898f6bd7d4ed4c75f0282b0f7bb9d0e61b387b76db60b3cbf34bf09109ccb33fab742a8bddc0c8ba3caf5c0b75bb04"),
+	alpha: decodeHex(""),
+	beta:  decodeHex("9d574bf9b8302ec0fc1e21c3ec5368269527b87b462ce36dab2d14ccf80c53cccf6758f058c5b1c856b116388152bbe509ee3b9ecfe63d93c3b4346c1fbc6c54"),
+}
+
+// TestRFCVectors_expandMessageXMD uses K.3 of RFC 9380.
+func TestRFCVectors_expandMessageXMD(t *testing.T) {
+	out, err := expandMessageXMD([]byte(""), []byte("QUUX-V01-CS02-with-expander-SHA512-256"), 0x20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "6b9a7312411d92f921c6f68ca0b6380730a1a4d982c507211a90964c394179ba", hex.EncodeToString(out))
+}
+
+// TestRFCVectors_encodeToCurveELL2 uses J.5.2 of RFC 9380.
+func TestRFCVectors_encodeToCurveELL2(t *testing.T) {
+	p, err := encodeToCurveELL2([]byte(""), []byte("QUUX-V01-CS02-with-edwards25519_XMD:SHA-512_ELL2_NU_"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	x, y, z, _ := p.ExtendedCoordinates()
+	zInv := new(field.Element).Invert(z)
+	assert.Equal(t, "1ff2b70ecf862799e11b7ae744e3489aa058ce805dd323a936375a84695e76da", bigEndianHex(new(field.Element).Multiply(x, zInv)))
+	assert.Equal(t, "222e314d04a4d5725e9f2aff9fb2a6b69ef375a1214eb19021ceab2d687f0f9b", bigEndianHex(new(field.Element).Multiply(y, zInv)))
+}
+
+func TestRFCVectors_hashToCurveELL2(t *testing.T) {
+	h, err := hashToCurveELL2(ell2Vector.pk, ell2Vector.alpha)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, ell2Vector.h, h.Bytes())
+}
+
+func TestRFCVectors_ProveELL2(t *testing.T) {
+	sk, err := Edwards25519ELL2.NewPrivateKey(append(ell2Vector.sk, ell2Vector.pk...))
+	if err != nil {
+		t.Fatal(err)
+	}
+	beta, proof, err := sk.Prove(ell2Vector.alpha)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, ell2Vector.pi, proof)
+	assert.Equal(t, ell2Vector.beta, beta)
+
+	beta0, err := sk.Evaluate(ell2Vector.alpha)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, ell2Vector.beta, beta0)
+}
+
+func TestRFCVectors_VerifyELL2(t *testing.T) {
+	pk, err := Edwards25519ELL2.NewPublicKey(ell2Vector.pk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	verified, beta, err := pk.Verify(ell2Vector.alpha, ell2Vector.pi)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.True(t, verified)
+	assert.Equal(t, ell2Vector.beta, beta)
+
+	verified, betas, err := pk.VerifyMany([][]byte{ell2Vector.alpha}, [][]byte{ell2Vector.pi})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.True(t, verified)
+	assert.Equal(t, [][]byte{ell2Vector.beta}, betas)
+}
+
+// TestELL2CrossTAI checks that ELL2 shares keys with TAI,
+// but has separate outputs and proofs.
+func TestELL2CrossTAI(t *testing.T) {
+	for _, vector := range testVectors {
+		skB := append(append([]byte{}, vector.sk...), vector.pk...)
+		skT, err := Edwards25519TAI.NewPrivateKey(skB)
+		if err != nil {
+			t.Fatal(err)
+		}
+		skE, err := Edwards25519ELL2.NewPrivateKey(skB)
+		if err != nil {
+			t.Fatal(err)
+		}
+		skE0, err := Edwards25519ELL2.GenerateKey(bytes.NewReader(vector.sk))
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, skT.PublicKey(), skE0.PublicKey())
+		assert.Equal(t, skB, skE0.Bytes())
+
+		pkT, err := skT.Public()
+		if err != nil {
+			t.Fatal(err)
+		}
+		pkE, err := skE.Public()
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, pkT.Bytes(), pkE.Bytes())
+
+		vrfT, proofT, err := skT.Prove(vector.alpha)
+		if err != nil {
+			t.Fatal(err)
+		}
+		vrfE, proofE, err := skE.Prove(vector.alpha)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, vector.beta, vrfT)
+		assert.NotEqual(t, vrfT, vrfE)
+
+		verified, _, _ := pkE.Verify(vector.alpha, proofT)
+		assert.False(t, verified)
+		verified, _, _ = pkT.Verify(vector.alpha, proofE)
+		assert.False(t, verified)
+		verified, vrfE0, err := pkE.Verify(vector.alpha, proofE)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.True(t, verified)
+		assert.Equal(t, vrfE, vrfE0)
+	}
+}
+
+func TestELL2SmallOrderPk(t *testing.T) {
+	_, err := Edwards25519ELL2.NewPublicKey(identityBytes(Edwards25519ELL2))
+	assert.Error(t, err)
+}
+
+func TestELL2FlipBitForgery(t *testing.T) {
+	sk, err := Edwards25519ELL2.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pk, _ := sk.Public()
+	alice := []byte("alice")
+
+	for i := 0; i < ProofSize; i++ {
+		_, aliceProof, _ := sk.Prove(alice)
+		for j := uint(0); j < 8; j++ {
+			aliceProof[i] ^= 1 << j
+			verified, _, _ := pk.Verify(alice, aliceProof)
+			if verified {
+				t.Fatalf("forged by using aliceVRF[%d]^%d:\n (sk=%X)", i, j, sk.sk)
+			}
+		}
+	}
+}
+
+func BenchmarkHashToCurveELL2(b *testing.B) {
+	sk, _ := GenerateKey(nil)
+	alice := []byte("alice")
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		_, _ = hashToCurveELL2(sk.pk, alice)
+	}
+}
+
+func BenchmarkProveELL2(b *testing.B) {
+	sk, err := Edwards25519ELL2.GenerateKey(nil)
+	if err != nil {
+		b.Fatal(err)
+	}
+	alice := []byte("alice")
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		_, _, _ = sk.Prove(alice)
+	}
+}
+
+func bigEndianHex(fe *field.Element) string {
+	b := fe.Bytes()
+	for i, j := 0, len(b)-1; i < j; i, j = i+1, j-1 {
+		b[i], b[j] = b[j], b[i]
+	}
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,284 @@
+package vrf
+
+import (
+	"bytes"
+	"crypto/sha512"
+	"errors"
+	"math/big"
+
+	"filippo.io/edwards25519"
+	"filippo.io/edwards25519/field"
+)
+
+// Ristretto255 is the ECVRF construction from RFC 9381,
+// instantiated with the ristretto255 group from [RFC 9496] and SHA-512.
+// it differs from [Edwards25519TAI] as follows:
+//   - points use the ristretto255 encoding, which is canonical
+//     and has no small sub-group, so the cofactor is 1.
+//   - hash-to-curve is the constant-time ristretto255 one-way map
+//     applied to SHA-512(suite_string || 0x01 || PK || alpha || 0x00).
+//   - the secret scalar is SHA-512(seed) reduced mod the group order,
+//     instead of an RFC 8032 clamped scalar.
+//
+// proofs have the same size as [Edwards25519TAI] proofs.
+//
+// [RFC 9496]: https://datatracker.ietf.org/doc/rfc9496/
+var Ristretto255 = &Suite{
+	id:            suiteIDRistretto255,
+	hashToCurve:   hashToCurveR255,
+	secretScalar:  uniformScalar,
+	encodePoint:   encodeR255,
+	decodePoint:   decodeR255,
+	clearCofactor: func(p *edwards25519.Point) *edwards25519.Point { return p },
+}
+
+// suiteIDRistretto255 is outside the range of RFC 9381 suite strings.
+const suiteIDRistretto255 = 0x80
+
+// Constants from Section 4.1 of RFC 9496.
+var (
+	r255D = feFromDecimal(
+		"37095705934669439343138083508754565189542113879843219016388785533085940283555")
+	r255SqrtM1 = feFromDecimal(
+		"19681161376707505956807079304988542015446066515923890162744021073123829784752")
+	r255SqrtADMinusOne = feFromDecimal(
+		"25063068953384623474111414158702152701244531502492656460079210482610430750235")
+	r255InvSqrtAMinusD = feFromDecimal(
+		"54469307008909316920995813868745141605393597292927456921205312896311721017578")
+	r255OneMinusDSq = feFromDecimal(
+		"1159843021668779879193775521855586647937357759715417654439879720876111806838")
+	r255DMinusOneSq = feFromDecimal(
+		"40440834346308536858101042469323190826248399146238708352240133220865137265952")
+)
+
+func hashToCurveR255(pk, alpha []byte) (*edwards25519.Point, error) {
+	h := sha512.New()
+	h.Write([]byte{suiteIDRistretto255, 0x01})
+	h.Write(pk)
+	h.Write(alpha)
+	h.Write([]byte{0x00})
+	return fromUniformBytesR255(h.Sum(nil))
+}
+
+// uniformScalar reduces the full 64-byte expanded seed mod the group order.
+func uniformScalar(h []byte) (*edwards25519.Scalar, error) {
+	return edwards25519.NewScalar().SetUniformBytes(h)
+}
+
+// fromUniformBytesR255 implements the hash-to-group operation
+// from Section 4.3.4 of RFC 9496.
+func fromUniformBytesR255(b []byte) (*edwards25519.Point, error) {
+	if len(b) != 64 {
+		return nil, errors.New("ecvrf: bad uniform bytes length")
+	}
+	// SetBytes ignores the most significant bit,
+	// as required for the one-way map input.
+	t0, err := new(field.Element).SetBytes(b[:32])
+	if err != nil {
+		return nil, err
+	}
+	t1, err := new(field.Element).SetBytes(b[32:])
+	if err != nil {
+		return nil, err
+	}
+	p0, err := mapR255(t0)
+	if err != nil {
+		return nil, err
+	}
+	p1, err := mapR255(t1)
+	if err != nil {
+		return nil, err
+	}
+	return (&edwards25519.Point{}).Add(p0, p1), nil
+}
+
+// mapR255 is MAP from Section 4.3.4 of RFC 9496.
+func mapR255(t *field.Element) (*edwards25519.Point, error) {
+	one := new(field.Element).One()
+
+	// r = SQRT_M1 * t^2
+	r := new(field.Element).Multiply(r255SqrtM1, new(field.Element).Square(t))
+
+	// u = (r + 1) * ONE_MINUS_D_SQ
+	u := new(field.Element).Add(r, one)
+	u.Multiply(u, r255OneMinusDSq)
+
+	// c = -1
+	c := new(field.Element).Negate(one)
+
+	// v = (c - r*D) * (r + D)
+	rPlusD := new(field.Element).Add(r, r255D)
+	v := new(field.Element).Multiply(r, r255D)
+	v.Subtract(c, v)
+	v.Multiply(v, rPlusD)
+
+	// (was_square, s) = SQRT_RATIO_M1(u, v)
+	s, wasSquare := new(field.Element).SqrtRatio(u, v)
+
+	// s_prime = -CT_ABS(s*t)
+	sPrime := new(field.Element).Multiply(s, t)
+	sPrime.Absolute(sPrime)
+	sPrime.Negate(sPrime)
+
+	// s = CT_SELECT(s IF was_square ELSE s_prime)
+	// c = CT_SELECT(c IF was_square ELSE r)
+	s.Select(s, sPrime, wasSquare)
+	c.Select(c, r, wasSquare)
+
+	// N = c * (r - 1) * D_MINUS_ONE_SQ - v
+	n := new(field.Element).Subtract(r, one)
+	n.Multiply(c, n)
+	n.Multiply(n, r255DMinusOneSq)
+	n.Subtract(n, v)
+
+	s2 := new(field.Element).Square(s)
+
+	// w0 = 2 * s * v
+	w0 := new(field.Element).Multiply(s, v)
+	w0.Add(w0, w0)
+	// w1 = N * SQRT_AD_MINUS_ONE
+	w1 := new(field.Element).Multiply(n, r255SqrtADMinusOne)
+	// w2 = 1 - s^2
+	w2 := new(field.Element).Subtract(one, s2)
+	// w3 = 1 + s^2
+	w3 := new(field.Element).Add(one, s2)
+
+	// return (w0*w3, w2*w1, w1*w3, w0*w2)
+	return (&edwards25519.Point{}).SetExtendedCoordinates(
+		new(field.Element).Multiply(w0, w3),
+		new(field.Element).Multiply(w2, w1),
+		new(field.Element).Multiply(w1, w3),
+		new(field.Element).Multiply(w0, w2),
+	)
+}
+
+// encodeR255 implements Section 4.3.2 of RFC 9496.
+func encodeR255(p *edwards25519.Point) []byte {
+	x0, y0, z0, t0 := p.ExtendedCoordinates()
+	one := new(field.Element).One()
+
+	// u1 = (z0 + y0) * (z0 - y0)
+	u1 := new(field.Element).Add(z0, y0)
+	u1.Multiply(u1, new(field.Element).Subtract(z0, y0))
+
+	// u2 = x0 * y0
+	u2 := new(field.Element).Multiply(x0, y0)
+
+	// Ignore was_square since this is always square.
+	// (_, invsqrt) = SQRT_RATIO_M1(1, u1 * u2^2)
+	tmp := new(field.Element).Square(u2)
+	tmp.Multiply(tmp, u1)
+	invSqrt, _ := new(field.Element).SqrtRatio(one, tmp)
+
+	// den1 = invsqrt * u1
+	// den2 = invsqrt * u2
+	den1 := new(field.Element).Multiply(invSqrt, u1)
+	den2 := new(field.Element).Multiply(invSqrt, u2)
+	// z_inv = den1 * den2 * t0
+	zInv := new(field.Element).Multiply(den1, den2)
+	zInv.Multiply(zInv, t0)
+
+	// ix0 = x0 * SQRT_M1
+	// iy0 = y0 * SQRT_M1
+	ix0 := new(field.Element).Multiply(x0, r255SqrtM1)
+	iy0 := new(field.Element).Multiply(y0, r255SqrtM1)
+	// enchanted_denominator = den1 * INVSQRT_A_MINUS_D
+	enchanted := new(field.Element).Multiply(den1, r255InvSqrtAMinusD)
+
+	// rotate = IS_NEGATIVE(t0 * z_inv)
+	rotate := new(field.Element).Multiply(t0, zInv).IsNegative()
+
+	// x = CT_SELECT(iy0 IF rotate ELSE x0)
+	// y = CT_SELECT(ix0 IF rotate ELSE y0)
+	// den_inv = CT_SELECT(enchanted_denominator IF rotate ELSE den2)
+	x := new(field.Element).Select(iy0, x0, rotate)
+	y := new(field.Element).Select(ix0, y0, rotate)
+	denInv := new(field.Element).Select(enchanted, den2, rotate)
+
+	// y = CT_NEG(y, IS_NEGATIVE(x * z_inv))
+	negY := new(field.Element).Negate(y)
+	y.Select(negY, y, new(field.Element).Multiply(x, zInv).IsNegative())
+
+	// s = CT_ABS(den_inv * (z0 - y))
+	s := new(field.Element).Subtract(z0, y)
+	s.Multiply(s, denInv)
+	s.Absolute(s)
+	return s.Bytes()
+}
+
+// decodeR255 implements Section 4.3.1 of RFC 9496.
+func decodeR255(b []byte) (*edwards25519.Point, error) {
+	errEnc := errors.New("ecvrf: bad ristretto255 encoding")
+	if len(b) != 32 {
+		return nil, errEnc
+	}
+	one := new(field.Element).One()
+
+	// reject non-canonical and negative field elements.
+	s, err := new(field.Element).SetBytes(b)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(s.Bytes(), b) {
+		return nil, errEnc
+	}
+	if s.IsNegative() == 1 {
+		return nil, errEnc
+	}
+
+	// ss = s^2
+	// u1 = 1 - ss
+	// u2 = 1 + ss
+	// u2_sqr = u2^2
+	ss := new(field.Element).Square(s)
+	u1 := new(field.Element).Subtract(one, ss)
+	u2 := new(field.Element).Add(one, ss)
+	u2Sqr := new(field.Element).Square(u2)
+
+	// v = -(D * u1^2) - u2_sqr
+	v := new(field.Element).Square(u1)
+	v.Multiply(v, r255D)
+	v.Negate(v)
+	v.Subtract(v, u2Sqr)
+
+	// (was_square, invsqrt) = SQRT_RATIO_M1(1, v * u2_sqr)
+	invSqrt, wasSquare := new(field.Element).SqrtRatio(one, new(field.Element).Multiply(v, u2Sqr))
+
+	// den_x = invsqrt * u2
+	// den_y = invsqrt * den_x * v
+	denX := new(field.Element).Multiply(invSqrt, u2)
+	denY := new(field.Element).Multiply(invSqrt, denX)
+	denY.Multiply(denY, v)
+
+	// x = CT_ABS(2 * s * den_x)
+	// y = u1 * den_y
+	// t = x * y
+	x := new(field.Element).Add(s, s)
+	x.Multiply(x, denX)
+	x.Absolute(x)
+	y := new(field.Element).Multiply(u1, denY)
+	t := new(field.Element).Multiply(x, y)
+
+	// If was_square is FALSE, or IS_NEGATIVE(t) returns TRUE, or y = 0, decoding fails.
+	if wasSquare == 0 || t.IsNegative() == 1 || y.Equal(new(field.Element).Zero()) == 1 {
+		return nil, errEnc
+	}
+	return (&edwards25519.Point{}).SetExtendedCoordinates(x, y, one, t)
+}
+
+func feFromDecimal(s string) *field.Element {
+	n, ok := new(big.Int).SetString(s, 10)
+	if !ok {
+		panic("ecvrf: bad decimal constant")
+	}
+	// big.Int is big-endian, field elements are little-endian.
+	b := n.FillBytes(make([]byte, 32))
+	for i, j := 0, len(b)-1; i < j; i, j = i+1, j-1 {
+		b[i], b[j] = b[j], b[i]
+	}
+	fe, err := new(field.Element).SetBytes(b)
+	if err != nil {
+		panic("ecvrf: bad field constant")
+	}
+	return fe
+}
@@ -1,12 +1,17 @@
 // This started from https://pkg.go.dev/github.com/ProtonMail/go-ecvrf/ecvrf,
 // with modifications to check for adversarial pks, improve pk.Verify performance,
-// reduce duplicate computation, add Evaluate.
+// reduce duplicate computation, add Evaluate, and support multiple suites.
 //
 // Package ecvrf implements ECVRF-EDWARDS25519-SHA512-TAI, a verifiable random
 // function described in draft-irtf-cfrg-vrf-10.
 // This VRF uses the Edwards form of Curve25519, SHA512 and the try-and-increment
 // hash-to-curve function.
 // See: https://datatracker.ietf.org/doc/draft-irtf-cfrg-vrf/
+//
+// It also implements ECVRF-EDWARDS25519-SHA512-ELL2, which uses constant-time
+// Elligator 2 hash-to-curve, see [Edwards25519ELL2],
+// and the same ECVRF construction over the ristretto255
+// prime-order group, see [Ristretto255].
 package vrf
 
 import (
@@ -16,6 +21,8 @@ import (
 	"crypto/sha512"
 	"errors"
 	"io"
+	"runtime"
+	"sync"
 
 	"filippo.io/edwards25519"
 )
@@ -30,21 +37,58 @@ const (
 	suiteID          = 0x03
 )
 
+// Suite is an ECVRF ciphersuite.
+// all suites share the ECVRF prove and verify algorithms,
+// and differ in their group encoding and hash-to-curve.
+type Suite struct {
+	// id is the suite_string, used for hash domain separation.
+	id byte
+	// hashToCurve is ECVRF_encode_to_curve, using pk as the salt.
+	hashToCurve func(pk, alpha []byte) (*edwards25519.Point, error)
+	// secretScalar derives the secret scalar from the expanded seed.
+	secretScalar func(h []byte) (*edwards25519.Scalar, error)
+	// encodePoint is point_to_string.
+	encodePoint func(p *edwards25519.Point) []byte
+	// decodePoint is string_to_point.
+	decodePoint func(b []byte) (*edwards25519.Point, error)
+	// clearCofactor multiplies by the cofactor.
+	clearCofactor func(p *edwards25519.Point) *edwards25519.Point
+}
+
+var (
+	// Edwards25519TAI is ECVRF-EDWARDS25519-SHA512-TAI from RFC 9381.
+	Edwards25519TAI = &Suite{
+		id:            suiteID,
+		hashToCurve:   hashToCurveTAI,
+		secretScalar:  clampedScalar,
+		encodePoint:   (*edwards25519.Point).Bytes,
+		decodePoint:   decodeEdwards,
+		clearCofactor: multByCofactor,
+	}
+)
+
 // PrivateKey contains the VRF private key, a standard ed25519 key and the precomputed secret scalar.
 type PrivateKey struct {
 	sk, pk []byte
 	x      *edwards25519.Scalar
+	suite  *Suite
 }
 
 // PublicKey contains the VRF public key, the canonical representation of a point on the ed25519 curve.
 type PublicKey struct {
-	pk []byte
-	y  *edwards25519.Point
+	pk    []byte
+	y     *edwards25519.Point
+	suite *Suite
+}
+
+// GenerateKey creates an [Edwards25519TAI] key pair, see [Suite.GenerateKey].
+func GenerateKey(rnd io.Reader) (sk *PrivateKey, err error) {
+	return Edwards25519TAI.GenerateKey(rnd)
 }
 
 // GenerateKey creates a public/private key pair using rnd for randomness.
 // If rnd is nil, crypto/rand is used.
-func GenerateKey(rnd io.Reader) (sk *PrivateKey, err error) {
+func (suite *Suite) GenerateKey(rnd io.Reader) (sk *PrivateKey, err error) {
 	if rnd == nil {
 		rnd = rand.Reader
 	}
@@ -57,41 +101,57 @@ func GenerateKey(rnd io.Reader) (sk *PrivateKey, err error) {
 	// Generate the private key, the secret scalar, and the public key
 	// according to Section 5.1.5 of RFC8032 and cache the values.
 	h := sha512.Sum512(seed)
-	s, err := edwards25519.NewScalar().SetBytesWithClamping(h[:scalarSize])
+	s, err := suite.secretScalar(h[:])
 	if err != nil {
 		return nil, err
 	}
 
 	A := (&edwards25519.Point{}).ScalarBaseMult(s)
-	return &PrivateKey{seed, A.Bytes(), s}, err
+	return &PrivateKey{seed, suite.encodePoint(A), s, suite}, err
 }
 
-// NewPrivateKey generates a PrivateKey object from a standard RFC8032
-// Ed25519 64-byte private key.
+// NewPrivateKey parses an [Edwards25519TAI] key, see [Suite.NewPrivateKey].
 func NewPrivateKey(skBytes []byte) (sk *PrivateKey, err error) {
+	return Edwards25519TAI.NewPrivateKey(skBytes)
+}
+
+// NewPrivateKey generates a PrivateKey object from a 64-byte private key,
+// the seed followed by the encoded public key.
+// For [Edwards25519TAI], this is a standard RFC8032 Ed25519 private key.
+func (suite *Suite) NewPrivateKey(skBytes []byte) (sk *PrivateKey, err error) {
 	if len(skBytes) != PrivateKeySize {
 		return nil, errors.New("ecvrf: bad private key size")
 	}
 
 	// Generate the secret scalar according to Section 5.1.5 of RFC8032.
 	h := sha512.Sum512(skBytes[:scalarSize])
-	s, err := edwards25519.NewScalar().SetBytesWithClamping(h[:scalarSize])
+	s, err := suite.secretScalar(h[:])
 	if err != nil {
 		return nil, err
 	}
 
-	return &PrivateKey{skBytes[:scalarSize], skBytes[scalarSize:], s}, err
+	return &PrivateKey{skBytes[:scalarSize], skBytes[scalarSize:], s, suite}, err
 }
 
 // Public extracts the public VRF key from the underlying private-key.
 func (sk *PrivateKey) Public() (*PublicKey, error) {
-	return NewPublicKey(sk.pk)
+	return sk.suite.NewPublicKey(sk.pk)
 }
 
 func (sk *PrivateKey) PublicKey() []byte {
 	return sk.pk
 }
 
+// Suite returns the suite of sk.
+func (sk *PrivateKey) Suite() *Suite {
+	return sk.suite
+}
+
+// Seed returns the 32-byte seed that sk was generated from.
+func (sk *PrivateKey) Seed() []byte {
+	return bytes.Clone(sk.sk)
+}
+
 // Bytes serialises the private VRF key in a bytearray.
 func (sk *PrivateKey) Bytes() []byte {
 	buf := make([]byte, PrivateKeySize)
@@ -100,21 +160,25 @@ func (sk *PrivateKey) Bytes() []byte {
 	return buf
 }
 
-// NewPublicKey generates a PublicKey object from a standard RFC8032
-// Ed25519 32-byte public key.
+// NewPublicKey parses an [Edwards25519TAI] key, see [Suite.NewPublicKey].
 func NewPublicKey(pkBytes []byte) (*PublicKey, error) {
-	y, err := (&edwards25519.Point{}).SetBytes(pkBytes)
+	return Edwards25519TAI.NewPublicKey(pkBytes)
+}
+
+// NewPublicKey generates a PublicKey object from a 32-byte encoded point.
+// For [Edwards25519TAI], this is a standard RFC8032 Ed25519 public key.
+func (suite *Suite) NewPublicKey(pkBytes []byte) (*PublicKey, error) {
+	y, err := suite.decodePoint(pkBytes)
 	if err != nil {
 		return nil, err
 	}
 	// check if point on small sub-group. ECVRF_validate_key from RFC 9381.
 	// this should be included in edwards25519, see tracking [issue].
 	// [issue]: https://github.com/FiloSottile/edwards25519/issues/33.
-	if (&edwards25519.Point{}).MultByCofactor(y).
-		Equal(edwards25519.NewIdentityPoint()) == 1 {
+	if bytes.Equal(suite.encodePoint(suite.clearCofactor(y)), identityBytes(suite)) {
 		return nil, errors.New("ecvrf: pk on small sub-group")
 	}
-	return &PublicKey{pk: pkBytes, y: y}, nil
+	return &PublicKey{pk: pkBytes, y: y, suite: suite}, nil
 }
 
 // Bytes serialises the private VRF key in a bytearray.
@@ -122,26 +186,32 @@ func (pk *PublicKey) Bytes() []byte {
 	return pk.pk
 }
 
+// Suite returns the suite of pk.
+func (pk *PublicKey) Suite() *Suite {
+	return pk.suite
+}
+
 // Prove returns a proof such that Verify(pk, message, vrf, proof) == true
 // for a given message and public key pair sk/pk.
 // This function is defined in section 5.1 of draft-irtf-cfrg-vrf-10.
 func (sk *PrivateKey) Prove(message []byte) (vrf, proof []byte, err error) {
+	suite := sk.suite
 	// Step 1 is done in key generation/parsing
-	h, err := hashToCurveTAI(sk.pk, message)
+	h, err := suite.hashToCurve(sk.pk, message)
 	if err != nil {
 		return nil, nil, err
 	}
 
 	gamma := (&edwards25519.Point{}).ScalarMult(sk.x, h)
-	hByt := h.Bytes()
+	hByt := suite.encodePoint(h)
 	kHash := generateNonceHash(sk.sk, hByt)
 	k, err := edwards25519.NewScalar().SetUniformBytes(kHash)
 	if err != nil {
 		return nil, nil, err
 	}
 
-	gammaByt := gamma.Bytes()
-	c := hashPoints(
+	gammaByt := suite.encodePoint(gamma)
+	c := suite.hashPoints(
 		hByt,
 		gammaByt,
 		[]*edwards25519.Point{
@@ -164,29 +234,30 @@ func (sk *PrivateKey) Prove(message []byte) (vrf, proof []byte, err error) {
 	copy(proof[pointSize:], c)
 	copy(proof[pointSize+intermediateSize:], s.Bytes())
 
-	return proofToHash(gamma), proof, nil
+	return suite.proofToHash(gamma), proof, nil
 }
 
 // Evaluate computes a VRF output, without the overhead of generating a proof.
 func (sk *PrivateKey) Evaluate(message []byte) (vrf []byte, err error) {
-	h, err := hashToCurveTAI(sk.pk, message)
+	h, err := sk.suite.hashToCurve(sk.pk, message)
 	if err != nil {
 		return nil, err
 	}
 	gamma := (&edwards25519.Point{}).ScalarMult(sk.x, h)
-	return proofToHash(gamma), nil
+	return sk.suite.proofToHash(gamma), nil
 }
 
 // Verify verifies that the given proof matches the message and the public
 // key pk. When true it also returns the expected VRF string.
 // This function is defined in section 5.3 of draft-irtf-cfrg-vrf-10.
 func (pk *PublicKey) Verify(message, proof []byte) (verified bool, vrf []byte, err error) {
+	suite := pk.suite
 	if len(proof) != ProofSize {
 		return false, nil, errors.New("ecvrf: bad proof length")
 	}
 
 	gammaByt := proof[:pointSize]
-	gamma, err := (&edwards25519.Point{}).SetBytes(gammaByt)
+	gamma, err := suite.decodePoint(gammaByt)
 	if err != nil {
 		return false, nil, err
 	}
@@ -201,7 +272,7 @@ func (pk *PublicKey) Verify(message, proof []byte) (verified bool, vrf []byte, e
 		return false, nil, err
 	}
 
-	h, err := hashToCurveTAI(pk.pk, message)
+	h, err := suite.hashToCurve(pk.pk, message)
 	if err != nil {
 		return false, nil, err
 	}
@@ -219,16 +290,114 @@ func (pk *PublicKey) Verify(message, proof []byte) (verified bool, vrf []byte, e
 	)
 
 	// If c and c' are different
-	hByt := h.Bytes()
-	if !bytes.Equal(hashPoints(hByt, gammaByt, []*edwards25519.Point{u, v}), proof[pointSize:pointSize+intermediateSize]) {
+	hByt := suite.encodePoint(h)
+	if !bytes.Equal(suite.hashPoints(hByt, gammaByt, []*edwards25519.Point{u, v}), proof[pointSize:pointSize+intermediateSize]) {
 		return false, nil, nil
 	}
 
-	return true, proofToHash(gamma), nil
+	return true, suite.proofToHash(gamma), nil
+}
+
+// VerifyMany verifies that each proofs[i] matches messages[i] under pk.
+// When true it also returns the expected VRF strings, in order.
+// All inputs are public, so U = s*B - c*Y and V = s*H - c*Gamma are
+// computed with variable-time multi-scalar multiplication instead of four
+// constant-time scalar multiplications,
+// and large inputs are split across GOMAXPROCS workers.
+//
+// This is not batch verification, and can't be with this proof format.
+// A proof (Gamma, c, s) only commits to U and V through the hash c,
+// so each U and V has to be computed on its own before c can be checked.
+// Once they're computed, the check is a hash compare, and there's nothing
+// left for a random linear combination to save.
+// Batching would need a proof that carries U and V, e.g., (Gamma, U, V, s),
+// so that one multi-scalar multiplication can check
+// sum_i z_i*(s_i*B - c_i*Y - U_i) and sum_i z_i*(s_i*H_i - c_i*Gamma_i - V_i).
+// That's a different, non-RFC proof format, with its own wire encoding.
+func (pk *PublicKey) VerifyMany(messages, proofs [][]byte) (verified bool, vrfs [][]byte, err error) {
+	if len(messages) != len(proofs) {
+		return false, nil, errors.New("ecvrf: messages and proofs differ in length")
+	}
+	n := len(proofs)
+	vrfs = make([][]byte, n)
+	oks := make([]bool, n)
+	errs := make([]error, n)
+	workers := min(runtime.GOMAXPROCS(0), (n+manyChunk-1)/manyChunk)
+	wg := new(sync.WaitGroup)
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := w; i < n; i += workers {
+				oks[i], vrfs[i], errs[i] = pk.verifyVarTime(messages[i], proofs[i])
+			}
+		}()
+	}
+	wg.Wait()
+
+	for i := 0; i < n; i++ {
+		if errs[i] != nil {
+			return false, nil, errs[i]
+		}
+		if !oks[i] {
+			return false, nil, nil
+		}
+	}
+	return true, vrfs, nil
 }
 
 // -- internal functions --
 
+// manyChunk is the min number of proofs worth spawning a worker for.
+const manyChunk = 8
+
+// verifyVarTime is [PublicKey.Verify] with variable-time scalar mults.
+func (pk *PublicKey) verifyVarTime(message, proof []byte) (verified bool, vrf []byte, err error) {
+	suite := pk.suite
+	if len(proof) != ProofSize {
+		return false, nil, errors.New("ecvrf: bad proof length")
+	}
+
+	gammaByt := proof[:pointSize]
+	gamma, err := suite.decodePoint(gammaByt)
+	if err != nil {
+		return false, nil, err
+	}
+
+	c, err := cToScalar(proof[pointSize : pointSize+intermediateSize])
+	if err != nil {
+		return false, nil, err
+	}
+	negC := edwards25519.NewScalar().Negate(c)
+
+	s, err := edwards25519.NewScalar().SetCanonicalBytes(proof[pointSize+intermediateSize:])
+	if err != nil {
+		return false, nil, err
+	}
+
+	h, err := suite.hashToCurve(pk.pk, message)
+	if err != nil {
+		return false, nil, err
+	}
+
+	// U = s*B - c*Y
+	u := (&edwards25519.Point{}).VarTimeDoubleScalarBaseMult(negC, pk.y, s)
+
+	// V = s*H - c*Gamma
+	v := (&edwards25519.Point{}).VarTimeMultiScalarMult(
+		[]*edwards25519.Scalar{s, negC},
+		[]*edwards25519.Point{h, gamma},
+	)
+
+	// If c and c' are different
+	hByt := suite.encodePoint(h)
+	if !bytes.Equal(suite.hashPoints(hByt, gammaByt, []*edwards25519.Point{u, v}), proof[pointSize:pointSize+intermediateSize]) {
+		return false, nil, nil
+	}
+
+	return true, suite.proofToHash(gamma), nil
+}
+
 // Step 5.1.2 of draft-irtf-cfrg-vrf-10 implemented as defined by the section
 // 5.4.1.1, ECVRF_hash_to_curve_try_and_increment.
 func hashToCurveTAI(pk, alpha []byte) (*edwards25519.Point, error) {
@@ -265,13 +434,13 @@ func generateNonceHash(sk, h []byte) []byte {
 
 // hashPoints implements step 5.1.6 of draft-irtf-cfrg-vrf-10 as defined by the section
 // 5.4.3, ECVRF_hash_points.
-func hashPoints(hByt, gammaByt []byte, points []*edwards25519.Point) []byte {
+func (suite *Suite) hashPoints(hByt, gammaByt []byte, points []*edwards25519.Point) []byte {
 	h := sha512.New()
-	h.Write([]byte{suiteID, 0x02})
+	h.Write([]byte{suite.id, 0x02})
 	h.Write(hByt)
 	h.Write(gammaByt)
 	for _, point := range points {
-		h.Write(point.Bytes())
+		h.Write(suite.encodePoint(point))
 	}
 	h.Write([]byte{0x00})
 
@@ -279,11 +448,11 @@ func hashPoints(hByt, gammaByt []byte, points []*edwards25519.Point) []byte {
 }
 
 // proofToHash implements section 5.2 of draft-irtf-cfrg-vrf-10.
-func proofToHash(gamma *edwards25519.Point) []byte {
+func (suite *Suite) proofToHash(gamma *edwards25519.Point) []byte {
 	h := sha512.New()
-	gammaC := (&edwards25519.Point{}).MultByCofactor(gamma)
-	h.Write([]byte{suiteID, 0x03})
-	h.Write(gammaC.Bytes())
+	gammaC := suite.clearCofactor(gamma)
+	h.Write([]byte{suite.id, 0x03})
+	h.Write(suite.encodePoint(gammaC))
 	h.Write([]byte{0x00})
 
 	return h.Sum(nil)
@@ -295,3 +464,20 @@ func cToScalar(c []byte) (*edwards25519.Scalar, error) {
 	copy(cRaw, c)
 	return edwards25519.NewScalar().SetCanonicalBytes(cRaw)
 }
+
+// clampedScalar derives the secret scalar according to Section 5.1.5 of RFC8032.
+func clampedScalar(h []byte) (*edwards25519.Scalar, error) {
+	return edwards25519.NewScalar().SetBytesWithClamping(h[:scalarSize])
+}
+
+func decodeEdwards(b []byte) (*edwards25519.Point, error) {
+	return (&edwards25519.Point{}).SetBytes(b)
+}
+
+func multByCofactor(p *edwards25519.Point) *edwards25519.Point {
+	return (&edwards25519.Point{}).MultByCofactor(p)
+}
+
+func identityBytes(suite *Suite) []byte {
+	return suite.encodePoint(edwards25519.NewIdentityPoint())
+}
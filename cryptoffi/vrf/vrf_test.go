@@ -209,6 +209,71 @@ func TestFlipBitForgery(t *testing.T) {
 	}
 }
 
+func TestRFCVectors_VerifyMany(t *testing.T) {
+	for _, vector := range testVectors {
+		key, _ := NewPublicKey(vector.pk)
+
+		verified, betas, err := key.VerifyMany([][]byte{vector.alpha}, [][]byte{vector.pi})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		assert.True(t, verified)
+		assert.Equal(t, [][]byte{vector.beta}, betas)
+	}
+}
+
+func TestVerifyMany(t *testing.T) {
+	sk, err := GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pk, _ := sk.Public()
+
+	var msgs, proofs, vrfs [][]byte
+	for i := 0; i < 16; i++ {
+		msg := []byte{byte(i)}
+		vrf, proof, err := sk.Prove(msg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		msgs = append(msgs, msg)
+		proofs = append(proofs, proof)
+		vrfs = append(vrfs, vrf)
+	}
+
+	verified, vrfs0, err := pk.VerifyMany(msgs, proofs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.True(t, verified)
+	assert.Equal(t, vrfs, vrfs0)
+
+	// empty input.
+	verified, vrfs0, err = pk.VerifyMany(nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.True(t, verified)
+	assert.Empty(t, vrfs0)
+
+	// mismatched lengths.
+	_, _, err = pk.VerifyMany(msgs, proofs[1:])
+	assert.Error(t, err)
+
+	// one swapped message fails the whole call.
+	msgs[3], msgs[4] = msgs[4], msgs[3]
+	verified, _, _ = pk.VerifyMany(msgs, proofs)
+	assert.False(t, verified)
+	msgs[3], msgs[4] = msgs[4], msgs[3]
+
+	// one bad proof fails the whole call.
+	proofs[7] = bytes.Clone(proofs[7])
+	proofs[7][pointSize] ^= 1
+	verified, _, _ = pk.VerifyMany(msgs, proofs)
+	assert.False(t, verified)
+}
+
 func BenchmarkProve(b *testing.B) {
 	sk, err := GenerateKey(nil)
 	if err != nil {
@@ -236,3 +301,39 @@ func BenchmarkVerify(b *testing.B) {
 		_, _, _ = pk.Verify(alice, proof)
 	}
 }
+
+func BenchmarkVerifyMany(b *testing.B) {
+	pk, msgs, proofs := benchProofs(b)
+	b.ResetTimer()
+
+	for n := 0; n < b.N; n++ {
+		_, _, _ = pk.VerifyMany(msgs, proofs)
+	}
+}
+
+// BenchmarkVerifyManySerial is the [BenchmarkVerifyMany] baseline.
+func BenchmarkVerifyManySerial(b *testing.B) {
+	pk, msgs, proofs := benchProofs(b)
+	b.ResetTimer()
+
+	for n := 0; n < b.N; n++ {
+		for i := range msgs {
+			_, _, _ = pk.Verify(msgs[i], proofs[i])
+		}
+	}
+}
+
+func benchProofs(b *testing.B) (pk *PublicKey, msgs, proofs [][]byte) {
+	sk, err := GenerateKey(nil)
+	if err != nil {
+		b.Fatal(err)
+	}
+	pk, _ = sk.Public()
+	for i := 0; i < 64; i++ {
+		msg := []byte{byte(i)}
+		_, proof, _ := sk.Prove(msg)
+		msgs = append(msgs, msg)
+		proofs = append(proofs, proof)
+	}
+	return
+}
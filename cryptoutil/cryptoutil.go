@@ -4,8 +4,8 @@ import (
 	"github.com/sanjit-bhat/pav/cryptoffi"
 )
 
-func Hash(b []byte) (hash []byte) {
-	hr := cryptoffi.NewHasher()
+func Hash(suite byte, b []byte) (hash []byte) {
+	hr := cryptoffi.NewHasher(suite)
 	hr.Write(b)
 	return hr.Sum(nil)
 }
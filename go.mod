@@ -9,7 +9,9 @@ require (
 	github.com/goose-lang/std v0.7.0
 	github.com/stretchr/testify v1.11.1
 	github.com/tchajed/marshal v0.6.5
+	golang.org/x/crypto v0.53.0
 	golang.org/x/tools v0.47.0
+	lukechampine.com/blake3 v1.4.1
 )
 
 tool (
@@ -24,6 +26,7 @@ require (
 	github.com/davecgh/go-spew v1.1.1 // indirect
 	github.com/fatih/color v1.19.0 // indirect
 	github.com/inconshreveable/mousetrap v1.1.0 // indirect
+	github.com/klauspost/cpuid/v2 v2.0.9 // indirect
 	github.com/mattn/go-colorable v0.1.14 // indirect
 	github.com/mattn/go-isatty v0.0.20 // indirect
 	github.com/mit-pdos/perennial v0.0.0-20260506192151-f884a505d89d // indirect
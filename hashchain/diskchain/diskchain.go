@@ -0,0 +1,293 @@
+// Package diskchain is a [hashchain.HashChain] backed by an append-only file.
+//
+// the file has a header, Suite ++ Mode, followed by the vals.
+// a separate head file has the len and last link of the chain,
+// along with the caller's sig on them.
+// an aux file has a len-prefixed record for each val,
+// for callers to keep per-val data, e.g., an epoch's updates.
+// vals past the head were never signed, e.g., from a crash mid-epoch
+// or a torn write, so Open drops them, along with their aux records.
+package diskchain
+
+import (
+	"bytes"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/sanjit-bhat/pav/cryptoffi"
+	"github.com/sanjit-bhat/pav/hashchain"
+	"github.com/sanjit-bhat/pav/safemarshal"
+	"github.com/tchajed/marshal"
+)
+
+const hdrLen = 2
+
+// Head is the signed end of a chain.
+type Head struct {
+	Len  uint64
+	Link []byte
+	Sig  []byte
+}
+
+type Chain struct {
+	f        *os.File
+	aux      *os.File
+	headPath string
+	chain    *hashchain.HashChain
+	len      uint64
+	// auxOffs has the end of each aux record, after a 0 start.
+	auxOffs []uint64
+}
+
+// Open the chain at path, creating it if needed.
+// it returns the head, or nil if the chain was never signed.
+// it errors if the file doesn't match suite and mode, or if its vals
+// don't match the head.
+// callers should check the head sig, e.g., with [ktcore.VerifyLinkSig].
+func Open(path string, suite, mode byte) (c *Chain, head *Head, err bool) {
+	f, err0 := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o600)
+	if err0 != nil {
+		err = true
+		return
+	}
+	aux, err0 := os.OpenFile(path+".aux", os.O_RDWR|os.O_CREATE, 0o600)
+	if err0 != nil {
+		f.Close()
+		err = true
+		return
+	}
+	c = &Chain{f: f, aux: aux, headPath: path + ".head", chain: hashchain.NewWithMode(suite, mode)}
+	if head, err = c.recover(suite, mode); err {
+		c.Close()
+		c = nil
+		return
+	}
+	if err = c.recoverAux(); err {
+		c.Close()
+		c = nil
+		return
+	}
+	return
+}
+
+func (c *Chain) recover(suite, mode byte) (head *Head, err bool) {
+	b, err0 := os.ReadFile(c.f.Name())
+	if err0 != nil {
+		err = true
+		return
+	}
+	// the header write might have been torn.
+	if len(b) < hdrLen {
+		b = nil
+	} else if b[0] != suite || b[1] != mode {
+		err = true
+		return
+	}
+
+	var vals []byte
+	if b != nil {
+		vals = b[hdrLen:]
+	}
+	numVals := uint64(len(vals)) / cryptoffi.HashLen
+	hb, err0 := os.ReadFile(c.headPath)
+	if err0 != nil && !errors.Is(err0, fs.ErrNotExist) {
+		err = true
+		return
+	}
+	if err0 == nil {
+		if head, err = decodeHead(hb); err {
+			return
+		}
+		// signed vals should have been synced.
+		if head.Len > numVals {
+			err = true
+			return
+		}
+	}
+
+	var keep uint64
+	if head != nil {
+		keep = head.Len
+	}
+	for i := uint64(0); i < keep; i++ {
+		c.chain.Append(vals[i*cryptoffi.HashLen : (i+1)*cryptoffi.HashLen])
+	}
+	c.len = keep
+	if head != nil && !bytes.Equal(head.Link, c.getLink()) {
+		err = true
+		return
+	}
+
+	// drop unsigned vals, and re-write the header in case it was torn.
+	if c.f.Truncate(int64(hdrLen+keep*cryptoffi.HashLen)) != nil {
+		err = true
+		return
+	}
+	if _, err0 := c.f.WriteAt([]byte{suite, mode}, 0); err0 != nil {
+		err = true
+		return
+	}
+	err = c.f.Sync() != nil
+	return
+}
+
+// recoverAux keeps the aux records for the recovered vals.
+func (c *Chain) recoverAux() (err bool) {
+	b, err0 := os.ReadFile(c.aux.Name())
+	if err0 != nil {
+		err = true
+		return
+	}
+	c.auxOffs = []uint64{0}
+	rem := b
+	for i := uint64(0); i < c.len; i++ {
+		rec, rem0, err0 := safemarshal.ReadSlice1D(rem)
+		if err0 {
+			// aux records are synced before their vals.
+			err = true
+			return
+		}
+		end := c.auxOffs[i] + 8 + uint64(len(rec))
+		c.auxOffs = append(c.auxOffs, end)
+		rem = rem0
+	}
+	if c.aux.Truncate(int64(c.auxOffs[c.len])) != nil {
+		err = true
+		return
+	}
+	err = c.aux.Sync() != nil
+	return
+}
+
+// Append adds val with an empty aux record.
+// it syncs both before returning, and panics if it can't write them.
+func (c *Chain) Append(val []byte) (newLink []byte) {
+	return c.AppendAux(val, nil)
+}
+
+// AppendAux is [Chain.Append], with aux as val's aux record.
+func (c *Chain) AppendAux(val, aux []byte) (newLink []byte) {
+	rec := safemarshal.WriteSlice1D(nil, aux)
+	auxOff := c.auxOffs[c.len]
+	if _, err := c.aux.WriteAt(rec, int64(auxOff)); err != nil {
+		panic("diskchain: write aux: " + err.Error())
+	}
+	if err := c.aux.Sync(); err != nil {
+		panic("diskchain: sync aux: " + err.Error())
+	}
+	c.auxOffs = append(c.auxOffs, auxOff+uint64(len(rec)))
+
+	off := int64(hdrLen + c.len*cryptoffi.HashLen)
+	if _, err := c.f.WriteAt(val, off); err != nil {
+		panic("diskchain: write: " + err.Error())
+	}
+	if err := c.f.Sync(); err != nil {
+		panic("diskchain: sync: " + err.Error())
+	}
+	c.len++
+	return c.chain.Append(val)
+}
+
+// SetHead durably records sig on the current len and last link.
+// it atomically replaces the old head.
+func (c *Chain) SetHead(sig []byte) (err bool) {
+	head := &Head{Len: c.len, Link: c.getLink(), Sig: sig}
+	tmp := c.headPath + ".tmp"
+	f, err0 := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
+	if err0 != nil {
+		return true
+	}
+	_, err0 = f.Write(encodeHead(head))
+	if err0 == nil {
+		err0 = f.Sync()
+	}
+	if f.Close() != nil || err0 != nil {
+		return true
+	}
+	if os.Rename(tmp, c.headPath) != nil {
+		return true
+	}
+	// sync the dir to persist the rename.
+	d, err0 := os.Open(filepath.Dir(c.headPath))
+	if err0 != nil {
+		return true
+	}
+	defer d.Close()
+	return d.Sync() != nil
+}
+
+// Chain returns the in-memory chain, e.g., for proofs.
+// callers should only append through [Chain.Append].
+func (c *Chain) Chain() *hashchain.HashChain {
+	return c.chain
+}
+
+// Val reads the val at idx, e.g., to replay the chain after [Open].
+func (c *Chain) Val(idx uint64) (val []byte, err bool) {
+	if idx >= c.len {
+		err = true
+		return
+	}
+	val = make([]byte, cryptoffi.HashLen)
+	_, err0 := c.f.ReadAt(val, int64(hdrLen+idx*cryptoffi.HashLen))
+	err = err0 != nil
+	return
+}
+
+// Aux reads the aux record of the val at idx.
+func (c *Chain) Aux(idx uint64) (aux []byte, err bool) {
+	if idx >= c.len {
+		err = true
+		return
+	}
+	// skip the record len.
+	start := c.auxOffs[idx] + 8
+	aux = make([]byte, c.auxOffs[idx+1]-start)
+	_, err0 := c.aux.ReadAt(aux, int64(start))
+	err = err0 != nil
+	return
+}
+
+// Len is the number of vals.
+func (c *Chain) Len() uint64 {
+	return c.len
+}
+
+func (c *Chain) Close() (err bool) {
+	err0 := c.f.Close()
+	err1 := c.aux.Close()
+	return err0 != nil || err1 != nil
+}
+
+func (c *Chain) getLink() []byte {
+	return c.chain.Tail().Link()
+}
+
+func encodeHead(h *Head) []byte {
+	b := marshal.WriteInt(nil, h.Len)
+	b = safemarshal.WriteSlice1D(b, h.Link)
+	return safemarshal.WriteSlice1D(b, h.Sig)
+}
+
+func decodeHead(b []byte) (h *Head, err bool) {
+	n, b0, err := safemarshal.ReadInt(b)
+	if err {
+		return
+	}
+	link, b1, err := safemarshal.ReadSlice1D(b0)
+	if err {
+		return
+	}
+	sig, b2, err := safemarshal.ReadSlice1D(b1)
+	if err {
+		return
+	}
+	if len(b2) != 0 || uint64(len(link)) != cryptoffi.HashLen {
+		err = true
+		return
+	}
+	h = &Head{Len: n, Link: link, Sig: sig}
+	return
+}
@@ -0,0 +1,178 @@
+package diskchain
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sanjit-bhat/pav/cryptoffi"
+	"github.com/sanjit-bhat/pav/hashchain"
+)
+
+func TestDiskChain(t *testing.T) {
+	for _, mode := range []byte{hashchain.ModeLinear, hashchain.ModeLog} {
+		testDiskChain(t, mode)
+	}
+}
+
+func testDiskChain(t *testing.T, mode byte) {
+	suite := cryptoffi.HashSuiteSha256
+	path := filepath.Join(t.TempDir(), "chain")
+	c, head, err := Open(path, suite, mode)
+	if err {
+		t.Fatal()
+	}
+	if head != nil {
+		t.Fatal()
+	}
+	exp := hashchain.NewWithMode(suite, mode)
+	var vals [][]byte
+	for i := 0; i < 20; i++ {
+		val := cryptoffi.RandBytes(cryptoffi.HashLen)
+		vals = append(vals, val)
+		link := c.Append(val)
+		if !bytes.Equal(link, exp.Append(val)) {
+			t.Fatal()
+		}
+		if c.SetHead([]byte{byte(i)}) {
+			t.Fatal()
+		}
+	}
+	if c.Close() {
+		t.Fatal()
+	}
+
+	// reopen gives back the chain.
+	c, head, err = Open(path, suite, mode)
+	if err {
+		t.Fatal()
+	}
+	if head.Len != 20 || !bytes.Equal(head.Sig, []byte{19}) {
+		t.Fatal()
+	}
+	if !bytes.Equal(head.Link, exp.Tail().Link()) {
+		t.Fatal()
+	}
+	if !bytes.Equal(c.Chain().Prove(5), exp.Prove(5)) {
+		t.Fatal()
+	}
+	if val, err := c.Val(3); err || !bytes.Equal(val, vals[3]) {
+		t.Fatal()
+	}
+	if _, err := c.Val(20); !err {
+		t.Fatal()
+	}
+
+	// unsigned vals and torn writes get dropped.
+	c.Append(cryptoffi.RandBytes(cryptoffi.HashLen))
+	if c.Close() {
+		t.Fatal()
+	}
+	f, err0 := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0)
+	if err0 != nil {
+		t.Fatal(err0)
+	}
+	f.Write([]byte{1, 2, 3})
+	f.Close()
+	c, head, err = Open(path, suite, mode)
+	if err {
+		t.Fatal()
+	}
+	if c.Len() != 20 || head.Len != 20 {
+		t.Fatal()
+	}
+	link := cryptoffi.RandBytes(cryptoffi.HashLen)
+	if !bytes.Equal(c.Append(link), exp.Append(link)) {
+		t.Fatal()
+	}
+	if c.SetHead(nil) || c.Close() {
+		t.Fatal()
+	}
+
+	// wrong params.
+	if _, _, err = Open(path, suite, mode^1); !err {
+		t.Fatal()
+	}
+	if _, _, err = Open(path, cryptoffi.HashSuiteBlake3, mode); !err {
+		t.Fatal()
+	}
+
+	// corrupt signed val.
+	b, _ := os.ReadFile(path)
+	b[hdrLen+3] ^= 1
+	os.WriteFile(path, b, 0o600)
+	if _, _, err = Open(path, suite, mode); !err {
+		t.Fatal()
+	}
+	// lost signed val.
+	b[hdrLen+3] ^= 1
+	os.WriteFile(path, b[:len(b)-int(cryptoffi.HashLen)], 0o600)
+	if _, _, err = Open(path, suite, mode); !err {
+		t.Fatal()
+	}
+}
+
+func TestTornHeader(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "chain")
+	os.WriteFile(path, []byte{0}, 0o600)
+	c, head, err := Open(path, cryptoffi.HashSuiteSha256, hashchain.ModeLog)
+	if err || head != nil {
+		t.Fatal()
+	}
+	c.Append(make([]byte, cryptoffi.HashLen))
+	if c.SetHead(nil) || c.Close() {
+		t.Fatal()
+	}
+	c, head, err = Open(path, cryptoffi.HashSuiteSha256, hashchain.ModeLog)
+	if err || head.Len != 1 {
+		t.Fatal()
+	}
+	c.Close()
+}
+
+func TestAux(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "chain")
+	c, _, err := Open(path, cryptoffi.HashSuiteSha256, hashchain.ModeLinear)
+	if err {
+		t.Fatal()
+	}
+	for i := 0; i < 5; i++ {
+		c.AppendAux(make([]byte, cryptoffi.HashLen), bytes.Repeat([]byte{byte(i)}, i))
+	}
+	if c.SetHead(nil) {
+		t.Fatal()
+	}
+	// an unsigned val, with its aux record torn.
+	c.AppendAux(make([]byte, cryptoffi.HashLen), []byte{5})
+	if c.Close() {
+		t.Fatal()
+	}
+	b, _ := os.ReadFile(path + ".aux")
+	os.WriteFile(path+".aux", b[:len(b)-1], 0o600)
+
+	c, head, err := Open(path, cryptoffi.HashSuiteSha256, hashchain.ModeLinear)
+	if err || head.Len != 5 {
+		t.Fatal()
+	}
+	for i := 0; i < 5; i++ {
+		aux, err := c.Aux(uint64(i))
+		if err || !bytes.Equal(aux, bytes.Repeat([]byte{byte(i)}, i)) {
+			t.Fatal()
+		}
+	}
+	if _, err := c.Aux(5); !err {
+		t.Fatal()
+	}
+	c.AppendAux(make([]byte, cryptoffi.HashLen), []byte{6})
+	if aux, err := c.Aux(5); err || !bytes.Equal(aux, []byte{6}) {
+		t.Fatal()
+	}
+	c.Close()
+
+	// lost signed aux record.
+	os.WriteFile(path+".aux", nil, 0o600)
+	if _, _, err = Open(path, cryptoffi.HashSuiteSha256, hashchain.ModeLinear); !err {
+		t.Fatal()
+	}
+}
@@ -9,41 +9,120 @@ import (
 	"github.com/sanjit-bhat/pav/cryptoutil"
 )
 
+// chain modes.
+const (
+	// ModeLinear links each val to the last link,
+	// so proofs grow linearly with the number of vals they cover.
+	ModeLinear byte = iota
+	// ModeLog commits to the vals with an RFC 6962-style merkle tree,
+	// so proofs grow logarithmically.
+	// the link is the tree hash.
+	ModeLog
+)
+
+// CheckMode errors if mode isn't a valid chain mode.
+func CheckMode(mode byte) (err bool) {
+	return mode > ModeLog
+}
+
 type HashChain struct {
+	// suite is the [cryptoffi] hash suite.
+	suite byte
+	mode  byte
+	// predLastLink is only for [ModeLinear].
 	predLastLink []byte
 	lastLink     []byte
 	// vals is pre-flattened to quickly convert it to a proof.
 	vals []byte
+	// links is pre-flattened, with the link after each val.
+	// it's only for [ModeLinear].
+	links []byte
+	// tree is only for [ModeLog], see [HashChain.subHash].
+	tree [][]byte
 }
 
 // Append adds a val.
 // it expects val to be of constant len, which lets us encode smaller proofs.
 func (c *HashChain) Append(val []byte) (newLink []byte) {
 	std.Assert(uint64(len(val)) == cryptoffi.HashLen)
+	if c.mode == ModeLog {
+		c.vals = append(c.vals, val...)
+		c.appendTree(val)
+		c.lastLink = c.subHash(0, uint64(len(c.vals))/cryptoffi.HashLen)
+		return c.lastLink
+	}
 	c.predLastLink = c.lastLink
-	c.lastLink = GetNextLink(c.lastLink, val)
+	c.lastLink = GetNextLink(c.suite, c.lastLink, val)
 	c.vals = append(c.vals, val...)
+	c.links = append(c.links, c.lastLink...)
 	return c.lastLink
 }
 
 // Prove transitions from knowing a prevLen prefix to knowing the latest list.
 // it expects prevLen <= curr len.
 func (c *HashChain) Prove(prevLen uint64) (proof []byte) {
-	start := prevLen * cryptoffi.HashLen
-	return bytes.Clone(c.vals[start:])
+	return c.ProveSkip(prevLen, uint64(len(c.vals))/cryptoffi.HashLen)
+}
+
+// ProveSkip is [HashChain.Prove], but to the newLen prefix
+// instead of the latest list, e.g., to skip to a checkpoint.
+// for [ModeLog], the proof is logarithmic in newLen.
+// it expects prevLen <= newLen <= curr len.
+func (c *HashChain) ProveSkip(prevLen, newLen uint64) (proof []byte) {
+	std.Assert(newLen <= uint64(len(c.vals))/cryptoffi.HashLen)
+	if c.mode == ModeLog {
+		return c.proveLog(prevLen, newLen)
+	}
+	std.Assert(prevLen <= newLen)
+	return bytes.Clone(c.vals[prevLen*cryptoffi.HashLen : newLen*cryptoffi.HashLen])
+}
+
+// ProveIncl proves that the val at idx is in the knownLen prefix.
+// unlike [HashChain.Prove], the verifier only needs the last link,
+// not the link before idx.
+// it expects idx < knownLen <= curr len.
+func (c *HashChain) ProveIncl(idx, knownLen uint64) (proof []byte) {
+	if c.mode == ModeLog {
+		return c.proveInclLog(idx, knownLen)
+	}
+	var prevLink []byte
+	if idx == 0 {
+		prevLink = GetEmptyLink(c.suite)
+	} else {
+		prevLink = c.links[(idx-1)*cryptoffi.HashLen : idx*cryptoffi.HashLen]
+	}
+	start := idx * cryptoffi.HashLen
+	end := knownLen * cryptoffi.HashLen
+	proof = append(proof, prevLink...)
+	proof = append(proof, c.vals[start:end]...)
+	return
 }
 
 // Bootstrap hashchain verifiers with the last value.
+// the proof extends the bootLen prefix, whose link is bootLink.
 // it expects non-empty values.
-func (c *HashChain) Bootstrap() (bootLink []byte, proof []byte) {
+func (c *HashChain) Bootstrap() (bootLen uint64, bootLink []byte, proof []byte) {
+	if c.mode == ModeLog {
+		// log proofs don't depend on the prefix, so use the empty one.
+		return 0, GetEmptyLink(c.suite), c.proveLog(0, uint64(len(c.vals))/cryptoffi.HashLen)
+	}
 	start := uint64(len(c.vals)) - cryptoffi.HashLen
-	return c.predLastLink, bytes.Clone(c.vals[start:])
+	bootLen = start / cryptoffi.HashLen
+	return bootLen, c.predLastLink, bytes.Clone(c.vals[start:])
 }
 
-// Verify updates prevLink with proof.
+// Verify updates prevLink, the link of a prevLen prefix, with proof.
 // if extended length is 0, new val is nil.
 // it errors for a badly-encoded proof.
-func Verify(prevLink, proof []byte) (extLen uint64, newVal []byte, newLink []byte, err bool) {
+func Verify(mode, suite byte, prevLen uint64, prevLink, proof []byte) (extLen uint64, newVal []byte, newLink []byte, err bool) {
+	if mode == ModeLog {
+		extLen, newVal, newLink, _, err = verifyLog(suite, prevLen, prevLink, proof)
+		return
+	}
+	return verifyLinear(suite, prevLink, proof)
+}
+
+func verifyLinear(suite byte, prevLink, proof []byte) (extLen uint64, newVal []byte, newLink []byte, err bool) {
 	proofLen := uint64(len(proof))
 	if proofLen%cryptoffi.HashLen != 0 {
 		err = true
@@ -55,21 +134,60 @@ func Verify(prevLink, proof []byte) (extLen uint64, newVal []byte, newLink []byt
 	for i := uint64(0); i < extLen; i++ {
 		newVal = proof[:cryptoffi.HashLen]
 		proof = proof[cryptoffi.HashLen:]
-		newLink = GetNextLink(newLink, newVal)
+		newLink = GetNextLink(suite, newLink, newVal)
 	}
 	return
 }
 
-func New() *HashChain {
-	return &HashChain{lastLink: GetEmptyLink()}
+// VerifyIncl returns the val at idx and the link of the knownLen prefix
+// that includes it.
+// callers should check that they got the expected link.
+// it errors for a badly-encoded proof.
+func VerifyIncl(mode, suite byte, idx, knownLen uint64, proof []byte) (val, link []byte, err bool) {
+	if idx >= knownLen {
+		err = true
+		return
+	}
+	if mode == ModeLog {
+		return verifyInclLog(suite, idx, knownLen, proof)
+	}
+	if uint64(len(proof)) < 2*cryptoffi.HashLen {
+		err = true
+		return
+	}
+	prevLink := proof[:cryptoffi.HashLen]
+	vals := proof[cryptoffi.HashLen:]
+	val = vals[:cryptoffi.HashLen]
+	extLen, _, link, err := verifyLinear(suite, prevLink, vals)
+	if err {
+		return
+	}
+	if extLen != knownLen-idx {
+		err = true
+		return
+	}
+	return
+}
+
+// New returns an empty [ModeLinear] hashchain that hashes with suite.
+func New(suite byte) *HashChain {
+	return NewWithMode(suite, ModeLinear)
+}
+
+// NewWithMode returns an empty hashchain that hashes with suite
+// and has the given chain mode.
+func NewWithMode(suite, mode byte) *HashChain {
+	std.Assert(!cryptoffi.CheckHashSuite(suite))
+	std.Assert(!CheckMode(mode))
+	return &HashChain{suite: suite, mode: mode, lastLink: GetEmptyLink(suite)}
 }
 
-func GetEmptyLink() []byte {
-	return cryptoutil.Hash(nil)
+func GetEmptyLink(suite byte) []byte {
+	return cryptoutil.Hash(suite, nil)
 }
 
-func GetNextLink(prevLink, nextVal []byte) []byte {
-	hr := cryptoffi.NewHasher()
+func GetNextLink(suite byte, prevLink, nextVal []byte) []byte {
+	hr := cryptoffi.NewHasher(suite)
 	hr.Write(prevLink)
 	hr.Write(nextVal)
 	return hr.Sum(nil)
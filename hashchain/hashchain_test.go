@@ -9,16 +9,24 @@ import (
 )
 
 func TestHashChain(t *testing.T) {
+	for _, suite := range []byte{cryptoffi.HashSuiteSha256, cryptoffi.HashSuiteSha512_256, cryptoffi.HashSuiteBlake3} {
+		for _, mode := range []byte{ModeLinear, ModeLog} {
+			testHashChain(t, suite, mode)
+		}
+	}
+}
+
+func testHashChain(t *testing.T, suite, mode byte) {
 	var seed [32]byte
 	rndSrc := rand.NewChaCha8(seed)
 	rnd := rand.New(rndSrc)
-	chain := New()
-	links := [][]byte{GetEmptyLink()}
+	chain := NewWithMode(suite, mode)
+	links := [][]byte{GetEmptyLink(suite)}
 
 	{
 		// empty chain.
 		p := chain.Prove(0)
-		newLen, newVal, newLink, err := Verify(links[0], p)
+		newLen, newVal, newLink, err := Verify(mode, suite, 0, links[0], p)
 		if err {
 			t.Fatal()
 		}
@@ -41,7 +49,7 @@ func TestHashChain(t *testing.T) {
 
 		prevLen := rnd.Uint64N(newLen + 1)
 		proof0 := chain.Prove(prevLen)
-		extLen0, newVal0, newLink0, err := Verify(links[prevLen], proof0)
+		extLen0, newVal0, newLink0, err := Verify(mode, suite, prevLen, links[prevLen], proof0)
 		if err {
 			t.Fatal()
 		}
@@ -58,19 +66,180 @@ func TestHashChain(t *testing.T) {
 			t.Fatal()
 		}
 
-		startLink, startVal := chain.Bootstrap()
-		extLen1, newVal1, newLink1, err := Verify(startLink, startVal)
+		startLen, startLink, startProof := chain.Bootstrap()
+		tail, newVal1, err := VerifyTail(mode, suite, startLen, startLink, startProof)
 		if err {
 			t.Fatal()
 		}
-		if extLen1 != 1 {
+		if tail.Len() != newLen {
 			t.Fatal()
 		}
 		if !bytes.Equal(newVal, newVal1) {
 			t.Fatal()
 		}
-		if !bytes.Equal(newLink, newLink1) {
+		if !bytes.Equal(newLink, tail.Link()) {
+			t.Fatal()
+		}
+
+		// the tail extends like the chain.
+		if newLen%50 == 0 {
+			extVal := make([]byte, cryptoffi.HashLen)
+			rndSrc.Read(extVal)
+			chain0 := NewWithMode(suite, mode)
+			for i := uint64(0); i < newLen; i++ {
+				chain0.Append(chain.vals[i*cryptoffi.HashLen : (i+1)*cryptoffi.HashLen])
+			}
+			tail0 := chain.Tail()
+			if !bytes.Equal(chain0.Append(extVal), tail.Append(extVal).Link()) {
+				t.Fatal()
+			}
+			if !bytes.Equal(chain0.lastLink, tail0.Append(extVal).Link()) {
+				t.Fatal()
+			}
+		}
+	}
+}
+
+func TestLogProofSize(t *testing.T) {
+	suite := cryptoffi.HashSuiteSha256
+	chain := NewWithMode(suite, ModeLog)
+	val := make([]byte, cryptoffi.HashLen)
+	var link []byte
+	for i := 0; i < 10_000; i++ {
+		val[0] = byte(i)
+		link = chain.Append(val)
+	}
+	p := chain.Prove(3)
+	// roughly 2 log(n) hashes.
+	if uint64(len(p)) > 2*14*cryptoffi.HashLen+8+cryptoffi.HashLen {
+		t.Fatal(len(p))
+	}
+	extLen, _, link0, err := Verify(ModeLog, suite, 3, chain.subHash(0, 3), p)
+	if err {
+		t.Fatal()
+	}
+	if extLen != 10_000-3 || !bytes.Equal(link, link0) {
+		t.Fatal()
+	}
+
+	// bad proofs.
+	for i := 0; i < len(p); i += 7 {
+		if _, _, _, err := Verify(ModeLog, suite, 3, chain.subHash(0, 3), p[:i]); !err {
+			t.Fatal()
+		}
+	}
+	p[len(p)-1] ^= 1
+	if _, _, _, err := Verify(ModeLog, suite, 3, chain.subHash(0, 3), p); !err {
+		t.Fatal()
+	}
+	p[len(p)-1] ^= 1
+	if _, _, _, err := Verify(ModeLog, suite, 4, chain.subHash(0, 3), p); !err {
+		t.Fatal()
+	}
+}
+
+func TestIncl(t *testing.T) {
+	for _, mode := range []byte{ModeLinear, ModeLog} {
+		testIncl(t, mode)
+	}
+}
+
+func testIncl(t *testing.T, mode byte) {
+	suite := cryptoffi.HashSuiteSha256
+	var seed [32]byte
+	rnd := rand.NewChaCha8(seed)
+	chain := NewWithMode(suite, mode)
+	var vals, links [][]byte
+	for i := 0; i < 100; i++ {
+		val := make([]byte, cryptoffi.HashLen)
+		rnd.Read(val)
+		vals = append(vals, val)
+		links = append(links, chain.Append(val))
+	}
+
+	for knownLen := uint64(1); knownLen <= 100; knownLen++ {
+		for idx := uint64(0); idx < knownLen; idx++ {
+			p := chain.ProveIncl(idx, knownLen)
+			val, link, err := VerifyIncl(mode, suite, idx, knownLen, p)
+			if err {
+				t.Fatal()
+			}
+			if !bytes.Equal(val, vals[idx]) {
+				t.Fatal()
+			}
+			if !bytes.Equal(link, links[knownLen-1]) {
+				t.Fatal()
+			}
+		}
+	}
+
+	// bad encodings.
+	for _, p := range [][]byte{nil, make([]byte, cryptoffi.HashLen), make([]byte, cryptoffi.HashLen+1), make([]byte, 2*cryptoffi.HashLen+1)} {
+		if _, _, err := VerifyIncl(mode, suite, 10, 20, p); !err {
 			t.Fatal()
 		}
 	}
+	// the wrong idx.
+	if _, _, err := VerifyIncl(mode, suite, 20, 20, chain.ProveIncl(10, 20)); !err {
+		t.Fatal()
+	}
+
+	// a wrong val gives a different link.
+	// linear proofs start with prevLink, log proofs with the val.
+	p := chain.ProveIncl(10, 20)
+	valOff := 0
+	if mode == ModeLinear {
+		valOff = int(cryptoffi.HashLen)
+	}
+	p[valOff] ^= 1
+	val, link, err := VerifyIncl(mode, suite, 10, 20, p)
+	if err {
+		t.Fatal()
+	}
+	if bytes.Equal(val, vals[10]) {
+		t.Fatal()
+	}
+	if bytes.Equal(link, links[19]) {
+		t.Fatal()
+	}
+}
+
+func TestSkip(t *testing.T) {
+	for _, mode := range []byte{ModeLinear, ModeLog} {
+		testSkip(t, mode)
+	}
+}
+
+func testSkip(t *testing.T, mode byte) {
+	suite := cryptoffi.HashSuiteSha256
+	var seed [32]byte
+	rnd := rand.NewChaCha8(seed)
+	chain := NewWithMode(suite, mode)
+	var vals [][]byte
+	links := [][]byte{GetEmptyLink(suite)}
+	for i := 0; i < 40; i++ {
+		val := make([]byte, cryptoffi.HashLen)
+		rnd.Read(val)
+		vals = append(vals, val)
+		links = append(links, chain.Append(val))
+	}
+
+	for newLen := uint64(1); newLen <= 40; newLen++ {
+		for prevLen := uint64(0); prevLen < newLen; prevLen++ {
+			p := chain.ProveSkip(prevLen, newLen)
+			extLen, val, link, err := Verify(mode, suite, prevLen, links[prevLen], p)
+			if err {
+				t.Fatal()
+			}
+			if extLen != newLen-prevLen {
+				t.Fatal()
+			}
+			if !bytes.Equal(val, vals[newLen-1]) {
+				t.Fatal()
+			}
+			if !bytes.Equal(link, links[newLen]) {
+				t.Fatal()
+			}
+		}
+	}
 }
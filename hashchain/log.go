@@ -0,0 +1,328 @@
+package hashchain
+
+import (
+	"bytes"
+
+	"github.com/goose-lang/std"
+	"github.com/sanjit-bhat/pav/cryptoffi"
+	"github.com/sanjit-bhat/pav/safemarshal"
+	"github.com/tchajed/marshal"
+)
+
+// [ModeLog] follows RFC 9162, sec 2.1, with domain-separated
+// leaf and inner hashes.
+//
+// log proof encoding:
+//   - Prove: ExtLen ++ Val ++ LastIncl ++ Consist,
+//     where Val is the last val, LastIncl is its inclusion proof,
+//     and Consist is the consistency proof from the prev len.
+//     for ExtLen 0, it's just ExtLen.
+//   - ProveIncl: Val ++ Incl.
+const (
+	leafTag byte = iota
+	innerTag
+)
+
+// appendTree adds val to the tree levels.
+// level i has the hash of each full sub-tree with 2^i leaves,
+// pre-flattened.
+func (c *HashChain) appendTree(val []byte) {
+	h := getLeafHash(c.suite, val)
+	for lvl := 0; ; lvl++ {
+		if lvl == len(c.tree) {
+			c.tree = append(c.tree, nil)
+		}
+		c.tree[lvl] = append(c.tree[lvl], h...)
+		n := uint64(len(c.tree[lvl]))
+		// odd number of sub-trees, so no new parent.
+		if (n/cryptoffi.HashLen)%2 == 1 {
+			return
+		}
+		left := c.tree[lvl][n-2*cryptoffi.HashLen : n-cryptoffi.HashLen]
+		h = getInnerHash(c.suite, left, h)
+	}
+}
+
+// subHash returns the tree hash of vals in [start, end).
+// it expects start to be aligned to the largest power of 2
+// less than end-start, as in all RFC 9162 sub-trees.
+func (c *HashChain) subHash(start, end uint64) []byte {
+	size := end - start
+	if size == 0 {
+		return GetEmptyLink(c.suite)
+	}
+	k := getSplit(size)
+	// full sub-trees are in the tree levels.
+	if k == size {
+		var lvl uint64
+		for (uint64(1) << lvl) < size {
+			lvl++
+		}
+		idx := start / size
+		return c.tree[lvl][idx*cryptoffi.HashLen : (idx+1)*cryptoffi.HashLen]
+	}
+	return getInnerHash(c.suite, c.subHash(start, start+k), c.subHash(start+k, end))
+}
+
+// proveIncl appends the inclusion proof for the leaf at idx in the
+// sub-tree [start, end).
+func (c *HashChain) proveIncl(proof []byte, idx, start, end uint64) []byte {
+	size := end - start
+	if size == 1 {
+		return proof
+	}
+	k := getSplit(size - 1)
+	if idx < k {
+		proof0 := c.proveIncl(proof, idx, start, start+k)
+		return append(proof0, c.subHash(start+k, end)...)
+	}
+	proof0 := c.proveIncl(proof, idx-k, start+k, end)
+	return append(proof0, c.subHash(start, start+k)...)
+}
+
+// proveConsist appends the consistency proof from the first m leaves of
+// the sub-tree [start, end). isFull says if the m leaves are a sub-tree
+// whose hash the verifier already has.
+func (c *HashChain) proveConsist(proof []byte, m, start, end uint64, isFull bool) []byte {
+	size := end - start
+	if m == size {
+		if isFull {
+			return proof
+		}
+		return append(proof, c.subHash(start, end)...)
+	}
+	k := getSplit(size - 1)
+	if m <= k {
+		proof0 := c.proveConsist(proof, m, start, start+k, isFull)
+		return append(proof0, c.subHash(start+k, end)...)
+	}
+	proof0 := c.proveConsist(proof, m-k, start+k, end, false)
+	return append(proof0, c.subHash(start, start+k)...)
+}
+
+// proveLog proves the n prefix from the prevLen prefix.
+func (c *HashChain) proveLog(prevLen, n uint64) (proof []byte) {
+	std.Assert(prevLen <= n)
+	proof = marshal.WriteInt(proof, n-prevLen)
+	if prevLen == n {
+		return
+	}
+	proof = marshal.WriteBytes(proof, c.vals[(n-1)*cryptoffi.HashLen:n*cryptoffi.HashLen])
+	proof = c.proveIncl(proof, n-1, 0, n)
+	if prevLen != 0 {
+		proof = c.proveConsist(proof, prevLen, 0, n, true)
+	}
+	return
+}
+
+func (c *HashChain) proveInclLog(idx, knownLen uint64) (proof []byte) {
+	proof = marshal.WriteBytes(proof, c.vals[idx*cryptoffi.HashLen:(idx+1)*cryptoffi.HashLen])
+	return c.proveIncl(proof, idx, 0, knownLen)
+}
+
+// verifyLog also returns the peaks of the new tree, see [Tail].
+// peaks is nil if extLen is 0.
+func verifyLog(suite byte, prevLen uint64, prevLink, proof []byte) (extLen uint64, newVal, newLink []byte, peaks [][]byte, err bool) {
+	extLen, b0, err := safemarshal.ReadInt(proof)
+	if err {
+		return
+	}
+	if extLen == 0 {
+		if len(b0) != 0 {
+			err = true
+			return
+		}
+		newLink = prevLink
+		return
+	}
+	if !std.SumNoOverflow(prevLen, extLen) {
+		err = true
+		return
+	}
+	n := prevLen + extLen
+	newVal, b1, err := safemarshal.ReadBytes(b0, cryptoffi.HashLen)
+	if err {
+		return
+	}
+	incl, consist, err := safemarshal.ReadBytes(b1, getLastInclLen(n)*cryptoffi.HashLen)
+	if err {
+		return
+	}
+	peaks, newLink = getPeaks(suite, n, newVal, incl)
+
+	if prevLen == 0 {
+		// anything is consistent with the empty tree.
+		if len(consist) != 0 || !bytes.Equal(prevLink, GetEmptyLink(suite)) {
+			err = true
+		}
+		return
+	}
+	newLink0, err := verifyConsist(suite, prevLen, n, prevLink, consist)
+	if err {
+		return
+	}
+	if !bytes.Equal(newLink, newLink0) {
+		err = true
+		return
+	}
+	return
+}
+
+func verifyInclLog(suite byte, idx, knownLen uint64, proof []byte) (val, link []byte, err bool) {
+	val, incl, err := safemarshal.ReadBytes(proof, cryptoffi.HashLen)
+	if err {
+		return
+	}
+	if uint64(len(incl))%cryptoffi.HashLen != 0 {
+		err = true
+		return
+	}
+	// RFC 9162, sec 2.1.3.2.
+	fn := idx
+	sn := knownLen - 1
+	r := getLeafHash(suite, val)
+	for len(incl) != 0 {
+		p := incl[:cryptoffi.HashLen]
+		incl = incl[cryptoffi.HashLen:]
+		if sn == 0 {
+			err = true
+			return
+		}
+		if fn%2 == 1 || fn == sn {
+			r = getInnerHash(suite, p, r)
+			for fn%2 == 0 && fn != 0 {
+				fn >>= 1
+				sn >>= 1
+			}
+		} else {
+			r = getInnerHash(suite, r, p)
+		}
+		fn >>= 1
+		sn >>= 1
+	}
+	if sn != 0 {
+		err = true
+		return
+	}
+	link = r
+	return
+}
+
+// verifyConsist returns the hash of the n-leaf tree that extends
+// the m-leaf tree with hash mHash.
+// it expects 0 < m < n.
+func verifyConsist(suite byte, m, n uint64, mHash, proof []byte) (nHash []byte, err bool) {
+	if uint64(len(proof))%cryptoffi.HashLen != 0 {
+		err = true
+		return
+	}
+	// RFC 9162, sec 2.1.4.2.
+	path := proof
+	if getSplit(m) == m {
+		path = append(bytes.Clone(mHash), proof...)
+	}
+	if len(path) == 0 {
+		err = true
+		return
+	}
+	fn := m - 1
+	sn := n - 1
+	for fn%2 == 1 {
+		fn >>= 1
+		sn >>= 1
+	}
+	fr := path[:cryptoffi.HashLen]
+	sr := fr
+	path = path[cryptoffi.HashLen:]
+	for len(path) != 0 {
+		c := path[:cryptoffi.HashLen]
+		path = path[cryptoffi.HashLen:]
+		if sn == 0 {
+			err = true
+			return
+		}
+		if fn%2 == 1 || fn == sn {
+			fr = getInnerHash(suite, c, fr)
+			sr = getInnerHash(suite, c, sr)
+			for fn%2 == 0 && fn != 0 {
+				fn >>= 1
+				sn >>= 1
+			}
+		} else {
+			sr = getInnerHash(suite, sr, c)
+		}
+		fn >>= 1
+		sn >>= 1
+	}
+	if sn != 0 || !bytes.Equal(fr, mHash) {
+		err = true
+		return
+	}
+	nHash = sr
+	return
+}
+
+// getPeaks returns the peaks and hash of an n-leaf tree from its last val
+// and the val's inclusion proof.
+// the proof has the hashes inside the last val's peak, then the other peaks,
+// from smallest to largest.
+func getPeaks(suite byte, n uint64, val, incl []byte) (peaks [][]byte, hash []byte) {
+	peaks = make([][]byte, 64)
+	hash = getLeafHash(suite, val)
+	var lvl uint64
+	for ; n&(1<<lvl) == 0; lvl++ {
+		hash = getInnerHash(suite, incl[:cryptoffi.HashLen], hash)
+		incl = incl[cryptoffi.HashLen:]
+	}
+	peaks[lvl] = hash
+	for lvl++; lvl < 64; lvl++ {
+		if n&(1<<lvl) != 0 {
+			peaks[lvl] = incl[:cryptoffi.HashLen]
+			incl = incl[cryptoffi.HashLen:]
+			hash = getInnerHash(suite, peaks[lvl], hash)
+		}
+	}
+	return
+}
+
+// getLastInclLen returns the number of hashes in the inclusion proof
+// of the last val in an n-leaf tree.
+func getLastInclLen(n uint64) (l uint64) {
+	// the trailing zeros give the hashes inside the last peak.
+	var lvl uint64
+	for ; n&(1<<lvl) == 0; lvl++ {
+		l++
+	}
+	// the other set bits give the other peaks.
+	for lvl++; lvl < 64; lvl++ {
+		if n&(1<<lvl) != 0 {
+			l++
+		}
+	}
+	return
+}
+
+// getSplit returns the largest power of 2 that's at most n.
+// it expects n > 0.
+func getSplit(n uint64) uint64 {
+	k := uint64(1)
+	for k <= n/2 {
+		k <<= 1
+	}
+	return k
+}
+
+func getLeafHash(suite byte, val []byte) []byte {
+	hr := cryptoffi.NewHasher(suite)
+	hr.Write([]byte{leafTag})
+	hr.Write(val)
+	return hr.Sum(nil)
+}
+
+func getInnerHash(suite byte, left, right []byte) []byte {
+	hr := cryptoffi.NewHasher(suite)
+	hr.Write([]byte{innerTag})
+	hr.Write(left)
+	hr.Write(right)
+	return hr.Sum(nil)
+}
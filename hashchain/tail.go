@@ -0,0 +1,110 @@
+package hashchain
+
+import (
+	"github.com/goose-lang/std"
+	"github.com/sanjit-bhat/pav/cryptoffi"
+)
+
+// Tail has just enough of a chain to extend it, without the vals.
+// it's immutable.
+type Tail struct {
+	suite byte
+	mode  byte
+	len   uint64
+	link  []byte
+	// peaks is only for [ModeLog].
+	// if bit i of len is set, peaks[i] is the hash of the
+	// full sub-tree with 2^i leaves.
+	peaks [][]byte
+}
+
+// VerifyTail is [Verify], but returns a [Tail] for the extended chain.
+// it errors if proof doesn't add any vals.
+func VerifyTail(mode, suite byte, prevLen uint64, prevLink, proof []byte) (t *Tail, newVal []byte, err bool) {
+	var extLen uint64
+	var newLink []byte
+	var peaks [][]byte
+	if mode == ModeLog {
+		extLen, newVal, newLink, peaks, err = verifyLog(suite, prevLen, prevLink, proof)
+	} else {
+		extLen, newVal, newLink, err = verifyLinear(suite, prevLink, proof)
+	}
+	if err {
+		return
+	}
+	if extLen == 0 {
+		err = true
+		return
+	}
+	if !std.SumNoOverflow(prevLen, extLen) {
+		err = true
+		return
+	}
+	t = &Tail{suite: suite, mode: mode, len: prevLen + extLen, link: newLink, peaks: peaks}
+	return
+}
+
+// Tail returns the chain's tail, e.g., to resume verifying it
+// after a restart.
+func (c *HashChain) Tail() *Tail {
+	n := uint64(len(c.vals)) / cryptoffi.HashLen
+	t := &Tail{suite: c.suite, mode: c.mode, len: n, link: c.lastLink}
+	if c.mode != ModeLog {
+		return t
+	}
+	// the peak for bit lvl starts after the larger peaks.
+	t.peaks = make([][]byte, 64)
+	for lvl := uint64(0); lvl < 64; lvl++ {
+		if n&(1<<lvl) == 0 {
+			continue
+		}
+		idx := (n >> (lvl + 1)) * 2
+		t.peaks[lvl] = c.tree[lvl][idx*cryptoffi.HashLen : (idx+1)*cryptoffi.HashLen]
+	}
+	return t
+}
+
+// Len is the number of vals in the chain.
+func (t *Tail) Len() uint64 {
+	return t.len
+}
+
+// Link is the last link of the chain.
+func (t *Tail) Link() []byte {
+	return t.link
+}
+
+// Append returns the tail with val added.
+// it expects val to be of constant len, like [HashChain.Append].
+func (t *Tail) Append(val []byte) *Tail {
+	std.Assert(std.SumNoOverflow(t.len, 1))
+	n := t.len + 1
+	if t.mode != ModeLog {
+		return &Tail{suite: t.suite, mode: t.mode, len: n, link: GetNextLink(t.suite, t.link, val)}
+	}
+
+	// merge equal-size peaks, like adding 1 to len.
+	peaks := make([][]byte, 64)
+	copy(peaks, t.peaks)
+	h := getLeafHash(t.suite, val)
+	var lvl uint64
+	for ; t.len&(1<<lvl) != 0; lvl++ {
+		h = getInnerHash(t.suite, peaks[lvl], h)
+		peaks[lvl] = nil
+	}
+	peaks[lvl] = h
+
+	// the tree hash folds the peaks from smallest to largest.
+	var link []byte
+	for lvl := uint64(0); lvl < 64; lvl++ {
+		if peaks[lvl] == nil {
+			continue
+		}
+		if link == nil {
+			link = peaks[lvl]
+		} else {
+			link = getInnerHash(t.suite, peaks[lvl], link)
+		}
+	}
+	return &Tail{suite: t.suite, mode: t.mode, len: n, link: link, peaks: peaks}
+}
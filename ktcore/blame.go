@@ -21,5 +21,6 @@ const (
 	// in this system, these are the only [BlameUnknown]s:
 	//  * misc network errors.
 	//  * Auditor.Get errors.
+	//  * Client.GetAtEpoch on an epoch the client hasn't seen.
 	BlameUnknown
 )
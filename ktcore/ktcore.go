@@ -9,20 +9,39 @@ import (
 	"github.com/sanjit-bhat/pav/cryptoutil"
 )
 
-func SignVrf(sk *cryptoffi.SigPrivateKey, vrfPk []byte) (sig []byte) {
-	b := make([]byte, 0, 1+8+32)
-	b = VrfSigEncode(b, &VrfSig{SigTag: VrfSigTag, VrfPk: vrfPk})
+// SignVrf signs the server's static params: its VRF and hash suites,
+// and its VRF pk.
+func SignVrf(sk *cryptoffi.SigPrivateKey, vrfSuite, hashSuite byte, vrfPk []byte) (sig []byte) {
+	b := make([]byte, 0, 1+1+1+8+32)
+	b = VrfSigEncode(b, &VrfSig{SigTag: VrfSigTag, VrfSuite: vrfSuite, HashSuite: hashSuite, VrfPk: vrfPk})
 	// benchmark: turn off sigs for akd compat.
 	sig = sk.Sign(b)
 	return
 }
 
-func VerifyVrfSig(pk cryptoffi.SigPublicKey, vrfPk, sig []byte) (err bool) {
-	b := make([]byte, 0, 1+8+32)
-	b = VrfSigEncode(b, &VrfSig{SigTag: VrfSigTag, VrfPk: vrfPk})
+// VerifyVrfSig errs if the sig doesn't cover the params,
+// or if vrfPk doesn't have length [cryptoffi.VrfPkLen],
+// which no honest server signs.
+func VerifyVrfSig(pk cryptoffi.SigPublicKey, vrfSuite, hashSuite byte, vrfPk, sig []byte) (err bool) {
+	if uint64(len(vrfPk)) != cryptoffi.VrfPkLen {
+		return true
+	}
+	b := make([]byte, 0, 1+1+1+8+32)
+	b = VrfSigEncode(b, &VrfSig{SigTag: VrfSigTag, VrfSuite: vrfSuite, HashSuite: hashSuite, VrfPk: vrfPk})
 	return pk.Verify(b, sig)
 }
 
+// CheckVrfSigMsg errs if b isn't the canonical encoding of a [VrfSig],
+// i.e., VrfSigEncode(VrfSigDecode(b)) != b, or if it has the wrong tag.
+// it's for callers that get signed-over bytes, rather than params.
+func CheckVrfSigMsg(b []byte) (err bool) {
+	o, err := VrfSigDecodeStrict(b)
+	if err {
+		return
+	}
+	return o.SigTag != VrfSigTag
+}
+
 func SignLink(sk *cryptoffi.SigPrivateKey, epoch uint64, link []byte) (sig []byte) {
 	b := make([]byte, 0, 1+8+8+cryptoffi.HashLen)
 	b = LinkSigEncode(b, &LinkSig{SigTag: LinkSigTag, Epoch: epoch, Link: link})
@@ -31,12 +50,26 @@ func SignLink(sk *cryptoffi.SigPrivateKey, epoch uint64, link []byte) (sig []byt
 	return
 }
 
+// VerifyLinkSig errs if the sig doesn't cover the link,
+// or if the link doesn't have length [cryptoffi.HashLen].
 func VerifyLinkSig(pk cryptoffi.SigPublicKey, epoch uint64, link, sig []byte) (err bool) {
+	if uint64(len(link)) != cryptoffi.HashLen {
+		return true
+	}
 	b := make([]byte, 0, 1+8+8+cryptoffi.HashLen)
 	b = LinkSigEncode(b, &LinkSig{SigTag: LinkSigTag, Epoch: epoch, Link: link})
 	return pk.Verify(b, sig)
 }
 
+// CheckLinkSigMsg is [CheckVrfSigMsg] for a [LinkSig].
+func CheckLinkSigMsg(b []byte) (err bool) {
+	o, err := LinkSigDecodeStrict(b)
+	if err {
+		return
+	}
+	return o.SigTag != LinkSigTag
+}
+
 func ProveMapLabel(sk *cryptoffi.VrfPrivateKey, uid uint64, ver uint64) (label []byte, proof []byte) {
 	b := make([]byte, 0, 16)
 	b = MapLabelEncode(b, &MapLabel{Uid: uid, Ver: ver})
@@ -55,16 +88,28 @@ func CheckMapLabel(pk *cryptoffi.VrfPublicKey, uid, ver uint64, proof []byte) (l
 	return pk.Verify(b, proof)
 }
 
-func GetMapVal(pk []byte, rand []byte) (val []byte) {
+// CheckMapLabels checks the labels for uid's versions
+// [startVer, startVer+len(proofs)), verifying the VRF proofs across cores.
+func CheckMapLabels(pk *cryptoffi.VrfPublicKey, uid, startVer uint64, proofs [][]byte) (labels [][]byte, err bool) {
+	data := make([][]byte, 0, len(proofs))
+	for i := range proofs {
+		b := make([]byte, 0, 16)
+		b = MapLabelEncode(b, &MapLabel{Uid: uid, Ver: startVer + uint64(i)})
+		data = append(data, b)
+	}
+	return pk.VerifyMany(data, proofs)
+}
+
+func GetMapVal(hashSuite byte, pk []byte, rand []byte) (val []byte) {
 	b := make([]byte, 0, 8+32+8+cryptoffi.HashLen)
 	b = CommitOpenEncode(b, &CommitOpen{Val: pk, Rand: rand})
-	return cryptoutil.Hash(b)
+	return cryptoutil.Hash(hashSuite, b)
 }
 
 // GetCommitRand computes the psuedo-random (wrt commitSecret) bits
 // used in a mapVal commitment.
-func GetCommitRand(commitSecret, label []byte) (rand []byte) {
-	hr := cryptoffi.NewHasher()
+func GetCommitRand(hashSuite byte, commitSecret, label []byte) (rand []byte) {
+	hr := cryptoffi.NewHasher(hashSuite)
 	hr.Write(commitSecret)
 	hr.Write(label)
 	return hr.Sum(nil)
@@ -5,15 +5,21 @@ const (
 	LinkSigTag
 )
 
+// serde: strict.
 type VrfSig struct {
-	SigTag byte
-	VrfPk  []byte
+	SigTag    byte
+	VrfSuite  byte
+	HashSuite byte
+	// serde: len 32.
+	VrfPk []byte
 }
 
+// serde: strict.
 type LinkSig struct {
 	SigTag byte
 	Epoch  uint64
-	Link   []byte
+	// serde: len 32.
+	Link []byte
 }
 
 type MapLabel struct {
@@ -27,23 +33,72 @@ type CommitOpen struct {
 }
 
 type Memb struct {
-	LabelProof  []byte
-	PkOpen      *CommitOpen
+	// serde: len 80.
+	LabelProof []byte
+	PkOpen     *CommitOpen
+	// at most a sib per level, plus another leaf.
+	// serde: maxlen 8281.
 	MerkleProof []byte
 }
 
 type NonMemb struct {
-	LabelProof  []byte
+	// serde: len 80.
+	LabelProof []byte
+	// at most a sib per level, plus another leaf.
+	// serde: maxlen 8281.
 	MerkleProof []byte
 }
 
+// serde: version 1.
 type AuditProof struct {
+	// serde: stream.
 	Updates []*UpdateProof
+	// serde: len 64.
 	LinkSig []byte
 }
 
 type UpdateProof struct {
-	MapLabel     []byte
-	MapVal       []byte
+	// serde: len 32.
+	MapLabel []byte
+	// serde: len 32.
+	MapVal []byte
+	// at most a sib per level, plus another leaf.
+	// serde: maxlen 8281.
 	NonMembProof []byte
 }
+
+// Evid is irrefutable (i.e., cryptographic) evidence that
+// a party signed contradicting statements.
+// a user can whistleblow by providing this to other users.
+// serde: union.
+type Evid struct {
+	Vrf  *EvidVrf
+	Link *EvidLink
+}
+
+// EvidVrf has sigs over different (VRF suite, hash suite, VRF pk) params.
+type EvidVrf struct {
+	VrfSuite0  byte
+	HashSuite0 byte
+	VrfPk0     []byte
+	// serde: len 64.
+	Sig0       []byte
+	VrfSuite1  byte
+	HashSuite1 byte
+	VrfPk1     []byte
+	// serde: len 64.
+	Sig1 []byte
+}
+
+// EvidLink has sigs over different hashchain links, for the same epoch.
+type EvidLink struct {
+	Epoch uint64
+	// serde: len 32.
+	Link0 []byte
+	// serde: len 64.
+	Sig0 []byte
+	// serde: len 32.
+	Link1 []byte
+	// serde: len 64.
+	Sig1 []byte
+}
@@ -10,6 +10,8 @@ import (
 func VrfSigEncode(b0 []byte, o *VrfSig) []byte {
 	var b = b0
 	b = safemarshal.WriteByte(b, o.SigTag)
+	b = safemarshal.WriteByte(b, o.VrfSuite)
+	b = safemarshal.WriteByte(b, o.HashSuite)
 	b = safemarshal.WriteSlice1D(b, o.VrfPk)
 	return b
 }
@@ -18,11 +20,32 @@ func VrfSigDecode(b0 []byte) (*VrfSig, []byte, bool) {
 	if err1 {
 		return nil, nil, true
 	}
-	a2, b2, err2 := safemarshal.ReadSlice1D(b1)
+	a2, b2, err2 := safemarshal.ReadByte(b1)
 	if err2 {
 		return nil, nil, true
 	}
-	return &VrfSig{SigTag: a1, VrfPk: a2}, b2, false
+	a3, b3, err3 := safemarshal.ReadByte(b2)
+	if err3 {
+		return nil, nil, true
+	}
+	a4, b4, err4 := safemarshal.ReadSlice1D(b3)
+	if err4 {
+		return nil, nil, true
+	}
+	if uint64(len(a4)) != 32 {
+		return nil, nil, true
+	}
+	return &VrfSig{SigTag: a1, VrfSuite: a2, HashSuite: a3, VrfPk: a4}, b4, false
+}
+func VrfSigDecodeStrict(b []byte) (*VrfSig, bool) {
+	o, _, err := VrfSigDecode(b)
+	if err {
+		return nil, true
+	}
+	if safemarshal.CheckCanonical(b, VrfSigEncode(nil, o)) {
+		return nil, true
+	}
+	return o, false
 }
 func LinkSigEncode(b0 []byte, o *LinkSig) []byte {
 	var b = b0
@@ -44,8 +67,21 @@ func LinkSigDecode(b0 []byte) (*LinkSig, []byte, bool) {
 	if err3 {
 		return nil, nil, true
 	}
+	if uint64(len(a3)) != 32 {
+		return nil, nil, true
+	}
 	return &LinkSig{SigTag: a1, Epoch: a2, Link: a3}, b3, false
 }
+func LinkSigDecodeStrict(b []byte) (*LinkSig, bool) {
+	o, _, err := LinkSigDecode(b)
+	if err {
+		return nil, true
+	}
+	if safemarshal.CheckCanonical(b, LinkSigEncode(nil, o)) {
+		return nil, true
+	}
+	return o, false
+}
 func MapLabelEncode(b0 []byte, o *MapLabel) []byte {
 	var b = b0
 	b = marshal.WriteInt(b, o.Uid)
@@ -92,6 +128,9 @@ func MembDecode(b0 []byte) (*Memb, []byte, bool) {
 	if err1 {
 		return nil, nil, true
 	}
+	if uint64(len(a1)) != 80 {
+		return nil, nil, true
+	}
 	a2, b2, err2 := CommitOpenDecode(b1)
 	if err2 {
 		return nil, nil, true
@@ -100,6 +139,9 @@ func MembDecode(b0 []byte) (*Memb, []byte, bool) {
 	if err3 {
 		return nil, nil, true
 	}
+	if uint64(len(a3)) > 8281 {
+		return nil, nil, true
+	}
 	return &Memb{LabelProof: a1, PkOpen: a2, MerkleProof: a3}, b3, false
 }
 func NonMembEncode(b0 []byte, o *NonMemb) []byte {
@@ -113,20 +155,37 @@ func NonMembDecode(b0 []byte) (*NonMemb, []byte, bool) {
 	if err1 {
 		return nil, nil, true
 	}
+	if uint64(len(a1)) != 80 {
+		return nil, nil, true
+	}
 	a2, b2, err2 := safemarshal.ReadSlice1D(b1)
 	if err2 {
 		return nil, nil, true
 	}
+	if uint64(len(a2)) > 8281 {
+		return nil, nil, true
+	}
 	return &NonMemb{LabelProof: a1, MerkleProof: a2}, b2, false
 }
 func AuditProofEncode(b0 []byte, o *AuditProof) []byte {
-	var b = b0
+	var b []byte
 	b = UpdateProofSlice1DEncode(b, o.Updates)
 	b = safemarshal.WriteSlice1D(b, o.LinkSig)
-	return b
+	return safemarshal.WriteSlice1D(marshal.WriteInt(b0, 1), b)
 }
 func AuditProofDecode(b0 []byte) (*AuditProof, []byte, bool) {
-	a1, b1, err1 := UpdateProofSlice1DDecode(b0)
+	ver, bVer, errVer := safemarshal.ReadInt(b0)
+	if errVer {
+		return nil, nil, true
+	}
+	if ver == 0 {
+		return nil, nil, true
+	}
+	body, tail, errBody := safemarshal.ReadSlice1D(bVer)
+	if errBody {
+		return nil, nil, true
+	}
+	a1, b1, err1 := UpdateProofSlice1DDecode(body)
 	if err1 {
 		return nil, nil, true
 	}
@@ -134,7 +193,13 @@ func AuditProofDecode(b0 []byte) (*AuditProof, []byte, bool) {
 	if err2 {
 		return nil, nil, true
 	}
-	return &AuditProof{Updates: a1, LinkSig: a2}, b2, false
+	if uint64(len(a2)) != 64 {
+		return nil, nil, true
+	}
+	if ver <= 1 && len(b2) != 0 {
+		return nil, nil, true
+	}
+	return &AuditProof{Updates: a1, LinkSig: a2}, tail, false
 }
 func UpdateProofEncode(b0 []byte, o *UpdateProof) []byte {
 	var b = b0
@@ -148,13 +213,182 @@ func UpdateProofDecode(b0 []byte) (*UpdateProof, []byte, bool) {
 	if err1 {
 		return nil, nil, true
 	}
+	if uint64(len(a1)) != 32 {
+		return nil, nil, true
+	}
 	a2, b2, err2 := safemarshal.ReadSlice1D(b1)
 	if err2 {
 		return nil, nil, true
 	}
+	if uint64(len(a2)) != 32 {
+		return nil, nil, true
+	}
 	a3, b3, err3 := safemarshal.ReadSlice1D(b2)
 	if err3 {
 		return nil, nil, true
 	}
+	if uint64(len(a3)) > 8281 {
+		return nil, nil, true
+	}
 	return &UpdateProof{MapLabel: a1, MapVal: a2, NonMembProof: a3}, b3, false
 }
+func EvidEncode(b0 []byte, o *Evid) []byte {
+	var b = b0
+	if o.Vrf != nil && o.Link == nil {
+		b = safemarshal.WriteByte(b, 0)
+		return EvidVrfEncode(b, o.Vrf)
+	}
+	if o.Vrf == nil && o.Link != nil {
+		b = safemarshal.WriteByte(b, 1)
+		return EvidLinkEncode(b, o.Link)
+	}
+	panic("serde: Evid must have exactly one variant")
+}
+func EvidDecode(b0 []byte) (*Evid, []byte, bool) {
+	tag, b1, err1 := safemarshal.ReadByte(b0)
+	if err1 {
+		return nil, nil, true
+	}
+	if tag == 0 {
+		x, b2, err2 := EvidVrfDecode(b1)
+		if err2 {
+			return nil, nil, true
+		}
+		return &Evid{Vrf: x}, b2, false
+	}
+	if tag == 1 {
+		x, b2, err2 := EvidLinkDecode(b1)
+		if err2 {
+			return nil, nil, true
+		}
+		return &Evid{Link: x}, b2, false
+	}
+	return nil, nil, true
+}
+func EvidVrfEncode(b0 []byte, o *EvidVrf) []byte {
+	var b = b0
+	b = safemarshal.WriteByte(b, o.VrfSuite0)
+	b = safemarshal.WriteByte(b, o.HashSuite0)
+	b = safemarshal.WriteSlice1D(b, o.VrfPk0)
+	b = safemarshal.WriteSlice1D(b, o.Sig0)
+	b = safemarshal.WriteByte(b, o.VrfSuite1)
+	b = safemarshal.WriteByte(b, o.HashSuite1)
+	b = safemarshal.WriteSlice1D(b, o.VrfPk1)
+	b = safemarshal.WriteSlice1D(b, o.Sig1)
+	return b
+}
+func EvidVrfDecode(b0 []byte) (*EvidVrf, []byte, bool) {
+	a1, b1, err1 := safemarshal.ReadByte(b0)
+	if err1 {
+		return nil, nil, true
+	}
+	a2, b2, err2 := safemarshal.ReadByte(b1)
+	if err2 {
+		return nil, nil, true
+	}
+	a3, b3, err3 := safemarshal.ReadSlice1D(b2)
+	if err3 {
+		return nil, nil, true
+	}
+	a4, b4, err4 := safemarshal.ReadSlice1D(b3)
+	if err4 {
+		return nil, nil, true
+	}
+	if uint64(len(a4)) != 64 {
+		return nil, nil, true
+	}
+	a5, b5, err5 := safemarshal.ReadByte(b4)
+	if err5 {
+		return nil, nil, true
+	}
+	a6, b6, err6 := safemarshal.ReadByte(b5)
+	if err6 {
+		return nil, nil, true
+	}
+	a7, b7, err7 := safemarshal.ReadSlice1D(b6)
+	if err7 {
+		return nil, nil, true
+	}
+	a8, b8, err8 := safemarshal.ReadSlice1D(b7)
+	if err8 {
+		return nil, nil, true
+	}
+	if uint64(len(a8)) != 64 {
+		return nil, nil, true
+	}
+	return &EvidVrf{VrfSuite0: a1, HashSuite0: a2, VrfPk0: a3, Sig0: a4, VrfSuite1: a5, HashSuite1: a6, VrfPk1: a7, Sig1: a8}, b8, false
+}
+func EvidLinkEncode(b0 []byte, o *EvidLink) []byte {
+	var b = b0
+	b = marshal.WriteInt(b, o.Epoch)
+	b = safemarshal.WriteSlice1D(b, o.Link0)
+	b = safemarshal.WriteSlice1D(b, o.Sig0)
+	b = safemarshal.WriteSlice1D(b, o.Link1)
+	b = safemarshal.WriteSlice1D(b, o.Sig1)
+	return b
+}
+func EvidLinkDecode(b0 []byte) (*EvidLink, []byte, bool) {
+	a1, b1, err1 := safemarshal.ReadInt(b0)
+	if err1 {
+		return nil, nil, true
+	}
+	a2, b2, err2 := safemarshal.ReadSlice1D(b1)
+	if err2 {
+		return nil, nil, true
+	}
+	if uint64(len(a2)) != 32 {
+		return nil, nil, true
+	}
+	a3, b3, err3 := safemarshal.ReadSlice1D(b2)
+	if err3 {
+		return nil, nil, true
+	}
+	if uint64(len(a3)) != 64 {
+		return nil, nil, true
+	}
+	a4, b4, err4 := safemarshal.ReadSlice1D(b3)
+	if err4 {
+		return nil, nil, true
+	}
+	if uint64(len(a4)) != 32 {
+		return nil, nil, true
+	}
+	a5, b5, err5 := safemarshal.ReadSlice1D(b4)
+	if err5 {
+		return nil, nil, true
+	}
+	if uint64(len(a5)) != 64 {
+		return nil, nil, true
+	}
+	return &EvidLink{Epoch: a1, Link0: a2, Sig0: a3, Link1: a4, Sig1: a5}, b5, false
+}
+func UpdateProofSlice1DEncode(b0 []byte, o []*UpdateProof) []byte {
+	var b = b0
+	b = marshal.WriteInt(b, uint64(len(o)))
+	for _, e := range o {
+		b = UpdateProofEncode(b, e)
+	}
+	return b
+}
+func UpdateProofSlice1DDecode(b0 []byte) ([]*UpdateProof, []byte, bool) {
+	length, b1, err1 := safemarshal.ReadInt(b0)
+	if err1 {
+		return nil, nil, true
+	}
+	var loopO []*UpdateProof
+	var loopErr bool
+	var loopB = b1
+	for i := uint64(0); i < length; i++ {
+		a2, loopB1, err2 := UpdateProofDecode(loopB)
+		loopB = loopB1
+		if err2 {
+			loopErr = true
+			break
+		}
+		loopO = append(loopO, a2)
+	}
+	if loopErr {
+		return nil, nil, true
+	}
+	return loopO, loopB, false
+}
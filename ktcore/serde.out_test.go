@@ -0,0 +1,601 @@
+// Auto-generated from spec "github.com/sanjit-bhat/pav/ktcore/serde.go"
+// using compiler "github.com/sanjit-bhat/pav/serde".
+package ktcore
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"math/rand/v2"
+	"os"
+	"testing"
+)
+
+func FuzzVrfSigDecode(f *testing.F) {
+	rnd := rand.New(rand.NewPCG(0, 0))
+	for i := 0; i < 4; i++ {
+		f.Add(VrfSigEncode(nil, randVrfSig(rnd)))
+	}
+	f.Fuzz(func(t *testing.T, b []byte) {
+		x, _, err := VrfSigDecode(b)
+		if err {
+			return
+		}
+		b0 := VrfSigEncode(nil, x)
+		y, rem, err := VrfSigDecode(b0)
+		if err || len(rem) != 0 {
+			t.Fatal()
+		}
+		if !bytes.Equal(b0, VrfSigEncode(nil, y)) {
+			t.Fatal()
+		}
+	})
+}
+func TestVrfSigRoundTrip(t *testing.T) {
+	rnd := rand.New(rand.NewPCG(0, 0))
+	for i := 0; i < 100; i++ {
+		b := VrfSigEncode(nil, randVrfSig(rnd))
+		x, rem, err := VrfSigDecode(b)
+		if err || len(rem) != 0 {
+			t.Fatal()
+		}
+		if !bytes.Equal(b, VrfSigEncode(nil, x)) {
+			t.Fatal()
+		}
+	}
+}
+func TestVrfSigDecodeStrict(t *testing.T) {
+	rnd := rand.New(rand.NewPCG(0, 0))
+	for i := 0; i < 100; i++ {
+		b := VrfSigEncode(nil, randVrfSig(rnd))
+		if _, err := VrfSigDecodeStrict(b); err {
+			t.Fatal()
+		}
+		if _, err := VrfSigDecodeStrict(append(b, 0)); !err {
+			t.Fatal()
+		}
+	}
+}
+func FuzzLinkSigDecode(f *testing.F) {
+	rnd := rand.New(rand.NewPCG(0, 0))
+	for i := 0; i < 4; i++ {
+		f.Add(LinkSigEncode(nil, randLinkSig(rnd)))
+	}
+	f.Fuzz(func(t *testing.T, b []byte) {
+		x, _, err := LinkSigDecode(b)
+		if err {
+			return
+		}
+		b0 := LinkSigEncode(nil, x)
+		y, rem, err := LinkSigDecode(b0)
+		if err || len(rem) != 0 {
+			t.Fatal()
+		}
+		if !bytes.Equal(b0, LinkSigEncode(nil, y)) {
+			t.Fatal()
+		}
+	})
+}
+func TestLinkSigRoundTrip(t *testing.T) {
+	rnd := rand.New(rand.NewPCG(0, 0))
+	for i := 0; i < 100; i++ {
+		b := LinkSigEncode(nil, randLinkSig(rnd))
+		x, rem, err := LinkSigDecode(b)
+		if err || len(rem) != 0 {
+			t.Fatal()
+		}
+		if !bytes.Equal(b, LinkSigEncode(nil, x)) {
+			t.Fatal()
+		}
+	}
+}
+func TestLinkSigDecodeStrict(t *testing.T) {
+	rnd := rand.New(rand.NewPCG(0, 0))
+	for i := 0; i < 100; i++ {
+		b := LinkSigEncode(nil, randLinkSig(rnd))
+		if _, err := LinkSigDecodeStrict(b); err {
+			t.Fatal()
+		}
+		if _, err := LinkSigDecodeStrict(append(b, 0)); !err {
+			t.Fatal()
+		}
+	}
+}
+func FuzzMapLabelDecode(f *testing.F) {
+	rnd := rand.New(rand.NewPCG(0, 0))
+	for i := 0; i < 4; i++ {
+		f.Add(MapLabelEncode(nil, randMapLabel(rnd)))
+	}
+	f.Fuzz(func(t *testing.T, b []byte) {
+		x, _, err := MapLabelDecode(b)
+		if err {
+			return
+		}
+		b0 := MapLabelEncode(nil, x)
+		y, rem, err := MapLabelDecode(b0)
+		if err || len(rem) != 0 {
+			t.Fatal()
+		}
+		if !bytes.Equal(b0, MapLabelEncode(nil, y)) {
+			t.Fatal()
+		}
+	})
+}
+func TestMapLabelRoundTrip(t *testing.T) {
+	rnd := rand.New(rand.NewPCG(0, 0))
+	for i := 0; i < 100; i++ {
+		b := MapLabelEncode(nil, randMapLabel(rnd))
+		x, rem, err := MapLabelDecode(b)
+		if err || len(rem) != 0 {
+			t.Fatal()
+		}
+		if !bytes.Equal(b, MapLabelEncode(nil, x)) {
+			t.Fatal()
+		}
+	}
+}
+func FuzzCommitOpenDecode(f *testing.F) {
+	rnd := rand.New(rand.NewPCG(0, 0))
+	for i := 0; i < 4; i++ {
+		f.Add(CommitOpenEncode(nil, randCommitOpen(rnd)))
+	}
+	f.Fuzz(func(t *testing.T, b []byte) {
+		x, _, err := CommitOpenDecode(b)
+		if err {
+			return
+		}
+		b0 := CommitOpenEncode(nil, x)
+		y, rem, err := CommitOpenDecode(b0)
+		if err || len(rem) != 0 {
+			t.Fatal()
+		}
+		if !bytes.Equal(b0, CommitOpenEncode(nil, y)) {
+			t.Fatal()
+		}
+	})
+}
+func TestCommitOpenRoundTrip(t *testing.T) {
+	rnd := rand.New(rand.NewPCG(0, 0))
+	for i := 0; i < 100; i++ {
+		b := CommitOpenEncode(nil, randCommitOpen(rnd))
+		x, rem, err := CommitOpenDecode(b)
+		if err || len(rem) != 0 {
+			t.Fatal()
+		}
+		if !bytes.Equal(b, CommitOpenEncode(nil, x)) {
+			t.Fatal()
+		}
+	}
+}
+func FuzzMembDecode(f *testing.F) {
+	rnd := rand.New(rand.NewPCG(0, 0))
+	for i := 0; i < 4; i++ {
+		f.Add(MembEncode(nil, randMemb(rnd)))
+	}
+	f.Fuzz(func(t *testing.T, b []byte) {
+		x, _, err := MembDecode(b)
+		if err {
+			return
+		}
+		b0 := MembEncode(nil, x)
+		y, rem, err := MembDecode(b0)
+		if err || len(rem) != 0 {
+			t.Fatal()
+		}
+		if !bytes.Equal(b0, MembEncode(nil, y)) {
+			t.Fatal()
+		}
+	})
+}
+func TestMembRoundTrip(t *testing.T) {
+	rnd := rand.New(rand.NewPCG(0, 0))
+	for i := 0; i < 100; i++ {
+		b := MembEncode(nil, randMemb(rnd))
+		x, rem, err := MembDecode(b)
+		if err || len(rem) != 0 {
+			t.Fatal()
+		}
+		if !bytes.Equal(b, MembEncode(nil, x)) {
+			t.Fatal()
+		}
+	}
+}
+func FuzzNonMembDecode(f *testing.F) {
+	rnd := rand.New(rand.NewPCG(0, 0))
+	for i := 0; i < 4; i++ {
+		f.Add(NonMembEncode(nil, randNonMemb(rnd)))
+	}
+	f.Fuzz(func(t *testing.T, b []byte) {
+		x, _, err := NonMembDecode(b)
+		if err {
+			return
+		}
+		b0 := NonMembEncode(nil, x)
+		y, rem, err := NonMembDecode(b0)
+		if err || len(rem) != 0 {
+			t.Fatal()
+		}
+		if !bytes.Equal(b0, NonMembEncode(nil, y)) {
+			t.Fatal()
+		}
+	})
+}
+func TestNonMembRoundTrip(t *testing.T) {
+	rnd := rand.New(rand.NewPCG(0, 0))
+	for i := 0; i < 100; i++ {
+		b := NonMembEncode(nil, randNonMemb(rnd))
+		x, rem, err := NonMembDecode(b)
+		if err || len(rem) != 0 {
+			t.Fatal()
+		}
+		if !bytes.Equal(b, NonMembEncode(nil, x)) {
+			t.Fatal()
+		}
+	}
+}
+func FuzzAuditProofDecode(f *testing.F) {
+	rnd := rand.New(rand.NewPCG(0, 0))
+	for i := 0; i < 4; i++ {
+		f.Add(AuditProofEncode(nil, randAuditProof(rnd)))
+	}
+	f.Fuzz(func(t *testing.T, b []byte) {
+		x, _, err := AuditProofDecode(b)
+		if err {
+			return
+		}
+		b0 := AuditProofEncode(nil, x)
+		y, rem, err := AuditProofDecode(b0)
+		if err || len(rem) != 0 {
+			t.Fatal()
+		}
+		if !bytes.Equal(b0, AuditProofEncode(nil, y)) {
+			t.Fatal()
+		}
+	})
+}
+func TestAuditProofRoundTrip(t *testing.T) {
+	rnd := rand.New(rand.NewPCG(0, 0))
+	for i := 0; i < 100; i++ {
+		b := AuditProofEncode(nil, randAuditProof(rnd))
+		x, rem, err := AuditProofDecode(b)
+		if err || len(rem) != 0 {
+			t.Fatal()
+		}
+		if !bytes.Equal(b, AuditProofEncode(nil, x)) {
+			t.Fatal()
+		}
+	}
+}
+func FuzzUpdateProofDecode(f *testing.F) {
+	rnd := rand.New(rand.NewPCG(0, 0))
+	for i := 0; i < 4; i++ {
+		f.Add(UpdateProofEncode(nil, randUpdateProof(rnd)))
+	}
+	f.Fuzz(func(t *testing.T, b []byte) {
+		x, _, err := UpdateProofDecode(b)
+		if err {
+			return
+		}
+		b0 := UpdateProofEncode(nil, x)
+		y, rem, err := UpdateProofDecode(b0)
+		if err || len(rem) != 0 {
+			t.Fatal()
+		}
+		if !bytes.Equal(b0, UpdateProofEncode(nil, y)) {
+			t.Fatal()
+		}
+	})
+}
+func TestUpdateProofRoundTrip(t *testing.T) {
+	rnd := rand.New(rand.NewPCG(0, 0))
+	for i := 0; i < 100; i++ {
+		b := UpdateProofEncode(nil, randUpdateProof(rnd))
+		x, rem, err := UpdateProofDecode(b)
+		if err || len(rem) != 0 {
+			t.Fatal()
+		}
+		if !bytes.Equal(b, UpdateProofEncode(nil, x)) {
+			t.Fatal()
+		}
+	}
+}
+func FuzzEvidDecode(f *testing.F) {
+	rnd := rand.New(rand.NewPCG(0, 0))
+	for i := 0; i < 4; i++ {
+		f.Add(EvidEncode(nil, randEvid(rnd)))
+	}
+	f.Fuzz(func(t *testing.T, b []byte) {
+		x, _, err := EvidDecode(b)
+		if err {
+			return
+		}
+		b0 := EvidEncode(nil, x)
+		y, rem, err := EvidDecode(b0)
+		if err || len(rem) != 0 {
+			t.Fatal()
+		}
+		if !bytes.Equal(b0, EvidEncode(nil, y)) {
+			t.Fatal()
+		}
+	})
+}
+func TestEvidRoundTrip(t *testing.T) {
+	rnd := rand.New(rand.NewPCG(0, 0))
+	for i := 0; i < 100; i++ {
+		b := EvidEncode(nil, randEvid(rnd))
+		x, rem, err := EvidDecode(b)
+		if err || len(rem) != 0 {
+			t.Fatal()
+		}
+		if !bytes.Equal(b, EvidEncode(nil, x)) {
+			t.Fatal()
+		}
+	}
+}
+func FuzzEvidVrfDecode(f *testing.F) {
+	rnd := rand.New(rand.NewPCG(0, 0))
+	for i := 0; i < 4; i++ {
+		f.Add(EvidVrfEncode(nil, randEvidVrf(rnd)))
+	}
+	f.Fuzz(func(t *testing.T, b []byte) {
+		x, _, err := EvidVrfDecode(b)
+		if err {
+			return
+		}
+		b0 := EvidVrfEncode(nil, x)
+		y, rem, err := EvidVrfDecode(b0)
+		if err || len(rem) != 0 {
+			t.Fatal()
+		}
+		if !bytes.Equal(b0, EvidVrfEncode(nil, y)) {
+			t.Fatal()
+		}
+	})
+}
+func TestEvidVrfRoundTrip(t *testing.T) {
+	rnd := rand.New(rand.NewPCG(0, 0))
+	for i := 0; i < 100; i++ {
+		b := EvidVrfEncode(nil, randEvidVrf(rnd))
+		x, rem, err := EvidVrfDecode(b)
+		if err || len(rem) != 0 {
+			t.Fatal()
+		}
+		if !bytes.Equal(b, EvidVrfEncode(nil, x)) {
+			t.Fatal()
+		}
+	}
+}
+func FuzzEvidLinkDecode(f *testing.F) {
+	rnd := rand.New(rand.NewPCG(0, 0))
+	for i := 0; i < 4; i++ {
+		f.Add(EvidLinkEncode(nil, randEvidLink(rnd)))
+	}
+	f.Fuzz(func(t *testing.T, b []byte) {
+		x, _, err := EvidLinkDecode(b)
+		if err {
+			return
+		}
+		b0 := EvidLinkEncode(nil, x)
+		y, rem, err := EvidLinkDecode(b0)
+		if err || len(rem) != 0 {
+			t.Fatal()
+		}
+		if !bytes.Equal(b0, EvidLinkEncode(nil, y)) {
+			t.Fatal()
+		}
+	})
+}
+func TestEvidLinkRoundTrip(t *testing.T) {
+	rnd := rand.New(rand.NewPCG(0, 0))
+	for i := 0; i < 100; i++ {
+		b := EvidLinkEncode(nil, randEvidLink(rnd))
+		x, rem, err := EvidLinkDecode(b)
+		if err || len(rem) != 0 {
+			t.Fatal()
+		}
+		if !bytes.Equal(b, EvidLinkEncode(nil, x)) {
+			t.Fatal()
+		}
+	}
+}
+func randVrfSig(rnd *rand.Rand) *VrfSig {
+	o := &VrfSig{}
+	o.SigTag = byte(rnd.Uint32())
+	o.VrfSuite = byte(rnd.Uint32())
+	o.HashSuite = byte(rnd.Uint32())
+	o.VrfPk = randBytes(rnd, 32)
+	return o
+}
+func randLinkSig(rnd *rand.Rand) *LinkSig {
+	o := &LinkSig{}
+	o.SigTag = byte(rnd.Uint32())
+	o.Epoch = rnd.Uint64()
+	o.Link = randBytes(rnd, 32)
+	return o
+}
+func randMapLabel(rnd *rand.Rand) *MapLabel {
+	o := &MapLabel{}
+	o.Uid = rnd.Uint64()
+	o.Ver = rnd.Uint64()
+	return o
+}
+func randCommitOpen(rnd *rand.Rand) *CommitOpen {
+	o := &CommitOpen{}
+	o.Val = randBytes(rnd, rnd.IntN(4))
+	o.Rand = randBytes(rnd, rnd.IntN(4))
+	return o
+}
+func randMemb(rnd *rand.Rand) *Memb {
+	o := &Memb{}
+	o.LabelProof = randBytes(rnd, 80)
+	o.PkOpen = randCommitOpen(rnd)
+	o.MerkleProof = randBytes(rnd, rnd.IntN(8281+1))
+	return o
+}
+func randNonMemb(rnd *rand.Rand) *NonMemb {
+	o := &NonMemb{}
+	o.LabelProof = randBytes(rnd, 80)
+	o.MerkleProof = randBytes(rnd, rnd.IntN(8281+1))
+	return o
+}
+func randAuditProof(rnd *rand.Rand) *AuditProof {
+	o := &AuditProof{}
+	o.Updates = randSlUpdateProof(rnd, rnd.IntN(4))
+	o.LinkSig = randBytes(rnd, 64)
+	return o
+}
+func randUpdateProof(rnd *rand.Rand) *UpdateProof {
+	o := &UpdateProof{}
+	o.MapLabel = randBytes(rnd, 32)
+	o.MapVal = randBytes(rnd, 32)
+	o.NonMembProof = randBytes(rnd, rnd.IntN(8281+1))
+	return o
+}
+func randEvid(rnd *rand.Rand) *Evid {
+	o := &Evid{}
+	tag := rnd.IntN(2)
+	if tag == 0 {
+		o.Vrf = randEvidVrf(rnd)
+	}
+	if tag == 1 {
+		o.Link = randEvidLink(rnd)
+	}
+	return o
+}
+func randEvidVrf(rnd *rand.Rand) *EvidVrf {
+	o := &EvidVrf{}
+	o.VrfSuite0 = byte(rnd.Uint32())
+	o.HashSuite0 = byte(rnd.Uint32())
+	o.VrfPk0 = randBytes(rnd, rnd.IntN(4))
+	o.Sig0 = randBytes(rnd, 64)
+	o.VrfSuite1 = byte(rnd.Uint32())
+	o.HashSuite1 = byte(rnd.Uint32())
+	o.VrfPk1 = randBytes(rnd, rnd.IntN(4))
+	o.Sig1 = randBytes(rnd, 64)
+	return o
+}
+func randEvidLink(rnd *rand.Rand) *EvidLink {
+	o := &EvidLink{}
+	o.Epoch = rnd.Uint64()
+	o.Link0 = randBytes(rnd, 32)
+	o.Sig0 = randBytes(rnd, 64)
+	o.Link1 = randBytes(rnd, 32)
+	o.Sig1 = randBytes(rnd, 64)
+	return o
+}
+func randSlUpdateProof(rnd *rand.Rand, n int) []*UpdateProof {
+	var x []*UpdateProof
+	for i := 0; i < n; i++ {
+		x = append(x, randUpdateProof(rnd))
+	}
+	return x
+}
+func randBytes(rnd *rand.Rand, n int) []byte {
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = byte(rnd.Uint32())
+	}
+	return b
+}
+func vectorCodec(name string, b []byte) (enc []byte, err bool) {
+	switch name {
+	case "VrfSig":
+		x, rem, errb := VrfSigDecode(b)
+		if errb || len(rem) != 0 {
+			return nil, true
+		}
+		return VrfSigEncode(nil, x), false
+	case "LinkSig":
+		x, rem, errb := LinkSigDecode(b)
+		if errb || len(rem) != 0 {
+			return nil, true
+		}
+		return LinkSigEncode(nil, x), false
+	case "MapLabel":
+		x, rem, errb := MapLabelDecode(b)
+		if errb || len(rem) != 0 {
+			return nil, true
+		}
+		return MapLabelEncode(nil, x), false
+	case "CommitOpen":
+		x, rem, errb := CommitOpenDecode(b)
+		if errb || len(rem) != 0 {
+			return nil, true
+		}
+		return CommitOpenEncode(nil, x), false
+	case "Memb":
+		x, rem, errb := MembDecode(b)
+		if errb || len(rem) != 0 {
+			return nil, true
+		}
+		return MembEncode(nil, x), false
+	case "NonMemb":
+		x, rem, errb := NonMembDecode(b)
+		if errb || len(rem) != 0 {
+			return nil, true
+		}
+		return NonMembEncode(nil, x), false
+	case "AuditProof":
+		x, rem, errb := AuditProofDecode(b)
+		if errb || len(rem) != 0 {
+			return nil, true
+		}
+		return AuditProofEncode(nil, x), false
+	case "UpdateProof":
+		x, rem, errb := UpdateProofDecode(b)
+		if errb || len(rem) != 0 {
+			return nil, true
+		}
+		return UpdateProofEncode(nil, x), false
+	case "Evid":
+		x, rem, errb := EvidDecode(b)
+		if errb || len(rem) != 0 {
+			return nil, true
+		}
+		return EvidEncode(nil, x), false
+	case "EvidVrf":
+		x, rem, errb := EvidVrfDecode(b)
+		if errb || len(rem) != 0 {
+			return nil, true
+		}
+		return EvidVrfEncode(nil, x), false
+	case "EvidLink":
+		x, rem, errb := EvidLinkDecode(b)
+		if errb || len(rem) != 0 {
+			return nil, true
+		}
+		return EvidLinkEncode(nil, x), false
+	}
+	return nil, true
+}
+func TestVectors(t *testing.T) {
+	data, err := os.ReadFile("serde.vectors.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var vs struct {
+		Vectors []struct {
+			Name, Struct, Hex string
+		}
+	}
+	err = json.Unmarshal(data, &vs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(vs.Vectors) == 0 {
+		t.Fatal("no vectors")
+	}
+	for _, v := range vs.Vectors {
+		b, err := hex.DecodeString(v.Hex)
+		if err != nil {
+			t.Fatal(err)
+		}
+		enc, errb := vectorCodec(v.Struct, b)
+		if errb {
+			t.Fatalf("%s: decode failed", v.Name)
+		}
+		if !bytes.Equal(b, enc) {
+			t.Fatalf("%s: encoding differs", v.Name)
+		}
+	}
+}
@@ -0,0 +1,278 @@
+//go:build !goose
+
+// Auto-generated from spec "github.com/sanjit-bhat/pav/ktcore/serde.go"
+// using compiler "github.com/sanjit-bhat/pav/serde".
+package ktcore
+
+import "github.com/sanjit-bhat/pav/safemarshal/stream"
+
+func VrfSigDecodeStream(r *stream.Reader) (*VrfSig, bool) {
+	a1, err1 := r.Byte()
+	if err1 {
+		return nil, true
+	}
+	a2, err2 := r.Byte()
+	if err2 {
+		return nil, true
+	}
+	a3, err3 := r.Byte()
+	if err3 {
+		return nil, true
+	}
+	a4, err4 := r.Slice1D()
+	if err4 {
+		return nil, true
+	}
+	if uint64(len(a4)) != 32 {
+		return nil, true
+	}
+	return &VrfSig{SigTag: a1, VrfSuite: a2, HashSuite: a3, VrfPk: a4}, false
+}
+func LinkSigDecodeStream(r *stream.Reader) (*LinkSig, bool) {
+	a1, err1 := r.Byte()
+	if err1 {
+		return nil, true
+	}
+	a2, err2 := r.Int()
+	if err2 {
+		return nil, true
+	}
+	a3, err3 := r.Slice1D()
+	if err3 {
+		return nil, true
+	}
+	if uint64(len(a3)) != 32 {
+		return nil, true
+	}
+	return &LinkSig{SigTag: a1, Epoch: a2, Link: a3}, false
+}
+func MapLabelDecodeStream(r *stream.Reader) (*MapLabel, bool) {
+	a1, err1 := r.Int()
+	if err1 {
+		return nil, true
+	}
+	a2, err2 := r.Int()
+	if err2 {
+		return nil, true
+	}
+	return &MapLabel{Uid: a1, Ver: a2}, false
+}
+func CommitOpenDecodeStream(r *stream.Reader) (*CommitOpen, bool) {
+	a1, err1 := r.Slice1D()
+	if err1 {
+		return nil, true
+	}
+	a2, err2 := r.Slice1D()
+	if err2 {
+		return nil, true
+	}
+	return &CommitOpen{Val: a1, Rand: a2}, false
+}
+func MembDecodeStream(r *stream.Reader) (*Memb, bool) {
+	a1, err1 := r.Slice1D()
+	if err1 {
+		return nil, true
+	}
+	if uint64(len(a1)) != 80 {
+		return nil, true
+	}
+	a2, err2 := CommitOpenDecodeStream(r)
+	if err2 {
+		return nil, true
+	}
+	a3, err3 := r.Slice1D()
+	if err3 {
+		return nil, true
+	}
+	if uint64(len(a3)) > 8281 {
+		return nil, true
+	}
+	return &Memb{LabelProof: a1, PkOpen: a2, MerkleProof: a3}, false
+}
+func NonMembDecodeStream(r *stream.Reader) (*NonMemb, bool) {
+	a1, err1 := r.Slice1D()
+	if err1 {
+		return nil, true
+	}
+	if uint64(len(a1)) != 80 {
+		return nil, true
+	}
+	a2, err2 := r.Slice1D()
+	if err2 {
+		return nil, true
+	}
+	if uint64(len(a2)) > 8281 {
+		return nil, true
+	}
+	return &NonMemb{LabelProof: a1, MerkleProof: a2}, false
+}
+func AuditProofDecodeStream(r *stream.Reader, eachUpdates func(*UpdateProof) bool) (*AuditProof, bool) {
+	ver, errVer := r.Int()
+	if errVer {
+		return nil, true
+	}
+	if ver == 0 {
+		return nil, true
+	}
+	length, errLen := r.Int()
+	if errLen {
+		return nil, true
+	}
+	body, errBody := r.Limit(length)
+	if errBody {
+		return nil, true
+	}
+	var a1 []*UpdateProof
+	length1, err1 := body.Count(24)
+	if err1 {
+		return nil, true
+	}
+	for i := uint64(0); i < length1; i++ {
+		x, err := UpdateProofDecodeStream(body)
+		if err {
+			return nil, true
+		}
+		if eachUpdates == nil {
+			a1 = append(a1, x)
+			continue
+		}
+		if eachUpdates(x) {
+			return nil, true
+		}
+	}
+	a2, err2 := body.Slice1D()
+	if err2 {
+		return nil, true
+	}
+	if uint64(len(a2)) != 64 {
+		return nil, true
+	}
+	if ver <= 1 && body.Budget() != 0 {
+		return nil, true
+	}
+	if body.Discard() {
+		return nil, true
+	}
+	return &AuditProof{Updates: a1, LinkSig: a2}, false
+}
+func UpdateProofDecodeStream(r *stream.Reader) (*UpdateProof, bool) {
+	a1, err1 := r.Slice1D()
+	if err1 {
+		return nil, true
+	}
+	if uint64(len(a1)) != 32 {
+		return nil, true
+	}
+	a2, err2 := r.Slice1D()
+	if err2 {
+		return nil, true
+	}
+	if uint64(len(a2)) != 32 {
+		return nil, true
+	}
+	a3, err3 := r.Slice1D()
+	if err3 {
+		return nil, true
+	}
+	if uint64(len(a3)) > 8281 {
+		return nil, true
+	}
+	return &UpdateProof{MapLabel: a1, MapVal: a2, NonMembProof: a3}, false
+}
+func EvidDecodeStream(r *stream.Reader) (*Evid, bool) {
+	tag, err1 := r.Byte()
+	if err1 {
+		return nil, true
+	}
+	if tag == 0 {
+		x, err2 := EvidVrfDecodeStream(r)
+		if err2 {
+			return nil, true
+		}
+		return &Evid{Vrf: x}, false
+	}
+	if tag == 1 {
+		x, err2 := EvidLinkDecodeStream(r)
+		if err2 {
+			return nil, true
+		}
+		return &Evid{Link: x}, false
+	}
+	return nil, true
+}
+func EvidVrfDecodeStream(r *stream.Reader) (*EvidVrf, bool) {
+	a1, err1 := r.Byte()
+	if err1 {
+		return nil, true
+	}
+	a2, err2 := r.Byte()
+	if err2 {
+		return nil, true
+	}
+	a3, err3 := r.Slice1D()
+	if err3 {
+		return nil, true
+	}
+	a4, err4 := r.Slice1D()
+	if err4 {
+		return nil, true
+	}
+	if uint64(len(a4)) != 64 {
+		return nil, true
+	}
+	a5, err5 := r.Byte()
+	if err5 {
+		return nil, true
+	}
+	a6, err6 := r.Byte()
+	if err6 {
+		return nil, true
+	}
+	a7, err7 := r.Slice1D()
+	if err7 {
+		return nil, true
+	}
+	a8, err8 := r.Slice1D()
+	if err8 {
+		return nil, true
+	}
+	if uint64(len(a8)) != 64 {
+		return nil, true
+	}
+	return &EvidVrf{VrfSuite0: a1, HashSuite0: a2, VrfPk0: a3, Sig0: a4, VrfSuite1: a5, HashSuite1: a6, VrfPk1: a7, Sig1: a8}, false
+}
+func EvidLinkDecodeStream(r *stream.Reader) (*EvidLink, bool) {
+	a1, err1 := r.Int()
+	if err1 {
+		return nil, true
+	}
+	a2, err2 := r.Slice1D()
+	if err2 {
+		return nil, true
+	}
+	if uint64(len(a2)) != 32 {
+		return nil, true
+	}
+	a3, err3 := r.Slice1D()
+	if err3 {
+		return nil, true
+	}
+	if uint64(len(a3)) != 64 {
+		return nil, true
+	}
+	a4, err4 := r.Slice1D()
+	if err4 {
+		return nil, true
+	}
+	if uint64(len(a4)) != 32 {
+		return nil, true
+	}
+	a5, err5 := r.Slice1D()
+	if err5 {
+		return nil, true
+	}
+	if uint64(len(a5)) != 64 {
+		return nil, true
+	}
+	return &EvidLink{Epoch: a1, Link0: a2, Sig0: a3, Link1: a4, Sig1: a5}, false
+}
@@ -0,0 +1,98 @@
+package ktcore
+
+import (
+	"testing"
+
+	"github.com/sanjit-bhat/pav/cryptoffi"
+	"github.com/tchajed/marshal"
+)
+
+func TestUpdateProofSlice1DDecode(t *testing.T) {
+	b := marshal.WriteInt(nil, 0x8000000000000000)
+	_, _, err := UpdateProofSlice1DDecode(b)
+	if !err {
+		t.Errorf("should have errored")
+	}
+}
+
+func TestEvidDecode(t *testing.T) {
+	e0 := &Evid{Link: &EvidLink{Epoch: 3, Link0: make([]byte, 32), Sig0: make([]byte, 64), Link1: make([]byte, 32), Sig1: make([]byte, 64)}}
+	e1, rem, err := EvidDecode(EvidEncode(nil, e0))
+	if err || len(rem) != 0 {
+		t.Fatal()
+	}
+	if e1.Vrf != nil || e1.Link.Epoch != 3 {
+		t.Fatal()
+	}
+
+	// unknown tag.
+	if _, _, err := EvidDecode([]byte{2}); !err {
+		t.Errorf("should have errored")
+	}
+}
+
+func TestEvidEncode(t *testing.T) {
+	for _, e := range []*Evid{{}, {Vrf: &EvidVrf{}, Link: &EvidLink{}}} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("should have panicked")
+				}
+			}()
+			EvidEncode(nil, e)
+		}()
+	}
+}
+
+func TestEvidLinkDecode(t *testing.T) {
+	// links must be hashes.
+	e := &EvidLink{Link0: make([]byte, 31), Link1: make([]byte, 32)}
+	if _, _, err := EvidLinkDecode(EvidLinkEncode(nil, e)); !err {
+		t.Errorf("should have errored")
+	}
+}
+
+func TestSigMsgStrict(t *testing.T) {
+	b := LinkSigEncode(nil, &LinkSig{SigTag: LinkSigTag, Epoch: 3, Link: make([]byte, 32)})
+	if CheckLinkSigMsg(b) {
+		t.Fatal()
+	}
+	// the lax decoder leaves trailing bytes for the caller.
+	if _, _, err := LinkSigDecode(append(b, 0)); err {
+		t.Fatal()
+	}
+	if !CheckLinkSigMsg(append(b, 0)) {
+		t.Fatal()
+	}
+	// a VrfSig msg isn't a LinkSig msg.
+	b = VrfSigEncode(nil, &VrfSig{SigTag: VrfSigTag, VrfSuite: 1, HashSuite: 2, VrfPk: make([]byte, 32)})
+	if CheckVrfSigMsg(b) || !CheckLinkSigMsg(b) {
+		t.Fatal()
+	}
+	if !CheckVrfSigMsg(b[:len(b)-1]) {
+		t.Fatal()
+	}
+}
+
+func TestVerifySigLens(t *testing.T) {
+	pk, sk := cryptoffi.SigGenerateKey()
+	// the sig covers a msg with a short link.
+	link := make([]byte, 31)
+	sig := sk.Sign(LinkSigEncode(nil, &LinkSig{SigTag: LinkSigTag, Epoch: 3, Link: link}))
+	if !VerifyLinkSig(pk, 3, link, sig) {
+		t.Fatal()
+	}
+	if VerifyLinkSig(pk, 3, make([]byte, 32), SignLink(sk, 3, make([]byte, 32))) {
+		t.Fatal()
+	}
+
+	vrfPk := make([]byte, 33)
+	sig = sk.Sign(VrfSigEncode(nil, &VrfSig{SigTag: VrfSigTag, VrfPk: vrfPk}))
+	if !VerifyVrfSig(pk, 0, 0, vrfPk, sig) {
+		t.Fatal()
+	}
+	e := &Evid{Vrf: &EvidVrf{VrfPk0: vrfPk, Sig0: sig, VrfPk1: make([]byte, 32), Sig1: SignVrf(sk, 0, 0, make([]byte, 32))}}
+	if !e.Check(pk) {
+		t.Fatal()
+	}
+}
@@ -0,0 +1,323 @@
+package merkle
+
+import (
+	"bytes"
+
+	"github.com/goose-lang/std"
+	"github.com/sanjit-bhat/pav/cryptoffi"
+	"github.com/tchajed/marshal"
+)
+
+// parMinEntries is the min batch size to split across goroutines.
+const parMinEntries uint64 = 256
+
+// PutBatch adds entries, with the same result as a [Map.Put] of each entry
+// in path order, i.e., ordered by label bits, starting from the root.
+// it returns a copy of entries in path order, with their update proofs,
+// each of which [VerifyUpdate] accepts.
+// it expects distinct labels, none of which are already in the map.
+//
+// unlike Put, it builds disjoint sub-trees in parallel,
+// and only hashes each new inner node once.
+func (m *Map) PutBatch(entries []*Entry) (sorted []*Entry, updProofs [][]byte) {
+	for _, e := range entries {
+		std.Assert(uint64(len(e.Label)) == cryptoffi.HashLen)
+	}
+	sorted = sortPath(entries)
+	for i := 1; i < len(sorted); i++ {
+		std.Assert(!bytes.Equal(sorted[i-1].Label, sorted[i].Label))
+	}
+
+	old := m.root
+	items := make([]*item, 0, len(sorted))
+	for _, e := range sorted {
+		items = append(items, &item{label: e.Label, val: e.Val})
+	}
+	root := bulkPut(m.suite, m.store, old, 0, items)
+
+	// each proof depends on the tree right before its entry.
+	// entries earlier in path order have the new sub-trees, and
+	// entries later in path order have the old ones.
+	n := uint64(len(sorted))
+	updProofs = make([][]byte, n)
+	prove := func(i uint64) {
+		updProofs[i] = proveBatch(m.suite, m.store, old, root, sorted, i)
+	}
+	if n < parMinEntries {
+		for i := uint64(0); i < n; i++ {
+			prove(i)
+		}
+	} else {
+		parEach(n, prove)
+	}
+	m.root = flush(m.store, root)
+	return
+}
+
+// sortPath returns a copy of entries in path order.
+func sortPath(entries []*Entry) []*Entry {
+	n := uint64(len(entries))
+	if n <= 1 {
+		return append([]*Entry{}, entries...)
+	}
+	left := sortPath(entries[:n/2])
+	right := sortPath(entries[n/2:])
+	out := make([]*Entry, 0, n)
+	var i, j uint64
+	for i < uint64(len(left)) && j < uint64(len(right)) {
+		if comparePath(left[i].Label, right[j].Label) <= 0 {
+			out = append(out, left[i])
+			i++
+		} else {
+			out = append(out, right[j])
+			j++
+		}
+	}
+	out = append(out, left[i:]...)
+	return append(out, right[j:]...)
+}
+
+// item is a new entry, or an existing leaf node.
+type item struct {
+	label []byte
+	val   []byte
+	// n is an existing leaf, which we re-use.
+	n node
+}
+
+// bulkPut returns the n sub-tree, whose top is at level top,
+// with items inserted.
+// it expects items in path order, and with the path to top.
+func bulkPut(suite byte, st Store, n node, top uint64, items []*item) node {
+	if len(items) == 0 {
+		return n
+	}
+	std.Assert(top <= maxDepth)
+	switch n0 := load(st, n).(type) {
+	case nil:
+		return build(suite, top, items)
+	case *leaf:
+		// merge the existing leaf into the items.
+		items0 := make([]*item, 0, len(items)+1)
+		var pos uint64
+		for pos < uint64(len(items)) && comparePath(items[pos].label, n0.label) < 0 {
+			pos++
+		}
+		std.Assert(pos == uint64(len(items)) || !bytes.Equal(items[pos].label, n0.label))
+		items0 = append(items0, items[:pos]...)
+		items0 = append(items0, &item{label: n0.label, val: n0.val, n: n})
+		items0 = append(items0, items[pos:]...)
+		return build(suite, top, items0)
+	case *inner:
+		first := items[0].label
+		// without skipped levels, the items give the path bits.
+		pathLabel := first
+		split := n0.depth
+		if top < n0.depth {
+			pathLabel = getPathLabel(st, n0)
+			last := items[len(items)-1].label
+			split = min(getDiff(first, last), getDiff(first, pathLabel))
+		}
+		if split < n0.depth {
+			// some items leave the tree at a skipped level, so split there.
+			rest := newInner(suite, split+1, n0.depth, pathLabel, n0.child0, n0.child1)
+			pos := splitItems(items, split)
+			var child0, child1 node
+			if getBit(pathLabel, split) {
+				child0 = build(suite, split+1, items[:pos])
+				child1 = bulkPut(suite, st, rest, split+1, items[pos:])
+			} else {
+				child0 = bulkPut(suite, st, rest, split+1, items[:pos])
+				child1 = build(suite, split+1, items[pos:])
+			}
+			return newInner(suite, top, split, pathLabel, child0, child1)
+		}
+		// all items follow the skipped levels, and split at depth.
+		pos := splitItems(items, n0.depth)
+		var child0, child1 node
+		both(uint64(len(items)), func() {
+			child0 = bulkPut(suite, st, n0.child0, n0.depth+1, items[:pos])
+		}, func() {
+			child1 = bulkPut(suite, st, n0.child1, n0.depth+1, items[pos:])
+		})
+		return newInner(suite, top, n0.depth, pathLabel, child0, child1)
+	}
+	panic("merkle: insert into cut node")
+}
+
+// build returns a new sub-tree, whose top is at level top, with items.
+// it expects at least one item, in path order.
+func build(suite byte, top uint64, items []*item) node {
+	if len(items) == 1 {
+		it := items[0]
+		if it.n != nil {
+			return it.n
+		}
+		return newLeaf(suite, it.label, it.val)
+	}
+	first := items[0].label
+	split := getDiff(first, items[len(items)-1].label)
+	std.Assert(top <= split)
+	pos := splitItems(items, split)
+	var child0, child1 node
+	both(uint64(len(items)), func() {
+		child0 = build(suite, split+1, items[:pos])
+	}, func() {
+		child1 = build(suite, split+1, items[pos:])
+	})
+	return newInner(suite, top, split, first, child0, child1)
+}
+
+// both runs f0 and f1, in parallel if there are enough items.
+func both(nItems uint64, f0, f1 func()) {
+	if nItems < parMinEntries {
+		f0()
+		f1()
+		return
+	}
+	parBoth(f0, f1)
+}
+
+// splitItems returns the pos of the first item with bit lvl set.
+// it expects items in path order, so it binary searches.
+func splitItems(items []*item, lvl uint64) uint64 {
+	var lo uint64
+	hi := uint64(len(items))
+	for lo < hi {
+		mid := lo + (hi-lo)/2
+		if getBit(items[mid].label, lvl) {
+			hi = mid
+		} else {
+			lo = mid + 1
+		}
+	}
+	return lo
+}
+
+// getPathLabel returns the label of some leaf under n,
+// which has the bits of n's skipped levels.
+func getPathLabel(st Store, n *inner) []byte {
+	var curr node = n
+	for {
+		switch n0 := load(st, curr).(type) {
+		case *leaf:
+			return n0.label
+		case *inner:
+			curr = n0.child0
+		default:
+			panic("merkle: leafless inner node")
+		}
+	}
+}
+
+// proveBatch returns the update proof for entries[i], which is a
+// non-membership proof in the tree with entries[:i] added to old.
+// in that tree, each sibling of entries[i] either has all its entries
+// (if it's earlier in path order) or none (if it's later).
+// so we take siblings from the final tree, root, or from old.
+func proveBatch(suite byte, st Store, old, root node, entries []*Entry, i uint64) []byte {
+	label := entries[i].Label
+	// the old tree gives the levels with at least 2 or 1 old leaves.
+	path, l, err := walk(st, old, label)
+	std.Assert(!err)
+	pathNew, lNew, err := walk(st, root, label)
+	std.Assert(!err)
+	var old2, old1 uint64
+	if l != nil {
+		std.Assert(!bytes.Equal(label, l.label))
+		var top uint64
+		if len(path) != 0 {
+			top = path[len(path)-1].depth + 1
+		}
+		diff := getDiff(label, l.label)
+		old2 = min(top, diff+1)
+		old1 = diff + 1
+	}
+	// earlier entries give the levels with at least 2 or 1 new leaves.
+	var new2, new1 uint64
+	if i >= 1 {
+		new1 = getDiff(label, entries[i-1].Label) + 1
+	}
+	if i >= 2 {
+		new2 = getDiff(label, entries[i-2].Label) + 1
+	}
+
+	// label leaves the tree at the first level with at most 1 leaf.
+	depth := max(old2, new2, min(old1, new1))
+	var other *Entry
+	if depth < old1 {
+		other = &Entry{Label: l.label, Val: l.val}
+	} else if depth < new1 {
+		other = entries[i-1]
+	}
+
+	sibsOld := getSibs(suite, path, l, label, depth)
+	sibsNew := getSibs(suite, pathNew, lNew, label, depth)
+	proof := make([]byte, 0, getProofCap(depth))
+	proof = marshal.WriteInt(proof, depth*cryptoffi.HashLen) // SibsLen
+	// sibs go from the bottom up.
+	for lvl := depth; lvl > 0; lvl-- {
+		off := (depth - lvl) * cryptoffi.HashLen
+		if getBit(label, lvl-1) {
+			proof = append(proof, sibsNew[off:off+cryptoffi.HashLen]...)
+		} else {
+			proof = append(proof, sibsOld[off:off+cryptoffi.HashLen]...)
+		}
+	}
+	if other == nil {
+		proof = marshal.WriteBool(proof, false) // IsOtherLeaf
+		proof = marshal.WriteInt(proof, 0)      // empty LeafLabelLen
+		proof = marshal.WriteInt(proof, 0)      // empty LeafValLen
+		return proof
+	}
+	proof = marshal.WriteBool(proof, true) // IsOtherLeaf
+	proof = marshal.WriteInt(proof, uint64(len(other.Label)))
+	proof = marshal.WriteBytes(proof, other.Label)
+	proof = marshal.WriteInt(proof, uint64(len(other.Val)))
+	proof = marshal.WriteBytes(proof, other.Val)
+	return proof
+}
+
+// getSibs returns the hash of the sibling sub-tree of label
+// at each level above depth, starting from the bottom.
+// it takes the result of a [walk] down label.
+// unlike a proof, depth can go past where label leaves the tree.
+func getSibs(suite byte, path []*inner, l *leaf, label []byte, depth uint64) []byte {
+	var leave uint64
+	if len(path) != 0 {
+		leave = path[len(path)-1].depth + 1
+	}
+	diff := maxDepth
+	if l != nil {
+		diff = getDiff(label, l.label)
+		if diff < leave {
+			leave = diff + 1
+		}
+	}
+	sibs := make([]byte, 0, max(depth, leave)*cryptoffi.HashLen)
+	// past leave, a sibling only has the leaf, if it's there.
+	for lvl := depth; lvl > leave; lvl-- {
+		if lvl-1 == diff {
+			sibs = append(sibs, l.getHash()...)
+		} else {
+			sibs = append(sibs, emptyHashes[suite]...)
+		}
+	}
+	full := appendSibs(suite, nil, path, l, label, leave)
+	if depth < leave {
+		return full[(leave-depth)*cryptoffi.HashLen:]
+	}
+	return append(sibs, full...)
+}
+
+// comparePath orders labels by their bits, starting from the root.
+func comparePath(label0, label1 []byte) int {
+	diff := getDiff(label0, label1)
+	if diff == maxDepth {
+		return 0
+	}
+	if getBit(label0, diff) {
+		return 1
+	}
+	return -1
+}
@@ -0,0 +1,36 @@
+//go:build !goose
+
+package merkle
+
+import (
+	"runtime"
+	"sync"
+)
+
+// parBoth runs f0 and f1 in parallel.
+func parBoth(f0, f1 func()) {
+	wg := new(sync.WaitGroup)
+	wg.Add(1)
+	go func() {
+		f0()
+		wg.Done()
+	}()
+	f1()
+	wg.Wait()
+}
+
+// parEach runs f on each i < n, spread across GOMAXPROCS goroutines.
+func parEach(n uint64, f func(i uint64)) {
+	nWorkers := uint64(runtime.GOMAXPROCS(0))
+	wg := new(sync.WaitGroup)
+	for w := uint64(0); w < nWorkers; w++ {
+		wg.Add(1)
+		go func() {
+			for i := w; i < n; i += nWorkers {
+				f(i)
+			}
+			wg.Done()
+		}()
+	}
+	wg.Wait()
+}
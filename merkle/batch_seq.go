@@ -0,0 +1,17 @@
+//go:build goose
+
+package merkle
+
+// parBoth runs f0 and then f1.
+// it's the sequential model of the goroutine version.
+func parBoth(f0, f1 func()) {
+	f0()
+	f1()
+}
+
+// parEach runs f on each i < n, in order.
+func parEach(n uint64, f func(i uint64)) {
+	for i := uint64(0); i < n; i++ {
+		f(i)
+	}
+}
@@ -0,0 +1,84 @@
+package merkle
+
+import (
+	"bytes"
+	"math/rand/v2"
+	"slices"
+	"testing"
+
+	"github.com/sanjit-bhat/pav/cryptoffi"
+)
+
+func TestPutBatch(t *testing.T) {
+	var seed [32]byte
+	rnd := rand.NewChaCha8(seed)
+	mkLabel := func(prefix []byte, prefixLen uint64) []byte {
+		l := make([]byte, cryptoffi.HashLen)
+		rnd.Read(l)
+		copy(l, prefix[:prefixLen])
+		return l
+	}
+	base := mkLabel(nil, 0)
+
+	for _, nOld := range []int{0, 1, 2, 1_000} {
+		for _, nNew := range []int{0, 1, 2, 3, 100, 2_000} {
+			m0 := New(cryptoffi.HashSuiteSha256)
+			m1 := NewWithStore(cryptoffi.HashSuiteSha256, &memStore{})
+			for i := 0; i < nOld; i++ {
+				// some labels share long prefixes.
+				l := mkLabel(base, uint64(i*5)%28)
+				m0.Put(l, []byte{1})
+				m1.Put(l, []byte{1})
+			}
+			var entries []*Entry
+			for i := 0; i < nNew; i++ {
+				l := mkLabel(base, uint64(i*3)%28)
+				entries = append(entries, &Entry{Label: l, Val: []byte{2}})
+			}
+			old := m0.Hash()
+			seq := m0.Snapshot()
+			orig := slices.Clone(entries)
+			sorted, proofs := m0.PutBatch(entries)
+			_, proofs1 := m1.PutBatch(entries)
+			if len(proofs) != len(entries) {
+				t.Fatal()
+			}
+			// the caller's entries keep their order.
+			if !slices.Equal(entries, orig) {
+				t.Fatal()
+			}
+
+			// proofs chain like sequential puts in path order.
+			dig := old
+			for i, e := range sorted {
+				if i > 0 && comparePath(sorted[i-1].Label, e.Label) >= 0 {
+					t.Fatal()
+				}
+				dOld, dNew, err := VerifyUpdate(m0.suite, e.Label, e.Val, proofs[i])
+				if err {
+					t.Fatal()
+				}
+				if !bytes.Equal(dig, dOld) {
+					t.Fatal()
+				}
+				if !bytes.Equal(proofs[i], proofs1[i]) {
+					t.Fatal()
+				}
+				if !bytes.Equal(proofs[i], seq.Put(e.Label, e.Val)) {
+					t.Fatal()
+				}
+				dig = dNew
+			}
+			if !bytes.Equal(dig, m0.Hash()) {
+				t.Fatal()
+			}
+			if !bytes.Equal(m0.Hash(), m1.Hash()) {
+				t.Fatal()
+			}
+			for _, e := range entries {
+				proveAndVerify(t, m0, e.Label, true, e.Val)
+				proveAndVerify(t, m1, e.Label, true, e.Val)
+			}
+		}
+	}
+}
@@ -4,11 +4,15 @@ import (
 	"bytes"
 	"math"
 	"math/rand/v2"
+	"path/filepath"
+	"runtime"
 	"testing"
 	"time"
 
 	"github.com/aclements/go-moremath/stats"
 	"github.com/sanjit-bhat/pav/benchutil"
+	"github.com/sanjit-bhat/pav/cryptoffi"
+	"github.com/sanjit-bhat/pav/merkle/diskstore"
 )
 
 const (
@@ -16,7 +20,74 @@ const (
 )
 
 func TestBenchMerkPut(t *testing.T) {
-	m, _ := seedMap(defNSeed)
+	benchutil.Report(benchMerkPut(cryptoffi.HashSuiteSha256))
+}
+
+func TestBenchMerkPutSha512_256(t *testing.T) {
+	benchutil.Report(benchMerkPut(cryptoffi.HashSuiteSha512_256))
+}
+
+func TestBenchMerkPutBlake3(t *testing.T) {
+	benchutil.Report(benchMerkPut(cryptoffi.HashSuiteBlake3))
+}
+
+func benchMerkPut(suite byte) (nOps int, ms []*benchutil.Metric) {
+	m, _ := seedMap(suite, defNSeed)
+	nOps = 500_000
+
+	start := time.Now()
+	for i := 0; i < nOps; i++ {
+		l := mkRandLabel()
+		v := mkRandVal()
+		m.Put(l, v)
+	}
+	total := time.Since(start)
+
+	m0 := float64(total.Microseconds()) / float64(nOps)
+	m1 := float64(total.Milliseconds())
+	ms = []*benchutil.Metric{
+		{N: m0, Unit: "us/op"},
+		{N: m1, Unit: "total(ms)"},
+	}
+	return
+}
+
+func TestBenchMerkPutBatch(t *testing.T) {
+	m, _ := seedMap(cryptoffi.HashSuiteSha256, defNSeed)
+	batchSz := 5_000
+	nBatches := 100
+
+	var total time.Duration
+	for i := 0; i < nBatches; i++ {
+		entries := make([]*Entry, 0, batchSz)
+		for j := 0; j < batchSz; j++ {
+			entries = append(entries, &Entry{Label: mkRandLabel(), Val: mkRandVal()})
+		}
+		t0 := time.Now()
+		m.PutBatch(entries)
+		total += time.Since(t0)
+	}
+
+	nOps := batchSz * nBatches
+	m0 := float64(total.Microseconds()) / float64(nOps)
+	m1 := float64(total.Milliseconds())
+	benchutil.Report(nOps, []*benchutil.Metric{
+		{N: m0, Unit: "us/op"},
+		{N: m1, Unit: "total(ms)"},
+	})
+}
+
+func TestBenchMerkPutDisk(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nodes")
+	st, err := diskstore.Open(path, 1<<20)
+	if err {
+		t.Fatal()
+	}
+	defer st.Close()
+	m := NewWithStore(cryptoffi.HashSuiteSha256, st)
+	for i := uint64(0); i < defNSeed; i++ {
+		m.Put(mkRandLabel(), mkRandVal())
+	}
 	nOps := 500_000
 
 	start := time.Now()
@@ -36,7 +107,7 @@ func TestBenchMerkPut(t *testing.T) {
 }
 
 func TestBenchMerkGenVer(t *testing.T) {
-	m, labels := seedMap(defNSeed)
+	m, labels := seedMap(cryptoffi.HashSuiteSha256, defNSeed)
 	nOps := 5_000_000
 
 	var totalGen time.Duration
@@ -52,7 +123,7 @@ func TestBenchMerkGenVer(t *testing.T) {
 		d := m.Hash()
 
 		t1 := time.Now()
-		d0, _ := VerifyMemb(l, v, p)
+		d0, _ := VerifyMemb(m.suite, l, v, p)
 		bytes.Equal(d, d0)
 		t2 := time.Now()
 
@@ -73,7 +144,10 @@ func TestBenchMerkGenVer(t *testing.T) {
 }
 
 func TestBenchMerkSize(t *testing.T) {
-	m, labels := seedMap(defNSeed)
+	heap0 := getHeap()
+	m, labels := seedMap(cryptoffi.HashSuiteSha256, defNSeed)
+	// labels are also on the heap, but they're a small fixed cost.
+	heap1 := getHeap()
 	samp := &stats.Sample{Xs: make([]float64, 0, defNSeed)}
 	for _, label := range labels {
 		isReg, _, p := m.Prove(label)
@@ -84,11 +158,20 @@ func TestBenchMerkSize(t *testing.T) {
 	}
 	benchutil.Report(1, []*benchutil.Metric{
 		{N: math.Round(samp.Mean()), Unit: "B"},
+		{N: math.Round(float64(heap1-heap0) / float64(defNSeed)), Unit: "B/entry(mem)"},
 	})
+	runtime.KeepAlive(m)
+}
+
+func getHeap() uint64 {
+	runtime.GC()
+	var s runtime.MemStats
+	runtime.ReadMemStats(&s)
+	return s.HeapAlloc
 }
 
-func seedMap(sz uint64) (m *Map, labels [][]byte) {
-	m = &Map{}
+func seedMap(suite byte, sz uint64) (m *Map, labels [][]byte) {
+	m = New(suite)
 	labels = make([][]byte, 0, sz)
 	for i := uint64(0); i < sz; i++ {
 		l := mkRandLabel()
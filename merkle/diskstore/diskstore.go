@@ -0,0 +1,211 @@
+// Package diskstore is a disk-backed [merkle.Store].
+//
+// it keeps an append-only log of node records, where a ptr is one more
+// than the record's file offset.
+// an LRU cache holds recently-used records.
+package diskstore
+
+import (
+	"bufio"
+	"container/list"
+	"encoding/binary"
+	"io"
+	"os"
+	"sync"
+)
+
+// bufSz is how many appended bytes we buffer before writing them out.
+const bufSz = 1 << 16
+
+type Store struct {
+	mu *sync.Mutex
+	f  *os.File
+	// bufOff is the file offset of buf.
+	bufOff uint64
+	buf    []byte
+	cache  *cache
+}
+
+// Open the log at path, creating it if needed.
+// the cache holds up to cacheSz records.
+// it drops a partial record at the end, e.g., from a torn write.
+func Open(path string, cacheSz uint64) (s *Store, err bool) {
+	f, err0 := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o600)
+	if err0 != nil {
+		err = true
+		return
+	}
+	end, err := recoverLog(f)
+	if err {
+		f.Close()
+		return
+	}
+	s = &Store{
+		mu:     new(sync.Mutex),
+		f:      f,
+		bufOff: end,
+		buf:    make([]byte, 0, bufSz),
+		cache:  newCache(cacheSz),
+	}
+	return
+}
+
+// recoverLog returns the end of the last whole record,
+// and truncates anything after it.
+func recoverLog(f *os.File) (end uint64, err bool) {
+	info, err0 := f.Stat()
+	if err0 != nil {
+		err = true
+		return
+	}
+	size := uint64(info.Size())
+	r := bufio.NewReader(io.NewSectionReader(f, 0, int64(size)))
+	var hdr [8]byte
+	for {
+		if _, err0 := io.ReadFull(r, hdr[:]); err0 != nil {
+			break
+		}
+		n := binary.LittleEndian.Uint64(hdr[:])
+		if n > size-end-8 {
+			break
+		}
+		if _, err0 := r.Discard(int(n)); err0 != nil {
+			err = true
+			return
+		}
+		end += 8 + n
+	}
+	if end == size {
+		return
+	}
+	if f.Truncate(int64(end)) != nil {
+		err = true
+		return
+	}
+	err = f.Sync() != nil
+	return
+}
+
+// Append panics if it can't write out the buffer.
+func (s *Store) Append(b []byte) (ptr uint64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.buf)+8+len(b) > bufSz && len(s.buf) != 0 {
+		s.writeBuf()
+	}
+	off := s.bufOff + uint64(len(s.buf))
+	s.buf = binary.LittleEndian.AppendUint64(s.buf, uint64(len(b)))
+	s.buf = append(s.buf, b...)
+	ptr = off + 1
+	s.cache.put(ptr, b)
+	return
+}
+
+// Get panics if it can't read ptr.
+func (s *Store) Get(ptr uint64) (b []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if b, ok := s.cache.get(ptr); ok {
+		return b
+	}
+	off := ptr - 1
+	if off >= s.bufOff {
+		b = readRecord(s.buf[off-s.bufOff:])
+	} else {
+		b = s.readFile(off)
+	}
+	s.cache.put(ptr, b)
+	return
+}
+
+// Sync makes all appended records durable.
+func (s *Store) Sync() (err bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.writeBuf()
+	return s.f.Sync() != nil
+}
+
+// Close syncs and closes the log.
+func (s *Store) Close() (err bool) {
+	if s.Sync() {
+		err = true
+	}
+	if s.f.Close() != nil {
+		err = true
+	}
+	return
+}
+
+func (s *Store) writeBuf() {
+	if _, err := s.f.WriteAt(s.buf, int64(s.bufOff)); err != nil {
+		panic("diskstore: write: " + err.Error())
+	}
+	s.bufOff += uint64(len(s.buf))
+	s.buf = s.buf[:0]
+}
+
+func (s *Store) readFile(off uint64) []byte {
+	var hdr [8]byte
+	if _, err := s.f.ReadAt(hdr[:], int64(off)); err != nil {
+		panic("diskstore: read: " + err.Error())
+	}
+	n := binary.LittleEndian.Uint64(hdr[:])
+	// don't trust the len past the end of the file.
+	if n > s.bufOff-off-8 {
+		panic("diskstore: read: bad record len")
+	}
+	b := make([]byte, n)
+	if n0, err := s.f.ReadAt(b, int64(off+8)); uint64(n0) != n {
+		panic("diskstore: read: " + err.Error())
+	}
+	return b
+}
+
+func readRecord(buf []byte) []byte {
+	n := binary.LittleEndian.Uint64(buf)
+	b := make([]byte, n)
+	copy(b, buf[8:8+n])
+	return b
+}
+
+// cache is an LRU map from ptr to record.
+type cache struct {
+	sz    uint64
+	order *list.List
+	elems map[uint64]*list.Element
+}
+
+type entry struct {
+	ptr uint64
+	b   []byte
+}
+
+func newCache(sz uint64) *cache {
+	return &cache{sz: sz, order: list.New(), elems: make(map[uint64]*list.Element)}
+}
+
+func (c *cache) get(ptr uint64) ([]byte, bool) {
+	e, ok := c.elems[ptr]
+	if !ok {
+		return nil, false
+	}
+	c.order.MoveToFront(e)
+	return e.Value.(*entry).b, true
+}
+
+func (c *cache) put(ptr uint64, b []byte) {
+	if c.sz == 0 {
+		return
+	}
+	if e, ok := c.elems[ptr]; ok {
+		c.order.MoveToFront(e)
+		return
+	}
+	c.elems[ptr] = c.order.PushFront(&entry{ptr: ptr, b: b})
+	if uint64(c.order.Len()) > c.sz {
+		last := c.order.Back()
+		c.order.Remove(last)
+		delete(c.elems, last.Value.(*entry).ptr)
+	}
+}
@@ -0,0 +1,96 @@
+package diskstore
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nodes")
+	s, err := Open(path, 4)
+	if err {
+		t.Fatal()
+	}
+	var ptrs []uint64
+	var recs [][]byte
+	for i := 0; i < 10_000; i++ {
+		b := bytes.Repeat([]byte{byte(i)}, i%100)
+		ptrs = append(ptrs, s.Append(b))
+		recs = append(recs, b)
+	}
+	check := func(s *Store) {
+		for i, ptr := range ptrs {
+			if !bytes.Equal(s.Get(ptr), recs[i]) {
+				t.Fatal()
+			}
+		}
+	}
+	// some records in buf, some in file.
+	check(s)
+	if s.Close() {
+		t.Fatal()
+	}
+
+	s, err = Open(path, 4)
+	if err {
+		t.Fatal()
+	}
+	check(s)
+	// appends go after existing records.
+	ptr := s.Append([]byte("new"))
+	if !bytes.Equal(s.Get(ptr), []byte("new")) {
+		t.Fatal()
+	}
+	check(s)
+	if s.Close() {
+		t.Fatal()
+	}
+}
+
+func TestTorn(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nodes")
+	s, err := Open(path, 0)
+	if err {
+		t.Fatal()
+	}
+	ptr0 := s.Append([]byte("rec0"))
+	if s.Close() {
+		t.Fatal()
+	}
+	info, _ := os.Stat(path)
+	size := info.Size()
+
+	// a torn header, and a torn record with a len past the end.
+	for _, torn := range [][]byte{{1, 2, 3}, {100, 0, 0, 0, 0, 0, 0, 0, 'a', 'b'}} {
+		f, _ := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0)
+		f.Write(torn)
+		f.Close()
+
+		s, err = Open(path, 0)
+		if err {
+			t.Fatal()
+		}
+		if info, _ = os.Stat(path); info.Size() != size {
+			t.Fatal()
+		}
+		// new records go right after the last whole one.
+		ptr1 := s.Append([]byte("rec1"))
+		if s.Close() {
+			t.Fatal()
+		}
+		s, err = Open(path, 0)
+		if err {
+			t.Fatal()
+		}
+		if !bytes.Equal(s.Get(ptr0), []byte("rec0")) || !bytes.Equal(s.Get(ptr1), []byte("rec1")) {
+			t.Fatal()
+		}
+		if s.Close() {
+			t.Fatal()
+		}
+		info, _ = os.Stat(path)
+		size = info.Size()
+	}
+}
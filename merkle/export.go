@@ -0,0 +1,206 @@
+//go:build !goose
+
+package merkle
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+
+	"github.com/goose-lang/std"
+	"github.com/sanjit-bhat/pav/cryptoffi"
+	"github.com/tchajed/marshal"
+)
+
+// export format:
+//   - each entry is entryRecTag ++ LabelLen ++ Label ++ ValLen ++ Val,
+//     in path order.
+//   - the end is endRecTag ++ NumEntries.
+//
+// the end record lets [Import] catch truncated exports.
+const (
+	entryRecTag byte = iota
+	endRecTag
+)
+
+// Export writes the entries starting with the first prefixLen bits of prefix.
+// use prefixLen 0 for the entire map.
+// it streams entries, so it doesn't hold them in memory.
+// it errors if it can't write to w.
+func (m *Map) Export(w io.Writer, prefix []byte, prefixLen uint64) (err bool) {
+	bw := bufio.NewWriter(w)
+	var num uint64
+	for label, val := range m.Prefix(prefix, prefixLen) {
+		b := make([]byte, 0, 1+8+uint64(len(label))+8+uint64(len(val)))
+		b = append(b, entryRecTag)
+		b = marshal.WriteInt(b, uint64(len(label)))
+		b = marshal.WriteBytes(b, label)
+		b = marshal.WriteInt(b, uint64(len(val)))
+		b = marshal.WriteBytes(b, val)
+		if _, err0 := bw.Write(b); err0 != nil {
+			err = true
+			return
+		}
+		num++
+	}
+	b := marshal.WriteInt([]byte{endRecTag}, num)
+	if _, err0 := bw.Write(b); err0 != nil {
+		err = true
+		return
+	}
+	return bw.Flush() != nil
+}
+
+// Import returns a map with the entries from an [Map.Export].
+// it hashes with suite, and keeps its nodes in st, which may be nil for
+// an in-memory map.
+// it builds the tree as the entries arrive, flushing each finished
+// sub-tree to st, so it only holds one path of nodes in memory.
+// it errors if r doesn't have a well-formed export.
+// callers that expect some hash should check that they got the right one.
+func Import(suite byte, st Store, r io.Reader) (m *Map, err bool) {
+	std.Assert(!cryptoffi.CheckHashSuite(suite))
+	br := bufio.NewReader(r)
+	b := &builder{suite: suite, st: st}
+	var num uint64
+	for {
+		tag, err0 := br.ReadByte()
+		if err0 != nil {
+			err = true
+			return
+		}
+		if tag == endRecTag {
+			break
+		}
+		if tag != entryRecTag {
+			err = true
+			return
+		}
+		label, err1 := readSlice(br)
+		if err1 {
+			err = true
+			return
+		}
+		val, err1 := readSlice(br)
+		if err1 {
+			err = true
+			return
+		}
+		if uint64(len(label)) != cryptoffi.HashLen {
+			err = true
+			return
+		}
+		// path order also rules out duplicate labels.
+		if b.last != nil && comparePath(b.last, label) >= 0 {
+			err = true
+			return
+		}
+		b.add(label, val)
+		num++
+	}
+	num0, err1 := readInt(br)
+	if err1 {
+		err = true
+		return
+	}
+	if num0 != num {
+		err = true
+		return
+	}
+	if _, err0 := br.ReadByte(); err0 != io.EOF {
+		err = true
+		return
+	}
+
+	m = &Map{suite: suite, store: st, root: flush(st, b.finish())}
+	return
+}
+
+// builder makes a tree from leaves added in path order.
+// the tree so far is the right-most path, where each frame
+// has a finished child0 and an unfinished child1.
+// cur is the finished sub-tree below the last frame.
+type builder struct {
+	suite  byte
+	st     Store
+	frames []*frame
+	cur    node
+	// last is the label of the last leaf.
+	last []byte
+}
+
+type frame struct {
+	depth  uint64
+	child0 node
+}
+
+// add expects label to come after the last label in path order.
+func (b *builder) add(label, val []byte) {
+	if b.last != nil {
+		// the new leaf splits off at diff, which finishes
+		// the frames below diff.
+		diff := getDiff(b.last, label)
+		for b.hasBelow(diff) {
+			// the parent is the next frame, if it's also below diff,
+			// or else the split at diff.
+			top := diff + 1
+			if len(b.frames) > 1 && b.frames[len(b.frames)-2].depth > diff {
+				top = b.frames[len(b.frames)-2].depth + 1
+			}
+			b.pop(top)
+		}
+		b.frames = append(b.frames, &frame{depth: diff, child0: flush(b.st, b.cur)})
+	}
+	b.cur = newLeaf(b.suite, label, val)
+	b.last = label
+}
+
+// finish returns the root, or nil if there are no leaves.
+func (b *builder) finish() node {
+	for len(b.frames) != 0 {
+		var top uint64
+		if len(b.frames) > 1 {
+			top = b.frames[len(b.frames)-2].depth + 1
+		}
+		b.pop(top)
+	}
+	return b.cur
+}
+
+func (b *builder) hasBelow(depth uint64) bool {
+	return len(b.frames) != 0 && b.frames[len(b.frames)-1].depth > depth
+}
+
+// pop finishes the last frame, whose parent is at level top.
+func (b *builder) pop(top uint64) {
+	f := b.frames[len(b.frames)-1]
+	b.frames = b.frames[:len(b.frames)-1]
+	b.cur = newInner(b.suite, top, f.depth, b.last, f.child0, b.cur)
+}
+
+func readInt(r io.Reader) (x uint64, err bool) {
+	var b [8]byte
+	if _, err0 := io.ReadFull(r, b[:]); err0 != nil {
+		err = true
+		return
+	}
+	x, _ = marshal.ReadInt(b[:])
+	return
+}
+
+// readSlice reads a length-prefixed slice.
+// it only allocates as it reads, so a bad length can't exhaust memory.
+func readSlice(r io.Reader) (b []byte, err bool) {
+	n, err := readInt(r)
+	if err {
+		return
+	}
+	buf := new(bytes.Buffer)
+	n0, err0 := io.CopyN(buf, r, int64(min(n, 1<<62)))
+	if err0 != nil || uint64(n0) != n {
+		err = true
+		return
+	}
+	b = buf.Bytes()
+	return
+}
@@ -0,0 +1,28 @@
+//go:build !goose
+
+package merkle
+
+import (
+	"iter"
+
+	"github.com/goose-lang/std"
+)
+
+// All returns the map entries in path order, i.e., ordered by label bits,
+// starting from the root.
+// the labels and vals are immutable references into the map.
+func (m *Map) All() iter.Seq2[[]byte, []byte] {
+	return m.Prefix(nil, 0)
+}
+
+// Prefix returns, in path order, the entries whose labels start with
+// the first prefixLen bits of prefix.
+// it only visits the sub-tree under that prefix.
+func (m *Map) Prefix(prefix []byte, prefixLen uint64) iter.Seq2[[]byte, []byte] {
+	std.Assert(prefixLen <= maxDepth)
+	std.Assert(prefixLen <= uint64(len(prefix))*8)
+	return func(yield func(label, val []byte) bool) {
+		n := getPrefix(m.store, m.root, prefix, prefixLen)
+		iterate(m.store, n, yield)
+	}
+}
@@ -0,0 +1,209 @@
+package merkle
+
+import (
+	"bytes"
+	"math/rand/v2"
+	"slices"
+	"testing"
+
+	"github.com/sanjit-bhat/pav/cryptoffi"
+)
+
+func TestIter(t *testing.T) {
+	var seed [32]byte
+	rnd := rand.NewChaCha8(seed)
+	base := make([]byte, cryptoffi.HashLen)
+	rnd.Read(base)
+	m := NewWithStore(cryptoffi.HashSuiteSha256, &memStore{})
+	if m.Len() != 0 {
+		t.Fatal()
+	}
+	var labels [][]byte
+	for i := 0; i < 500; i++ {
+		// some labels share long prefixes.
+		l := make([]byte, cryptoffi.HashLen)
+		rnd.Read(l)
+		copy(l, base[:i%20])
+		m.Put(l, []byte{byte(i)})
+		labels = append(labels, l)
+	}
+	slices.SortFunc(labels, comparePath)
+
+	if m.Len() != uint64(len(labels)) {
+		t.Fatal()
+	}
+	var got [][]byte
+	for label := range m.All() {
+		got = append(got, label)
+	}
+	if !slices.EqualFunc(got, labels, bytes.Equal) {
+		t.Fatal()
+	}
+
+	for _, prefixLen := range []uint64{0, 1, 7, 8, 9, 60, 100, maxDepth} {
+		var exp [][]byte
+		for _, l := range labels {
+			if HasPrefix(l, base, prefixLen) {
+				exp = append(exp, l)
+			}
+		}
+		var got [][]byte
+		for label, val := range m.Prefix(base, prefixLen) {
+			_, val0, _ := m.Prove(label)
+			if !bytes.Equal(val, val0) {
+				t.Fatal()
+			}
+			got = append(got, label)
+		}
+		if !slices.EqualFunc(got, exp, bytes.Equal) {
+			t.Fatal(prefixLen)
+		}
+	}
+
+	// early stop.
+	var n int
+	for range m.All() {
+		n++
+		if n == 10 {
+			break
+		}
+	}
+	if n != 10 {
+		t.Fatal()
+	}
+}
+
+func TestExport(t *testing.T) {
+	suite := cryptoffi.HashSuiteBlake3
+	m := New(suite)
+	for i := 0; i < 300; i++ {
+		m.Put(mkRandLabel(), mkRandVal())
+	}
+	m.Put(mkRandLabel(), nil)
+	buf := new(bytes.Buffer)
+	if m.Export(buf, nil, 0) {
+		t.Fatal()
+	}
+	exp := buf.Bytes()
+
+	m0, err := Import(suite, nil, bytes.NewReader(exp))
+	if err {
+		t.Fatal()
+	}
+	if !bytes.Equal(m.Hash(), m0.Hash()) {
+		t.Fatal()
+	}
+	m1, err := Import(suite, &memStore{}, bytes.NewReader(exp))
+	if err {
+		t.Fatal()
+	}
+	if !bytes.Equal(m.Hash(), m1.Hash()) {
+		t.Fatal()
+	}
+	if m1.Verify() {
+		t.Fatal()
+	}
+
+	// prefix export.
+	label := mkRandLabel()
+	buf.Reset()
+	if m.Export(buf, label, 3) {
+		t.Fatal()
+	}
+	m2, err := Import(suite, nil, buf)
+	if err {
+		t.Fatal()
+	}
+	var n uint64
+	for l := range m.Prefix(label, 3) {
+		if inMap, _, _ := m2.Prove(l); !inMap {
+			t.Fatal()
+		}
+		n++
+	}
+	if n == 0 || n != m2.Len() {
+		t.Fatal()
+	}
+
+	// empty export.
+	buf.Reset()
+	if New(suite).Export(buf, nil, 0) {
+		t.Fatal()
+	}
+	m3, err := Import(suite, nil, buf)
+	if err {
+		t.Fatal()
+	}
+	if !bytes.Equal(m3.Hash(), New(suite).Hash()) {
+		t.Fatal()
+	}
+
+	// bad exports.
+	for i := 0; i < len(exp); i += 17 {
+		if _, err := Import(suite, nil, bytes.NewReader(exp[:i])); !err {
+			t.Fatal(i)
+		}
+	}
+	if _, err := Import(suite, nil, bytes.NewReader(append(slices.Clone(exp), 0))); !err {
+		t.Fatal()
+	}
+	// out-of-order entries.
+	m4 := New(suite)
+	m4.Put(mkRandLabel(), []byte{1})
+	m4.Put(mkRandLabel(), []byte{2})
+	buf.Reset()
+	if m4.Export(buf, nil, 0) {
+		t.Fatal()
+	}
+	exp4 := buf.Bytes()
+	// the end record is 9 bytes.
+	recLen := (len(exp4) - 9) / 2
+	swap := slices.Concat(exp4[recLen:2*recLen], exp4[:recLen], exp4[2*recLen:])
+	if _, err := Import(suite, nil, bytes.NewReader(swap)); !err {
+		t.Fatal()
+	}
+	if _, err := Import(suite, nil, bytes.NewReader(exp4)); err {
+		t.Fatal()
+	}
+}
+
+func TestVerify(t *testing.T) {
+	for _, st := range []Store{nil, &memStore{}} {
+		m := NewWithStore(cryptoffi.HashSuiteSha256, st)
+		if m.Verify() {
+			t.Fatal()
+		}
+		var labels [][]byte
+		for i := 0; i < 100; i++ {
+			l := mkRandLabel()
+			m.Put(l, mkRandVal())
+			labels = append(labels, l)
+		}
+		if m.Verify() {
+			t.Fatal()
+		}
+	}
+
+	// corrupt a leaf deep in the tree.
+	m := New(cryptoffi.HashSuiteSha256)
+	label := mkRandLabel()
+	m.Put(label, []byte{1})
+	for i := 0; i < 100; i++ {
+		m.Put(mkRandLabel(), mkRandVal())
+	}
+	_, l, _ := walk(nil, m.root, label)
+	l.val[0] ^= 1
+	if !m.Verify() {
+		t.Fatal()
+	}
+	l.val[0] ^= 1
+	if m.Verify() {
+		t.Fatal()
+	}
+	// move an inner node to the wrong depth.
+	path, _, _ := walk(nil, m.root, label)
+	path[len(path)-1].depth++
+	if !m.Verify() {
+		t.Fatal()
+	}
+}
@@ -0,0 +1,83 @@
+package merkle
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"math/rand/v2"
+	"os"
+	"testing"
+
+	"github.com/sanjit-bhat/pav/cryptoffi"
+)
+
+// layoutVec pins hashes and proofs from the tree before path compression.
+// testdata/layout.json was generated with the old node layout.
+type layoutVec struct {
+	Suite    byte
+	Hash     string
+	Proofs   []*layoutProof
+	UpdLabel string
+	UpdVal   string
+	UpdProof string
+	UpdHash  string
+}
+
+type layoutProof struct {
+	Label string
+	InMap bool
+	Val   string
+	Proof string
+}
+
+func TestLayout(t *testing.T) {
+	b, err := os.ReadFile("testdata/layout.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var vecs []*layoutVec
+	if err = json.Unmarshal(b, &vecs); err != nil {
+		t.Fatal(err)
+	}
+	for _, v := range vecs {
+		var seed [32]byte
+		rnd := rand.NewChaCha8(seed)
+		m := New(v.Suite)
+		for i := 0; i < 64; i++ {
+			label := make([]byte, cryptoffi.HashLen)
+			val := make([]byte, 4)
+			rnd.Read(label)
+			rnd.Read(val)
+			m.Put(label, val)
+		}
+		if !bytes.Equal(m.Hash(), unhex(t, v.Hash)) {
+			t.Fatal(v.Suite)
+		}
+
+		for _, p := range v.Proofs {
+			inMap, val, proof := m.Prove(unhex(t, p.Label))
+			if inMap != p.InMap || !bytes.Equal(val, unhex(t, p.Val)) {
+				t.Fatal(v.Suite, p.Label)
+			}
+			if !bytes.Equal(proof, unhex(t, p.Proof)) {
+				t.Fatal(v.Suite, p.Label)
+			}
+		}
+
+		upd := m.Put(unhex(t, v.UpdLabel), unhex(t, v.UpdVal))
+		if !bytes.Equal(upd, unhex(t, v.UpdProof)) {
+			t.Fatal(v.Suite)
+		}
+		if !bytes.Equal(m.Hash(), unhex(t, v.UpdHash)) {
+			t.Fatal(v.Suite)
+		}
+	}
+}
+
+func unhex(t *testing.T, s string) []byte {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
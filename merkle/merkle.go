@@ -19,8 +19,7 @@ const (
 	innerNodeTag
 )
 
-// nodeTy used to distinguish in-memory nodes.
-// empty node is nil node ptr.
+// nodeTy used to distinguish nodes in a [Store].
 const (
 	cutNodeTy byte = iota
 	leafNodeTy
@@ -30,91 +29,183 @@ const (
 const maxDepth = cryptoffi.HashLen * 8
 
 var (
-	// emptyHash pre-computed. frequently used.
-	emptyHash = compEmptyHash()
+	// emptyHashes pre-computed for each hash suite. frequently used.
+	emptyHashes = [][]byte{
+		compEmptyHash(cryptoffi.HashSuiteSha256),
+		compEmptyHash(cryptoffi.HashSuiteSha512_256),
+		compEmptyHash(cryptoffi.HashSuiteBlake3),
+	}
 )
 
+// Map is a merkle map.
+// the zero value is an empty map with hash suite [cryptoffi.HashSuiteSha256].
 type Map struct {
-	root *node
-}
-
-// node contains union of different nodeTy's.
-// NOTE: it may be cleaner to use diff structs for the diff node types.
-// their interface includes compHash and getHash.
-// compHash has a unique impl, but getHash is the same.
-type node struct {
-	nodeTy byte
-	hash   []byte
-	// only for inner node.
-	child0 *node
-	// only for inner node.
-	child1 *node
-	// only for leaf node.
+	root node
+	// suite is the [cryptoffi] hash suite.
+	suite byte
+	// store is nil for an in-memory map.
+	store Store
+}
+
+// New returns an empty map that hashes with suite.
+func New(suite byte) *Map {
+	std.Assert(!cryptoffi.CheckHashSuite(suite))
+	return &Map{suite: suite}
+}
+
+// node is a *leaf, *inner, or *cut. an empty node is nil.
+//
+// the tree is path-compressed.
+// an inner node skips the levels above it whose nodes would only have
+// one non-empty child.
+// hashes and proofs still include the skipped levels, so they're the same
+// as for an uncompressed tree.
+type node interface {
+	getHash() []byte
+}
+
+type leaf struct {
+	hash  [cryptoffi.HashLen]byte
 	label []byte
-	// only for leaf node.
-	val []byte
+	val   []byte
+}
+
+type inner struct {
+	// hash is for the top of the skipped levels,
+	// i.e., where the parent points to this node.
+	hash [cryptoffi.HashLen]byte
+	// depth is the level of the label bit that splits the children.
+	depth  uint64
+	child0 node
+	child1 node
+}
+
+// cut hides a sub-tree.
+type cut struct {
+	hash [cryptoffi.HashLen]byte
+	// ptr locates the sub-tree in the map's [Store], or is 0 if it's not stored.
+	// a cut with a ptr is a stub that we load on demand.
+	ptr uint64
+}
+
+func (n *leaf) getHash() []byte {
+	return n.hash[:]
+}
+
+func (n *inner) getHash() []byte {
+	return n.hash[:]
+}
+
+func (n *cut) getHash() []byte {
+	return n.hash[:]
 }
 
 // Put adds the leaf (label, val), storing immutable references to both.
 // for liveness and safety reasons, it expects the label to have fixed length.
+// nodes are copy-on-write, so Put never changes a [Map.Snapshot].
 func (m *Map) Put(label []byte, val []byte) (updProof []byte) {
 	std.Assert(uint64(len(label)) == cryptoffi.HashLen)
 	// Put is part of external API, which does not expose cut trees.
-	// therefore, we meet the precond for node.prove and put.
-	inMap, _, updProof := m.root.prove(label, true)
+	// therefore, we meet the precond for prove and put.
+	inMap, _, updProof := prove(m.suite, m.store, m.root, label, true)
 	// for now, [VerifyUpdate] only works for monotonic update.
 	std.Assert(!inMap)
-	std.Assert(!put(&m.root, 0, label, val))
+	root, err := put(m.suite, m.store, m.root, label, val)
+	std.Assert(!err)
+	m.root = flush(m.store, root)
 	return
 }
 
-// put inserts leaf node (label, val) into the n0 sub-tree.
+// Snapshot returns the current map.
+// it takes constant time and shares all nodes with m.
+func (m *Map) Snapshot() *Map {
+	return &Map{root: m.root, suite: m.suite, store: m.store}
+}
+
+// put returns the n sub-tree with leaf (label, val) inserted.
+// it doesn't modify n, but instead copies the nodes along label.
+// it loads stubs from st, which may be nil if there are none.
 // it errors iff there's an insert into a cut node, since that almost always
 // leaves the tree in an unintended state.
-func put(n0 **node, depth uint64, label, val []byte) (err bool) {
-	std.Assert(depth <= maxDepth)
-	n := *n0
-
-	// empty.
-	if n == nil {
-		// replace with leaf.
-		leaf := &node{nodeTy: leafNodeTy, label: label, val: val}
-		*n0 = leaf
-		leaf.hash = compLeafHash(label, val)
+func put(suite byte, st Store, n node, label, val []byte) (n0 node, err bool) {
+	_, l, err := walk(st, n, label)
+	if err {
 		return
 	}
+	// if label leaves the tree at a skipped level,
+	// the leaf we reached has the skipped bits.
+	pathLabel := label
+	diff := maxDepth
+	if l != nil {
+		pathLabel = l.label
+		diff = getDiff(label, l.label)
+	}
+	n0 = insert(suite, st, n, 0, label, val, pathLabel, diff)
+	return
+}
 
-	if n.nodeTy == leafNodeTy {
+// insert puts (label, val) into the n sub-tree, whose top is at level top.
+// diff is the first level where label differs from the tree,
+// or maxDepth if there's no such level.
+func insert(suite byte, st Store, n node, top uint64, label, val, pathLabel []byte, diff uint64) node {
+	std.Assert(top <= maxDepth)
+	switch n0 := load(st, n).(type) {
+	case nil:
+		return newLeaf(suite, label, val)
+	case *leaf:
 		// on exact label match, replace val.
-		if bytes.Equal(n.label, label) {
-			n.val = val
-			n.hash = compLeafHash(label, val)
-			return
+		if diff == maxDepth {
+			return newLeaf(suite, label, val)
 		}
-
-		// otherwise, replace with inner node that links
-		// to existing leaf, and recurse.
-		inner := &node{nodeTy: innerNodeTy}
-		*n0 = inner
-		oldChild, _ := inner.getChild(n.label, depth)
-		*oldChild = n
-		newChild, _ := inner.getChild(label, depth)
-		std.Assert(!put(newChild, depth+1, label, val))
-		inner.hash = compInnerHash(inner.child0.getHash(), inner.child1.getHash())
-		return
+		// otherwise, split at the first diff bit.
+		// keep n, which might be smaller than n0.
+		std.Assert(top <= diff)
+		return newSplit(suite, top, diff, label, newLeaf(suite, label, val), n)
+	case *inner:
+		// label leaves the tree at a skipped level, so split there.
+		if diff < n0.depth {
+			std.Assert(top <= diff)
+			rest := newInner(suite, diff+1, n0.depth, pathLabel, n0.child0, n0.child1)
+			return newSplit(suite, top, diff, label, newLeaf(suite, label, val), rest)
+		}
+		// recurse.
+		child0 := n0.child0
+		child1 := n0.child1
+		if getBit(label, n0.depth) {
+			child1 = insert(suite, st, child1, n0.depth+1, label, val, pathLabel, diff)
+		} else {
+			child0 = insert(suite, st, child0, n0.depth+1, label, val, pathLabel, diff)
+		}
+		return newInner(suite, top, n0.depth, label, child0, child1)
 	}
+	// walk already errored on cut nodes.
+	panic("merkle: insert into cut node")
+}
 
-	if n.nodeTy == innerNodeTy {
-		c, _ := n.getChild(label, depth)
-		// recurse.
-		if err = put(c, depth+1, label, val); err {
+// walk follows label down the n sub-tree, only checking the bits
+// at inner nodes.
+// it returns the inner nodes on the way, and the leaf at the end, if any.
+// it errors if it reaches a cut node that's not a stub.
+func walk(st Store, n node, label []byte) (path []*inner, l *leaf, err bool) {
+	// enough for most trees.
+	path = make([]*inner, 0, 32)
+	curr := load(st, n)
+	for {
+		switch n0 := curr.(type) {
+		case nil:
+			return
+		case *leaf:
+			l = n0
+			return
+		case *inner:
+			path = append(path, n0)
+			child, _ := n0.getChild(label)
+			curr = load(st, child)
+		case *cut:
+			err = true
 			return
 		}
-		n.hash = compInnerHash(n.child0.getHash(), n.child1.getHash())
-		return
 	}
-	std.Assert(n.nodeTy == cutNodeTy)
-	return true
 }
 
 // Prove the membership of label.
@@ -122,13 +213,40 @@ func (m *Map) Prove(label []byte) (inMap bool, val, entryProof []byte) {
 	std.Assert(uint64(len(label)) == cryptoffi.HashLen)
 	// Prove is part of the external API, which does not expose cut trees.
 	// therefore, we meet the precond.
-	return m.root.prove(label, true)
+	return prove(m.suite, m.store, m.root, label, true)
 }
 
-// prove expects no cut nodes along label.
-func (n *node) prove(label []byte, getProof bool) (inTree bool, val, proof []byte) {
-	found, foundLabel, val, proof := n.find(0, label, getProof)
+// prove expects no cut nodes along label, except for stubs in st.
+func prove(suite byte, st Store, n node, label []byte, getProof bool) (inTree bool, val, proof []byte) {
+	path, l, err := walk(st, n, label)
+	if err {
+		// cut hides the sub-tree, so don't know if there.
+		panic("merkle: find into cut node")
+	}
+	// depth is the level where label leaves the tree.
+	var top uint64
+	if len(path) != 0 {
+		top = path[len(path)-1].depth + 1
+	}
+	depth := top
+	var found bool
+	var foundLabel []byte
+	if l != nil {
+		diff := getDiff(label, l.label)
+		if diff < top {
+			// label hits an empty node at a skipped level.
+			depth = diff + 1
+		} else {
+			found = true
+			foundLabel = l.label
+			val = l.val
+		}
+	}
+
 	if getProof {
+		// leave space for SibsLen (8).
+		proof = make([]byte, 8, getProofCap(depth))
+		proof = appendSibs(suite, proof, path, l, label, depth)
 		binary.LittleEndian.PutUint64(proof, uint64(len(proof))-8) // SibsLen
 	}
 
@@ -138,6 +256,7 @@ func (n *node) prove(label []byte, getProof bool) (inTree bool, val, proof []byt
 			proof = marshal.WriteInt(proof, 0)      // empty LeafLabelLen
 			proof = marshal.WriteInt(proof, 0)      // empty LeafValLen
 		}
+		val = nil
 		return
 	}
 	if !bytes.Equal(foundLabel, label) {
@@ -159,41 +278,33 @@ func (n *node) prove(label []byte, getProof bool) (inTree bool, val, proof []byt
 	return
 }
 
-// find searches the tree for a leaf node down path label.
-// it expects no cut nodes along label.
-func (n *node) find(depth uint64, label []byte, getProof bool) (found bool, foundLabel, foundVal, sibs []byte) {
-	// if empty, not found.
-	if n == nil {
-		if getProof {
-			// leave space for SibsLen (8).
-			sibs = make([]byte, 8, getProofCap(depth))
+// appendSibs appends a sibling hash for each level above depth,
+// starting from the bottom.
+// if depth ends within the skipped levels of some inner node,
+// the last leaf has the skipped bits.
+func appendSibs(suite byte, sibs []byte, path []*inner, l *leaf, label []byte, depth uint64) []byte {
+	sibs0 := sibs
+	for i := len(path) - 1; i >= 0; i-- {
+		n := path[i]
+		var top uint64
+		if i != 0 {
+			top = path[i-1].depth + 1
 		}
-		return
-	}
-
-	// if leaf, found!
-	if n.nodeTy == leafNodeTy {
-		found = true
-		foundLabel = n.label
-		foundVal = n.val
-		if getProof {
-			sibs = make([]byte, 8, getProofCap(depth))
+		if n.depth < depth {
+			_, sib := n.getChild(label)
+			sibs0 = append(sibs0, getHash(suite, sib)...)
+		} else if top < depth {
+			// label leaves the tree at level depth-1,
+			// where the sibling is the rest of the skipped levels.
+			h := compInnerHash(suite, getHash(suite, n.child0), getHash(suite, n.child1))
+			sibs0 = append(sibs0, compSkipHash(suite, l.label, depth, n.depth, h)...)
 		}
-		return
-	}
-
-	// recurse down inner.
-	if n.nodeTy == innerNodeTy {
-		child, sib := n.getChild(label, depth)
-		found, foundLabel, foundVal, sibs = (*child).find(depth+1, label, getProof)
-		if getProof {
-			// proof will have sibling hash for each inner node.
-			sibs = append(sibs, (*sib).getHash()...)
+		end := min(n.depth, depth-1)
+		for lvl := top; lvl < end; lvl++ {
+			sibs0 = append(sibs0, emptyHashes[suite]...)
 		}
-		return
 	}
-	// cut hides the sub-tree, so don't know if there.
-	panic("merkle: find into cut node")
+	return sibs0
 }
 
 func getProofCap(depth uint64) uint64 {
@@ -206,45 +317,115 @@ func getProofCap(depth uint64) uint64 {
 // VerifyMemb checks that (label, val) in tree described by proof.
 // to save on bandwidth, some callers get hash from Verify.
 // callers that expect some hash should check that they got the right one.
-func VerifyMemb(label, val, entryProof []byte) (hash []byte, err bool) {
-	tr, err := proofToTree(label, entryProof)
+func VerifyMemb(suite byte, label, val, entryProof []byte) (hash []byte, err bool) {
+	tr, err := proofToTree(suite, label, entryProof)
 	if err {
 		return
 	}
-	std.Assert(!put(&tr, 0, label, val))
-	hash = tr.getHash()
+	tr, err0 := put(suite, nil, tr, label, val)
+	std.Assert(!err0)
+	hash = getHash(suite, tr)
 	return
 }
 
 // VerifyNonMemb checks that label not in tree described by proof.
-func VerifyNonMemb(label, entryProof []byte) (hash []byte, err bool) {
-	tr, err := proofToTree(label, entryProof)
+func VerifyNonMemb(suite byte, label, entryProof []byte) (hash []byte, err bool) {
+	tr, err := proofToTree(suite, label, entryProof)
 	if err {
 		return
 	}
-	hash = tr.getHash()
+	hash = getHash(suite, tr)
 	return
 }
 
 // VerifyUpdate returns the hash for an old tree without label and
 // the hash after inserting (label, val).
-func VerifyUpdate(label, val, updProof []byte) (hashOld, hashNew []byte, err bool) {
-	tr, err := proofToTree(label, updProof)
+func VerifyUpdate(suite byte, label, val, updProof []byte) (hashOld, hashNew []byte, err bool) {
+	tr, err := proofToTree(suite, label, updProof)
 	if err {
 		return
 	}
-	hashOld = tr.getHash()
-	std.Assert(!put(&tr, 0, label, val))
-	hashNew = tr.getHash()
+	hashOld = getHash(suite, tr)
+	tr, err0 := put(suite, nil, tr, label, val)
+	std.Assert(!err0)
+	hashNew = getHash(suite, tr)
 	return
 }
 
 func (m *Map) Hash() []byte {
-	return m.root.getHash()
+	return getHash(m.suite, m.root)
+}
+
+// Verify recomputes every hash in the map, and checks that each node
+// is where its labels say it should be.
+// it errors on mismatch, e.g., from memory or [Store] corruption.
+func (m *Map) Verify() (err bool) {
+	_, _, err = verify(m.suite, m.store, m.root, 0)
+	return
+}
+
+// verify checks the n sub-tree, whose top is at level top.
+// it returns the hash, and a sample label from the sub-tree,
+// which has the bits of all levels above the first split.
+func verify(suite byte, st Store, n node, top uint64) (hash, label []byte, err bool) {
+	if n == nil {
+		hash = emptyHashes[suite]
+		return
+	}
+	if c, ok := n.(*cut); ok {
+		// a map only has stub cuts.
+		if c.ptr == 0 {
+			err = true
+			return
+		}
+		if hash, label, err = verify(suite, st, load(st, c), top); err {
+			return
+		}
+		if !bytes.Equal(hash, c.getHash()) {
+			err = true
+		}
+		return
+	}
+	switch n0 := n.(type) {
+	case *leaf:
+		if uint64(len(n0.label)) != cryptoffi.HashLen {
+			err = true
+			return
+		}
+		hash = compLeafHash(suite, n0.label, n0.val)
+		label = n0.label
+	case *inner:
+		// an inner node always splits two non-empty children.
+		if n0.depth < top || n0.depth >= maxDepth || n0.child0 == nil || n0.child1 == nil {
+			err = true
+			return
+		}
+		h0, label0, err0 := verify(suite, st, n0.child0, n0.depth+1)
+		if err0 {
+			err = true
+			return
+		}
+		h1, label1, err0 := verify(suite, st, n0.child1, n0.depth+1)
+		if err0 {
+			err = true
+			return
+		}
+		if getDiff(label0, label1) != n0.depth || getBit(label0, n0.depth) {
+			err = true
+			return
+		}
+		h := compInnerHash(suite, h0, h1)
+		hash = compSkipHash(suite, label0, top, n0.depth, h)
+		label = label0
+	}
+	if !bytes.Equal(hash, n.getHash()) {
+		err = true
+	}
+	return
 }
 
 // proofToTree guarantees that label not in tree and that label has fixed len.
-func proofToTree(label, proof []byte) (tr *node, err bool) {
+func proofToTree(suite byte, label, proof []byte) (tr node, err bool) {
 	if uint64(len(label)) != cryptoffi.HashLen {
 		err = true
 		return
@@ -262,7 +443,7 @@ func proofToTree(label, proof []byte) (tr *node, err bool) {
 		err = true
 		return
 	}
-	tr = newShell(0, label, p.Siblings)
+	tr = newShell(suite, 0, label, p.Siblings)
 	if p.IsOtherLeaf {
 		if uint64(len(p.LeafLabel)) != cryptoffi.HashLen {
 			err = true
@@ -272,43 +453,70 @@ func proofToTree(label, proof []byte) (tr *node, err bool) {
 			err = true
 			return
 		}
-		if err = put(&tr, 0, p.LeafLabel, p.LeafVal); err {
+		if tr, err = put(suite, nil, tr, p.LeafLabel, p.LeafVal); err {
 			return
 		}
 	}
 	return
 }
 
-func newShell(depth uint64, label []byte, sibs []byte) (n *node) {
+// newShell has an uncompressed inner node at each level,
+// with a cut node as the sibling.
+func newShell(suite byte, depth uint64, label []byte, sibs []byte) node {
 	sibsLen := uint64(len(sibs))
 	if sibsLen == 0 {
-		return
+		return nil
 	}
 	split := sibsLen - cryptoffi.HashLen
 	sibs0 := sibs[:split]
-	hash := sibs[split:]
-	cut := &node{nodeTy: cutNodeTy, hash: hash}
-	inner := &node{nodeTy: innerNodeTy}
-	child, sib := inner.getChild(label, depth)
-	*sib = cut
-	*child = newShell(depth+1, label, sibs0)
-	inner.hash = compInnerHash(inner.child0.getHash(), inner.child1.getHash())
-	return inner
+	sib := &cut{}
+	copy(sib.hash[:], sibs[split:])
+	child := newShell(suite, depth+1, label, sibs0)
+	if getBit(label, depth) {
+		return newInner(suite, depth, depth, label, sib, child)
+	} else {
+		return newInner(suite, depth, depth, label, child, sib)
+	}
+}
+
+func newLeaf(suite byte, label, val []byte) *leaf {
+	n := &leaf{label: label, val: val}
+	copy(n.hash[:], compLeafHash(suite, label, val))
+	return n
+}
+
+// newInner returns an inner node at depth, which skips levels
+// in [top, depth) along pathLabel.
+func newInner(suite byte, top, depth uint64, pathLabel []byte, child0, child1 node) *inner {
+	n := &inner{depth: depth, child0: child0, child1: child1}
+	h := compInnerHash(suite, getHash(suite, child0), getHash(suite, child1))
+	copy(n.hash[:], compSkipHash(suite, pathLabel, top, depth, h))
+	return n
 }
 
-func (n *node) getHash() []byte {
+// newSplit returns an inner node at depth, with n0 down label and n1 on the
+// other side.
+func newSplit(suite byte, top, depth uint64, label []byte, n0, n1 node) *inner {
+	if getBit(label, depth) {
+		return newInner(suite, top, depth, label, n1, n0)
+	} else {
+		return newInner(suite, top, depth, label, n0, n1)
+	}
+}
+
+func getHash(suite byte, n node) []byte {
 	if n == nil {
-		return emptyHash
+		return emptyHashes[suite]
 	}
-	return n.hash
+	return n.getHash()
 }
 
-func compEmptyHash() []byte {
-	return cryptoutil.Hash([]byte{emptyNodeTag})
+func compEmptyHash(suite byte) []byte {
+	return cryptoutil.Hash(suite, []byte{emptyNodeTag})
 }
 
-func compLeafHash(label, val []byte) []byte {
-	hr := cryptoffi.NewHasher()
+func compLeafHash(suite byte, label, val []byte) []byte {
+	hr := cryptoffi.NewHasher(suite)
 	hr.Write([]byte{leafNodeTag})
 	hr.Write(marshal.WriteInt(nil, uint64(len(label))))
 	hr.Write(label)
@@ -317,21 +525,36 @@ func compLeafHash(label, val []byte) []byte {
 	return hr.Sum(nil)
 }
 
-func compInnerHash(child0, child1 []byte) []byte {
-	hr := cryptoffi.NewHasher()
+func compInnerHash(suite byte, child0, child1 []byte) []byte {
+	hr := cryptoffi.NewHasher(suite)
 	hr.Write([]byte{innerNodeTag})
 	hr.Write(child0)
 	hr.Write(child1)
 	return hr.Sum(nil)
 }
 
+// compSkipHash takes the hash of a node at depth, and returns the hash at top
+// of the skipped levels in between, which go down pathLabel.
+// each skipped level is an inner node with an empty sibling.
+func compSkipHash(suite byte, pathLabel []byte, top, depth uint64, hash []byte) []byte {
+	h := hash
+	for lvl := depth; lvl > top; lvl-- {
+		if getBit(pathLabel, lvl-1) {
+			h = compInnerHash(suite, emptyHashes[suite], h)
+		} else {
+			h = compInnerHash(suite, h, emptyHashes[suite])
+		}
+	}
+	return h
+}
+
 // getChild returns a child and its sibling child,
 // relative to the bit referenced by label and depth.
-func (n *node) getChild(label []byte, depth uint64) (**node, **node) {
-	if getBit(label, depth) {
-		return &n.child1, &n.child0
+func (n *inner) getChild(label []byte) (node, node) {
+	if getBit(label, n.depth) {
+		return n.child1, n.child0
 	} else {
-		return &n.child0, &n.child1
+		return n.child0, n.child1
 	}
 }
 
@@ -347,3 +570,20 @@ func getBit(b []byte, n uint64) bool {
 		return true
 	}
 }
+
+// getDiff returns the first bit where b0 and b1 differ,
+// or maxDepth if they're the same.
+// it expects them to have fixed length.
+func getDiff(b0, b1 []byte) uint64 {
+	for i := uint64(0); i < cryptoffi.HashLen; i++ {
+		x := b0[i] ^ b1[i]
+		if x != 0 {
+			for off := uint64(0); off < 8; off++ {
+				if x&(1<<off) != 0 {
+					return i*8 + off
+				}
+			}
+		}
+	}
+	return maxDepth
+}
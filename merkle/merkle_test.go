@@ -70,9 +70,9 @@ func proveAndVerify(t *testing.T, m *Map, label []byte, expInMap bool, expVal []
 	var hash0 []byte
 	var err bool
 	if inMap {
-		hash0, err = VerifyMemb(label, val, proof)
+		hash0, err = VerifyMemb(m.suite, label, val, proof)
 	} else {
-		hash0, err = VerifyNonMemb(label, proof)
+		hash0, err = VerifyNonMemb(m.suite, label, proof)
 	}
 	if err {
 		t.Fatal()
@@ -82,8 +82,16 @@ func proveAndVerify(t *testing.T, m *Map, label []byte, expInMap bool, expVal []
 	}
 }
 
+var hashSuites = []byte{cryptoffi.HashSuiteSha256, cryptoffi.HashSuiteSha512_256, cryptoffi.HashSuiteBlake3}
+
 func TestUpdate(t *testing.T) {
-	m := &Map{}
+	for _, suite := range hashSuites {
+		testUpdate(t, suite)
+	}
+}
+
+func testUpdate(t *testing.T, suite byte) {
+	m := New(suite)
 	var seed [32]byte
 	rnd := rand.NewChaCha8(seed)
 
@@ -101,7 +109,7 @@ func TestUpdate(t *testing.T) {
 		m.Put(l, v)
 		dNew := m.Hash()
 
-		dOld0, dNew0, err := VerifyUpdate(l, v, p)
+		dOld0, dNew0, err := VerifyUpdate(suite, l, v, p)
 		if err {
 			t.Fatal()
 		}
@@ -113,3 +121,115 @@ func TestUpdate(t *testing.T) {
 		}
 	}
 }
+
+func TestHashSuites(t *testing.T) {
+	l := make([]byte, cryptoffi.HashLen)
+	v := []byte("v")
+	var hashes [][]byte
+	for _, suite := range hashSuites {
+		m := New(suite)
+		m.Put(l, v)
+		proveAndVerify(t, m, l, true, v)
+		hashes = append(hashes, m.Hash())
+	}
+
+	// suites have diff hashes for the same map.
+	for i := 0; i < len(hashes); i++ {
+		for j := i + 1; j < len(hashes); j++ {
+			if bytes.Equal(hashes[i], hashes[j]) {
+				t.Fatal()
+			}
+		}
+	}
+
+	// proofs only give the right hash under the right suite.
+	m := New(cryptoffi.HashSuiteBlake3)
+	m.Put(l, v)
+	_, _, p := m.Prove(l)
+	h, err := VerifyMemb(cryptoffi.HashSuiteSha256, l, v, p)
+	if err {
+		t.Fatal()
+	}
+	if bytes.Equal(h, m.Hash()) {
+		t.Fatal()
+	}
+}
+
+func TestSnapshot(t *testing.T) {
+	m := New(cryptoffi.HashSuiteSha256)
+	var seed [32]byte
+	rnd := rand.NewChaCha8(seed)
+	var labels [][]byte
+	var snaps []*Map
+	var hashes [][]byte
+
+	for i := 0; i < 1_000; i++ {
+		l := make([]byte, cryptoffi.HashLen)
+		v := make([]byte, 4)
+		rnd.Read(l)
+		rnd.Read(v)
+		m.Put(l, v)
+		labels = append(labels, l)
+		snaps = append(snaps, m.Snapshot())
+		hashes = append(hashes, m.Hash())
+	}
+
+	// later puts don't change earlier snapshots.
+	for i, s := range snaps {
+		if !bytes.Equal(s.Hash(), hashes[i]) {
+			t.Fatal()
+		}
+		inMap, val, _ := m.Prove(labels[i])
+		if !inMap {
+			t.Fatal()
+		}
+		proveAndVerify(t, s, labels[i], true, val)
+		if i+1 < len(labels) {
+			proveAndVerify(t, s, labels[i+1], false, nil)
+		}
+	}
+
+	// puts to a snapshot don't change the original.
+	s := snaps[0]
+	s.Put(labels[1], []byte("v"))
+	if !bytes.Equal(snaps[1].Hash(), hashes[1]) {
+		t.Fatal()
+	}
+	proveAndVerify(t, s, labels[1], true, []byte("v"))
+	if !bytes.Equal(m.Hash(), hashes[len(hashes)-1]) {
+		t.Fatal()
+	}
+}
+
+func TestSharedPrefix(t *testing.T) {
+	m := New(cryptoffi.HashSuiteSha256)
+	var seed [32]byte
+	rnd := rand.NewChaCha8(seed)
+	base := make([]byte, cryptoffi.HashLen)
+	rnd.Read(base)
+
+	// labels that share long prefixes with base,
+	// which leaves many skipped levels.
+	var labels [][]byte
+	for i := 0; i < 200; i++ {
+		l := make([]byte, cryptoffi.HashLen)
+		rnd.Read(l)
+		copy(l, base[:uint64(i*7)%cryptoffi.HashLen])
+		labels = append(labels, l)
+		proveAndVerify(t, m, l, false, nil)
+		m.Put(l, []byte{byte(i)})
+		proveAndVerify(t, m, l, true, []byte{byte(i)})
+	}
+	for i, l := range labels {
+		proveAndVerify(t, m, l, true, []byte{byte(i)})
+		// labels that leave the tree at each level along l.
+		for bit := uint64(0); bit < maxDepth; bit += 13 {
+			l0 := bytes.Clone(l)
+			l0[bit/8] ^= 1 << (bit % 8)
+			inMap, _, _ := m.Prove(l0)
+			if !inMap {
+				proveAndVerify(t, m, l0, false, nil)
+			}
+		}
+	}
+}
@@ -0,0 +1,241 @@
+package merkle
+
+import (
+	"github.com/goose-lang/std"
+	"github.com/sanjit-bhat/pav/cryptoffi"
+)
+
+// ProvePrefix returns, in path order, all entries whose labels start with
+// the first prefixLen bits of prefix, along with a proof that there are
+// no others.
+// it lets callers split work on a large map by prefix.
+func (m *Map) ProvePrefix(prefix []byte, prefixLen uint64) (entries []*Entry, proof []byte) {
+	std.Assert(prefixLen <= maxDepth)
+	std.Assert(prefixLen <= uint64(len(prefix))*8)
+	st := m.store
+	// path has the inner nodes above prefixLen.
+	var path []*inner
+	curr := load(st, m.root)
+	for {
+		n, ok := curr.(*inner)
+		if !ok || n.depth >= prefixLen {
+			break
+		}
+		path = append(path, n)
+		child, _ := n.getChild(prefix)
+		curr = load(st, child)
+	}
+	var top uint64
+	if len(path) != 0 {
+		top = path[len(path)-1].depth + 1
+	}
+
+	// depth is the level where the prefix leaves the tree, or prefixLen
+	// if the prefix has a non-empty sub-tree.
+	depth := top
+	var sibs []byte
+	var other *leaf
+	switch n := curr.(type) {
+	case nil:
+		// only the root can be empty.
+	case *leaf:
+		diff := getPrefixDiff(n.label, prefix, prefixLen)
+		if diff < top {
+			// prefix hits an empty node at a skipped level.
+			depth = diff + 1
+		} else if diff == prefixLen {
+			entries = append(entries, &Entry{Label: n.label, Val: n.val})
+		} else {
+			other = n
+		}
+		sibs = appendSibs(m.suite, sibs, path, n, prefix, depth)
+	case *inner:
+		// the skipped levels might leave the prefix.
+		l := &leaf{label: getPathLabel(st, n)}
+		diff := getPrefixDiff(l.label, prefix, prefixLen)
+		if diff < prefixLen {
+			depth = diff + 1
+			// if prefix leaves within n's skipped levels,
+			// the sibling is the rest of n.
+			sibs = appendSibs(m.suite, sibs, append(path, n), l, prefix, depth)
+			break
+		}
+		iterate(st, n, func(label, val []byte) bool {
+			entries = append(entries, &Entry{Label: label, Val: val})
+			return true
+		})
+		// n's skipped levels above prefixLen have empty siblings.
+		depth = prefixLen
+		for lvl := prefixLen; lvl > top; lvl-- {
+			sibs = append(sibs, emptyHashes[m.suite]...)
+		}
+		sibs = appendSibs(m.suite, sibs, path, l, prefix, top)
+	}
+
+	p := &Proof{Siblings: sibs}
+	if other != nil {
+		p.IsOtherLeaf = true
+		p.LeafLabel = other.label
+		p.LeafVal = other.val
+	}
+	proof = ProofEncode(nil, p)
+	return
+}
+
+// VerifyPrefix checks that entries are exactly the entries under prefix
+// in the tree described by proof, and returns the tree's hash.
+// it expects entries in path order.
+// callers that expect some hash should check that they got the right one.
+func VerifyPrefix(suite byte, prefix []byte, prefixLen uint64, entries []*Entry, proof []byte) (hash []byte, err bool) {
+	if prefixLen > maxDepth || prefixLen > uint64(len(prefix))*8 {
+		err = true
+		return
+	}
+	p, _, err := ProofDecode(proof)
+	if err {
+		return
+	}
+	if uint64(len(p.Siblings))%cryptoffi.HashLen != 0 {
+		err = true
+		return
+	}
+	depth := uint64(len(p.Siblings)) / cryptoffi.HashLen
+	if depth > prefixLen {
+		err = true
+		return
+	}
+	items := make([]*item, 0, len(entries))
+	for i, e := range entries {
+		if uint64(len(e.Label)) != cryptoffi.HashLen {
+			err = true
+			return
+		}
+		if !HasPrefix(e.Label, prefix, prefixLen) {
+			err = true
+			return
+		}
+		// path order also rules out duplicate labels.
+		if i > 0 && comparePath(entries[i-1].Label, e.Label) >= 0 {
+			err = true
+			return
+		}
+		items = append(items, &item{label: e.Label, val: e.Val})
+	}
+
+	// n is the node where the prefix leaves the tree.
+	var n node
+	if p.IsOtherLeaf {
+		// the other leaf is on the prefix path, but not under the prefix.
+		if len(items) != 0 || uint64(len(p.LeafLabel)) != cryptoffi.HashLen {
+			err = true
+			return
+		}
+		diff := getPrefixDiff(p.LeafLabel, prefix, prefixLen)
+		if diff < depth || diff == prefixLen {
+			err = true
+			return
+		}
+		n = newLeaf(suite, p.LeafLabel, p.LeafVal)
+	} else if len(items) >= 2 {
+		// multiple entries only fit under the prefix.
+		if depth != prefixLen {
+			err = true
+			return
+		}
+		n = build(suite, prefixLen, items)
+	} else if len(items) == 1 {
+		n = newLeaf(suite, items[0].label, items[0].val)
+	}
+
+	// sibs go from the bottom up.
+	hash = getHash(suite, n)
+	for lvl := depth; lvl > 0; lvl-- {
+		off := (depth - lvl) * cryptoffi.HashLen
+		sib := p.Siblings[off : off+cryptoffi.HashLen]
+		if getBit(prefix, lvl-1) {
+			hash = compInnerHash(suite, sib, hash)
+		} else {
+			hash = compInnerHash(suite, hash, sib)
+		}
+	}
+	return
+}
+
+// Len returns the number of entries.
+// nodes don't store sub-tree sizes, so it walks every entry,
+// loading each node from the store, in O(n) time.
+// callers that need the size often should track it themselves.
+func (m *Map) Len() (n uint64) {
+	iterate(m.store, m.root, func(label, val []byte) bool {
+		n++
+		return true
+	})
+	return
+}
+
+// getPrefix returns the highest node whose sub-tree has exactly
+// the labels starting with prefix, or nil if there are none.
+func getPrefix(st Store, n node, prefix []byte, prefixLen uint64) node {
+	curr := load(st, n)
+	for {
+		switch n0 := curr.(type) {
+		case nil:
+			return nil
+		case *leaf:
+			if !HasPrefix(n0.label, prefix, prefixLen) {
+				return nil
+			}
+			return n0
+		case *inner:
+			if n0.depth >= prefixLen {
+				// skipped levels might leave the prefix.
+				if !HasPrefix(getPathLabel(st, n0), prefix, prefixLen) {
+					return nil
+				}
+				return n0
+			}
+			if getBit(prefix, n0.depth) {
+				curr = load(st, n0.child1)
+			} else {
+				curr = load(st, n0.child0)
+			}
+		case *cut:
+			// maps don't have cut nodes, except for stubs.
+			panic("merkle: iterate into cut node")
+		}
+	}
+}
+
+// iterate yields the n sub-tree's leaves in path order.
+// it returns false if yield stopped early.
+func iterate(st Store, n node, yield func(label, val []byte) bool) bool {
+	switch n0 := load(st, n).(type) {
+	case nil:
+		return true
+	case *leaf:
+		return yield(n0.label, n0.val)
+	case *inner:
+		if !iterate(st, n0.child0, yield) {
+			return false
+		}
+		return iterate(st, n0.child1, yield)
+	}
+	panic("merkle: iterate into cut node")
+}
+
+// HasPrefix returns true if label starts with the first prefixLen bits
+// of prefix.
+func HasPrefix(label, prefix []byte, prefixLen uint64) bool {
+	return getPrefixDiff(label, prefix, prefixLen) == prefixLen
+}
+
+// getPrefixDiff returns the first of the prefixLen prefix bits where
+// label differs, or prefixLen if there's no such bit.
+func getPrefixDiff(label, prefix []byte, prefixLen uint64) uint64 {
+	for i := uint64(0); i < prefixLen; i++ {
+		if getBit(label, i) != getBit(prefix, i) {
+			return i
+		}
+	}
+	return prefixLen
+}
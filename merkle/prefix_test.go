@@ -0,0 +1,96 @@
+package merkle
+
+import (
+	"bytes"
+	"math/rand/v2"
+	"testing"
+
+	"github.com/sanjit-bhat/pav/cryptoffi"
+)
+
+func TestPrefix(t *testing.T) {
+	var seed [32]byte
+	rnd := rand.NewChaCha8(seed)
+	base := make([]byte, cryptoffi.HashLen)
+	rnd.Read(base)
+	mkLabel := func(prefixLen int) []byte {
+		l := make([]byte, cryptoffi.HashLen)
+		rnd.Read(l)
+		copy(l, base[:prefixLen])
+		return l
+	}
+
+	for _, sz := range []int{0, 1, 2, 3, 50, 500} {
+		m := NewWithStore(cryptoffi.HashSuiteSha256, &memStore{})
+		var labels [][]byte
+		for i := 0; i < sz; i++ {
+			// some labels share long prefixes.
+			l := mkLabel(i % 8)
+			m.Put(l, []byte{byte(i)})
+			labels = append(labels, l)
+		}
+		prefixes := [][]byte{base, mkLabel(0), mkLabel(3)}
+		if sz != 0 {
+			prefixes = append(prefixes, labels[0], labels[sz-1])
+		}
+		for _, prefix := range prefixes {
+			for prefixLen := uint64(0); prefixLen <= maxDepth; prefixLen += 1 + prefixLen/4 {
+				checkPrefix(t, m, prefix, prefixLen)
+			}
+			checkPrefix(t, m, prefix, maxDepth)
+		}
+	}
+}
+
+// checkPrefix checks that a prefix proof verifies, and that
+// changing its entries makes it fail.
+func checkPrefix(t *testing.T, m *Map, prefix []byte, prefixLen uint64) {
+	entries, proof := m.ProvePrefix(prefix, prefixLen)
+	var n int
+	for label, val := range m.Prefix(prefix, prefixLen) {
+		if n >= len(entries) {
+			t.Fatal()
+		}
+		if !bytes.Equal(label, entries[n].Label) || !bytes.Equal(val, entries[n].Val) {
+			t.Fatal()
+		}
+		n++
+	}
+	if n != len(entries) {
+		t.Fatal()
+	}
+	hash, err := VerifyPrefix(m.suite, prefix, prefixLen, entries, proof)
+	if err {
+		t.Fatal(prefixLen)
+	}
+	if !bytes.Equal(hash, m.Hash()) {
+		t.Fatal(prefixLen, len(entries))
+	}
+
+	// missing entry.
+	if len(entries) != 0 {
+		hash, err := VerifyPrefix(m.suite, prefix, prefixLen, entries[1:], proof)
+		if !err && bytes.Equal(hash, m.Hash()) {
+			t.Fatal()
+		}
+		e := &Entry{Label: entries[0].Label, Val: []byte{5, 5}}
+		bad := append([]*Entry{e}, entries[1:]...)
+		hash, err = VerifyPrefix(m.suite, prefix, prefixLen, bad, proof)
+		if !err && bytes.Equal(hash, m.Hash()) {
+			t.Fatal()
+		}
+	}
+	// extra entry.
+	label := bytes.Clone(prefix)
+	label[cryptoffi.HashLen-1] ^= 1
+	if inMap, _, _ := m.Prove(label); !inMap {
+		extra := append([]*Entry{{Label: label, Val: []byte{1}}}, entries...)
+		for i := 1; i < len(extra) && comparePath(extra[i-1].Label, extra[i].Label) > 0; i++ {
+			extra[i-1], extra[i] = extra[i], extra[i-1]
+		}
+		hash, err := VerifyPrefix(m.suite, prefix, prefixLen, extra, proof)
+		if !err && bytes.Equal(hash, m.Hash()) {
+			t.Fatal()
+		}
+	}
+}
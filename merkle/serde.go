@@ -10,3 +10,9 @@ type Proof struct {
 	LeafLabel   []byte
 	LeafVal     []byte
 }
+
+// Entry is a (Label, Val) for [Map.PutBatch] and [Map.ProvePrefix].
+type Entry struct {
+	Label []byte
+	Val   []byte
+}
@@ -34,3 +34,20 @@ func ProofDecode(b0 []byte) (*Proof, []byte, bool) {
 	}
 	return &Proof{Siblings: a1, IsOtherLeaf: a2, LeafLabel: a3, LeafVal: a4}, b4, false
 }
+func EntryEncode(b0 []byte, o *Entry) []byte {
+	var b = b0
+	b = safemarshal.WriteSlice1D(b, o.Label)
+	b = safemarshal.WriteSlice1D(b, o.Val)
+	return b
+}
+func EntryDecode(b0 []byte) (*Entry, []byte, bool) {
+	a1, b1, err1 := safemarshal.ReadSlice1D(b0)
+	if err1 {
+		return nil, nil, true
+	}
+	a2, b2, err2 := safemarshal.ReadSlice1D(b1)
+	if err2 {
+		return nil, nil, true
+	}
+	return &Entry{Label: a1, Val: a2}, b2, false
+}
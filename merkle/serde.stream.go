@@ -0,0 +1,38 @@
+//go:build !goose
+
+// Auto-generated from spec "github.com/sanjit-bhat/pav/merkle/serde.go"
+// using compiler "github.com/sanjit-bhat/pav/serde".
+package merkle
+
+import "github.com/sanjit-bhat/pav/safemarshal/stream"
+
+func ProofDecodeStream(r *stream.Reader) (*Proof, bool) {
+	a1, err1 := r.Slice1D()
+	if err1 {
+		return nil, true
+	}
+	a2, err2 := r.Bool()
+	if err2 {
+		return nil, true
+	}
+	a3, err3 := r.Slice1D()
+	if err3 {
+		return nil, true
+	}
+	a4, err4 := r.Slice1D()
+	if err4 {
+		return nil, true
+	}
+	return &Proof{Siblings: a1, IsOtherLeaf: a2, LeafLabel: a3, LeafVal: a4}, false
+}
+func EntryDecodeStream(r *stream.Reader) (*Entry, bool) {
+	a1, err1 := r.Slice1D()
+	if err1 {
+		return nil, true
+	}
+	a2, err2 := r.Slice1D()
+	if err2 {
+		return nil, true
+	}
+	return &Entry{Label: a1, Val: a2}, false
+}
@@ -0,0 +1,163 @@
+package merkle
+
+import (
+	"github.com/goose-lang/std"
+	"github.com/sanjit-bhat/pav/cryptoffi"
+	"github.com/sanjit-bhat/pav/safemarshal"
+	"github.com/tchajed/marshal"
+)
+
+// Store holds encoded nodes outside the Go heap, e.g., on disk.
+// nodes are immutable, so a Store only needs to append.
+// it's trusted, and should panic if it can't do an op.
+type Store interface {
+	// Append adds b and returns a non-zero ptr to it.
+	Append(b []byte) (ptr uint64)
+	// Get returns the b at ptr.
+	Get(ptr uint64) (b []byte)
+}
+
+// NewWithStore returns an empty map that hashes with suite
+// and keeps its nodes in st.
+// it has the same hashes and proofs as a map from [New].
+func NewWithStore(suite byte, st Store) *Map {
+	std.Assert(!cryptoffi.CheckHashSuite(suite))
+	return &Map{suite: suite, store: st}
+}
+
+// Open returns the map in st whose root is at ptr, e.g., from [Map.Ptr].
+func Open(suite byte, st Store, ptr uint64) *Map {
+	m := NewWithStore(suite, st)
+	if ptr == 0 {
+		return m
+	}
+	n := decodeNode(st.Get(ptr))
+	m.root = newStub(n.getHash(), ptr)
+	return m
+}
+
+// Ptr locates the map root in its [Store].
+// it's 0 for an empty map.
+func (m *Map) Ptr() uint64 {
+	std.Assert(m.store != nil)
+	if m.root == nil {
+		return 0
+	}
+	// after [flush], the root is always a stub.
+	return m.root.(*cut).ptr
+}
+
+func newStub(hash []byte, ptr uint64) *cut {
+	n := &cut{ptr: ptr}
+	copy(n.hash[:], hash)
+	return n
+}
+
+// load returns the full node for a stub, and otherwise returns n.
+// the stub's children are also stubs.
+func load(st Store, n node) node {
+	c, ok := n.(*cut)
+	if !ok || c.ptr == 0 {
+		return n
+	}
+	return decodeNode(st.Get(c.ptr))
+}
+
+// flush appends the unstored nodes in n to st, and returns a stub for n.
+// if st is nil, it returns n.
+func flush(st Store, n node) node {
+	if st == nil || n == nil {
+		return n
+	}
+	switch n0 := n.(type) {
+	case *cut:
+		std.Assert(n0.ptr != 0)
+		return n0
+	case *leaf:
+		ptr := st.Append(encodeLeaf(n0))
+		return newStub(n0.getHash(), ptr)
+	case *inner:
+		c0 := flush(st, n0.child0)
+		c1 := flush(st, n0.child1)
+		ptr := st.Append(encodeInner(n0, c0, c1))
+		return newStub(n0.getHash(), ptr)
+	}
+	panic("merkle: flush unknown node")
+}
+
+// encoding:
+//   - leaf: leafNodeTy ++ Hash ++ LabelLen ++ Label ++ ValLen ++ Val.
+//   - inner: innerNodeTy ++ Hash ++ Depth ++ Child0 ++ Child1,
+//     where Child is Ptr, followed by Hash if Ptr is non-zero.
+//
+// inner nodes have child hashes so that we can prove without
+// loading siblings.
+
+func encodeLeaf(n *leaf) []byte {
+	b := make([]byte, 0, 1+cryptoffi.HashLen+8+uint64(len(n.label))+8+uint64(len(n.val)))
+	b = append(b, leafNodeTy)
+	b = marshal.WriteBytes(b, n.hash[:])
+	b = marshal.WriteInt(b, uint64(len(n.label)))
+	b = marshal.WriteBytes(b, n.label)
+	b = marshal.WriteInt(b, uint64(len(n.val)))
+	b = marshal.WriteBytes(b, n.val)
+	return b
+}
+
+func encodeInner(n *inner, c0, c1 node) []byte {
+	b := make([]byte, 0, 1+cryptoffi.HashLen+8+2*(8+cryptoffi.HashLen))
+	b = append(b, innerNodeTy)
+	b = marshal.WriteBytes(b, n.hash[:])
+	b = marshal.WriteInt(b, n.depth)
+	b = encodeChild(b, c0)
+	b = encodeChild(b, c1)
+	return b
+}
+
+func encodeChild(b []byte, c node) []byte {
+	if c == nil {
+		return marshal.WriteInt(b, 0)
+	}
+	stub := c.(*cut)
+	std.Assert(stub.ptr != 0)
+	b0 := marshal.WriteInt(b, stub.ptr)
+	return marshal.WriteBytes(b0, stub.hash[:])
+}
+
+// decodeNode trusts that b came from encodeLeaf or encodeInner.
+func decodeNode(b []byte) node {
+	ty, b0, err := safemarshal.ReadByte(b)
+	std.Assert(!err)
+	hash, b1, err := safemarshal.ReadBytes(b0, cryptoffi.HashLen)
+	std.Assert(!err)
+	if ty == leafNodeTy {
+		label, b2, err := safemarshal.ReadSlice1D(b1)
+		std.Assert(!err)
+		val, b3, err := safemarshal.ReadSlice1D(b2)
+		std.Assert(!err)
+		std.Assert(len(b3) == 0)
+		n := &leaf{label: label, val: val}
+		copy(n.hash[:], hash)
+		return n
+	}
+	std.Assert(ty == innerNodeTy)
+	depth, b2, err := safemarshal.ReadInt(b1)
+	std.Assert(!err)
+	c0, b3 := decodeChild(b2)
+	c1, b4 := decodeChild(b3)
+	std.Assert(len(b4) == 0)
+	n := &inner{depth: depth, child0: c0, child1: c1}
+	copy(n.hash[:], hash)
+	return n
+}
+
+func decodeChild(b []byte) (c node, rem []byte) {
+	ptr, b0, err := safemarshal.ReadInt(b)
+	std.Assert(!err)
+	if ptr == 0 {
+		return nil, b0
+	}
+	hash, b1, err := safemarshal.ReadBytes(b0, cryptoffi.HashLen)
+	std.Assert(!err)
+	return newStub(hash, ptr), b1
+}
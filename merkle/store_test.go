@@ -0,0 +1,102 @@
+package merkle
+
+import (
+	"bytes"
+	"math/rand/v2"
+	"path/filepath"
+	"testing"
+
+	"github.com/sanjit-bhat/pav/cryptoffi"
+	"github.com/sanjit-bhat/pav/merkle/diskstore"
+)
+
+// memStore is a simple [Store] for testing.
+type memStore struct {
+	recs [][]byte
+}
+
+func (s *memStore) Append(b []byte) uint64 {
+	s.recs = append(s.recs, bytes.Clone(b))
+	return uint64(len(s.recs))
+}
+
+func (s *memStore) Get(ptr uint64) []byte {
+	return s.recs[ptr-1]
+}
+
+func TestStore(t *testing.T) {
+	st := &memStore{}
+	testStore(t, st, func() Store { return st })
+}
+
+func TestDiskStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nodes")
+	st, err := diskstore.Open(path, 1_000)
+	if err {
+		t.Fatal()
+	}
+	testStore(t, st, func() Store {
+		if st.Close() {
+			t.Fatal()
+		}
+		st, err = diskstore.Open(path, 1_000)
+		if err {
+			t.Fatal()
+		}
+		return st
+	})
+	if st.Close() {
+		t.Fatal()
+	}
+}
+
+// testStore checks that a map in st matches an in-memory map,
+// and that reopen gives back st's contents.
+func testStore(t *testing.T, st Store, reopen func() Store) {
+	suite := cryptoffi.HashSuiteSha256
+	m0 := New(suite)
+	m1 := NewWithStore(suite, st)
+	if m1.Ptr() != 0 {
+		t.Fatal()
+	}
+	var seed [32]byte
+	rnd := rand.NewChaCha8(seed)
+	var labels [][]byte
+	var snaps []*Map
+	var ptrs []uint64
+
+	for i := 0; i < 1_000; i++ {
+		l := make([]byte, cryptoffi.HashLen)
+		v := make([]byte, 4)
+		rnd.Read(l)
+		rnd.Read(v)
+		p0 := m0.Put(l, v)
+		p1 := m1.Put(l, v)
+		if !bytes.Equal(p0, p1) {
+			t.Fatal()
+		}
+		if !bytes.Equal(m0.Hash(), m1.Hash()) {
+			t.Fatal()
+		}
+		labels = append(labels, l)
+		snaps = append(snaps, m0.Snapshot())
+		ptrs = append(ptrs, m1.Ptr())
+	}
+
+	st = reopen()
+	for i, ptr := range ptrs {
+		m := Open(suite, st, ptr)
+		if !bytes.Equal(m.Hash(), snaps[i].Hash()) {
+			t.Fatal()
+		}
+		inMap0, v0, p0 := snaps[i].Prove(labels[i])
+		inMap1, v1, p1 := m.Prove(labels[i])
+		if !inMap0 || !inMap1 || !bytes.Equal(v0, v1) || !bytes.Equal(p0, p1) {
+			t.Fatal()
+		}
+		proveAndVerify(t, m, labels[i], true, v0)
+		if i+1 < len(labels) {
+			proveAndVerify(t, m, labels[i+1], false, nil)
+		}
+	}
+}
@@ -10,6 +10,7 @@ package netffi
 import (
 	"fmt"
 	"io"
+	"math"
 	"net"
 	"sync"
 
@@ -97,6 +98,39 @@ func (c *Conn) Receive() (data []byte, err bool) {
 	return data, false
 }
 
+// ReceiveStream passes the next msg to f as a reader, instead of
+// buffering it, for msgs that might be big.
+// f can read up to length bytes, and the rest get skipped.
+// it errors if f errors.
+func (c *Conn) ReceiveStream(f func(r io.Reader, length uint64) (err bool)) (err bool) {
+	c.recvMu.Lock()
+	defer c.recvMu.Unlock()
+
+	// encoding: len(data) ++ data.
+	header := make([]byte, 8)
+	if _, errg := io.ReadFull(c.c, header); errg != nil {
+		c.c.Close()
+		err = true
+		return
+	}
+	d := marshal.NewDec(header)
+	dataLen := d.GetInt()
+	if dataLen > math.MaxInt64 {
+		c.c.Close()
+		err = true
+		return
+	}
+
+	r := io.LimitReader(c.c, int64(dataLen))
+	err = f(r, dataLen)
+	// skip the rest to stay in sync with the sender.
+	if _, errg := io.Copy(io.Discard, r); errg != nil {
+		c.c.Close()
+		err = true
+	}
+	return
+}
+
 // # Listener
 
 type Listener struct {
@@ -2,6 +2,7 @@ package netffi
 
 import (
 	"bytes"
+	"io"
 	"math/rand/v2"
 	"testing"
 )
@@ -32,3 +33,35 @@ func makeUniqueAddr() uint64 {
 	// left shift to make IP 0.0.0.0.
 	return port << 32
 }
+
+func TestReceiveStream(t *testing.T) {
+	addr := makeUniqueAddr()
+	l := Listen(addr)
+
+	c0 := Dial(addr)
+	d0 := []byte{1, 2, 3}
+	if c0.Send(d0) {
+		t.Fatal()
+	}
+	if c0.Send(d0) {
+		t.Fatal()
+	}
+
+	// a partial read skips the rest of the msg.
+	c1 := l.Accept()
+	err := c1.ReceiveStream(func(r io.Reader, length uint64) bool {
+		b := make([]byte, 1)
+		_, err := io.ReadFull(r, b)
+		return err != nil || length != 3 || b[0] != 1
+	})
+	if err {
+		t.Fatal()
+	}
+	d1, err := c1.Receive()
+	if err {
+		t.Fatal()
+	}
+	if !bytes.Equal(d0, d1) {
+		t.Fatal()
+	}
+}
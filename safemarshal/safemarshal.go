@@ -1,6 +1,8 @@
 package safemarshal
 
 import (
+	"bytes"
+
 	"github.com/tchajed/marshal"
 )
 
@@ -83,6 +85,94 @@ func WriteByte(b []byte, data byte) []byte {
 	return marshal.WriteBytes(b, []byte{data})
 }
 
+func ReadInt32(b []byte) (data uint32, rem []byte, err bool) {
+	rem = b
+	if len(rem) < 4 {
+		err = true
+		return
+	}
+	data, rem = marshal.ReadInt32(rem)
+	return
+}
+
+func ReadConstInt32(b []byte, cst uint32) (rem []byte, err bool) {
+	rem = b
+	data, rem, err := ReadInt32(rem)
+	if err {
+		return
+	}
+	if data != cst {
+		err = true
+		return
+	}
+	return
+}
+
+// ReadInt16 reads a little-endian uint16, like [marshal.ReadInt32].
+func ReadInt16(b []byte) (data uint16, rem []byte, err bool) {
+	rem = b
+	if len(rem) < 2 {
+		err = true
+		return
+	}
+	data = uint16(rem[0]) | uint16(rem[1])<<8
+	rem = rem[2:]
+	return
+}
+
+func ReadConstInt16(b []byte, cst uint16) (rem []byte, err bool) {
+	rem = b
+	data, rem, err := ReadInt16(rem)
+	if err {
+		return
+	}
+	if data != cst {
+		err = true
+		return
+	}
+	return
+}
+
+func WriteInt16(b []byte, data uint16) []byte {
+	return append(b, byte(data), byte(data>>8))
+}
+
+// ReadInt64 reads an int64 in two's complement, like [ReadInt].
+func ReadInt64(b []byte) (data int64, rem []byte, err bool) {
+	data0, rem, err := ReadInt(b)
+	data = int64(data0)
+	return
+}
+
+func ReadConstInt64(b []byte, cst int64) (rem []byte, err bool) {
+	rem = b
+	data, rem, err := ReadInt64(rem)
+	if err {
+		return
+	}
+	if data != cst {
+		err = true
+		return
+	}
+	return
+}
+
+func WriteInt64(b []byte, data int64) []byte {
+	return marshal.WriteInt(b, uint64(data))
+}
+
+// ReadString reads a len-prefixed string, like [ReadSlice1D].
+func ReadString(b []byte) (data string, rem []byte, err bool) {
+	data0, rem, err := ReadSlice1D(b)
+	data = string(data0)
+	return
+}
+
+func WriteString(b []byte, data string) []byte {
+	b = marshal.WriteInt(b, uint64(len(data)))
+	return append(b, data...)
+}
+
 func ReadBytes(b []byte, length uint64) (data []byte, rem []byte, err bool) {
 	rem = b
 	if uint64(len(rem)) < length {
@@ -113,6 +203,11 @@ func ReadSlice2D(b []byte) (data [][]byte, rem []byte, err bool) {
 	if err {
 		return
 	}
+	// each elem has at least its len, so a bigger length can't fit.
+	if length > uint64(len(rem))/8 {
+		err = true
+		return
+	}
 	for i := uint64(0); i < length; i++ {
 		var data0 []byte
 		data0, rem, err = ReadSlice1D(rem)
@@ -138,6 +233,10 @@ func ReadSlice3D(b []byte) (data [][][]byte, rem []byte, err bool) {
 	if err {
 		return
 	}
+	if length > uint64(len(rem))/8 {
+		err = true
+		return
+	}
 	for i := uint64(0); i < length; i++ {
 		var data0 [][]byte
 		data0, rem, err = ReadSlice2D(rem)
@@ -156,3 +255,11 @@ func WriteSlice3D(b []byte, data [][][]byte) []byte {
 	}
 	return b
 }
+
+// CheckCanonical is for strict decoders, which only accept
+// the canonical encoding of an obj.
+// b is the input, and enc re-encodes the obj decoded from b.
+// it errs on trailing bytes and other non-canonical input.
+func CheckCanonical(b, enc []byte) (err bool) {
+	return !bytes.Equal(b, enc)
+}
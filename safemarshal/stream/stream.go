@@ -0,0 +1,225 @@
+// Package stream decodes [safemarshal] encodings from an [io.Reader],
+// without buffering the whole msg.
+//
+// a [Reader] has a budget on the bytes it reads.
+// lens are checked against the budget before reading,
+// so a bad len fails early, instead of allocating or looping on it.
+package stream
+
+import (
+	"io"
+	"math"
+
+	"github.com/tchajed/marshal"
+)
+
+// chunkSz is the most that Bytes allocates before the bytes arrive.
+const chunkSz = 1 << 16
+
+type Reader struct {
+	r      io.Reader
+	budget uint64
+}
+
+// NewReader reads at most budget bytes from r.
+func NewReader(r io.Reader, budget uint64) *Reader {
+	return &Reader{r: r, budget: budget}
+}
+
+// Budget returns how many more bytes the reader can read.
+func (r *Reader) Budget() uint64 {
+	return r.budget
+}
+
+// Bytes errors if length is over budget or r runs out of input.
+func (r *Reader) Bytes(length uint64) (data []byte, err bool) {
+	if length > r.budget {
+		err = true
+		return
+	}
+	r.budget -= length
+	// grow with the input, in case the sender lied about the len.
+	data = make([]byte, 0, min(length, chunkSz))
+	for uint64(len(data)) < length {
+		n := min(length-uint64(len(data)), chunkSz)
+		off := len(data)
+		data = append(data, make([]byte, n)...)
+		if _, err0 := io.ReadFull(r.r, data[off:]); err0 != nil {
+			data = nil
+			err = true
+			return
+		}
+	}
+	return
+}
+
+// Limit returns a reader for the next length bytes, e.g., a len-prefixed body.
+// length comes out of r's budget, and r can't be used until the
+// returned reader is done.
+func (r *Reader) Limit(length uint64) (sub *Reader, err bool) {
+	if length > r.budget || length > math.MaxInt64 {
+		err = true
+		return
+	}
+	r.budget -= length
+	sub = &Reader{r: io.LimitReader(r.r, int64(length)), budget: length}
+	return
+}
+
+// Discard skips the rest of the budget.
+func (r *Reader) Discard() (err bool) {
+	n, err0 := io.CopyN(io.Discard, r.r, int64(r.budget))
+	r.budget -= uint64(n)
+	return err0 != nil
+}
+
+// Count reads a count of elems, each at least minSz bytes.
+// it errors if the elems can't fit in the budget.
+func (r *Reader) Count(minSz uint64) (length uint64, err bool) {
+	length, err = r.Int()
+	if err {
+		return
+	}
+	if minSz != 0 && length > r.budget/minSz {
+		err = true
+		return
+	}
+	return
+}
+
+func (r *Reader) Bool() (data bool, err bool) {
+	x, err := r.Byte()
+	if err {
+		return
+	}
+	if x > 1 {
+		err = true
+		return
+	}
+	data = x == 1
+	return
+}
+
+func (r *Reader) ConstBool(cst bool) (err bool) {
+	data, err := r.Bool()
+	return err || data != cst
+}
+
+func (r *Reader) Byte() (data byte, err bool) {
+	b, err := r.Bytes(1)
+	if err {
+		return
+	}
+	data = b[0]
+	return
+}
+
+func (r *Reader) ConstByte(cst byte) (err bool) {
+	data, err := r.Byte()
+	return err || data != cst
+}
+
+// Int16 reads a little-endian uint16, like [safemarshal.ReadInt16].
+func (r *Reader) Int16() (data uint16, err bool) {
+	b, err := r.Bytes(2)
+	if err {
+		return
+	}
+	data = uint16(b[0]) | uint16(b[1])<<8
+	return
+}
+
+func (r *Reader) ConstInt16(cst uint16) (err bool) {
+	data, err := r.Int16()
+	return err || data != cst
+}
+
+func (r *Reader) Int32() (data uint32, err bool) {
+	b, err := r.Bytes(4)
+	if err {
+		return
+	}
+	data, _ = marshal.ReadInt32(b)
+	return
+}
+
+func (r *Reader) ConstInt32(cst uint32) (err bool) {
+	data, err := r.Int32()
+	return err || data != cst
+}
+
+func (r *Reader) Int() (data uint64, err bool) {
+	b, err := r.Bytes(8)
+	if err {
+		return
+	}
+	data, _ = marshal.ReadInt(b)
+	return
+}
+
+func (r *Reader) ConstInt(cst uint64) (err bool) {
+	data, err := r.Int()
+	return err || data != cst
+}
+
+// Int64 reads an int64 in two's complement, like [Reader.Int].
+func (r *Reader) Int64() (data int64, err bool) {
+	data0, err := r.Int()
+	data = int64(data0)
+	return
+}
+
+func (r *Reader) ConstInt64(cst int64) (err bool) {
+	data, err := r.Int64()
+	return err || data != cst
+}
+
+func (r *Reader) Slice1D() (data []byte, err bool) {
+	length, err := r.Int()
+	if err {
+		return
+	}
+	return r.Bytes(length)
+}
+
+// String reads a len-prefixed string, like [Reader.Slice1D].
+func (r *Reader) String() (data string, err bool) {
+	data0, err := r.Slice1D()
+	data = string(data0)
+	return
+}
+
+func (r *Reader) Slice2D() (data [][]byte, err bool) {
+	// each elem has at least its len.
+	length, err := r.Count(8)
+	if err {
+		return
+	}
+	for i := uint64(0); i < length; i++ {
+		var data0 []byte
+		data0, err = r.Slice1D()
+		if err {
+			data = nil
+			return
+		}
+		data = append(data, data0)
+	}
+	return
+}
+
+func (r *Reader) Slice3D() (data [][][]byte, err bool) {
+	length, err := r.Count(8)
+	if err {
+		return
+	}
+	for i := uint64(0); i < length; i++ {
+		var data0 [][]byte
+		data0, err = r.Slice2D()
+		if err {
+			data = nil
+			return
+		}
+		data = append(data, data0)
+	}
+	return
+}
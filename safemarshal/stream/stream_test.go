@@ -0,0 +1,104 @@
+package stream
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/sanjit-bhat/pav/safemarshal"
+	"github.com/tchajed/marshal"
+)
+
+func TestReader(t *testing.T) {
+	var b []byte
+	b = marshal.WriteBool(b, true)
+	b = safemarshal.WriteInt16(b, 3)
+	b = marshal.WriteInt32(b, 4)
+	b = safemarshal.WriteInt64(b, -5)
+	b = safemarshal.WriteString(b, "six")
+	b = safemarshal.WriteSlice3D(b, [][][]byte{{{7}, {8, 9}}, {}})
+	r := NewReader(bytes.NewReader(b), uint64(len(b)))
+
+	if x, err := r.Bool(); err || !x {
+		t.Fatal()
+	}
+	if x, err := r.Int16(); err || x != 3 {
+		t.Fatal()
+	}
+	if r.ConstInt32(4) {
+		t.Fatal()
+	}
+	if x, err := r.Int64(); err || x != -5 {
+		t.Fatal()
+	}
+	if x, err := r.String(); err || x != "six" {
+		t.Fatal()
+	}
+	x, err := r.Slice3D()
+	if err || len(x) != 2 || !bytes.Equal(x[0][1], []byte{8, 9}) {
+		t.Fatal()
+	}
+	if r.Budget() != 0 {
+		t.Fatal()
+	}
+	if _, err := r.Byte(); !err {
+		t.Fatal()
+	}
+}
+
+func TestBudget(t *testing.T) {
+	b := safemarshal.WriteSlice1D(nil, make([]byte, 100))
+	// the input has the bytes, but the budget doesn't.
+	r := NewReader(bytes.NewReader(b), 50)
+	if _, err := r.Slice1D(); !err {
+		t.Fatal()
+	}
+
+	// a huge len fails before reading or allocating.
+	b = marshal.WriteInt(nil, 1<<62)
+	r = NewReader(bytes.NewReader(b), 1<<63)
+	if _, err := r.Slice1D(); !err {
+		t.Fatal()
+	}
+	r = NewReader(bytes.NewReader(b), 1<<20)
+	if _, err := r.Slice2D(); !err {
+		t.Fatal()
+	}
+	if r.Budget() != 1<<20-8 {
+		t.Fatal()
+	}
+}
+
+func TestLimit(t *testing.T) {
+	b := safemarshal.WriteSlice1D(nil, []byte{1, 2, 3})
+	b = marshal.WriteInt(b, 4)
+	r := NewReader(bytes.NewReader(b), uint64(len(b)))
+	length, err := r.Int()
+	if err {
+		t.Fatal()
+	}
+	sub, err := r.Limit(length)
+	if err {
+		t.Fatal()
+	}
+	if x, err := sub.Byte(); err || x != 1 {
+		t.Fatal()
+	}
+	// the sub-reader can't read past its limit.
+	if _, err := sub.Int16(); err {
+		t.Fatal()
+	}
+	if _, err := sub.Byte(); !err {
+		t.Fatal()
+	}
+	if sub.Discard() {
+		t.Fatal()
+	}
+	if x, err := r.Int(); err || x != 4 {
+		t.Fatal()
+	}
+
+	r = NewReader(bytes.NewReader(b), 4)
+	if _, err := r.Limit(5); !err {
+		t.Fatal()
+	}
+}
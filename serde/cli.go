@@ -1,27 +1,174 @@
 package main
 
+//go:generate go run . -all
+
 import (
+	"bytes"
 	"flag"
+	"io/fs"
 	"log"
 	"os"
+	"path/filepath"
+	"slices"
 	"strings"
 )
 
-var in = flag.String("in", "", "required path to input file")
+var in = flag.String("in", "", "comma-separated paths to spec files from one pkg")
 var out = flag.String("out", "", "optional path to output file")
+var schemaOut = flag.String("schema", "", "optional path to JSON schema file")
+var vectorsOut = flag.String("vectors", "", "optional path to JSON test vectors file")
+var testOut = flag.String("test", "", "optional path to Go fuzz and round-trip test file")
+var streamOut = flag.String("stream", "", "optional path to Go stream decoders file")
+var all = flag.Bool("all", false, "regenerate the outputs of every serde.go in the module")
+var checkStale = flag.Bool("check", false, "don't write outputs, and fail if any are stale")
 
 func main() {
 	log.SetFlags(log.Lshortfile)
 	flag.Parse()
-	if *in == "" {
-		log.Panic("empty input file. maybe there was no input arg?")
+	var jobs []*job
+	if *all {
+		jobs = findJobs(findModRoot())
+	} else {
+		if *in == "" {
+			log.Panic("empty input file. maybe there was no input arg?")
+		}
+		specs := strings.Split(*in, ",")
+		if *out == "" {
+			*out = strings.Replace(specs[0], ".go", ".out.go", 1)
+		}
+		jobs = []*job{{specs: specs, out: *out, schema: *schemaOut, vectors: *vectorsOut, test: *testOut, stream: *streamOut}}
+	}
+
+	var stale []string
+	for _, j := range jobs {
+		stale = append(stale, j.run(*checkStale)...)
+	}
+	if len(stale) != 0 {
+		log.Fatal("stale outputs: ", strings.Join(stale, ", "))
+	}
+}
+
+// job compiles the specs of one pkg.
+// empty output paths are skipped.
+type job struct {
+	specs   []string
+	out     string
+	schema  string
+	vectors string
+	test    string
+	stream  string
+}
+
+// vectorsName is the vectors file, relative to the test file's dir.
+func (j *job) vectorsName() string {
+	if j.vectors == "" || j.test == "" {
+		return ""
+	}
+	rel, err := filepath.Rel(filepath.Dir(j.test), j.vectors)
+	if err != nil {
+		log.Panic(err)
+	}
+	return rel
+}
+
+// run writes the outputs, or if check, returns the stale ones.
+func (j *job) run(check bool) (stale []string) {
+	c := newCompiler(j.specs...)
+	// genGo must go first, since genTest resets the imports.
+	outs := []struct {
+		path string
+		gen  func() []byte
+	}{
+		{j.out, c.genGo},
+		{j.schema, c.genSchema},
+		{j.vectors, c.genVectors},
+		{j.test, func() []byte { return c.genTest(j.vectorsName()) }},
+		{j.stream, c.genStream},
 	}
-	res := compile(*in)
-	if *out == "" {
-		*out = strings.Replace(*in, ".go", ".out.go", 1)
+	for _, o := range outs {
+		if o.path == "" {
+			continue
+		}
+		data := o.gen()
+		if !check {
+			write(o.path, data)
+			continue
+		}
+		old, err := os.ReadFile(o.path)
+		if err != nil || !bytes.Equal(old, data) {
+			stale = append(stale, o.path)
+		}
 	}
-	if err := os.WriteFile(*out, res, 0644); err != nil {
-		log.Panic("failed to write to output file: ", *out)
+	return
+}
+
+func write(path string, data []byte) {
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		log.Panic("failed to write to output file: ", path)
+	}
+	log.Print("wrote output to file: ", path)
+}
+
+// findModRoot returns the closest dir up from the working dir with a go.mod.
+func findModRoot() string {
+	dir, err := os.Getwd()
+	if err != nil {
+		log.Panic(err)
+	}
+	for {
+		if _, err := os.Stat(filepath.Join(dir, "go.mod")); err == nil {
+			return dir
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			log.Panic("found no go.mod")
+		}
+		dir = parent
+	}
+}
+
+// findJobs finds each pkg in root with a serde.go.
+// the pkg's specs are serde.go and any *_serde.go.
+// besides serde.out.go, it regenerates the outputs that already exist.
+func findJobs(root string) []*job {
+	var jobs []*job
+	err := filepath.WalkDir(root, func(dir string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if dir != root && (d.Name() == "testdata" || strings.HasPrefix(d.Name(), ".")) {
+			return filepath.SkipDir
+		}
+		spec := filepath.Join(dir, "serde.go")
+		if _, err := os.Stat(spec); err != nil {
+			return nil
+		}
+		more, err := filepath.Glob(filepath.Join(dir, "*_serde.go"))
+		if err != nil {
+			return err
+		}
+		slices.Sort(more)
+		j := &job{specs: append([]string{spec}, more...), out: filepath.Join(dir, "serde.out.go")}
+		j.schema = existing(filepath.Join(dir, "serde.schema.json"))
+		j.vectors = existing(filepath.Join(dir, "serde.vectors.json"))
+		j.test = existing(filepath.Join(dir, "serde.out_test.go"))
+		j.stream = existing(filepath.Join(dir, "serde.stream.go"))
+		jobs = append(jobs, j)
+		return nil
+	})
+	if err != nil {
+		log.Panic(err)
+	}
+	return jobs
+}
+
+// existing returns path if it exists, and otherwise "".
+func existing(path string) string {
+	if _, err := os.Stat(path); err != nil {
+		return ""
 	}
-	log.Print("wrote output to file: ", *out)
+	return path
 }
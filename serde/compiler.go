@@ -2,7 +2,6 @@ package main
 
 // TODO: add "auto-generated" comment below `package foo` so it doesn't
 // show up in pkg docs.
-// TODO: allow for compilation even on private struct fields.
 
 import (
 	"bytes"
@@ -14,44 +13,84 @@ import (
 	"log"
 	"path"
 	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
 
 	"golang.org/x/tools/go/ast/astutil"
 	"golang.org/x/tools/go/packages"
+	"golang.org/x/tools/imports"
 )
 
-func compile(src string) []byte {
+func compile(srcs ...string) []byte {
+	return newCompiler(srcs...).genGo()
+}
+
+// newCompiler loads srcs, which are spec files from the same pkg.
+func newCompiler(srcs ...string) *compiler {
 	log.SetFlags(log.Lshortfile)
 	c := &compiler{}
-	sts := c.getStructs(src)
-	pkgName := c.file.Name.Name
-	fileId := path.Join(c.pkg.ID, path.Base(src))
+	c.sts = c.getStructs(srcs)
+	for _, src := range srcs {
+		c.fileIds = append(c.fileIds, path.Join(c.pkg.ID, path.Base(src)))
+	}
+	c.imports = make(map[string]bool)
+	c.helperTys = make(map[string]types.Type)
+	return c
+}
 
-	f := genFileHeader(pkgName, fileId)
-	for _, st := range sts {
+// genGo returns the Go codecs.
+func (c *compiler) genGo() []byte {
+	var decls []ast.Decl
+	for _, st := range c.sts {
 		enc, dec := c.shouldGen(st)
-		if enc {
-			f.Decls = append(f.Decls, c.genEncode(st))
+		isUnion := c.isUnion(st)
+		if enc && isUnion {
+			decls = append(decls, c.genUnionEncode(st))
+		} else if enc {
+			decls = append(decls, c.genEncode(st))
+		}
+		if dec && isUnion {
+			decls = append(decls, c.genUnionDecode(st))
+		} else if dec {
+			decls = append(decls, c.genDecode(st))
 		}
-		if dec {
-			f.Decls = append(f.Decls, c.genDecode(st))
+		if c.isStrict(st) {
+			decls = append(decls, c.genStrictDecode(st))
 		}
 	}
+	// helpers go after the structs, each after the helpers it calls.
+	for _, h := range c.helpers {
+		decls = append(decls, c.genHelper(h))
+	}
+
+	f := genFileHeader(c.file.Name.Name, c.fileIds, c.imports)
+	f.Decls = append(f.Decls, decls...)
 	return printGo(f)
 }
 
 type compiler struct {
-	pkg  *packages.Package
+	pkg *packages.Package
+	// file is the first spec file.
 	file *ast.File
+	// files has the syntax of pkg and its deps,
+	// to find comments on structs from other pkgs.
+	files   []*ast.File
+	fileIds []string
+	sts     []types.Object
+	// imports has the paths of other pkgs that we reference.
+	imports map[string]bool
+	// helpers has the non-struct codec funcs to generate.
+	helpers   []*helper
+	helperTys map[string]types.Type
 }
 
-// getStructs post-cond: return struct objects.
-func (c *compiler) getStructs(src string) []types.Object {
-	abs, err := filepath.Abs(src)
-	if err != nil {
-		log.Panic(err)
+// getStructs post-cond: return struct objects, in spec order.
+func (c *compiler) getStructs(srcs []string) []types.Object {
+	if len(srcs) == 0 {
+		log.Panic("no spec files")
 	}
-	dir := path.Dir(src)
+	dir := path.Dir(srcs[0])
 
 	mode := packages.NeedName | packages.NeedFiles
 	mode |= packages.NeedImports | packages.NeedDeps
@@ -60,7 +99,7 @@ func (c *compiler) getStructs(src string) []types.Object {
 		Mode: mode,
 		Dir:  dir,
 	}
-	pkgs, err := packages.Load(cfg, "")
+	pkgs, err := packages.Load(cfg, ".")
 	if err != nil {
 		log.Panic(err)
 	}
@@ -71,18 +110,39 @@ func (c *compiler) getStructs(src string) []types.Object {
 	c.pkg = pkg
 	info := pkg.TypesInfo
 
-	var file *ast.File
+	packages.Visit(pkgs, nil, func(p *packages.Package) {
+		c.files = append(c.files, p.Syntax...)
+	})
+	var sts []types.Object
+	for _, src := range srcs {
+		if path.Dir(src) != dir {
+			log.Panic("spec files are in different dirs: ", srcs)
+		}
+		file := getFile(pkg, src)
+		if c.file == nil {
+			c.file = file
+		}
+		sts = append(sts, getFileStructs(info, file)...)
+	}
+	return sts
+}
+
+func getFile(pkg *packages.Package, src string) *ast.File {
+	abs, err := filepath.Abs(src)
+	if err != nil {
+		log.Panic(err)
+	}
 	for _, f := range pkg.Syntax {
 		tokF := pkg.Fset.File(f.FileStart)
 		if abs == tokF.Name() {
-			file = f
+			return f
 		}
 	}
-	if file == nil {
-		log.Panic("found no files matching src. does src end in .go?")
-	}
-	c.file = file
+	log.Panic("found no files matching src. does src end in .go?")
+	return nil
+}
 
+func getFileStructs(info *types.Info, file *ast.File) []types.Object {
 	var sts []types.Object
 	for _, d := range file.Decls {
 		d2, ok := d.(*ast.GenDecl)
@@ -110,29 +170,31 @@ func (c *compiler) getStructs(src string) []types.Object {
 	return sts
 }
 
-func genFileHeader(pkgName, fileId string) *ast.File {
-	importDecl := &ast.GenDecl{
-		Tok: token.IMPORT,
-		Specs: []ast.Spec{
-			&ast.ImportSpec{
-				Path: &ast.BasicLit{
-					Kind:  token.STRING,
-					Value: "\"github.com/sanjit-bhat/pav/safemarshal\"",
-				},
-			},
-			&ast.ImportSpec{
-				Path: &ast.BasicLit{
-					Kind:  token.STRING,
-					Value: "\"github.com/tchajed/marshal\"",
-				},
-			},
-		},
+func genFileHeader(pkgName string, fileIds []string, imports map[string]bool) *ast.File {
+	paths := []string{"github.com/sanjit-bhat/pav/safemarshal", "github.com/tchajed/marshal"}
+	for p := range imports {
+		paths = append(paths, p)
+	}
+	slices.Sort(paths)
+	importDecl := &ast.GenDecl{Tok: token.IMPORT}
+	for _, p := range paths {
+		importDecl.Specs = append(importDecl.Specs, &ast.ImportSpec{
+			Path: &ast.BasicLit{Kind: token.STRING, Value: strconv.Quote(p)},
+		})
 	}
 	// Hacky: pkg comment fix. Pkg starts after pkg comment.
 	commPos := token.Pos(1)
+	var quoted []string
+	for _, id := range fileIds {
+		quoted = append(quoted, fmt.Sprintf("\"%s\"", id))
+	}
+	specs := "spec"
+	if len(fileIds) > 1 {
+		specs = "specs"
+	}
 	comm1 := &ast.Comment{
 		Slash: commPos,
-		Text:  fmt.Sprintf("// Auto-generated from spec \"%s\"", fileId),
+		Text:  fmt.Sprintf("// Auto-generated from %s %s", specs, strings.Join(quoted, ", ")),
 	}
 	comm2 := &ast.Comment{
 		Slash: commPos,
@@ -149,16 +211,32 @@ func genFileHeader(pkgName, fileId string) *ast.File {
 	return file
 }
 
-// shouldGen checks whether a struct has special comments to not gen some funcs.
-func (c *compiler) shouldGen(o types.Object) (encode bool, decode bool) {
-	encode, decode = true, true
-	p, _ := astutil.PathEnclosingInterval(c.file, o.Pos(), o.Pos())
+// fileOf returns the file that has pos.
+func (c *compiler) fileOf(pos token.Pos) *ast.File {
+	for _, f := range c.files {
+		if f.FileStart <= pos && pos <= f.FileEnd {
+			return f
+		}
+	}
+	log.Panic("no file has pos: ", pos)
+	return nil
+}
+
+// getTypeDoc returns the doc comments of a struct decl.
+func (c *compiler) getTypeDoc(o types.Object) []*ast.Comment {
+	p, _ := astutil.PathEnclosingInterval(c.fileOf(o.Pos()), o.Pos(), o.Pos())
 	// First two are Ident and TypeSpec.
 	d := p[2].(*ast.GenDecl)
 	if d.Doc == nil {
-		return
+		return nil
 	}
-	for _, comm := range d.Doc.List {
+	return d.Doc.List
+}
+
+// shouldGen checks whether a struct has special comments to not gen some funcs.
+func (c *compiler) shouldGen(o types.Object) (encode bool, decode bool) {
+	encode, decode = true, true
+	for _, comm := range c.getTypeDoc(o) {
 		if comm.Text == "// serde: no encode needed." {
 			encode = false
 		}
@@ -169,6 +247,16 @@ func (c *compiler) shouldGen(o types.Object) (encode bool, decode bool) {
 	return
 }
 
+// isUnion checks whether a struct has the special union comment.
+func (c *compiler) isUnion(o types.Object) bool {
+	for _, comm := range c.getTypeDoc(o) {
+		if comm.Text == "// serde: union." {
+			return true
+		}
+	}
+	return false
+}
+
 func (c *compiler) genEncode(o types.Object) *ast.FuncDecl {
 	name := o.Name()
 	st := o.Type().(*types.Named).Underlying().(*types.Struct)
@@ -188,23 +276,32 @@ func (c *compiler) genEncode(o types.Object) *ast.FuncDecl {
 		},
 	}
 	body := make([]ast.Stmt, 0)
-	varDecl := &ast.DeclStmt{Decl: &ast.GenDecl{
+	ver := c.getVersion(o)
+	c.checkVersion(st, ver)
+	var varDecl ast.Stmt = &ast.DeclStmt{Decl: &ast.GenDecl{
 		Tok: token.VAR,
 		Specs: []ast.Spec{&ast.ValueSpec{
 			Names:  []*ast.Ident{{Name: "b"}},
 			Values: []ast.Expr{&ast.Ident{Name: "b0"}},
 		}},
 	}}
+	if ver != 0 {
+		// versioned fields go in their own body.
+		varDecl = genVersionEncStart()
+	}
 	body = append(body, varDecl)
 	for i := 0; i < st.NumFields(); i++ {
 		field := st.Field(i)
-		body = append(body, c.genFieldEnc(field))
+		body = append(body, c.genFieldEnc(field)...)
 	}
-	retStmt := &ast.ReturnStmt{
+	var retStmt ast.Stmt = &ast.ReturnStmt{
 		Results: []ast.Expr{
 			&ast.Ident{Name: "b"},
 		},
 	}
+	if ver != 0 {
+		retStmt = genVersionEncEnd(ver)
+	}
 	body = append(body, retStmt)
 	return &ast.FuncDecl{
 		Name: &ast.Ident{Name: fmt.Sprintf("%vEncode", name)},
@@ -213,147 +310,140 @@ func (c *compiler) genEncode(o types.Object) *ast.FuncDecl {
 	}
 }
 
-func (c *compiler) genFieldEnc(field *types.Var) ast.Stmt {
-	var call *ast.CallExpr
-	switch fTy := field.Type().Underlying().(type) {
-	case *types.Basic:
-		call = c.genBasicEnc(field)
-	case *types.Slice:
-		call = &ast.CallExpr{
-			Fun:  c.genSliceEnc(fTy, 1),
-			Args: genStdFieldEncArgs(field.Name()),
-		}
-	case *types.Pointer:
-		_ = fTy.Elem().(*types.Named).Underlying().(*types.Struct)
-		call = c.genStructEnc(field)
-	case *types.Map:
-		call = c.genMapEnc(field)
-	default:
-		log.Panic("unsupported type: ", fTy)
+func (c *compiler) genFieldEnc(field *types.Var) []ast.Stmt {
+	if c.isOptional(field.Pos()) {
+		return c.genOptFieldEnc(field)
+	}
+	args := genStdFieldEncArgs(field.Name())
+	isCst, cst := c.getConst(field.Pos())
+	if isCst {
+		checkConstTy(field.Type())
+		args[1] = &ast.BasicLit{Kind: token.INT, Value: cst}
 	}
-	return &ast.AssignStmt{
+	call := &ast.CallExpr{
+		Fun:  c.getEncFunc(field.Type()),
+		Args: args,
+	}
+	return []ast.Stmt{&ast.AssignStmt{
 		Lhs: []ast.Expr{&ast.Ident{Name: "b"}},
 		Tok: token.ASSIGN,
 		Rhs: []ast.Expr{call},
-	}
+	}}
 }
 
-func (c *compiler) genBasicEnc(field *types.Var) *ast.CallExpr {
-	var fun *ast.SelectorExpr
-	basic := field.Type().Underlying().(*types.Basic)
-	switch basic.Kind() {
-	case types.Bool:
-		fun = &ast.SelectorExpr{
-			X:   &ast.Ident{Name: "marshal"},
-			Sel: &ast.Ident{Name: "WriteBool"},
-		}
-	case types.Byte:
-		fun = &ast.SelectorExpr{
-			X:   &ast.Ident{Name: "safemarshal"},
-			Sel: &ast.Ident{Name: "WriteByte"},
-		}
-	case types.Uint64:
-		fun = &ast.SelectorExpr{
-			X:   &ast.Ident{Name: "marshal"},
-			Sel: &ast.Ident{Name: "WriteInt"},
-		}
-	default:
-		log.Panic("unsupported type: ", basic.Name())
-	}
-	isCst, cst := c.getConst(field.Pos())
-	if isCst {
-		return &ast.CallExpr{
-			Fun: fun,
-			Args: []ast.Expr{
-				&ast.Ident{Name: "b"},
-				&ast.BasicLit{Kind: token.INT, Value: cst},
-			},
-		}
-	} else {
-		return &ast.CallExpr{
-			Fun:  fun,
-			Args: genStdFieldEncArgs(field.Name()),
-		}
-	}
+// basicFunc has the marshal funcs for a basic type.
+// the decoders are in safemarshal.
+type basicFunc struct {
+	encPkg string
+	enc    string
+	dec    string
+	// cstDec is empty if the type doesn't support consts.
+	cstDec string
+}
+
+var basicFuncs = map[types.BasicKind]*basicFunc{
+	types.Bool:   {"marshal", "WriteBool", "ReadBool", "ReadConstBool"},
+	types.Byte:   {"safemarshal", "WriteByte", "ReadByte", "ReadConstByte"},
+	types.Uint16: {"safemarshal", "WriteInt16", "ReadInt16", "ReadConstInt16"},
+	types.Uint32: {"marshal", "WriteInt32", "ReadInt32", "ReadConstInt32"},
+	types.Uint64: {"marshal", "WriteInt", "ReadInt", "ReadConstInt"},
+	types.Int64:  {"safemarshal", "WriteInt64", "ReadInt64", "ReadConstInt64"},
+	types.String: {"safemarshal", "WriteString", "ReadString", ""},
 }
 
-func (c *compiler) genSliceEnc(ty1 *types.Slice, depth int) ast.Expr {
-	if depth > 3 {
-		log.Panic("unsupported slice nesting beyond depth 3")
+func getBasicFunc(ty *types.Basic) *basicFunc {
+	f, ok := basicFuncs[ty.Kind()]
+	if !ok {
+		log.Panic("unsupported type: ", ty.Name())
 	}
-	switch ty2 := ty1.Elem().Underlying().(type) {
-	case *types.Slice:
-		return c.genSliceEnc(ty2, depth+1)
+	return f
+}
+
+// getEncFunc returns the func that encodes ty, with args (b, o).
+func (c *compiler) getEncFunc(ty types.Type) ast.Expr {
+	switch t := ty.Underlying().(type) {
 	case *types.Basic:
-		if ty2.Kind() != types.Byte {
-			log.Panicf("unsupported slice depth %v ty: %s", depth, ty2)
-		}
+		f := getBasicFunc(t)
 		return &ast.SelectorExpr{
-			X:   &ast.Ident{Name: "safemarshal"},
-			Sel: &ast.Ident{Name: fmt.Sprintf("WriteSlice%vD", depth)},
+			X:   &ast.Ident{Name: f.encPkg},
+			Sel: &ast.Ident{Name: f.enc},
 		}
 	case *types.Pointer:
-		n := ty2.Elem().(*types.Named)
-		_ = n.Underlying().(*types.Struct)
-		stName := n.Obj().Name()
-		return &ast.Ident{Name: fmt.Sprintf("%vSlice%vDEncode", stName, depth)}
+		return c.getStructFunc(getStructTy(t), "Encode")
+	case *types.Slice:
+		if depth := getByteSliceDepth(t); depth != 0 {
+			return &ast.SelectorExpr{
+				X:   &ast.Ident{Name: "safemarshal"},
+				Sel: &ast.Ident{Name: fmt.Sprintf("WriteSlice%vD", depth)},
+			}
+		}
+	case *types.Array, *types.Map:
 	default:
-		log.Panicf("unsupported slice depth %v ty: %s", depth, ty2)
+		log.Panic("unsupported type: ", ty)
 	}
-	return nil
+	return &ast.Ident{Name: c.addHelper(ty, false)}
 }
 
-func (c *compiler) genStructEnc(field *types.Var) *ast.CallExpr {
-	stName := field.Type().Underlying().(*types.Pointer).Elem().(*types.Named).Obj().Name()
-	return &ast.CallExpr{
-		Fun:  &ast.Ident{Name: fmt.Sprintf("%vEncode", stName)},
-		Args: genStdFieldEncArgs(field.Name()),
-	}
+// getStructTy returns the struct that ty points to.
+// currently, only support pointers to structs.
+func getStructTy(ty *types.Pointer) *types.Named {
+	n := ty.Elem().(*types.Named)
+	_ = n.Underlying().(*types.Struct)
+	return n
 }
 
-// TODO: maybe make this an abstraction that gets the handler name
-// for any type that we need a compiled / user-supplied handler.
-// TODO: if we took out the const feature, could maybe factor out the args
-// for all these CallExpr's.
-func (c *compiler) genMapEnc(field *types.Var) *ast.CallExpr {
-	fTy := field.Type().Underlying()
-	canon := getCanonTyName(fTy)
-	return &ast.CallExpr{
-		Fun:  &ast.Ident{Name: fmt.Sprintf("%vEncode", canon)},
-		Args: genStdFieldEncArgs(field.Name()),
+// getByteSliceDepth returns the nesting depth of a byte slice
+// that safemarshal supports, or 0 if it doesn't support ty.
+func getByteSliceDepth(ty *types.Slice) int {
+	var depth int
+	var elem types.Type = ty
+	for {
+		sl, ok := elem.Underlying().(*types.Slice)
+		if !ok {
+			break
+		}
+		depth++
+		elem = sl.Elem()
 	}
+	basic, ok := elem.Underlying().(*types.Basic)
+	if !ok || basic.Kind() != types.Byte || depth > 3 {
+		return 0
+	}
+	return depth
 }
 
-// TODO: right now this is definitely not canonical,
-// especially with nested types.
-func getCanonTyName(ty1 types.Type) string {
-	switch ty2 := ty1.(type) {
-	case *types.Basic:
-		return ty2.Name()
-	case *types.Slice:
-		return "Sl" + getCanonTyName(ty2.Elem().Underlying())
-	case *types.Map:
-		return "Map" + getCanonTyName(ty2.Key().Underlying()) + getCanonTyName(ty2.Elem().Underlying())
-	case *types.Pointer:
-		n := ty2.Elem().(*types.Named)
-		// currently, only support pointers to structs.
-		_ = n.Underlying().(*types.Struct)
-		return n.Obj().Name()
-	default:
-		log.Panicf("unsupported ty: %s", ty2)
+// getStructFunc returns the struct's codec func with suffix,
+// qualified by its pkg if it's from another pkg.
+func (c *compiler) getStructFunc(n *types.Named, suffix string) ast.Expr {
+	name := n.Obj().Name() + suffix
+	pkg := n.Obj().Pkg()
+	if pkg == c.pkg.Types {
+		return &ast.Ident{Name: name}
+	}
+	if !n.Obj().Exported() {
+		log.Panic("can't call codec of unexported struct from other pkg: ", n)
+	}
+	c.imports[pkg.Path()] = true
+	return &ast.SelectorExpr{
+		X:   &ast.Ident{Name: pkg.Name()},
+		Sel: &ast.Ident{Name: name},
 	}
-	return ""
 }
 
-// getConst uses ast pos to check if field has special constant comment.
-func (c *compiler) getConst(pos token.Pos) (isCst bool, cst string) {
-	p, _ := astutil.PathEnclosingInterval(c.file, pos, pos)
+// getFieldDoc uses ast pos to get the doc comments of a field.
+func (c *compiler) getFieldDoc(pos token.Pos) []*ast.Comment {
+	p, _ := astutil.PathEnclosingInterval(c.fileOf(pos), pos, pos)
 	// First node is ident, then there's field.
 	node := p[1].(*ast.Field)
 	if node.Doc == nil {
-		return false, ""
+		return nil
 	}
-	for _, comm := range node.Doc.List {
+	return node.Doc.List
+}
+
+// getConst checks if field has special constant comment.
+func (c *compiler) getConst(pos token.Pos) (isCst bool, cst string) {
+	for _, comm := range c.getFieldDoc(pos) {
 		text := comm.Text
 		text, found0 := strings.CutPrefix(text, "// serde: invariant: const ")
 		text, found1 := strings.CutSuffix(text, ".")
@@ -365,6 +455,57 @@ func (c *compiler) getConst(pos token.Pos) (isCst bool, cst string) {
 	return
 }
 
+// isOptional checks if field has special optional comment.
+func (c *compiler) isOptional(pos token.Pos) bool {
+	for _, comm := range c.getFieldDoc(pos) {
+		if comm.Text == "// serde: optional." {
+			return true
+		}
+	}
+	return false
+}
+
+// getLen checks if field has special length comment.
+// it returns the op that detects a bad len, or ILLEGAL if there's no comment.
+// it panics if there's more than one len comment.
+func (c *compiler) getLen(pos token.Pos) (op token.Token, n string) {
+	op = token.ILLEGAL
+	for _, comm := range c.getFieldDoc(pos) {
+		text, found := strings.CutSuffix(comm.Text, ".")
+		if !found {
+			continue
+		}
+		op0 := op
+		if x, ok := strings.CutPrefix(text, "// serde: len "); ok {
+			op, n = token.NEQ, x
+		} else if x, ok := strings.CutPrefix(text, "// serde: maxlen "); ok {
+			op, n = token.GTR, x
+		} else {
+			continue
+		}
+		if op0 != token.ILLEGAL {
+			log.Panic("multiple len comments at ", c.pkg.Fset.Position(pos))
+		}
+		if _, err := strconv.ParseUint(n, 10, 64); err != nil {
+			log.Panic("bad len: ", n)
+		}
+	}
+	return
+}
+
+// checkLenTy panics if ty doesn't support len bounds.
+func checkLenTy(ty types.Type) {
+	switch t := ty.Underlying().(type) {
+	case *types.Slice:
+		return
+	case *types.Basic:
+		if t.Kind() == types.String {
+			return
+		}
+	}
+	log.Panic("unsupported len type: ", ty)
+}
+
 func genStdFieldEncArgs(name string) []ast.Expr {
 	return []ast.Expr{
 		&ast.Ident{Name: "b"},
@@ -394,17 +535,44 @@ func (c *compiler) genDecode(o types.Object) *ast.FuncDecl {
 			},
 		},
 	}
+	ver := c.getVersion(o)
+	c.checkVersion(st, ver)
 	body := []ast.Stmt{}
+	if ver != 0 {
+		body = append(body, genVersionDec()...)
+	}
 	for i := 0; i < st.NumFields(); i++ {
 		field := st.Field(i)
-		body = append(body, c.genFieldDec(field, i)...)
+		oldB := fmt.Sprintf("b%v", i)
+		if ver != 0 && i == 0 {
+			oldB = "body"
+		}
+		since := c.getSince(field.Pos())
+		if since > 1 {
+			body = append(body, c.genSinceFieldDec(field, i, oldB, since)...)
+		} else {
+			body = append(body, c.genFieldDec(field, oldB, fmt.Sprint(i+1))...)
+		}
+	}
+	lastB := fmt.Sprintf("b%v", st.NumFields())
+	if ver != 0 {
+		if st.NumFields() == 0 {
+			lastB = "body"
+		}
+		body = append(body, genTrailingCheck(lastB, ver))
+		lastB = "tail"
 	}
 	var fieldsInit []ast.Expr
 	for i := 0; i < st.NumFields(); i++ {
 		field := st.Field(i)
+		var val ast.Expr = &ast.Ident{Name: fmt.Sprintf("a%v", i+1)}
+		// const decoders don't return the const.
+		if isCst, cst := c.getConst(field.Pos()); isCst {
+			val = &ast.BasicLit{Kind: token.INT, Value: cst}
+		}
 		init := &ast.KeyValueExpr{
 			Key:   &ast.Ident{Name: field.Name()},
-			Value: &ast.Ident{Name: fmt.Sprintf("a%v", i+1)},
+			Value: val,
 		}
 		fieldsInit = append(fieldsInit, init)
 	}
@@ -418,7 +586,7 @@ func (c *compiler) genDecode(o types.Object) *ast.FuncDecl {
 	ret := &ast.ReturnStmt{
 		Results: []ast.Expr{
 			objInit,
-			&ast.Ident{Name: fmt.Sprintf("b%v", st.NumFields())},
+			&ast.Ident{Name: lastB},
 			&ast.Ident{Name: "false"},
 		},
 	}
@@ -430,37 +598,43 @@ func (c *compiler) genDecode(o types.Object) *ast.FuncDecl {
 	}
 }
 
-func (c *compiler) genFieldDec(field *types.Var, fieldNum int) []ast.Stmt {
-	var call *ast.CallExpr
-	oldB := fmt.Sprintf("b%v", fieldNum)
-	switch fTy := field.Type().Underlying().(type) {
-	case *types.Basic:
-		call = c.genBasicDec(field, oldB)
-	case *types.Slice:
-		call = &ast.CallExpr{
-			Fun:  c.genSliceDec(fTy, 1),
+// genFieldDec decodes field from oldB,
+// defining the obj, rem bytes, and err with suffix.
+func (c *compiler) genFieldDec(field *types.Var, oldB, suffix string) []ast.Stmt {
+	if c.isOptional(field.Pos()) {
+		return c.genOptFieldDec(field, oldB, suffix)
+	}
+	var decCall *ast.CallExpr
+	isCst, cst := c.getConst(field.Pos())
+	if isCst {
+		decCall = &ast.CallExpr{
+			Fun: getConstDecFunc(field.Type()),
+			Args: []ast.Expr{
+				&ast.Ident{Name: oldB},
+				&ast.BasicLit{Kind: token.INT, Value: cst},
+			},
+		}
+	} else {
+		decCall = &ast.CallExpr{
+			Fun:  c.getDecFunc(field.Type()),
 			Args: []ast.Expr{&ast.Ident{Name: oldB}},
 		}
-	case *types.Pointer:
-		n := fTy.Elem().(*types.Named)
-		_ = n.Underlying().(*types.Struct)
-		call = c.genStructDec(n.Obj().Name(), oldB)
-	case *types.Map:
-		call = c.genMapDec(field, oldB)
-	default:
-		log.Panic("unsupported type: ", fTy)
 	}
-	newX := fmt.Sprintf("a%v", fieldNum+1)
-	newB := fmt.Sprintf("b%v", fieldNum+1)
-	newErr := fmt.Sprintf("err%v", fieldNum+1)
+	newX := "a" + suffix
+	newB := "b" + suffix
+	newErr := "err" + suffix
+	lhs := []ast.Expr{
+		&ast.Ident{Name: newX},
+		&ast.Ident{Name: newB},
+		&ast.Ident{Name: newErr},
+	}
+	if isCst {
+		lhs = lhs[1:]
+	}
 	assign := &ast.AssignStmt{
-		Lhs: []ast.Expr{
-			&ast.Ident{Name: newX},
-			&ast.Ident{Name: newB},
-			&ast.Ident{Name: newErr},
-		},
+		Lhs: lhs,
 		Tok: token.DEFINE,
-		Rhs: []ast.Expr{call},
+		Rhs: []ast.Expr{decCall},
 	}
 	err := &ast.IfStmt{
 		Cond: &ast.Ident{Name: newErr},
@@ -474,90 +648,56 @@ func (c *compiler) genFieldDec(field *types.Var, fieldNum int) []ast.Stmt {
 			},
 		}},
 	}
-	return []ast.Stmt{assign, err}
-}
-
-func (c *compiler) genBasicDec(field *types.Var, inBytsId string) *ast.CallExpr {
-	var cstFuncMod string
-	var args []ast.Expr
-	isCst, cst := c.getConst(field.Pos())
-	if isCst {
-		cstFuncMod = "Const"
-		args = []ast.Expr{
-			&ast.Ident{Name: inBytsId},
-			&ast.BasicLit{Kind: token.INT, Value: cst},
-		}
-	} else {
-		args = []ast.Expr{&ast.Ident{Name: inBytsId}}
-	}
-
-	var fun *ast.SelectorExpr
-	basic := field.Type().Underlying().(*types.Basic)
-	switch basic.Kind() {
-	case types.Bool:
-		fun = &ast.SelectorExpr{
-			X:   &ast.Ident{Name: "safemarshal"},
-			Sel: &ast.Ident{Name: fmt.Sprintf("Read%sBool", cstFuncMod)},
-		}
-	case types.Byte:
-		fun = &ast.SelectorExpr{
-			X:   &ast.Ident{Name: "safemarshal"},
-			Sel: &ast.Ident{Name: fmt.Sprintf("Read%sByte", cstFuncMod)},
+	stmts := []ast.Stmt{assign, err}
+	if op, n := c.getLen(field.Pos()); op != token.ILLEGAL {
+		checkLenTy(field.Type())
+		badLen := &ast.BinaryExpr{
+			X:  call(ident("uint64"), call(ident("len"), ident(newX))),
+			Op: op,
+			Y:  &ast.BasicLit{Kind: token.INT, Value: n},
 		}
-	case types.Uint64:
-		fun = &ast.SelectorExpr{
-			X:   &ast.Ident{Name: "safemarshal"},
-			Sel: &ast.Ident{Name: fmt.Sprintf("Read%sInt", cstFuncMod)},
-		}
-	default:
-		log.Panic("unsupported type: ", basic.Name())
-	}
-
-	return &ast.CallExpr{
-		Fun:  fun,
-		Args: args,
+		stmts = append(stmts, ifStmt(badLen, retErr(ident("nil"))))
 	}
+	return stmts
 }
 
-func (c *compiler) genSliceDec(ty1 *types.Slice, depth int) ast.Expr {
-	if depth > 3 {
-		log.Panic("unsupported slice nesting beyond depth 3")
-	}
-	switch ty2 := ty1.Elem().Underlying().(type) {
-	case *types.Slice:
-		return c.genSliceDec(ty2, depth+1)
+// getDecFunc returns the func that decodes ty, with arg b.
+func (c *compiler) getDecFunc(ty types.Type) ast.Expr {
+	switch t := ty.Underlying().(type) {
 	case *types.Basic:
-		if ty2.Kind() != types.Byte {
-			log.Panicf("unsupported slice depth %v ty: %s", depth, ty2)
-		}
 		return &ast.SelectorExpr{
 			X:   &ast.Ident{Name: "safemarshal"},
-			Sel: &ast.Ident{Name: fmt.Sprintf("ReadSlice%vD", depth)},
+			Sel: &ast.Ident{Name: getBasicFunc(t).dec},
 		}
 	case *types.Pointer:
-		n := ty2.Elem().(*types.Named)
-		_ = n.Underlying().(*types.Struct)
-		stName := n.Obj().Name()
-		return &ast.Ident{Name: fmt.Sprintf("%vSlice%vDDecode", stName, depth)}
+		return c.getStructFunc(getStructTy(t), "Decode")
+	case *types.Slice:
+		if depth := getByteSliceDepth(t); depth != 0 {
+			return &ast.SelectorExpr{
+				X:   &ast.Ident{Name: "safemarshal"},
+				Sel: &ast.Ident{Name: fmt.Sprintf("ReadSlice%vD", depth)},
+			}
+		}
+	case *types.Array, *types.Map:
 	default:
-		log.Panicf("unsupported slice depth %v ty: %s", depth, ty2)
+		log.Panic("unsupported type: ", ty)
 	}
-	return nil
+	return &ast.Ident{Name: c.addHelper(ty, true)}
 }
 
-func (c *compiler) genStructDec(stName string, inBytsId string) *ast.CallExpr {
-	return &ast.CallExpr{
-		Fun:  &ast.Ident{Name: fmt.Sprintf("%vDecode", stName)},
-		Args: []ast.Expr{&ast.Ident{Name: inBytsId}},
+// checkConstTy panics if ty doesn't support consts.
+func checkConstTy(ty types.Type) {
+	t, ok := ty.Underlying().(*types.Basic)
+	if !ok || getBasicFunc(t).cstDec == "" {
+		log.Panic("unsupported const type: ", ty)
 	}
 }
 
-func (c *compiler) genMapDec(field *types.Var, inBytsId string) *ast.CallExpr {
-	fTy := field.Type().Underlying()
-	canon := getCanonTyName(fTy)
-	return &ast.CallExpr{
-		Fun:  &ast.Ident{Name: fmt.Sprintf("%vDecode", canon)},
-		Args: []ast.Expr{&ast.Ident{Name: inBytsId}},
+func getConstDecFunc(ty types.Type) ast.Expr {
+	checkConstTy(ty)
+	return &ast.SelectorExpr{
+		X:   &ast.Ident{Name: "safemarshal"},
+		Sel: &ast.Ident{Name: getBasicFunc(ty.Underlying().(*types.Basic)).cstDec},
 	}
 }
 
@@ -570,5 +710,11 @@ func printGo(n any) []byte {
 	if err != nil {
 		log.Panic(err)
 	}
-	return buf.Bytes()
+	// group std imports, e.g., for map helpers.
+	opts := &imports.Options{FormatOnly: true, Comments: true, TabIndent: true, TabWidth: 8}
+	out, err := imports.Process("out.go", buf.Bytes(), opts)
+	if err != nil {
+		log.Panic(err)
+	}
+	return out
 }
@@ -5,6 +5,7 @@ import (
 	"os"
 	"os/exec"
 	"path"
+	"strings"
 	"testing"
 )
 
@@ -15,6 +16,7 @@ const (
 var update = flag.Bool("update", false, "update golden files")
 
 type entry struct {
+	// source has comma-separated spec files from one pkg.
 	source, golden string
 	runs           int
 }
@@ -26,6 +28,14 @@ var data = []entry{
 	{"nogen/nogen.go", "nogen/nogen.golden.go", 1},
 	{"const/const.go", "const/const.golden.go", 1},
 	{"nest/nest.go", "nest/nest.golden.go", 1},
+	{"slice/slice.go", "slice/slice.golden.go", 1},
+	{"more/more.go", "more/more.golden.go", 1},
+	{"option/option.go", "option/option.golden.go", 1},
+	{"length/length.go", "length/length.golden.go", 1},
+	{"version/version.go", "version/version.golden.go", 1},
+	{"multi/serde.go,multi/msg_serde.go", "multi/serde.golden.go", 1},
+	{"streamed/streamed.go", "streamed/streamed.golden.go", 1},
+	{"strict/strict.go", "strict/strict.golden.go", 1},
 }
 
 // tmpWrite writes data to a tmp file and returns the tmp file name.
@@ -50,7 +60,14 @@ func tmpWrite(t *testing.T, data []byte) string {
 }
 
 func check(t *testing.T, source, golden string) {
-	res := compile(source)
+	var srcs []string
+	for _, src := range strings.Split(source, ",") {
+		srcs = append(srcs, path.Join(dataDir, src))
+	}
+	checkRes(t, compile(srcs...), golden)
+}
+
+func checkRes(t *testing.T, res []byte, golden string) {
 	actual := tmpWrite(t, res)
 
 	cmd := exec.Command("diff", "--unified", "--color=always", golden, actual)
@@ -75,13 +92,87 @@ func check(t *testing.T, source, golden string) {
 func TestFiles(t *testing.T) {
 	t.Parallel()
 	for _, e := range data {
-		source := path.Join(dataDir, e.source)
 		golden := path.Join(dataDir, e.golden)
 		t.Run(e.source, func(t *testing.T) {
 			t.Parallel()
 			for i := 0; i < e.runs && !t.Failed(); i++ {
-				check(t, source, golden)
+				check(t, e.source, golden)
+			}
+		})
+	}
+}
+
+func TestBadLen(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("multiple len comments should panic")
+		}
+	}()
+	compile(path.Join(dataDir, "badlen/badlen.go"))
+}
+
+// schemaData has specs with schema and test vector goldens.
+var schemaData = []string{"const/const.go", "more/more.go", "option/option.go", "version/version.go", "strict/strict.go"}
+
+func TestSchema(t *testing.T) {
+	t.Parallel()
+	for _, source := range schemaData {
+		source := path.Join(dataDir, source)
+		t.Run(source, func(t *testing.T) {
+			t.Parallel()
+			c := newCompiler(source)
+			prefix := strings.TrimSuffix(source, ".go")
+			checkRes(t, c.genSchema(), prefix+".schema.json")
+			checkRes(t, c.genVectors(), prefix+".vectors.json")
+		})
+	}
+}
+
+// testData has specs with generated test goldens.
+var testData = []string{"more/more.go", "option/option.go", "version/version.go", "length/length.go", "slice/slice.go", "strict/strict.go"}
+
+func TestGenTest(t *testing.T) {
+	t.Parallel()
+	for _, source := range testData {
+		source := path.Join(dataDir, source)
+		t.Run(source, func(t *testing.T) {
+			t.Parallel()
+			c := newCompiler(source)
+			// check the vectors, if the spec has a golden for them.
+			var vectors string
+			if p := strings.TrimSuffix(source, ".go") + ".vectors.json"; exists(p) {
+				vectors = path.Base(p)
 			}
+			checkRes(t, c.genTest(vectors), strings.TrimSuffix(source, ".go")+".test.golden.go")
+		})
+	}
+}
+
+func exists(p string) bool {
+	_, err := os.Stat(p)
+	return err == nil
+}
+
+// streamData has specs with stream decoder goldens.
+var streamData = []string{"streamed/streamed.go", "more/more.go", "option/option.go", "version/version.go"}
+
+func TestGenStream(t *testing.T) {
+	t.Parallel()
+	for _, source := range streamData {
+		source := path.Join(dataDir, source)
+		t.Run(source, func(t *testing.T) {
+			t.Parallel()
+			c := newCompiler(source)
+			checkRes(t, c.genStream(), strings.TrimSuffix(source, ".go")+".stream.golden.go")
 		})
 	}
 }
+
+// TestStale checks that the module's checked-in outputs are up to date.
+func TestStale(t *testing.T) {
+	for _, j := range findJobs(findModRoot()) {
+		if stale := j.run(true); len(stale) != 0 {
+			t.Errorf("stale outputs, run 'go generate ./serde': %v", stale)
+		}
+	}
+}
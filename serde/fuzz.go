@@ -0,0 +1,382 @@
+package main
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"log"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// the generated tests check, for each struct, that:
+//   - Decode never panics, and its objs re-encode canonically.
+//     this is a fuzz target, with random objs as the seed corpus.
+//   - Decode(Encode(x)) == x for random objs x.
+//     since Decode maps empty slices to nil, we compare encodings.
+//   - for strict structs, DecodeStrict accepts Encode(x),
+//     and rejects it with a trailing byte.
+//   - if the pkg has test vectors, the codecs re-encode them canonically.
+
+// randFunc is a generated func that makes a random obj.
+type randFunc struct {
+	name string
+	ty   types.Type
+}
+
+// testGen has the state for generating tests.
+type testGen struct {
+	c         *compiler
+	funcs     []*randFunc
+	funcNames map[string]types.Type
+}
+
+// genTest returns the Go tests for the codecs.
+// vectors is the test vectors file, relative to the pkg, or "" for none.
+func (c *compiler) genTest(vectors string) []byte {
+	// the tests don't use the codec imports.
+	c.imports = make(map[string]bool)
+	g := &testGen{c: c, funcNames: make(map[string]types.Type)}
+	var decls []ast.Decl
+	for _, o := range c.sts {
+		enc, dec := c.shouldGen(o)
+		if !enc || !dec {
+			continue
+		}
+		randName := g.addRandFunc(types.NewPointer(o.Type()))
+		decls = append(decls, genFuzz(o.Name(), randName), genRoundTrip(o.Name(), randName))
+		if c.isStrict(o) {
+			decls = append(decls, genStrictTest(o.Name(), randName))
+		}
+	}
+	// rand funcs can queue more rand funcs.
+	for i := 0; i < len(g.funcs); i++ {
+		decls = append(decls, g.genRandFunc(g.funcs[i]))
+	}
+	decls = append(decls, genRandBytes())
+
+	imports := map[string]bool{"bytes": true, "math/rand/v2": true, "testing": true}
+	if vectors != "" {
+		decls = append(decls, c.genVectorTest(vectors)...)
+		for _, p := range []string{"encoding/hex", "encoding/json", "os"} {
+			imports[p] = true
+		}
+	}
+	for p := range c.imports {
+		imports[p] = true
+	}
+	f := genFileHeader(c.file.Name.Name, c.fileIds, nil)
+	f.Decls = []ast.Decl{genImports(imports)}
+	f.Decls = append(f.Decls, decls...)
+	return printGo(f)
+}
+
+// genFuzz makes FuzzFooDecode.
+func genFuzz(name, randName string) *ast.FuncDecl {
+	enc := ident(name + "Encode")
+	dec := ident(name + "Decode")
+	fatal := &ast.ExprStmt{X: call(sel("t", "Fatal"))}
+	fuzzBody := []ast.Stmt{
+		define([]string{"x", "_", "err"}, call(dec, ident("b"))),
+		ifStmt(ident("err"), ret()),
+		define([]string{"b0"}, call(enc, ident("nil"), ident("x"))),
+		define([]string{"y", "rem", "err"}, call(dec, ident("b0"))),
+		ifStmt(&ast.BinaryExpr{X: ident("err"), Op: token.LOR, Y: hasLen("rem")}, fatal),
+		ifStmt(notEqual(ident("b0"), call(enc, ident("nil"), ident("y"))), fatal),
+	}
+	fuzzFunc := &ast.FuncLit{
+		Type: &ast.FuncType{Params: &ast.FieldList{List: []*ast.Field{
+			{Names: []*ast.Ident{ident("t")}, Type: &ast.StarExpr{X: sel("testing", "T")}},
+			{Names: []*ast.Ident{ident("b")}, Type: &ast.ArrayType{Elt: ident("byte")}},
+		}}},
+		Body: block(fuzzBody...),
+	}
+	body := []ast.Stmt{
+		genNewRand(),
+		genLoop(intLit(4), &ast.ExprStmt{X: call(sel("f", "Add"),
+			call(enc, ident("nil"), call(ident(randName), ident("rnd"))))}),
+		&ast.ExprStmt{X: call(sel("f", "Fuzz"), fuzzFunc)},
+	}
+	return &ast.FuncDecl{
+		Name: ident("Fuzz" + upperFirst(name) + "Decode"),
+		Type: &ast.FuncType{Params: &ast.FieldList{List: []*ast.Field{
+			{Names: []*ast.Ident{ident("f")}, Type: &ast.StarExpr{X: sel("testing", "F")}},
+		}}},
+		Body: block(body...),
+	}
+}
+
+// genRoundTrip makes TestFooRoundTrip.
+func genRoundTrip(name, randName string) *ast.FuncDecl {
+	enc := ident(name + "Encode")
+	dec := ident(name + "Decode")
+	fatal := &ast.ExprStmt{X: call(sel("t", "Fatal"))}
+	loop := []ast.Stmt{
+		define([]string{"b"}, call(enc, ident("nil"), call(ident(randName), ident("rnd")))),
+		define([]string{"x", "rem", "err"}, call(dec, ident("b"))),
+		ifStmt(&ast.BinaryExpr{X: ident("err"), Op: token.LOR, Y: hasLen("rem")}, fatal),
+		ifStmt(notEqual(ident("b"), call(enc, ident("nil"), ident("x"))), fatal),
+	}
+	return &ast.FuncDecl{
+		Name: ident("Test" + upperFirst(name) + "RoundTrip"),
+		Type: &ast.FuncType{Params: &ast.FieldList{List: []*ast.Field{
+			{Names: []*ast.Ident{ident("t")}, Type: &ast.StarExpr{X: sel("testing", "T")}},
+		}}},
+		Body: block(genNewRand(), genLoop(intLit(100), loop...)),
+	}
+}
+
+// genStrictTest makes TestFooDecodeStrict.
+func genStrictTest(name, randName string) *ast.FuncDecl {
+	dec := ident(name + "DecodeStrict")
+	fatal := &ast.ExprStmt{X: call(sel("t", "Fatal"))}
+	accept := &ast.IfStmt{
+		Init: define([]string{"_", "err"}, call(dec, ident("b"))),
+		Cond: ident("err"),
+		Body: block(fatal),
+	}
+	reject := &ast.IfStmt{
+		Init: define([]string{"_", "err"}, call(dec, call(ident("append"), ident("b"), intLit(0)))),
+		Cond: &ast.UnaryExpr{Op: token.NOT, X: ident("err")},
+		Body: block(fatal),
+	}
+	loop := []ast.Stmt{
+		define([]string{"b"}, call(ident(name+"Encode"), ident("nil"), call(ident(randName), ident("rnd")))),
+		accept,
+		reject,
+	}
+	return &ast.FuncDecl{
+		Name: ident("Test" + upperFirst(name) + "DecodeStrict"),
+		Type: &ast.FuncType{Params: &ast.FieldList{List: []*ast.Field{
+			{Names: []*ast.Ident{ident("t")}, Type: &ast.StarExpr{X: sel("testing", "T")}},
+		}}},
+		Body: block(genNewRand(), genLoop(intLit(100), loop...)),
+	}
+}
+
+func genNewRand() ast.Stmt {
+	src := call(sel("rand", "NewPCG"), intLit(0), intLit(0))
+	return define([]string{"rnd"}, call(sel("rand", "New"), src))
+}
+
+// genLoop makes "for i := 0; i < n; i++".
+func genLoop(n ast.Expr, body ...ast.Stmt) ast.Stmt {
+	return &ast.ForStmt{
+		Init: define([]string{"i"}, intLit(0)),
+		Cond: &ast.BinaryExpr{X: ident("i"), Op: token.LSS, Y: n},
+		Post: &ast.IncDecStmt{X: ident("i"), Tok: token.INC},
+		Body: block(body...),
+	}
+}
+
+// upperFirst makes test names valid for unexported structs.
+func upperFirst(s string) string {
+	return strings.ToUpper(s[:1]) + s[1:]
+}
+
+func hasLen(x string) ast.Expr {
+	return &ast.BinaryExpr{X: call(ident("len"), ident(x)), Op: token.NEQ, Y: intLit(0)}
+}
+
+func notEqual(x, y ast.Expr) ast.Expr {
+	return &ast.UnaryExpr{Op: token.NOT, X: call(sel("bytes", "Equal"), x, y)}
+}
+
+func genImports(imports map[string]bool) *ast.GenDecl {
+	var paths []string
+	for p := range imports {
+		paths = append(paths, p)
+	}
+	slices.Sort(paths)
+	d := &ast.GenDecl{Tok: token.IMPORT}
+	for _, p := range paths {
+		d.Specs = append(d.Specs, &ast.ImportSpec{
+			Path: &ast.BasicLit{Kind: token.STRING, Value: strconv.Quote(p)},
+		})
+	}
+	return d
+}
+
+// addRandFunc queues a rand func for ty, and returns its name.
+// ty is a ptr to a struct, a slice, an array, or a map.
+func (g *testGen) addRandFunc(ty types.Type) string {
+	ty = ty.Underlying()
+	var name string
+	if t, ok := ty.(*types.Pointer); ok {
+		n := getStructTy(t)
+		name = "rand" + upperFirst(n.Obj().Name())
+		if pkg := n.Obj().Pkg(); pkg != g.c.pkg.Types {
+			name = "rand" + upperFirst(pkg.Name()) + n.Obj().Name()
+		}
+	} else {
+		name = "rand" + getCanonTyName(ty)
+	}
+	if other, ok := g.funcNames[name]; ok {
+		if !types.Identical(ty, other) {
+			log.Panicf("rand func name clash between %s and %s", ty, other)
+		}
+		return name
+	}
+	g.funcNames[name] = ty
+	g.funcs = append(g.funcs, &randFunc{name: name, ty: ty})
+	return name
+}
+
+func (g *testGen) genRandFunc(f *randFunc) *ast.FuncDecl {
+	params := []*ast.Field{{Names: []*ast.Ident{ident("rnd")}, Type: &ast.StarExpr{X: sel("rand", "Rand")}}}
+	var body []ast.Stmt
+	switch t := f.ty.(type) {
+	case *types.Pointer:
+		body = g.genRandStruct(getStructTy(t))
+	case *types.Slice:
+		// the caller picks the len.
+		params = append(params, &ast.Field{Names: []*ast.Ident{ident("n")}, Type: ident("int")})
+		elem := g.randExpr(t.Elem(), nil)
+		body = []ast.Stmt{
+			varDecl("x", g.c.getTypeExpr(t), nil),
+			genLoop(ident("n"), assign("x", call(ident("append"), ident("x"), elem))),
+			ret(ident("x")),
+		}
+	case *types.Array:
+		idx := &ast.IndexExpr{X: ident("x"), Index: ident("i")}
+		body = []ast.Stmt{
+			varDecl("x", g.c.getTypeExpr(t), nil),
+			&ast.RangeStmt{Key: ident("i"), Tok: token.DEFINE, X: ident("x"), Body: block(
+				&ast.AssignStmt{Lhs: []ast.Expr{idx}, Tok: token.ASSIGN, Rhs: []ast.Expr{g.randExpr(t.Elem(), nil)}},
+			)},
+			ret(ident("x")),
+		}
+	case *types.Map:
+		checkMapKeyTy(t.Key())
+		idx := &ast.IndexExpr{X: ident("x"), Index: g.randExpr(t.Key(), nil)}
+		body = []ast.Stmt{
+			define([]string{"x"}, call(ident("make"), g.c.getTypeExpr(t))),
+			define([]string{"n"}, call(sel("rnd", "IntN"), intLit(4))),
+			genLoop(ident("n"), &ast.AssignStmt{Lhs: []ast.Expr{idx}, Tok: token.ASSIGN, Rhs: []ast.Expr{g.randExpr(t.Elem(), nil)}}),
+			ret(ident("x")),
+		}
+	default:
+		log.Panic("unsupported type: ", f.ty)
+	}
+	return &ast.FuncDecl{
+		Name: ident(f.name),
+		Type: &ast.FuncType{
+			Params:  &ast.FieldList{List: params},
+			Results: &ast.FieldList{List: []*ast.Field{{Type: g.c.getTypeExpr(f.ty)}}},
+		},
+		Body: block(body...),
+	}
+}
+
+// genRandStruct sets each field, following the serde comments.
+func (g *testGen) genRandStruct(n *types.Named) []ast.Stmt {
+	c := g.c
+	st := n.Underlying().(*types.Struct)
+	body := []ast.Stmt{define([]string{"o"}, &ast.UnaryExpr{Op: token.AND,
+		X: &ast.CompositeLit{Type: c.getTypeExpr(n)}})}
+	setField := func(field *types.Var, val ast.Expr) ast.Stmt {
+		return &ast.AssignStmt{Lhs: []ast.Expr{sel("o", field.Name())}, Tok: token.ASSIGN, Rhs: []ast.Expr{val}}
+	}
+
+	if c.isUnion(n.Obj()) {
+		checkUnion(n.Obj().Name(), st)
+		body = append(body, define([]string{"tag"}, call(sel("rnd", "IntN"), intLit(st.NumFields()))))
+		for i := 0; i < st.NumFields(); i++ {
+			field := st.Field(i)
+			isTag := &ast.BinaryExpr{X: ident("tag"), Op: token.EQL, Y: intLit(i)}
+			body = append(body, ifStmt(isTag, setField(field, g.randExpr(field.Type(), nil))))
+		}
+		return append(body, ret(ident("o")))
+	}
+
+	for i := 0; i < st.NumFields(); i++ {
+		field := st.Field(i)
+		if isCst, cst := c.getConst(field.Pos()); isCst {
+			body = append(body, setField(field, ident(cst)))
+			continue
+		}
+		var length ast.Expr
+		if op, n := c.getLen(field.Pos()); op == token.NEQ {
+			length = ident(n)
+		} else if op == token.GTR {
+			length = call(sel("rnd", "IntN"), &ast.BinaryExpr{X: ident(n), Op: token.ADD, Y: intLit(1)})
+		}
+		set := setField(field, g.randExpr(field.Type(), length))
+		if c.isOptional(field.Pos()) {
+			isSet := &ast.BinaryExpr{X: call(sel("rnd", "IntN"), intLit(2)), Op: token.EQL, Y: intLit(0)}
+			set = ifStmt(isSet, set)
+		}
+		body = append(body, set)
+	}
+	return append(body, ret(ident("o")))
+}
+
+// randExpr returns a random obj of ty.
+// length is the len of slices and strings, or nil for a random len.
+func (g *testGen) randExpr(ty types.Type, length ast.Expr) ast.Expr {
+	if length == nil {
+		length = call(sel("rnd", "IntN"), intLit(4))
+	}
+	var x ast.Expr
+	var xTy string
+	switch t := ty.Underlying().(type) {
+	case *types.Basic:
+		_ = getBasicFunc(t)
+		switch t.Kind() {
+		case types.Bool:
+			x = &ast.BinaryExpr{X: call(sel("rnd", "IntN"), intLit(2)), Op: token.EQL, Y: intLit(0)}
+			xTy = "bool"
+		case types.Byte, types.Uint16:
+			x = call(ident(t.Name()), call(sel("rnd", "Uint32")))
+			xTy = t.Name()
+		case types.Uint32:
+			x, xTy = call(sel("rnd", "Uint32")), "uint32"
+		case types.Uint64:
+			x, xTy = call(sel("rnd", "Uint64")), "uint64"
+		case types.Int64:
+			x, xTy = call(sel("rnd", "Int64")), "int64"
+		case types.String:
+			x = call(ident("string"), call(ident("randBytes"), ident("rnd"), length))
+			xTy = "string"
+		}
+		// named basic types need a conversion.
+		if _, ok := ty.(*types.Basic); !ok || t.Name() != xTy {
+			x = call(g.c.getTypeExpr(ty), x)
+		}
+		return x
+	case *types.Pointer:
+		return call(ident(g.addRandFunc(t)), ident("rnd"))
+	case *types.Slice:
+		if isByte(t.Elem()) {
+			return call(ident("randBytes"), ident("rnd"), length)
+		}
+		return call(ident(g.addRandFunc(t)), ident("rnd"), length)
+	case *types.Array, *types.Map:
+		return call(ident(g.addRandFunc(t)), ident("rnd"))
+	default:
+		log.Panic("unsupported type: ", ty)
+	}
+	return nil
+}
+
+func genRandBytes() *ast.FuncDecl {
+	idx := &ast.IndexExpr{X: ident("b"), Index: ident("i")}
+	return &ast.FuncDecl{
+		Name: ident("randBytes"),
+		Type: &ast.FuncType{
+			Params: &ast.FieldList{List: []*ast.Field{
+				{Names: []*ast.Ident{ident("rnd")}, Type: &ast.StarExpr{X: sel("rand", "Rand")}},
+				{Names: []*ast.Ident{ident("n")}, Type: ident("int")},
+			}},
+			Results: &ast.FieldList{List: []*ast.Field{{Type: &ast.ArrayType{Elt: ident("byte")}}}},
+		},
+		Body: block(
+			define([]string{"b"}, call(ident("make"), &ast.ArrayType{Elt: ident("byte")}, ident("n"))),
+			&ast.RangeStmt{Key: ident("i"), Tok: token.DEFINE, X: ident("b"), Body: block(
+				&ast.AssignStmt{Lhs: []ast.Expr{idx}, Tok: token.ASSIGN, Rhs: []ast.Expr{call(ident("byte"), call(sel("rnd", "Uint32")))}},
+			)},
+			ret(ident("b")),
+		),
+	}
+}
@@ -0,0 +1,434 @@
+package main
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"log"
+	"strconv"
+)
+
+// helper is a generated codec func for a slice, array, or map.
+//
+// encodings:
+//   - slice: Len ++ each elem.
+//   - array: each elem. byte arrays are just the bytes.
+//   - map: Len ++ each key and val, in strictly increasing key order.
+//     decoding rejects other orders, so the encoding is canonical.
+type helper struct {
+	name  string
+	ty    types.Type
+	isDec bool
+}
+
+// addHelper queues a helper for ty, after the helpers that it calls,
+// and returns its name.
+func (c *compiler) addHelper(ty types.Type, isDec bool) string {
+	// named types are assignable to their underlying helper type.
+	ty = ty.Underlying()
+	name := getHelperName(ty, isDec)
+	if other, ok := c.helperTys[name]; ok {
+		if !types.Identical(ty, other) {
+			log.Panicf("helper name clash between %s and %s", ty, other)
+		}
+		return name
+	}
+	c.helperTys[name] = ty
+
+	var deps []types.Type
+	switch t := ty.(type) {
+	case *types.Slice:
+		deps = []types.Type{t.Elem()}
+	case *types.Array:
+		deps = []types.Type{t.Elem()}
+	case *types.Map:
+		checkMapKeyTy(t.Key())
+		deps = []types.Type{t.Key(), t.Elem()}
+	}
+	for _, dep := range deps {
+		if isDec {
+			c.getDecFunc(dep)
+		} else {
+			c.getEncFunc(dep)
+		}
+	}
+	c.helpers = append(c.helpers, &helper{name: name, ty: ty, isDec: isDec})
+	return name
+}
+
+// checkMapKeyTy panics if ty can't be sorted as a map key.
+func checkMapKeyTy(ty types.Type) {
+	t, ok := ty.Underlying().(*types.Basic)
+	if !ok {
+		log.Panic("unsupported map key type: ", ty)
+	}
+	switch t.Kind() {
+	case types.Byte, types.Uint16, types.Uint32, types.Uint64, types.Int64, types.String:
+	default:
+		log.Panic("unsupported map key type: ", ty)
+	}
+}
+
+// getHelperName gives slices of structs names like <Struct>Slice<N>D,
+// and other types their canonical name.
+func getHelperName(ty types.Type, isDec bool) string {
+	suffix := "Encode"
+	if isDec {
+		suffix = "Decode"
+	}
+	if st, depth := getStructSliceDepth(ty); depth != 0 {
+		return fmt.Sprintf("%vSlice%vD%v", st.Obj().Name(), depth, suffix)
+	}
+	return getCanonTyName(ty) + suffix
+}
+
+// getStructSliceDepth returns the nesting depth of a slice of structs,
+// or 0 if ty isn't one.
+func getStructSliceDepth(ty types.Type) (st *types.Named, depth int) {
+	elem := ty
+	for {
+		sl, ok := elem.Underlying().(*types.Slice)
+		if !ok {
+			break
+		}
+		depth++
+		elem = sl.Elem()
+	}
+	ptr, ok := elem.Underlying().(*types.Pointer)
+	if !ok {
+		return nil, 0
+	}
+	return getStructTy(ptr), depth
+}
+
+// TODO: right now this is definitely not canonical,
+// especially with nested types.
+func getCanonTyName(ty1 types.Type) string {
+	switch ty2 := ty1.(type) {
+	case *types.Basic:
+		return ty2.Name()
+	case *types.Slice:
+		return "Sl" + getCanonTyName(ty2.Elem().Underlying())
+	case *types.Array:
+		return fmt.Sprintf("Arr%v", ty2.Len()) + getCanonTyName(ty2.Elem().Underlying())
+	case *types.Map:
+		return "Map" + getCanonTyName(ty2.Key().Underlying()) + getCanonTyName(ty2.Elem().Underlying())
+	case *types.Pointer:
+		return getStructTy(ty2).Obj().Name()
+	default:
+		log.Panicf("unsupported ty: %s", ty2)
+	}
+	return ""
+}
+
+// getTypeExpr returns the type expr for ty,
+// qualified by pkg for named types from other pkgs.
+func (c *compiler) getTypeExpr(ty types.Type) ast.Expr {
+	switch t := ty.(type) {
+	case *types.Basic:
+		return &ast.Ident{Name: t.Name()}
+	case *types.Named:
+		pkg := t.Obj().Pkg()
+		if pkg == nil || pkg == c.pkg.Types {
+			return &ast.Ident{Name: t.Obj().Name()}
+		}
+		c.imports[pkg.Path()] = true
+		return &ast.SelectorExpr{
+			X:   &ast.Ident{Name: pkg.Name()},
+			Sel: &ast.Ident{Name: t.Obj().Name()},
+		}
+	case *types.Alias:
+		return c.getTypeExpr(types.Unalias(t))
+	case *types.Pointer:
+		return &ast.StarExpr{X: c.getTypeExpr(t.Elem())}
+	case *types.Slice:
+		return &ast.ArrayType{Elt: c.getTypeExpr(t.Elem())}
+	case *types.Array:
+		return &ast.ArrayType{Len: intLit(t.Len()), Elt: c.getTypeExpr(t.Elem())}
+	case *types.Map:
+		return &ast.MapType{Key: c.getTypeExpr(t.Key()), Value: c.getTypeExpr(t.Elem())}
+	default:
+		log.Panicf("unsupported ty: %s", t)
+	}
+	return nil
+}
+
+func (c *compiler) genHelper(h *helper) *ast.FuncDecl {
+	var body []ast.Stmt
+	switch t := h.ty.(type) {
+	case *types.Slice:
+		if h.isDec {
+			body = c.genSliceDec(t)
+		} else {
+			body = c.genSliceEnc(t)
+		}
+	case *types.Array:
+		if h.isDec {
+			body = c.genArrayDec(t)
+		} else {
+			body = c.genArrayEnc(t)
+		}
+	case *types.Map:
+		if h.isDec {
+			body = c.genMapDec(t)
+		} else {
+			body = c.genMapEnc(t)
+		}
+	}
+
+	byteSlice := &ast.ArrayType{Elt: ident("byte")}
+	var funcTy *ast.FuncType
+	if h.isDec {
+		funcTy = &ast.FuncType{
+			Params: &ast.FieldList{List: []*ast.Field{
+				{Names: []*ast.Ident{ident("b0")}, Type: byteSlice},
+			}},
+			Results: &ast.FieldList{List: []*ast.Field{
+				{Type: c.getTypeExpr(h.ty)},
+				{Type: &ast.ArrayType{Elt: ident("byte")}},
+				{Type: ident("bool")},
+			}},
+		}
+	} else {
+		funcTy = &ast.FuncType{
+			Params: &ast.FieldList{List: []*ast.Field{
+				{Names: []*ast.Ident{ident("b0")}, Type: byteSlice},
+				{Names: []*ast.Ident{ident("o")}, Type: c.getTypeExpr(h.ty)},
+			}},
+			Results: &ast.FieldList{List: []*ast.Field{
+				{Type: &ast.ArrayType{Elt: ident("byte")}},
+			}},
+		}
+	}
+	return &ast.FuncDecl{
+		Name: ident(h.name),
+		Type: funcTy,
+		Body: &ast.BlockStmt{List: body},
+	}
+}
+
+func (c *compiler) genSliceEnc(ty *types.Slice) []ast.Stmt {
+	lenEnc := assign("b", call(sel("marshal", "WriteInt"), ident("b"),
+		call(ident("uint64"), call(ident("len"), ident("o")))))
+	loop := &ast.RangeStmt{
+		Key:   ident("_"),
+		Value: ident("e"),
+		Tok:   token.DEFINE,
+		X:     ident("o"),
+		Body: block(
+			assign("b", call(c.getEncFunc(ty.Elem()), ident("b"), ident("e"))),
+		),
+	}
+	return []ast.Stmt{varDecl("b", nil, ident("b0")), lenEnc, loop, ret(ident("b"))}
+}
+
+// genSliceDec doesn't pre-allocate the slice, since the len is untrusted.
+func (c *compiler) genSliceDec(ty *types.Slice) []ast.Stmt {
+	loop := &ast.ForStmt{
+		Init: define([]string{"i"}, call(ident("uint64"), intLit(0))),
+		Cond: &ast.BinaryExpr{X: ident("i"), Op: token.LSS, Y: ident("length")},
+		Post: &ast.IncDecStmt{X: ident("i"), Tok: token.INC},
+		Body: block(
+			define([]string{"a2", "loopB1", "err2"}, call(c.getDecFunc(ty.Elem()), ident("loopB"))),
+			assign("loopB", ident("loopB1")),
+			ifStmt(ident("err2"), assign("loopErr", ident("true")), &ast.BranchStmt{Tok: token.BREAK}),
+			assign("loopO", call(ident("append"), ident("loopO"), ident("a2"))),
+		),
+	}
+	return []ast.Stmt{
+		define([]string{"length", "b1", "err1"}, call(sel("safemarshal", "ReadInt"), ident("b0"))),
+		ifStmt(ident("err1"), retErr(ident("nil"))),
+		varDecl("loopO", c.getTypeExpr(ty), nil),
+		varDecl("loopErr", ident("bool"), nil),
+		varDecl("loopB", nil, ident("b1")),
+		loop,
+		ifStmt(ident("loopErr"), retErr(ident("nil"))),
+		ret(ident("loopO"), ident("loopB"), ident("false")),
+	}
+}
+
+func isByteArray(ty *types.Array) bool {
+	basic, ok := ty.Elem().Underlying().(*types.Basic)
+	return ok && basic.Kind() == types.Byte
+}
+
+func (c *compiler) genArrayEnc(ty *types.Array) []ast.Stmt {
+	if isByteArray(ty) {
+		slice := &ast.SliceExpr{X: ident("o")}
+		return []ast.Stmt{ret(call(sel("marshal", "WriteBytes"), ident("b0"), slice))}
+	}
+	loop := &ast.RangeStmt{
+		Key:   ident("_"),
+		Value: ident("e"),
+		Tok:   token.DEFINE,
+		X:     ident("o"),
+		Body: block(
+			assign("b", call(c.getEncFunc(ty.Elem()), ident("b"), ident("e"))),
+		),
+	}
+	return []ast.Stmt{varDecl("b", nil, ident("b0")), loop, ret(ident("b"))}
+}
+
+func (c *compiler) genArrayDec(ty *types.Array) []ast.Stmt {
+	if isByteArray(ty) {
+		copyStmt := &ast.ExprStmt{X: call(ident("copy"), &ast.SliceExpr{X: ident("o")}, ident("a1"))}
+		return []ast.Stmt{
+			varDecl("o", c.getTypeExpr(ty), nil),
+			define([]string{"a1", "b1", "err1"}, call(sel("safemarshal", "ReadBytes"), ident("b0"), intLit(ty.Len()))),
+			ifStmt(ident("err1"), retErr(ident("o"))),
+			copyStmt,
+			ret(ident("o"), ident("b1"), ident("false")),
+		}
+	}
+	setElem := &ast.AssignStmt{
+		Lhs: []ast.Expr{&ast.IndexExpr{X: ident("loopO"), Index: ident("i")}},
+		Tok: token.ASSIGN,
+		Rhs: []ast.Expr{ident("a1")},
+	}
+	loop := &ast.ForStmt{
+		Init: define([]string{"i"}, call(ident("uint64"), intLit(0))),
+		Cond: &ast.BinaryExpr{X: ident("i"), Op: token.LSS, Y: intLit(ty.Len())},
+		Post: &ast.IncDecStmt{X: ident("i"), Tok: token.INC},
+		Body: block(
+			define([]string{"a1", "loopB1", "err1"}, call(c.getDecFunc(ty.Elem()), ident("loopB"))),
+			assign("loopB", ident("loopB1")),
+			ifStmt(ident("err1"), assign("loopErr", ident("true")), &ast.BranchStmt{Tok: token.BREAK}),
+			setElem,
+		),
+	}
+	return []ast.Stmt{
+		varDecl("loopO", c.getTypeExpr(ty), nil),
+		varDecl("loopErr", ident("bool"), nil),
+		varDecl("loopB", nil, ident("b0")),
+		loop,
+		ifStmt(ident("loopErr"), retErr(ident("loopO"))),
+		ret(ident("loopO"), ident("loopB"), ident("false")),
+	}
+}
+
+func (c *compiler) genMapEnc(ty *types.Map) []ast.Stmt {
+	c.imports["slices"] = true
+	keysLoop := &ast.RangeStmt{
+		Key:  ident("k"),
+		Tok:  token.DEFINE,
+		X:    ident("o"),
+		Body: block(assign("keys", call(ident("append"), ident("keys"), ident("k")))),
+	}
+	lenEnc := assign("b", call(sel("marshal", "WriteInt"), ident("b"),
+		call(ident("uint64"), call(ident("len"), ident("keys")))))
+	loop := &ast.RangeStmt{
+		Key:   ident("_"),
+		Value: ident("k"),
+		Tok:   token.DEFINE,
+		X:     ident("keys"),
+		Body: block(
+			assign("b", call(c.getEncFunc(ty.Key()), ident("b"), ident("k"))),
+			assign("b", call(c.getEncFunc(ty.Elem()), ident("b"),
+				&ast.IndexExpr{X: ident("o"), Index: ident("k")})),
+		),
+	}
+	return []ast.Stmt{
+		varDecl("b", nil, ident("b0")),
+		varDecl("keys", &ast.ArrayType{Elt: c.getTypeExpr(ty.Key())}, nil),
+		keysLoop,
+		&ast.ExprStmt{X: call(sel("slices", "Sort"), ident("keys"))},
+		lenEnc,
+		loop,
+		ret(ident("b")),
+	}
+}
+
+func (c *compiler) genMapDec(ty *types.Map) []ast.Stmt {
+	// keys must strictly increase, for a canonical encoding.
+	badOrder := &ast.BinaryExpr{
+		X:  &ast.BinaryExpr{X: ident("i"), Op: token.NEQ, Y: intLit(0)},
+		Op: token.LAND,
+		Y:  &ast.BinaryExpr{X: ident("a2"), Op: token.LEQ, Y: ident("loopPrev")},
+	}
+	setElem := &ast.AssignStmt{
+		Lhs: []ast.Expr{&ast.IndexExpr{X: ident("loopO"), Index: ident("a2")}},
+		Tok: token.ASSIGN,
+		Rhs: []ast.Expr{ident("a3")},
+	}
+	loop := &ast.ForStmt{
+		Init: define([]string{"i"}, call(ident("uint64"), intLit(0))),
+		Cond: &ast.BinaryExpr{X: ident("i"), Op: token.LSS, Y: ident("length")},
+		Post: &ast.IncDecStmt{X: ident("i"), Tok: token.INC},
+		Body: block(
+			define([]string{"a2", "loopB1", "err2"}, call(c.getDecFunc(ty.Key()), ident("loopB"))),
+			ifStmt(ident("err2"), assign("loopErr", ident("true")), &ast.BranchStmt{Tok: token.BREAK}),
+			ifStmt(badOrder, assign("loopErr", ident("true")), &ast.BranchStmt{Tok: token.BREAK}),
+			define([]string{"a3", "loopB2", "err3"}, call(c.getDecFunc(ty.Elem()), ident("loopB1"))),
+			ifStmt(ident("err3"), assign("loopErr", ident("true")), &ast.BranchStmt{Tok: token.BREAK}),
+			setElem,
+			assign("loopPrev", ident("a2")),
+			assign("loopB", ident("loopB2")),
+		),
+	}
+	return []ast.Stmt{
+		define([]string{"length", "b1", "err1"}, call(sel("safemarshal", "ReadInt"), ident("b0"))),
+		ifStmt(ident("err1"), retErr(ident("nil"))),
+		varDecl("loopO", nil, call(ident("make"), c.getTypeExpr(ty))),
+		varDecl("loopErr", ident("bool"), nil),
+		varDecl("loopB", nil, ident("b1")),
+		varDecl("loopPrev", c.getTypeExpr(ty.Key()), nil),
+		loop,
+		ifStmt(ident("loopErr"), retErr(ident("nil"))),
+		ret(ident("loopO"), ident("loopB"), ident("false")),
+	}
+}
+
+func ident(name string) *ast.Ident {
+	return &ast.Ident{Name: name}
+}
+
+func sel(x, name string) *ast.SelectorExpr {
+	return &ast.SelectorExpr{X: ident(x), Sel: ident(name)}
+}
+
+func intLit[T int | int64](x T) *ast.BasicLit {
+	return &ast.BasicLit{Kind: token.INT, Value: strconv.FormatInt(int64(x), 10)}
+}
+
+func call(fun ast.Expr, args ...ast.Expr) *ast.CallExpr {
+	return &ast.CallExpr{Fun: fun, Args: args}
+}
+
+func assign(lhs string, rhs ast.Expr) *ast.AssignStmt {
+	return &ast.AssignStmt{Lhs: []ast.Expr{ident(lhs)}, Tok: token.ASSIGN, Rhs: []ast.Expr{rhs}}
+}
+
+func define(lhs []string, rhs ast.Expr) *ast.AssignStmt {
+	var l []ast.Expr
+	for _, x := range lhs {
+		l = append(l, ident(x))
+	}
+	return &ast.AssignStmt{Lhs: l, Tok: token.DEFINE, Rhs: []ast.Expr{rhs}}
+}
+
+// varDecl is "var name ty = val", where ty or val may be nil.
+func varDecl(name string, ty ast.Expr, val ast.Expr) *ast.DeclStmt {
+	spec := &ast.ValueSpec{Names: []*ast.Ident{ident(name)}, Type: ty}
+	if val != nil {
+		spec.Values = []ast.Expr{val}
+	}
+	return &ast.DeclStmt{Decl: &ast.GenDecl{Tok: token.VAR, Specs: []ast.Spec{spec}}}
+}
+
+func block(stmts ...ast.Stmt) *ast.BlockStmt {
+	return &ast.BlockStmt{List: stmts}
+}
+
+func ifStmt(cond ast.Expr, body ...ast.Stmt) *ast.IfStmt {
+	return &ast.IfStmt{Cond: cond, Body: block(body...)}
+}
+
+func ret(results ...ast.Expr) *ast.ReturnStmt {
+	return &ast.ReturnStmt{Results: results}
+}
+
+// retErr returns the decode err, with val as the decoded obj.
+func retErr(val ast.Expr) *ast.ReturnStmt {
+	return ret(val, ident("nil"), ident("true"))
+}
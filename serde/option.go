@@ -0,0 +1,144 @@
+package main
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"log"
+	"strconv"
+)
+
+// optional fields are nilable ptrs, encoded as a presence bool,
+// followed by the obj if it's present.
+// unions are structs of nilable ptrs, where exactly one is set.
+// they're encoded as a tag byte (the field idx), followed by that field.
+
+// checkOptionalTy panics if ty can't be optional.
+func checkOptionalTy(ty types.Type) {
+	t, ok := ty.Underlying().(*types.Pointer)
+	if !ok {
+		log.Panic("unsupported optional type: ", ty)
+	}
+	_ = getStructTy(t)
+}
+
+func (c *compiler) genOptFieldEnc(field *types.Var) []ast.Stmt {
+	checkOptionalTy(field.Type())
+	x := sel("o", field.Name())
+	isSet := &ast.BinaryExpr{X: x, Op: token.NEQ, Y: ident("nil")}
+	return []ast.Stmt{
+		assign("b", call(sel("marshal", "WriteBool"), ident("b"), isSet)),
+		ifStmt(isSet,
+			assign("b", call(c.getEncFunc(field.Type()), ident("b"), x))),
+	}
+}
+
+func (c *compiler) genOptFieldDec(field *types.Var, oldB, suffix string) []ast.Stmt {
+	checkOptionalTy(field.Type())
+	newX := "a" + suffix
+	newB := "b" + suffix
+	newErr := "err" + suffix
+	isSet := "isSet" + suffix
+	return []ast.Stmt{
+		varDecl(newX, c.getTypeExpr(field.Type()), nil),
+		define([]string{isSet, newB, newErr}, call(sel("safemarshal", "ReadBool"), ident(oldB))),
+		ifStmt(ident(newErr), retErr(ident("nil"))),
+		ifStmt(ident(isSet),
+			define([]string{"x", "rem", "err"}, call(c.getDecFunc(field.Type()), ident(newB))),
+			ifStmt(ident("err"), retErr(ident("nil"))),
+			assign(newX, ident("x")),
+			assign(newB, ident("rem")),
+		),
+	}
+}
+
+// checkUnion panics if st can't be a union.
+func checkUnion(name string, st *types.Struct) {
+	if st.NumFields() == 0 || st.NumFields() > 256 {
+		log.Panicf("union %s has %d variants", name, st.NumFields())
+	}
+	for i := 0; i < st.NumFields(); i++ {
+		checkOptionalTy(st.Field(i).Type())
+	}
+}
+
+func (c *compiler) genUnionEncode(o types.Object) *ast.FuncDecl {
+	name := o.Name()
+	st := o.Type().Underlying().(*types.Struct)
+	checkUnion(name, st)
+	body := []ast.Stmt{varDecl("b", nil, ident("b0"))}
+	for i := 0; i < st.NumFields(); i++ {
+		var cond ast.Expr
+		for j := 0; j < st.NumFields(); j++ {
+			op := token.EQL
+			if i == j {
+				op = token.NEQ
+			}
+			isSet := &ast.BinaryExpr{X: sel("o", st.Field(j).Name()), Op: op, Y: ident("nil")}
+			if cond == nil {
+				cond = isSet
+			} else {
+				cond = &ast.BinaryExpr{X: cond, Op: token.LAND, Y: isSet}
+			}
+		}
+		field := st.Field(i)
+		body = append(body, ifStmt(cond,
+			assign("b", call(sel("safemarshal", "WriteByte"), ident("b"), intLit(i))),
+			ret(call(c.getEncFunc(field.Type()), ident("b"), sel("o", field.Name()))),
+		))
+	}
+	msg := fmt.Sprintf("serde: %s must have exactly one variant", name)
+	body = append(body, &ast.ExprStmt{X: call(ident("panic"),
+		&ast.BasicLit{Kind: token.STRING, Value: strconv.Quote(msg)})})
+	return &ast.FuncDecl{
+		Name: ident(name + "Encode"),
+		Type: &ast.FuncType{
+			Params: &ast.FieldList{List: []*ast.Field{
+				{Names: []*ast.Ident{ident("b0")}, Type: &ast.ArrayType{Elt: ident("byte")}},
+				{Names: []*ast.Ident{ident("o")}, Type: &ast.StarExpr{X: ident(name)}},
+			}},
+			Results: &ast.FieldList{List: []*ast.Field{{Type: &ast.ArrayType{Elt: ident("byte")}}}},
+		},
+		Body: block(body...),
+	}
+}
+
+func (c *compiler) genUnionDecode(o types.Object) *ast.FuncDecl {
+	name := o.Name()
+	st := o.Type().Underlying().(*types.Struct)
+	checkUnion(name, st)
+	body := []ast.Stmt{
+		define([]string{"tag", "b1", "err1"}, call(sel("safemarshal", "ReadByte"), ident("b0"))),
+		ifStmt(ident("err1"), retErr(ident("nil"))),
+	}
+	for i := 0; i < st.NumFields(); i++ {
+		field := st.Field(i)
+		obj := &ast.UnaryExpr{Op: token.AND, X: &ast.CompositeLit{
+			Type: ident(name),
+			Elts: []ast.Expr{&ast.KeyValueExpr{Key: ident(field.Name()), Value: ident("x")}},
+		}}
+		isTag := &ast.BinaryExpr{X: ident("tag"), Op: token.EQL, Y: intLit(i)}
+		body = append(body, ifStmt(isTag,
+			define([]string{"x", "b2", "err2"}, call(c.getDecFunc(field.Type()), ident("b1"))),
+			ifStmt(ident("err2"), retErr(ident("nil"))),
+			ret(obj, ident("b2"), ident("false")),
+		))
+	}
+	// unknown tag.
+	body = append(body, retErr(ident("nil")))
+	return &ast.FuncDecl{
+		Name: ident(name + "Decode"),
+		Type: &ast.FuncType{
+			Params: &ast.FieldList{List: []*ast.Field{
+				{Names: []*ast.Ident{ident("b0")}, Type: &ast.ArrayType{Elt: ident("byte")}},
+			}},
+			Results: &ast.FieldList{List: []*ast.Field{
+				{Type: &ast.StarExpr{X: ident(name)}},
+				{Type: &ast.ArrayType{Elt: ident("byte")}},
+				{Type: ident("bool")},
+			}},
+		},
+		Body: block(body...),
+	}
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"encoding/json"
+	"go/token"
+	"go/types"
+	"log"
+	"strconv"
+)
+
+// the schema is a language-neutral description of the wire format,
+// for implementing the codecs outside of Go.
+
+type schema struct {
+	Package string   `json:"package"`
+	Specs   []string `json:"specs"`
+	// Wire has the byte layout of each kind.
+	Wire    map[string]string `json:"wire"`
+	Structs []*schemaStruct   `json:"structs"`
+}
+
+type schemaStruct struct {
+	Name    string         `json:"name"`
+	Encode  bool           `json:"encode"`
+	Decode  bool           `json:"decode"`
+	Union   bool           `json:"union,omitempty"`
+	Strict  bool           `json:"strict,omitempty"`
+	Version uint64         `json:"version,omitempty"`
+	Fields  []*schemaField `json:"fields"`
+}
+
+type schemaField struct {
+	Name     string      `json:"name"`
+	Type     *schemaType `json:"type"`
+	Const    string      `json:"const,omitempty"`
+	Optional bool        `json:"optional,omitempty"`
+	Len      *uint64     `json:"len,omitempty"`
+	MaxLen   *uint64     `json:"maxlen,omitempty"`
+	Since    uint64      `json:"since,omitempty"`
+}
+
+type schemaType struct {
+	Kind string `json:"kind"`
+	// Len is for arrays.
+	Len  int64       `json:"len,omitempty"`
+	Key  *schemaType `json:"key,omitempty"`
+	Elem *schemaType `json:"elem,omitempty"`
+	// Package is for structs from other pkgs.
+	Package string `json:"package,omitempty"`
+	Name    string `json:"name,omitempty"`
+}
+
+var wire = map[string]string{
+	"bool":     "1 byte, 0 or 1.",
+	"byte":     "1 byte.",
+	"uint16":   "2 bytes, little-endian.",
+	"uint32":   "4 bytes, little-endian.",
+	"uint64":   "8 bytes, little-endian.",
+	"int64":    "8 bytes, little-endian two's complement.",
+	"string":   "uint64 len, then the bytes.",
+	"slice":    "uint64 len, then each elem.",
+	"array":    "each elem, with no len.",
+	"map":      "uint64 len, then each key and elem, in strictly increasing key order.",
+	"struct":   "each field, in order.",
+	"optional": "bool presence, then the field if it's present.",
+	"union":    "byte tag with the idx of the one non-nil field, then that field.",
+	"version":  "uint64 version, then the fields as a slice of bytes. decoders skip trailing fields from newer versions.",
+	"strict":   "decoders only accept the canonical encoding, with no trailing bytes or fields from newer versions.",
+}
+
+// genSchema returns the JSON schema.
+func (c *compiler) genSchema() []byte {
+	s := &schema{Package: c.pkg.PkgPath, Specs: c.fileIds, Wire: wire}
+	for _, o := range c.sts {
+		enc, dec := c.shouldGen(o)
+		st := o.Type().Underlying().(*types.Struct)
+		ss := &schemaStruct{
+			Name:    o.Name(),
+			Encode:  enc,
+			Decode:  dec,
+			Union:   c.isUnion(o),
+			Strict:  c.isStrict(o),
+			Version: c.getVersion(o),
+		}
+		for i := 0; i < st.NumFields(); i++ {
+			ss.Fields = append(ss.Fields, c.getSchemaField(st.Field(i)))
+		}
+		s.Structs = append(s.Structs, ss)
+	}
+	out, err := json.MarshalIndent(s, "", "\t")
+	if err != nil {
+		log.Panic(err)
+	}
+	return append(out, '\n')
+}
+
+func (c *compiler) getSchemaField(field *types.Var) *schemaField {
+	f := &schemaField{
+		Name:     field.Name(),
+		Type:     c.getSchemaType(field.Type()),
+		Optional: c.isOptional(field.Pos()),
+	}
+	if isCst, cst := c.getConst(field.Pos()); isCst {
+		f.Const = cst
+	}
+	if since := c.getSince(field.Pos()); since > 1 {
+		f.Since = since
+	}
+	op, n := c.getLen(field.Pos())
+	if op != token.ILLEGAL {
+		x, _ := strconv.ParseUint(n, 10, 64)
+		if op == token.NEQ {
+			f.Len = &x
+		} else {
+			f.MaxLen = &x
+		}
+	}
+	return f
+}
+
+func (c *compiler) getSchemaType(ty types.Type) *schemaType {
+	switch t := ty.Underlying().(type) {
+	case *types.Basic:
+		_ = getBasicFunc(t)
+		// e.g., uint8 is byte on the wire.
+		kind := t.Name()
+		if t.Kind() == types.Byte {
+			kind = "byte"
+		}
+		return &schemaType{Kind: kind}
+	case *types.Pointer:
+		n := getStructTy(t)
+		st := &schemaType{Kind: "struct", Name: n.Obj().Name()}
+		if n.Obj().Pkg() != c.pkg.Types {
+			st.Package = n.Obj().Pkg().Path()
+		}
+		return st
+	case *types.Slice:
+		return &schemaType{Kind: "slice", Elem: c.getSchemaType(t.Elem())}
+	case *types.Array:
+		return &schemaType{Kind: "array", Len: t.Len(), Elem: c.getSchemaType(t.Elem())}
+	case *types.Map:
+		checkMapKeyTy(t.Key())
+		return &schemaType{Kind: "map", Key: c.getSchemaType(t.Key()), Elem: c.getSchemaType(t.Elem())}
+	default:
+		log.Panic("unsupported type: ", ty)
+	}
+	return nil
+}
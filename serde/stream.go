@@ -0,0 +1,533 @@
+package main
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"log"
+	"strings"
+)
+
+// stream decoders read from a [stream.Reader], instead of a byte slice.
+// they go in their own file, with a !goose build tag,
+// since goose doesn't support io.
+//
+// a field with the stream annotation is a slice that can be
+// processed one elem at a time.
+// its decoder takes an "each" callback, which gets each elem
+// instead of the struct.
+// each sees elems before the rest of the msg is checked,
+// so callers must drop their work if the decoder errs.
+// if a streamed elem has its own streamed fields, their callbacks are
+// threaded through, named after both fields, e.g., eachPUpdates.
+// other nested decoders pass a nil callback, which keeps the elems in the struct.
+
+const streamPkg = "github.com/sanjit-bhat/pav/safemarshal/stream"
+
+// streamGen has the state for generating stream decoders.
+type streamGen struct {
+	c       *compiler
+	helpers []*helper
+	tys     map[string]types.Type
+}
+
+// genStream returns the Go stream decoders.
+func (c *compiler) genStream() []byte {
+	c.imports = map[string]bool{streamPkg: true}
+	g := &streamGen{c: c, tys: make(map[string]types.Type)}
+	var decls []ast.Decl
+	for _, o := range c.sts {
+		if _, dec := c.shouldGen(o); !dec {
+			continue
+		}
+		if c.isUnion(o) {
+			decls = append(decls, g.genUnionDecode(o))
+		} else {
+			decls = append(decls, g.genDecode(o))
+		}
+	}
+	// helpers can queue more helpers.
+	for i := 0; i < len(g.helpers); i++ {
+		decls = append(decls, g.genHelper(g.helpers[i]))
+	}
+
+	f := genFileHeader(c.file.Name.Name, c.fileIds, nil)
+	f.Decls = []ast.Decl{genImports(c.imports)}
+	f.Decls = append(f.Decls, decls...)
+	return append([]byte("//go:build !goose\n\n"), printGo(f)...)
+}
+
+// isStreamed checks if field has special stream comment.
+func (c *compiler) isStreamed(pos token.Pos) bool {
+	for _, comm := range c.getFieldDoc(pos) {
+		if comm.Text == "// serde: stream." {
+			return true
+		}
+	}
+	return false
+}
+
+// getStreamed returns the streamed fields of a struct.
+func (c *compiler) getStreamed(st *types.Struct) []*types.Var {
+	var fields []*types.Var
+	for i := 0; i < st.NumFields(); i++ {
+		field := st.Field(i)
+		if !c.isStreamed(field.Pos()) {
+			continue
+		}
+		if _, ok := field.Type().Underlying().(*types.Slice); !ok {
+			log.Panic("unsupported stream type: ", field.Type())
+		}
+		isCst, _ := c.getConst(field.Pos())
+		op, _ := c.getLen(field.Pos())
+		if isCst || op != token.ILLEGAL || c.isOptional(field.Pos()) {
+			log.Panic("unsupported annotation on stream field: ", field.Name())
+		}
+		fields = append(fields, field)
+	}
+	return fields
+}
+
+func eachName(field *types.Var) string {
+	return "each" + upperFirst(field.Name())
+}
+
+// eachParam is a callback param of a stream decoder.
+type eachParam struct {
+	name string
+	elem types.Type
+}
+
+// getEachParams returns the callbacks for a struct's streamed fields,
+// followed by the callbacks of each streamed elem, if it has any.
+func (c *compiler) getEachParams(st *types.Struct) []*eachParam {
+	var params []*eachParam
+	for _, field := range c.getStreamed(st) {
+		elem := field.Type().Underlying().(*types.Slice).Elem()
+		params = append(params, &eachParam{name: eachName(field), elem: elem})
+		params = append(params, c.getNestedEach(field)...)
+	}
+	return params
+}
+
+// getNestedEach returns the callbacks that field's elem decoder takes,
+// renamed with field's prefix.
+func (c *compiler) getNestedEach(field *types.Var) []*eachParam {
+	elem := field.Type().Underlying().(*types.Slice).Elem()
+	ptr, ok := elem.Underlying().(*types.Pointer)
+	if !ok {
+		return nil
+	}
+	n := getStructTy(ptr)
+	if c.isUnion(n.Obj()) {
+		return nil
+	}
+	var params []*eachParam
+	for _, p := range c.getEachParams(n.Underlying().(*types.Struct)) {
+		name := eachName(field) + strings.TrimPrefix(p.name, "each")
+		params = append(params, &eachParam{name: name, elem: p.elem})
+	}
+	return params
+}
+
+// genFuncTy makes "func(r *stream.Reader, each...) (ty, bool)".
+func (g *streamGen) genFuncTy(ty ast.Expr, eachs []*eachParam) *ast.FuncType {
+	params := []*ast.Field{
+		{Names: []*ast.Ident{ident("r")}, Type: &ast.StarExpr{X: sel("stream", "Reader")}},
+	}
+	for _, p := range eachs {
+		each := &ast.FuncType{
+			Params:  &ast.FieldList{List: []*ast.Field{{Type: g.c.getTypeExpr(p.elem)}}},
+			Results: &ast.FieldList{List: []*ast.Field{{Type: ident("bool")}}},
+		}
+		params = append(params, &ast.Field{Names: []*ast.Ident{ident(p.name)}, Type: each})
+	}
+	return &ast.FuncType{
+		Params: &ast.FieldList{List: params},
+		Results: &ast.FieldList{List: []*ast.Field{
+			{Type: ty},
+			{Type: ident("bool")},
+		}},
+	}
+}
+
+// retStreamErr returns the decode err, with val as the decoded obj.
+func retStreamErr(val ast.Expr) *ast.ReturnStmt {
+	return ret(val, ident("true"))
+}
+
+func (g *streamGen) genDecode(o types.Object) *ast.FuncDecl {
+	c := g.c
+	name := o.Name()
+	st := o.Type().Underlying().(*types.Struct)
+	ver := c.getVersion(o)
+	c.checkVersion(st, ver)
+
+	var body []ast.Stmt
+	rd := "r"
+	if ver != 0 {
+		rd = "body"
+		body = append(body,
+			define([]string{"ver", "errVer"}, call(sel("r", "Int"))),
+			ifStmt(ident("errVer"), retStreamErr(ident("nil"))),
+			ifStmt(&ast.BinaryExpr{X: ident("ver"), Op: token.EQL, Y: intLit(0)}, retStreamErr(ident("nil"))),
+			define([]string{"length", "errLen"}, call(sel("r", "Int"))),
+			ifStmt(ident("errLen"), retStreamErr(ident("nil"))),
+			define([]string{"body", "errBody"}, call(sel("r", "Limit"), ident("length"))),
+			ifStmt(ident("errBody"), retStreamErr(ident("nil"))),
+		)
+	}
+	for i := 0; i < st.NumFields(); i++ {
+		field := st.Field(i)
+		suffix := fmt.Sprint(i + 1)
+		since := c.getSince(field.Pos())
+		if since == 1 {
+			body = append(body, g.genFieldDec(field, rd, suffix)...)
+			continue
+		}
+		dec := g.genFieldDec(field, rd, "")
+		dec = append(dec, assign("a"+suffix, ident("a")))
+		hasField := &ast.BinaryExpr{X: ident("ver"), Op: token.GEQ, Y: intLit(int64(since))}
+		body = append(body,
+			varDecl("a"+suffix, c.getTypeExpr(field.Type()), nil),
+			ifStmt(hasField, dec...),
+		)
+	}
+	if ver != 0 {
+		isKnown := &ast.BinaryExpr{X: ident("ver"), Op: token.LEQ, Y: intLit(int64(ver))}
+		hasTrailing := &ast.BinaryExpr{X: call(sel("body", "Budget")), Op: token.NEQ, Y: intLit(0)}
+		body = append(body,
+			ifStmt(&ast.BinaryExpr{X: isKnown, Op: token.LAND, Y: hasTrailing}, retStreamErr(ident("nil"))),
+			// skip fields from newer versions.
+			ifStmt(call(sel("body", "Discard")), retStreamErr(ident("nil"))),
+		)
+	}
+
+	var fieldsInit []ast.Expr
+	for i := 0; i < st.NumFields(); i++ {
+		field := st.Field(i)
+		var val ast.Expr = ident(fmt.Sprintf("a%v", i+1))
+		if isCst, cst := c.getConst(field.Pos()); isCst {
+			val = &ast.BasicLit{Kind: token.INT, Value: cst}
+		}
+		fieldsInit = append(fieldsInit, &ast.KeyValueExpr{Key: ident(field.Name()), Value: val})
+	}
+	obj := &ast.UnaryExpr{Op: token.AND, X: &ast.CompositeLit{Type: ident(name), Elts: fieldsInit}}
+	body = append(body, ret(obj, ident("false")))
+	return &ast.FuncDecl{
+		Name: ident(name + "DecodeStream"),
+		Type: g.genFuncTy(&ast.StarExpr{X: ident(name)}, c.getEachParams(st)),
+		Body: block(body...),
+	}
+}
+
+// genFieldDec decodes field from rd,
+// defining the obj and err with suffix.
+func (g *streamGen) genFieldDec(field *types.Var, rd, suffix string) []ast.Stmt {
+	c := g.c
+	newX := "a" + suffix
+	newErr := "err" + suffix
+	if c.isOptional(field.Pos()) {
+		checkOptionalTy(field.Type())
+		isSet := "isSet" + suffix
+		return []ast.Stmt{
+			varDecl(newX, c.getTypeExpr(field.Type()), nil),
+			define([]string{isSet, newErr}, call(sel(rd, "Bool"))),
+			ifStmt(ident(newErr), retStreamErr(ident("nil"))),
+			ifStmt(ident(isSet),
+				define([]string{"x", "err"}, g.decCall(field.Type(), rd)),
+				ifStmt(ident("err"), retStreamErr(ident("nil"))),
+				assign(newX, ident("x")),
+			),
+		}
+	}
+	if isCst, cst := c.getConst(field.Pos()); isCst {
+		checkConstTy(field.Type())
+		dec := strings.TrimPrefix(getBasicFunc(field.Type().Underlying().(*types.Basic)).cstDec, "Read")
+		return []ast.Stmt{
+			ifStmt(call(sel(rd, dec), &ast.BasicLit{Kind: token.INT, Value: cst}), retStreamErr(ident("nil"))),
+		}
+	}
+	if c.isStreamed(field.Pos()) {
+		return g.genStreamedDec(field, rd, suffix)
+	}
+	stmts := []ast.Stmt{
+		define([]string{newX, newErr}, g.decCall(field.Type(), rd)),
+		ifStmt(ident(newErr), retStreamErr(ident("nil"))),
+	}
+	if op, n := c.getLen(field.Pos()); op != token.ILLEGAL {
+		checkLenTy(field.Type())
+		badLen := &ast.BinaryExpr{
+			X:  call(ident("uint64"), call(ident("len"), ident(newX))),
+			Op: op,
+			Y:  &ast.BasicLit{Kind: token.INT, Value: n},
+		}
+		stmts = append(stmts, ifStmt(badLen, retStreamErr(ident("nil"))))
+	}
+	return stmts
+}
+
+// genStreamedDec passes each elem to the field's callback,
+// or appends it if the callback is nil.
+func (g *streamGen) genStreamedDec(field *types.Var, rd, suffix string) []ast.Stmt {
+	ty := field.Type().Underlying().(*types.Slice)
+	newX := "a" + suffix
+	length := "length" + suffix
+	newErr := "err" + suffix
+	each := eachName(field)
+	elemDec := g.decCall(ty.Elem(), rd)
+	if nested := g.c.getNestedEach(field); len(nested) != 0 {
+		// thread the elem's callbacks through, in place of nils.
+		elemDec.Args = elemDec.Args[:1]
+		for _, p := range nested {
+			elemDec.Args = append(elemDec.Args, ident(p.name))
+		}
+	}
+	loop := &ast.ForStmt{
+		Init: define([]string{"i"}, call(ident("uint64"), intLit(0))),
+		Cond: &ast.BinaryExpr{X: ident("i"), Op: token.LSS, Y: ident(length)},
+		Post: &ast.IncDecStmt{X: ident("i"), Tok: token.INC},
+		Body: block(
+			define([]string{"x", "err"}, elemDec),
+			ifStmt(ident("err"), retStreamErr(ident("nil"))),
+			ifStmt(&ast.BinaryExpr{X: ident(each), Op: token.EQL, Y: ident("nil")},
+				assign(newX, call(ident("append"), ident(newX), ident("x"))),
+				&ast.BranchStmt{Tok: token.CONTINUE},
+			),
+			ifStmt(call(ident(each), ident("x")), retStreamErr(ident("nil"))),
+		),
+	}
+	return []ast.Stmt{
+		varDecl(newX, g.c.getTypeExpr(field.Type()), nil),
+		define([]string{length, newErr}, call(sel(rd, "Count"), intLit(int64(g.c.minSize(ty.Elem(), nil))))),
+		ifStmt(ident(newErr), retStreamErr(ident("nil"))),
+		loop,
+	}
+}
+
+// decCall returns the call that decodes ty from rd.
+func (g *streamGen) decCall(ty types.Type, rd string) *ast.CallExpr {
+	switch t := ty.Underlying().(type) {
+	case *types.Basic:
+		return call(sel(rd, strings.TrimPrefix(getBasicFunc(t).dec, "Read")))
+	case *types.Pointer:
+		n := getStructTy(t)
+		args := []ast.Expr{ident(rd)}
+		// nested decoders keep streamed elems in the struct.
+		for range g.c.getEachParams(n.Underlying().(*types.Struct)) {
+			args = append(args, ident("nil"))
+		}
+		return call(g.c.getStructFunc(n, "DecodeStream"), args...)
+	case *types.Slice:
+		if depth := getByteSliceDepth(t); depth != 0 {
+			return call(sel(rd, fmt.Sprintf("Slice%vD", depth)))
+		}
+	case *types.Array, *types.Map:
+	default:
+		log.Panic("unsupported type: ", ty)
+	}
+	return call(ident(g.addHelper(ty)), ident(rd))
+}
+
+// addHelper queues a stream helper for ty, and returns its name.
+func (g *streamGen) addHelper(ty types.Type) string {
+	ty = ty.Underlying()
+	name := getHelperName(ty, true) + "Stream"
+	if other, ok := g.tys[name]; ok {
+		if !types.Identical(ty, other) {
+			log.Panicf("helper name clash between %s and %s", ty, other)
+		}
+		return name
+	}
+	g.tys[name] = ty
+	g.helpers = append(g.helpers, &helper{name: name, ty: ty, isDec: true})
+	return name
+}
+
+func (g *streamGen) genHelper(h *helper) *ast.FuncDecl {
+	c := g.c
+	var body []ast.Stmt
+	switch t := h.ty.(type) {
+	case *types.Slice:
+		loop := &ast.ForStmt{
+			Init: define([]string{"i"}, call(ident("uint64"), intLit(0))),
+			Cond: &ast.BinaryExpr{X: ident("i"), Op: token.LSS, Y: ident("length")},
+			Post: &ast.IncDecStmt{X: ident("i"), Tok: token.INC},
+			Body: block(
+				define([]string{"a2", "err2"}, g.decCall(t.Elem(), "r")),
+				ifStmt(ident("err2"), retStreamErr(ident("nil"))),
+				assign("loopO", call(ident("append"), ident("loopO"), ident("a2"))),
+			),
+		}
+		body = []ast.Stmt{
+			define([]string{"length", "err1"}, call(sel("r", "Count"), intLit(int64(c.minSize(t.Elem(), nil))))),
+			ifStmt(ident("err1"), retStreamErr(ident("nil"))),
+			varDecl("loopO", c.getTypeExpr(t), nil),
+			loop,
+			ret(ident("loopO"), ident("false")),
+		}
+	case *types.Array:
+		if isByteArray(t) {
+			copyStmt := &ast.ExprStmt{X: call(ident("copy"), &ast.SliceExpr{X: ident("o")}, ident("a1"))}
+			body = []ast.Stmt{
+				varDecl("o", c.getTypeExpr(t), nil),
+				define([]string{"a1", "err1"}, call(sel("r", "Bytes"), intLit(t.Len()))),
+				ifStmt(ident("err1"), retStreamErr(ident("o"))),
+				copyStmt,
+				ret(ident("o"), ident("false")),
+			}
+			break
+		}
+		setElem := &ast.AssignStmt{
+			Lhs: []ast.Expr{&ast.IndexExpr{X: ident("loopO"), Index: ident("i")}},
+			Tok: token.ASSIGN,
+			Rhs: []ast.Expr{ident("a1")},
+		}
+		loop := &ast.ForStmt{
+			Init: define([]string{"i"}, call(ident("uint64"), intLit(0))),
+			Cond: &ast.BinaryExpr{X: ident("i"), Op: token.LSS, Y: intLit(t.Len())},
+			Post: &ast.IncDecStmt{X: ident("i"), Tok: token.INC},
+			Body: block(
+				define([]string{"a1", "err1"}, g.decCall(t.Elem(), "r")),
+				ifStmt(ident("err1"), retStreamErr(ident("loopO"))),
+				setElem,
+			),
+		}
+		body = []ast.Stmt{
+			varDecl("loopO", c.getTypeExpr(t), nil),
+			loop,
+			ret(ident("loopO"), ident("false")),
+		}
+	case *types.Map:
+		checkMapKeyTy(t.Key())
+		// keys must strictly increase, for a canonical encoding.
+		badOrder := &ast.BinaryExpr{
+			X:  &ast.BinaryExpr{X: ident("i"), Op: token.NEQ, Y: intLit(0)},
+			Op: token.LAND,
+			Y:  &ast.BinaryExpr{X: ident("a2"), Op: token.LEQ, Y: ident("loopPrev")},
+		}
+		setElem := &ast.AssignStmt{
+			Lhs: []ast.Expr{&ast.IndexExpr{X: ident("loopO"), Index: ident("a2")}},
+			Tok: token.ASSIGN,
+			Rhs: []ast.Expr{ident("a3")},
+		}
+		loop := &ast.ForStmt{
+			Init: define([]string{"i"}, call(ident("uint64"), intLit(0))),
+			Cond: &ast.BinaryExpr{X: ident("i"), Op: token.LSS, Y: ident("length")},
+			Post: &ast.IncDecStmt{X: ident("i"), Tok: token.INC},
+			Body: block(
+				define([]string{"a2", "err2"}, g.decCall(t.Key(), "r")),
+				ifStmt(ident("err2"), retStreamErr(ident("nil"))),
+				ifStmt(badOrder, retStreamErr(ident("nil"))),
+				define([]string{"a3", "err3"}, g.decCall(t.Elem(), "r")),
+				ifStmt(ident("err3"), retStreamErr(ident("nil"))),
+				setElem,
+				assign("loopPrev", ident("a2")),
+			),
+		}
+		minSz := c.minSize(t.Key(), nil) + c.minSize(t.Elem(), nil)
+		body = []ast.Stmt{
+			define([]string{"length", "err1"}, call(sel("r", "Count"), intLit(int64(minSz)))),
+			ifStmt(ident("err1"), retStreamErr(ident("nil"))),
+			varDecl("loopO", nil, call(ident("make"), c.getTypeExpr(t))),
+			varDecl("loopPrev", c.getTypeExpr(t.Key()), nil),
+			loop,
+			ret(ident("loopO"), ident("false")),
+		}
+	}
+	return &ast.FuncDecl{
+		Name: ident(h.name),
+		Type: g.genFuncTy(c.getTypeExpr(h.ty), nil),
+		Body: block(body...),
+	}
+}
+
+func (g *streamGen) genUnionDecode(o types.Object) *ast.FuncDecl {
+	name := o.Name()
+	st := o.Type().Underlying().(*types.Struct)
+	checkUnion(name, st)
+	body := []ast.Stmt{
+		define([]string{"tag", "err1"}, call(sel("r", "Byte"))),
+		ifStmt(ident("err1"), retStreamErr(ident("nil"))),
+	}
+	for i := 0; i < st.NumFields(); i++ {
+		field := st.Field(i)
+		obj := &ast.UnaryExpr{Op: token.AND, X: &ast.CompositeLit{
+			Type: ident(name),
+			Elts: []ast.Expr{&ast.KeyValueExpr{Key: ident(field.Name()), Value: ident("x")}},
+		}}
+		isTag := &ast.BinaryExpr{X: ident("tag"), Op: token.EQL, Y: intLit(i)}
+		body = append(body, ifStmt(isTag,
+			define([]string{"x", "err2"}, g.decCall(field.Type(), "r")),
+			ifStmt(ident("err2"), retStreamErr(ident("nil"))),
+			ret(obj, ident("false")),
+		))
+	}
+	// unknown tag.
+	body = append(body, retStreamErr(ident("nil")))
+	return &ast.FuncDecl{
+		Name: ident(name + "DecodeStream"),
+		Type: g.genFuncTy(&ast.StarExpr{X: ident(name)}, nil),
+		Body: block(body...),
+	}
+}
+
+// minSize returns the fewest bytes that encode ty.
+// seen has the structs that we're already sizing, to stop on cycles.
+func (c *compiler) minSize(ty types.Type, seen map[*types.Named]bool) uint64 {
+	switch t := ty.Underlying().(type) {
+	case *types.Basic:
+		_ = getBasicFunc(t)
+		switch t.Kind() {
+		case types.Bool, types.Byte:
+			return 1
+		case types.Uint16:
+			return 2
+		case types.Uint32:
+			return 4
+		default:
+			// uint64, int64, and string len.
+			return 8
+		}
+	case *types.Slice, *types.Map:
+		return 8
+	case *types.Array:
+		return uint64(t.Len()) * c.minSize(t.Elem(), seen)
+	case *types.Pointer:
+		n := getStructTy(t)
+		if seen[n] {
+			return 0
+		}
+		if seen == nil {
+			seen = make(map[*types.Named]bool)
+		}
+		seen[n] = true
+		defer delete(seen, n)
+		st := n.Underlying().(*types.Struct)
+		if c.isUnion(n.Obj()) {
+			sz := c.minSize(st.Field(0).Type(), seen)
+			for i := 1; i < st.NumFields(); i++ {
+				sz = min(sz, c.minSize(st.Field(i).Type(), seen))
+			}
+			return 1 + sz
+		}
+		if c.getVersion(n.Obj()) != 0 {
+			// the version and body len.
+			return 16
+		}
+		var sz uint64
+		for i := 0; i < st.NumFields(); i++ {
+			field := st.Field(i)
+			if c.isOptional(field.Pos()) {
+				sz += 1
+			} else {
+				sz += c.minSize(field.Type(), seen)
+			}
+		}
+		return sz
+	default:
+		log.Panic("unsupported type: ", ty)
+	}
+	return 0
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"go/ast"
+	"go/types"
+	"log"
+)
+
+// strict structs have a decoder that only accepts canonical encodings,
+// i.e., ones where Encode(Decode(b)) == b.
+// it rejects trailing bytes, and versions with unknown fields.
+// this is for signed-over payloads, so a sig can't cover two encodings.
+
+// isStrict checks whether a struct has the special strict comment.
+func (c *compiler) isStrict(o types.Object) bool {
+	for _, comm := range c.getTypeDoc(o) {
+		if comm.Text == "// serde: strict." {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *compiler) genStrictDecode(o types.Object) *ast.FuncDecl {
+	name := o.Name()
+	if enc, dec := c.shouldGen(o); !enc || !dec {
+		log.Panic("strict struct needs encode and decode: ", name)
+	}
+	enc := call(ident(name+"Encode"), ident("nil"), ident("o"))
+	return &ast.FuncDecl{
+		Name: ident(name + "DecodeStrict"),
+		Type: &ast.FuncType{
+			Params: &ast.FieldList{List: []*ast.Field{
+				{Names: []*ast.Ident{ident("b")}, Type: &ast.ArrayType{Elt: ident("byte")}},
+			}},
+			Results: &ast.FieldList{List: []*ast.Field{
+				{Type: &ast.StarExpr{X: ident(name)}},
+				{Type: ident("bool")},
+			}},
+		},
+		Body: block(
+			define([]string{"o", "_", "err"}, call(ident(name+"Decode"), ident("b"))),
+			ifStmt(ident("err"), ret(ident("nil"), ident("true"))),
+			ifStmt(call(sel("safemarshal", "CheckCanonical"), ident("b"), enc), ret(ident("nil"), ident("true"))),
+			ret(ident("o"), ident("false")),
+		),
+	}
+}
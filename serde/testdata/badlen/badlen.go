@@ -0,0 +1,7 @@
+package serde
+
+type args struct {
+	// serde: len 32.
+	// serde: maxlen 64.
+	a1 []byte
+}
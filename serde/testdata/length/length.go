@@ -0,0 +1,11 @@
+package serde
+
+type args struct {
+	// serde: len 32.
+	a1 []byte
+	// serde: maxlen 4.
+	a2 [][]byte
+	// serde: maxlen 10.
+	a3 string
+	a4 []byte
+}
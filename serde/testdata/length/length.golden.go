@@ -0,0 +1,45 @@
+// Auto-generated from spec "github.com/sanjit-bhat/pav/serde/testdata/length/length.go"
+// using compiler "github.com/sanjit-bhat/pav/serde".
+package serde
+
+import (
+	"github.com/sanjit-bhat/pav/safemarshal"
+	"github.com/tchajed/marshal"
+)
+
+func argsEncode(b0 []byte, o *args) []byte {
+	var b = b0
+	b = safemarshal.WriteSlice1D(b, o.a1)
+	b = safemarshal.WriteSlice2D(b, o.a2)
+	b = safemarshal.WriteString(b, o.a3)
+	b = safemarshal.WriteSlice1D(b, o.a4)
+	return b
+}
+func argsDecode(b0 []byte) (*args, []byte, bool) {
+	a1, b1, err1 := safemarshal.ReadSlice1D(b0)
+	if err1 {
+		return nil, nil, true
+	}
+	if uint64(len(a1)) != 32 {
+		return nil, nil, true
+	}
+	a2, b2, err2 := safemarshal.ReadSlice2D(b1)
+	if err2 {
+		return nil, nil, true
+	}
+	if uint64(len(a2)) > 4 {
+		return nil, nil, true
+	}
+	a3, b3, err3 := safemarshal.ReadString(b2)
+	if err3 {
+		return nil, nil, true
+	}
+	if uint64(len(a3)) > 10 {
+		return nil, nil, true
+	}
+	a4, b4, err4 := safemarshal.ReadSlice1D(b3)
+	if err4 {
+		return nil, nil, true
+	}
+	return &args{a1: a1, a2: a2, a3: a3, a4: a4}, b4, false
+}
@@ -0,0 +1,65 @@
+// Auto-generated from spec "github.com/sanjit-bhat/pav/serde/testdata/length/length.go"
+// using compiler "github.com/sanjit-bhat/pav/serde".
+package serde
+
+import (
+	"bytes"
+	"math/rand/v2"
+	"testing"
+)
+
+func FuzzArgsDecode(f *testing.F) {
+	rnd := rand.New(rand.NewPCG(0, 0))
+	for i := 0; i < 4; i++ {
+		f.Add(argsEncode(nil, randArgs(rnd)))
+	}
+	f.Fuzz(func(t *testing.T, b []byte) {
+		x, _, err := argsDecode(b)
+		if err {
+			return
+		}
+		b0 := argsEncode(nil, x)
+		y, rem, err := argsDecode(b0)
+		if err || len(rem) != 0 {
+			t.Fatal()
+		}
+		if !bytes.Equal(b0, argsEncode(nil, y)) {
+			t.Fatal()
+		}
+	})
+}
+func TestArgsRoundTrip(t *testing.T) {
+	rnd := rand.New(rand.NewPCG(0, 0))
+	for i := 0; i < 100; i++ {
+		b := argsEncode(nil, randArgs(rnd))
+		x, rem, err := argsDecode(b)
+		if err || len(rem) != 0 {
+			t.Fatal()
+		}
+		if !bytes.Equal(b, argsEncode(nil, x)) {
+			t.Fatal()
+		}
+	}
+}
+func randArgs(rnd *rand.Rand) *args {
+	o := &args{}
+	o.a1 = randBytes(rnd, 32)
+	o.a2 = randSlSlbyte(rnd, rnd.IntN(4+1))
+	o.a3 = string(randBytes(rnd, rnd.IntN(10+1)))
+	o.a4 = randBytes(rnd, rnd.IntN(4))
+	return o
+}
+func randSlSlbyte(rnd *rand.Rand, n int) [][]byte {
+	var x [][]byte
+	for i := 0; i < n; i++ {
+		x = append(x, randBytes(rnd, rnd.IntN(4)))
+	}
+	return x
+}
+func randBytes(rnd *rand.Rand, n int) []byte {
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = byte(rnd.Uint32())
+	}
+	return b
+}
@@ -0,0 +1,32 @@
+package serde
+
+type hash [32]byte
+
+type inner struct {
+	a1 uint64
+}
+
+type args struct {
+	a1  uint16
+	a2  uint32
+	a3  int64
+	a4  string
+	a5  [32]byte
+	a6  hash
+	a7  [2]uint64
+	a8  []uint64
+	a9  []string
+	a10 [][][][]byte
+	a11 map[string][]byte
+	a12 map[uint32]*inner
+	a13 [][2]*inner
+}
+
+type consts struct {
+	// serde: invariant: const 3.
+	a1 uint16
+	// serde: invariant: const 3.
+	a2 uint32
+	// serde: invariant: const 3.
+	a3 int64
+}
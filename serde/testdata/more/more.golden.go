@@ -0,0 +1,393 @@
+// Auto-generated from spec "github.com/sanjit-bhat/pav/serde/testdata/more/more.go"
+// using compiler "github.com/sanjit-bhat/pav/serde".
+package serde
+
+import (
+	"slices"
+
+	"github.com/sanjit-bhat/pav/safemarshal"
+	"github.com/tchajed/marshal"
+)
+
+func innerEncode(b0 []byte, o *inner) []byte {
+	var b = b0
+	b = marshal.WriteInt(b, o.a1)
+	return b
+}
+func innerDecode(b0 []byte) (*inner, []byte, bool) {
+	a1, b1, err1 := safemarshal.ReadInt(b0)
+	if err1 {
+		return nil, nil, true
+	}
+	return &inner{a1: a1}, b1, false
+}
+func argsEncode(b0 []byte, o *args) []byte {
+	var b = b0
+	b = safemarshal.WriteInt16(b, o.a1)
+	b = marshal.WriteInt32(b, o.a2)
+	b = safemarshal.WriteInt64(b, o.a3)
+	b = safemarshal.WriteString(b, o.a4)
+	b = Arr32byteEncode(b, o.a5)
+	b = Arr32byteEncode(b, o.a6)
+	b = Arr2uint64Encode(b, o.a7)
+	b = Sluint64Encode(b, o.a8)
+	b = SlstringEncode(b, o.a9)
+	b = SlSlSlSlbyteEncode(b, o.a10)
+	b = MapstringSlbyteEncode(b, o.a11)
+	b = Mapuint32innerEncode(b, o.a12)
+	b = SlArr2innerEncode(b, o.a13)
+	return b
+}
+func argsDecode(b0 []byte) (*args, []byte, bool) {
+	a1, b1, err1 := safemarshal.ReadInt16(b0)
+	if err1 {
+		return nil, nil, true
+	}
+	a2, b2, err2 := safemarshal.ReadInt32(b1)
+	if err2 {
+		return nil, nil, true
+	}
+	a3, b3, err3 := safemarshal.ReadInt64(b2)
+	if err3 {
+		return nil, nil, true
+	}
+	a4, b4, err4 := safemarshal.ReadString(b3)
+	if err4 {
+		return nil, nil, true
+	}
+	a5, b5, err5 := Arr32byteDecode(b4)
+	if err5 {
+		return nil, nil, true
+	}
+	a6, b6, err6 := Arr32byteDecode(b5)
+	if err6 {
+		return nil, nil, true
+	}
+	a7, b7, err7 := Arr2uint64Decode(b6)
+	if err7 {
+		return nil, nil, true
+	}
+	a8, b8, err8 := Sluint64Decode(b7)
+	if err8 {
+		return nil, nil, true
+	}
+	a9, b9, err9 := SlstringDecode(b8)
+	if err9 {
+		return nil, nil, true
+	}
+	a10, b10, err10 := SlSlSlSlbyteDecode(b9)
+	if err10 {
+		return nil, nil, true
+	}
+	a11, b11, err11 := MapstringSlbyteDecode(b10)
+	if err11 {
+		return nil, nil, true
+	}
+	a12, b12, err12 := Mapuint32innerDecode(b11)
+	if err12 {
+		return nil, nil, true
+	}
+	a13, b13, err13 := SlArr2innerDecode(b12)
+	if err13 {
+		return nil, nil, true
+	}
+	return &args{a1: a1, a2: a2, a3: a3, a4: a4, a5: a5, a6: a6, a7: a7, a8: a8, a9: a9, a10: a10, a11: a11, a12: a12, a13: a13}, b13, false
+}
+func constsEncode(b0 []byte, o *consts) []byte {
+	var b = b0
+	b = safemarshal.WriteInt16(b, 3)
+	b = marshal.WriteInt32(b, 3)
+	b = safemarshal.WriteInt64(b, 3)
+	return b
+}
+func constsDecode(b0 []byte) (*consts, []byte, bool) {
+	b1, err1 := safemarshal.ReadConstInt16(b0, 3)
+	if err1 {
+		return nil, nil, true
+	}
+	b2, err2 := safemarshal.ReadConstInt32(b1, 3)
+	if err2 {
+		return nil, nil, true
+	}
+	b3, err3 := safemarshal.ReadConstInt64(b2, 3)
+	if err3 {
+		return nil, nil, true
+	}
+	return &consts{a1: 3, a2: 3, a3: 3}, b3, false
+}
+func Arr32byteEncode(b0 []byte, o [32]byte) []byte {
+	return marshal.WriteBytes(b0, o[:])
+}
+func Arr2uint64Encode(b0 []byte, o [2]uint64) []byte {
+	var b = b0
+	for _, e := range o {
+		b = marshal.WriteInt(b, e)
+	}
+	return b
+}
+func Sluint64Encode(b0 []byte, o []uint64) []byte {
+	var b = b0
+	b = marshal.WriteInt(b, uint64(len(o)))
+	for _, e := range o {
+		b = marshal.WriteInt(b, e)
+	}
+	return b
+}
+func SlstringEncode(b0 []byte, o []string) []byte {
+	var b = b0
+	b = marshal.WriteInt(b, uint64(len(o)))
+	for _, e := range o {
+		b = safemarshal.WriteString(b, e)
+	}
+	return b
+}
+func SlSlSlSlbyteEncode(b0 []byte, o [][][][]byte) []byte {
+	var b = b0
+	b = marshal.WriteInt(b, uint64(len(o)))
+	for _, e := range o {
+		b = safemarshal.WriteSlice3D(b, e)
+	}
+	return b
+}
+func MapstringSlbyteEncode(b0 []byte, o map[string][]byte) []byte {
+	var b = b0
+	var keys []string
+	for k := range o {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	b = marshal.WriteInt(b, uint64(len(keys)))
+	for _, k := range keys {
+		b = safemarshal.WriteString(b, k)
+		b = safemarshal.WriteSlice1D(b, o[k])
+	}
+	return b
+}
+func Mapuint32innerEncode(b0 []byte, o map[uint32]*inner) []byte {
+	var b = b0
+	var keys []uint32
+	for k := range o {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	b = marshal.WriteInt(b, uint64(len(keys)))
+	for _, k := range keys {
+		b = marshal.WriteInt32(b, k)
+		b = innerEncode(b, o[k])
+	}
+	return b
+}
+func Arr2innerEncode(b0 []byte, o [2]*inner) []byte {
+	var b = b0
+	for _, e := range o {
+		b = innerEncode(b, e)
+	}
+	return b
+}
+func SlArr2innerEncode(b0 []byte, o [][2]*inner) []byte {
+	var b = b0
+	b = marshal.WriteInt(b, uint64(len(o)))
+	for _, e := range o {
+		b = Arr2innerEncode(b, e)
+	}
+	return b
+}
+func Arr32byteDecode(b0 []byte) ([32]byte, []byte, bool) {
+	var o [32]byte
+	a1, b1, err1 := safemarshal.ReadBytes(b0, 32)
+	if err1 {
+		return o, nil, true
+	}
+	copy(o[:], a1)
+	return o, b1, false
+}
+func Arr2uint64Decode(b0 []byte) ([2]uint64, []byte, bool) {
+	var loopO [2]uint64
+	var loopErr bool
+	var loopB = b0
+	for i := uint64(0); i < 2; i++ {
+		a1, loopB1, err1 := safemarshal.ReadInt(loopB)
+		loopB = loopB1
+		if err1 {
+			loopErr = true
+			break
+		}
+		loopO[i] = a1
+	}
+	if loopErr {
+		return loopO, nil, true
+	}
+	return loopO, loopB, false
+}
+func Sluint64Decode(b0 []byte) ([]uint64, []byte, bool) {
+	length, b1, err1 := safemarshal.ReadInt(b0)
+	if err1 {
+		return nil, nil, true
+	}
+	var loopO []uint64
+	var loopErr bool
+	var loopB = b1
+	for i := uint64(0); i < length; i++ {
+		a2, loopB1, err2 := safemarshal.ReadInt(loopB)
+		loopB = loopB1
+		if err2 {
+			loopErr = true
+			break
+		}
+		loopO = append(loopO, a2)
+	}
+	if loopErr {
+		return nil, nil, true
+	}
+	return loopO, loopB, false
+}
+func SlstringDecode(b0 []byte) ([]string, []byte, bool) {
+	length, b1, err1 := safemarshal.ReadInt(b0)
+	if err1 {
+		return nil, nil, true
+	}
+	var loopO []string
+	var loopErr bool
+	var loopB = b1
+	for i := uint64(0); i < length; i++ {
+		a2, loopB1, err2 := safemarshal.ReadString(loopB)
+		loopB = loopB1
+		if err2 {
+			loopErr = true
+			break
+		}
+		loopO = append(loopO, a2)
+	}
+	if loopErr {
+		return nil, nil, true
+	}
+	return loopO, loopB, false
+}
+func SlSlSlSlbyteDecode(b0 []byte) ([][][][]byte, []byte, bool) {
+	length, b1, err1 := safemarshal.ReadInt(b0)
+	if err1 {
+		return nil, nil, true
+	}
+	var loopO [][][][]byte
+	var loopErr bool
+	var loopB = b1
+	for i := uint64(0); i < length; i++ {
+		a2, loopB1, err2 := safemarshal.ReadSlice3D(loopB)
+		loopB = loopB1
+		if err2 {
+			loopErr = true
+			break
+		}
+		loopO = append(loopO, a2)
+	}
+	if loopErr {
+		return nil, nil, true
+	}
+	return loopO, loopB, false
+}
+func MapstringSlbyteDecode(b0 []byte) (map[string][]byte, []byte, bool) {
+	length, b1, err1 := safemarshal.ReadInt(b0)
+	if err1 {
+		return nil, nil, true
+	}
+	var loopO = make(map[string][]byte)
+	var loopErr bool
+	var loopB = b1
+	var loopPrev string
+	for i := uint64(0); i < length; i++ {
+		a2, loopB1, err2 := safemarshal.ReadString(loopB)
+		if err2 {
+			loopErr = true
+			break
+		}
+		if i != 0 && a2 <= loopPrev {
+			loopErr = true
+			break
+		}
+		a3, loopB2, err3 := safemarshal.ReadSlice1D(loopB1)
+		if err3 {
+			loopErr = true
+			break
+		}
+		loopO[a2] = a3
+		loopPrev = a2
+		loopB = loopB2
+	}
+	if loopErr {
+		return nil, nil, true
+	}
+	return loopO, loopB, false
+}
+func Mapuint32innerDecode(b0 []byte) (map[uint32]*inner, []byte, bool) {
+	length, b1, err1 := safemarshal.ReadInt(b0)
+	if err1 {
+		return nil, nil, true
+	}
+	var loopO = make(map[uint32]*inner)
+	var loopErr bool
+	var loopB = b1
+	var loopPrev uint32
+	for i := uint64(0); i < length; i++ {
+		a2, loopB1, err2 := safemarshal.ReadInt32(loopB)
+		if err2 {
+			loopErr = true
+			break
+		}
+		if i != 0 && a2 <= loopPrev {
+			loopErr = true
+			break
+		}
+		a3, loopB2, err3 := innerDecode(loopB1)
+		if err3 {
+			loopErr = true
+			break
+		}
+		loopO[a2] = a3
+		loopPrev = a2
+		loopB = loopB2
+	}
+	if loopErr {
+		return nil, nil, true
+	}
+	return loopO, loopB, false
+}
+func Arr2innerDecode(b0 []byte) ([2]*inner, []byte, bool) {
+	var loopO [2]*inner
+	var loopErr bool
+	var loopB = b0
+	for i := uint64(0); i < 2; i++ {
+		a1, loopB1, err1 := innerDecode(loopB)
+		loopB = loopB1
+		if err1 {
+			loopErr = true
+			break
+		}
+		loopO[i] = a1
+	}
+	if loopErr {
+		return loopO, nil, true
+	}
+	return loopO, loopB, false
+}
+func SlArr2innerDecode(b0 []byte) ([][2]*inner, []byte, bool) {
+	length, b1, err1 := safemarshal.ReadInt(b0)
+	if err1 {
+		return nil, nil, true
+	}
+	var loopO [][2]*inner
+	var loopErr bool
+	var loopB = b1
+	for i := uint64(0); i < length; i++ {
+		a2, loopB1, err2 := Arr2innerDecode(loopB)
+		loopB = loopB1
+		if err2 {
+			loopErr = true
+			break
+		}
+		loopO = append(loopO, a2)
+	}
+	if loopErr {
+		return nil, nil, true
+	}
+	return loopO, loopB, false
+}
@@ -0,0 +1,221 @@
+//go:build !goose
+
+// Auto-generated from spec "github.com/sanjit-bhat/pav/serde/testdata/more/more.go"
+// using compiler "github.com/sanjit-bhat/pav/serde".
+package serde
+
+import "github.com/sanjit-bhat/pav/safemarshal/stream"
+
+func innerDecodeStream(r *stream.Reader) (*inner, bool) {
+	a1, err1 := r.Int()
+	if err1 {
+		return nil, true
+	}
+	return &inner{a1: a1}, false
+}
+func argsDecodeStream(r *stream.Reader) (*args, bool) {
+	a1, err1 := r.Int16()
+	if err1 {
+		return nil, true
+	}
+	a2, err2 := r.Int32()
+	if err2 {
+		return nil, true
+	}
+	a3, err3 := r.Int64()
+	if err3 {
+		return nil, true
+	}
+	a4, err4 := r.String()
+	if err4 {
+		return nil, true
+	}
+	a5, err5 := Arr32byteDecodeStream(r)
+	if err5 {
+		return nil, true
+	}
+	a6, err6 := Arr32byteDecodeStream(r)
+	if err6 {
+		return nil, true
+	}
+	a7, err7 := Arr2uint64DecodeStream(r)
+	if err7 {
+		return nil, true
+	}
+	a8, err8 := Sluint64DecodeStream(r)
+	if err8 {
+		return nil, true
+	}
+	a9, err9 := SlstringDecodeStream(r)
+	if err9 {
+		return nil, true
+	}
+	a10, err10 := SlSlSlSlbyteDecodeStream(r)
+	if err10 {
+		return nil, true
+	}
+	a11, err11 := MapstringSlbyteDecodeStream(r)
+	if err11 {
+		return nil, true
+	}
+	a12, err12 := Mapuint32innerDecodeStream(r)
+	if err12 {
+		return nil, true
+	}
+	a13, err13 := SlArr2innerDecodeStream(r)
+	if err13 {
+		return nil, true
+	}
+	return &args{a1: a1, a2: a2, a3: a3, a4: a4, a5: a5, a6: a6, a7: a7, a8: a8, a9: a9, a10: a10, a11: a11, a12: a12, a13: a13}, false
+}
+func constsDecodeStream(r *stream.Reader) (*consts, bool) {
+	if r.ConstInt16(3) {
+		return nil, true
+	}
+	if r.ConstInt32(3) {
+		return nil, true
+	}
+	if r.ConstInt64(3) {
+		return nil, true
+	}
+	return &consts{a1: 3, a2: 3, a3: 3}, false
+}
+func Arr32byteDecodeStream(r *stream.Reader) ([32]byte, bool) {
+	var o [32]byte
+	a1, err1 := r.Bytes(32)
+	if err1 {
+		return o, true
+	}
+	copy(o[:], a1)
+	return o, false
+}
+func Arr2uint64DecodeStream(r *stream.Reader) ([2]uint64, bool) {
+	var loopO [2]uint64
+	for i := uint64(0); i < 2; i++ {
+		a1, err1 := r.Int()
+		if err1 {
+			return loopO, true
+		}
+		loopO[i] = a1
+	}
+	return loopO, false
+}
+func Sluint64DecodeStream(r *stream.Reader) ([]uint64, bool) {
+	length, err1 := r.Count(8)
+	if err1 {
+		return nil, true
+	}
+	var loopO []uint64
+	for i := uint64(0); i < length; i++ {
+		a2, err2 := r.Int()
+		if err2 {
+			return nil, true
+		}
+		loopO = append(loopO, a2)
+	}
+	return loopO, false
+}
+func SlstringDecodeStream(r *stream.Reader) ([]string, bool) {
+	length, err1 := r.Count(8)
+	if err1 {
+		return nil, true
+	}
+	var loopO []string
+	for i := uint64(0); i < length; i++ {
+		a2, err2 := r.String()
+		if err2 {
+			return nil, true
+		}
+		loopO = append(loopO, a2)
+	}
+	return loopO, false
+}
+func SlSlSlSlbyteDecodeStream(r *stream.Reader) ([][][][]byte, bool) {
+	length, err1 := r.Count(8)
+	if err1 {
+		return nil, true
+	}
+	var loopO [][][][]byte
+	for i := uint64(0); i < length; i++ {
+		a2, err2 := r.Slice3D()
+		if err2 {
+			return nil, true
+		}
+		loopO = append(loopO, a2)
+	}
+	return loopO, false
+}
+func MapstringSlbyteDecodeStream(r *stream.Reader) (map[string][]byte, bool) {
+	length, err1 := r.Count(16)
+	if err1 {
+		return nil, true
+	}
+	var loopO = make(map[string][]byte)
+	var loopPrev string
+	for i := uint64(0); i < length; i++ {
+		a2, err2 := r.String()
+		if err2 {
+			return nil, true
+		}
+		if i != 0 && a2 <= loopPrev {
+			return nil, true
+		}
+		a3, err3 := r.Slice1D()
+		if err3 {
+			return nil, true
+		}
+		loopO[a2] = a3
+		loopPrev = a2
+	}
+	return loopO, false
+}
+func Mapuint32innerDecodeStream(r *stream.Reader) (map[uint32]*inner, bool) {
+	length, err1 := r.Count(12)
+	if err1 {
+		return nil, true
+	}
+	var loopO = make(map[uint32]*inner)
+	var loopPrev uint32
+	for i := uint64(0); i < length; i++ {
+		a2, err2 := r.Int32()
+		if err2 {
+			return nil, true
+		}
+		if i != 0 && a2 <= loopPrev {
+			return nil, true
+		}
+		a3, err3 := innerDecodeStream(r)
+		if err3 {
+			return nil, true
+		}
+		loopO[a2] = a3
+		loopPrev = a2
+	}
+	return loopO, false
+}
+func SlArr2innerDecodeStream(r *stream.Reader) ([][2]*inner, bool) {
+	length, err1 := r.Count(16)
+	if err1 {
+		return nil, true
+	}
+	var loopO [][2]*inner
+	for i := uint64(0); i < length; i++ {
+		a2, err2 := Arr2innerDecodeStream(r)
+		if err2 {
+			return nil, true
+		}
+		loopO = append(loopO, a2)
+	}
+	return loopO, false
+}
+func Arr2innerDecodeStream(r *stream.Reader) ([2]*inner, bool) {
+	var loopO [2]*inner
+	for i := uint64(0); i < 2; i++ {
+		a1, err1 := innerDecodeStream(r)
+		if err1 {
+			return loopO, true
+		}
+		loopO[i] = a1
+	}
+	return loopO, false
+}
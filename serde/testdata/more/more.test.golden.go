@@ -0,0 +1,281 @@
+// Auto-generated from spec "github.com/sanjit-bhat/pav/serde/testdata/more/more.go"
+// using compiler "github.com/sanjit-bhat/pav/serde".
+package serde
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"math/rand/v2"
+	"os"
+	"testing"
+)
+
+func FuzzInnerDecode(f *testing.F) {
+	rnd := rand.New(rand.NewPCG(0, 0))
+	for i := 0; i < 4; i++ {
+		f.Add(innerEncode(nil, randInner(rnd)))
+	}
+	f.Fuzz(func(t *testing.T, b []byte) {
+		x, _, err := innerDecode(b)
+		if err {
+			return
+		}
+		b0 := innerEncode(nil, x)
+		y, rem, err := innerDecode(b0)
+		if err || len(rem) != 0 {
+			t.Fatal()
+		}
+		if !bytes.Equal(b0, innerEncode(nil, y)) {
+			t.Fatal()
+		}
+	})
+}
+func TestInnerRoundTrip(t *testing.T) {
+	rnd := rand.New(rand.NewPCG(0, 0))
+	for i := 0; i < 100; i++ {
+		b := innerEncode(nil, randInner(rnd))
+		x, rem, err := innerDecode(b)
+		if err || len(rem) != 0 {
+			t.Fatal()
+		}
+		if !bytes.Equal(b, innerEncode(nil, x)) {
+			t.Fatal()
+		}
+	}
+}
+func FuzzArgsDecode(f *testing.F) {
+	rnd := rand.New(rand.NewPCG(0, 0))
+	for i := 0; i < 4; i++ {
+		f.Add(argsEncode(nil, randArgs(rnd)))
+	}
+	f.Fuzz(func(t *testing.T, b []byte) {
+		x, _, err := argsDecode(b)
+		if err {
+			return
+		}
+		b0 := argsEncode(nil, x)
+		y, rem, err := argsDecode(b0)
+		if err || len(rem) != 0 {
+			t.Fatal()
+		}
+		if !bytes.Equal(b0, argsEncode(nil, y)) {
+			t.Fatal()
+		}
+	})
+}
+func TestArgsRoundTrip(t *testing.T) {
+	rnd := rand.New(rand.NewPCG(0, 0))
+	for i := 0; i < 100; i++ {
+		b := argsEncode(nil, randArgs(rnd))
+		x, rem, err := argsDecode(b)
+		if err || len(rem) != 0 {
+			t.Fatal()
+		}
+		if !bytes.Equal(b, argsEncode(nil, x)) {
+			t.Fatal()
+		}
+	}
+}
+func FuzzConstsDecode(f *testing.F) {
+	rnd := rand.New(rand.NewPCG(0, 0))
+	for i := 0; i < 4; i++ {
+		f.Add(constsEncode(nil, randConsts(rnd)))
+	}
+	f.Fuzz(func(t *testing.T, b []byte) {
+		x, _, err := constsDecode(b)
+		if err {
+			return
+		}
+		b0 := constsEncode(nil, x)
+		y, rem, err := constsDecode(b0)
+		if err || len(rem) != 0 {
+			t.Fatal()
+		}
+		if !bytes.Equal(b0, constsEncode(nil, y)) {
+			t.Fatal()
+		}
+	})
+}
+func TestConstsRoundTrip(t *testing.T) {
+	rnd := rand.New(rand.NewPCG(0, 0))
+	for i := 0; i < 100; i++ {
+		b := constsEncode(nil, randConsts(rnd))
+		x, rem, err := constsDecode(b)
+		if err || len(rem) != 0 {
+			t.Fatal()
+		}
+		if !bytes.Equal(b, constsEncode(nil, x)) {
+			t.Fatal()
+		}
+	}
+}
+func randInner(rnd *rand.Rand) *inner {
+	o := &inner{}
+	o.a1 = rnd.Uint64()
+	return o
+}
+func randArgs(rnd *rand.Rand) *args {
+	o := &args{}
+	o.a1 = uint16(rnd.Uint32())
+	o.a2 = rnd.Uint32()
+	o.a3 = rnd.Int64()
+	o.a4 = string(randBytes(rnd, rnd.IntN(4)))
+	o.a5 = randArr32byte(rnd)
+	o.a6 = randArr32byte(rnd)
+	o.a7 = randArr2uint64(rnd)
+	o.a8 = randSluint64(rnd, rnd.IntN(4))
+	o.a9 = randSlstring(rnd, rnd.IntN(4))
+	o.a10 = randSlSlSlSlbyte(rnd, rnd.IntN(4))
+	o.a11 = randMapstringSlbyte(rnd)
+	o.a12 = randMapuint32inner(rnd)
+	o.a13 = randSlArr2inner(rnd, rnd.IntN(4))
+	return o
+}
+func randConsts(rnd *rand.Rand) *consts {
+	o := &consts{}
+	o.a1 = 3
+	o.a2 = 3
+	o.a3 = 3
+	return o
+}
+func randArr32byte(rnd *rand.Rand) [32]byte {
+	var x [32]byte
+	for i := range x {
+		x[i] = byte(rnd.Uint32())
+	}
+	return x
+}
+func randArr2uint64(rnd *rand.Rand) [2]uint64 {
+	var x [2]uint64
+	for i := range x {
+		x[i] = rnd.Uint64()
+	}
+	return x
+}
+func randSluint64(rnd *rand.Rand, n int) []uint64 {
+	var x []uint64
+	for i := 0; i < n; i++ {
+		x = append(x, rnd.Uint64())
+	}
+	return x
+}
+func randSlstring(rnd *rand.Rand, n int) []string {
+	var x []string
+	for i := 0; i < n; i++ {
+		x = append(x, string(randBytes(rnd, rnd.IntN(4))))
+	}
+	return x
+}
+func randSlSlSlSlbyte(rnd *rand.Rand, n int) [][][][]byte {
+	var x [][][][]byte
+	for i := 0; i < n; i++ {
+		x = append(x, randSlSlSlbyte(rnd, rnd.IntN(4)))
+	}
+	return x
+}
+func randMapstringSlbyte(rnd *rand.Rand) map[string][]byte {
+	x := make(map[string][]byte)
+	n := rnd.IntN(4)
+	for i := 0; i < n; i++ {
+		x[string(randBytes(rnd, rnd.IntN(4)))] = randBytes(rnd, rnd.IntN(4))
+	}
+	return x
+}
+func randMapuint32inner(rnd *rand.Rand) map[uint32]*inner {
+	x := make(map[uint32]*inner)
+	n := rnd.IntN(4)
+	for i := 0; i < n; i++ {
+		x[rnd.Uint32()] = randInner(rnd)
+	}
+	return x
+}
+func randSlArr2inner(rnd *rand.Rand, n int) [][2]*inner {
+	var x [][2]*inner
+	for i := 0; i < n; i++ {
+		x = append(x, randArr2inner(rnd))
+	}
+	return x
+}
+func randSlSlSlbyte(rnd *rand.Rand, n int) [][][]byte {
+	var x [][][]byte
+	for i := 0; i < n; i++ {
+		x = append(x, randSlSlbyte(rnd, rnd.IntN(4)))
+	}
+	return x
+}
+func randArr2inner(rnd *rand.Rand) [2]*inner {
+	var x [2]*inner
+	for i := range x {
+		x[i] = randInner(rnd)
+	}
+	return x
+}
+func randSlSlbyte(rnd *rand.Rand, n int) [][]byte {
+	var x [][]byte
+	for i := 0; i < n; i++ {
+		x = append(x, randBytes(rnd, rnd.IntN(4)))
+	}
+	return x
+}
+func randBytes(rnd *rand.Rand, n int) []byte {
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = byte(rnd.Uint32())
+	}
+	return b
+}
+func vectorCodec(name string, b []byte) (enc []byte, err bool) {
+	switch name {
+	case "inner":
+		x, rem, errb := innerDecode(b)
+		if errb || len(rem) != 0 {
+			return nil, true
+		}
+		return innerEncode(nil, x), false
+	case "args":
+		x, rem, errb := argsDecode(b)
+		if errb || len(rem) != 0 {
+			return nil, true
+		}
+		return argsEncode(nil, x), false
+	case "consts":
+		x, rem, errb := constsDecode(b)
+		if errb || len(rem) != 0 {
+			return nil, true
+		}
+		return constsEncode(nil, x), false
+	}
+	return nil, true
+}
+func TestVectors(t *testing.T) {
+	data, err := os.ReadFile("more.vectors.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var vs struct {
+		Vectors []struct {
+			Name, Struct, Hex string
+		}
+	}
+	err = json.Unmarshal(data, &vs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(vs.Vectors) == 0 {
+		t.Fatal("no vectors")
+	}
+	for _, v := range vs.Vectors {
+		b, err := hex.DecodeString(v.Hex)
+		if err != nil {
+			t.Fatal(err)
+		}
+		enc, errb := vectorCodec(v.Struct, b)
+		if errb {
+			t.Fatalf("%s: decode failed", v.Name)
+		}
+		if !bytes.Equal(b, enc) {
+			t.Fatalf("%s: encoding differs", v.Name)
+		}
+	}
+}
@@ -0,0 +1,11 @@
+package multi
+
+type body struct {
+	data []byte
+	tags map[string]uint32
+}
+
+type sig struct {
+	// serde: len 64.
+	data []byte
+}
@@ -0,0 +1,8 @@
+package multi
+
+type msg struct {
+	id   uint64
+	body *body
+	// serde: optional.
+	sig *sig
+}
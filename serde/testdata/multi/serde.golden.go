@@ -0,0 +1,124 @@
+// Auto-generated from specs "github.com/sanjit-bhat/pav/serde/testdata/multi/serde.go", "github.com/sanjit-bhat/pav/serde/testdata/multi/msg_serde.go"
+// using compiler "github.com/sanjit-bhat/pav/serde".
+package multi
+
+import (
+	"slices"
+
+	"github.com/sanjit-bhat/pav/safemarshal"
+	"github.com/tchajed/marshal"
+)
+
+func msgEncode(b0 []byte, o *msg) []byte {
+	var b = b0
+	b = marshal.WriteInt(b, o.id)
+	b = bodyEncode(b, o.body)
+	b = marshal.WriteBool(b, o.sig != nil)
+	if o.sig != nil {
+		b = sigEncode(b, o.sig)
+	}
+	return b
+}
+func msgDecode(b0 []byte) (*msg, []byte, bool) {
+	a1, b1, err1 := safemarshal.ReadInt(b0)
+	if err1 {
+		return nil, nil, true
+	}
+	a2, b2, err2 := bodyDecode(b1)
+	if err2 {
+		return nil, nil, true
+	}
+	var a3 *sig
+	isSet3, b3, err3 := safemarshal.ReadBool(b2)
+	if err3 {
+		return nil, nil, true
+	}
+	if isSet3 {
+		x, rem, err := sigDecode(b3)
+		if err {
+			return nil, nil, true
+		}
+		a3 = x
+		b3 = rem
+	}
+	return &msg{id: a1, body: a2, sig: a3}, b3, false
+}
+func bodyEncode(b0 []byte, o *body) []byte {
+	var b = b0
+	b = safemarshal.WriteSlice1D(b, o.data)
+	b = Mapstringuint32Encode(b, o.tags)
+	return b
+}
+func bodyDecode(b0 []byte) (*body, []byte, bool) {
+	a1, b1, err1 := safemarshal.ReadSlice1D(b0)
+	if err1 {
+		return nil, nil, true
+	}
+	a2, b2, err2 := Mapstringuint32Decode(b1)
+	if err2 {
+		return nil, nil, true
+	}
+	return &body{data: a1, tags: a2}, b2, false
+}
+func sigEncode(b0 []byte, o *sig) []byte {
+	var b = b0
+	b = safemarshal.WriteSlice1D(b, o.data)
+	return b
+}
+func sigDecode(b0 []byte) (*sig, []byte, bool) {
+	a1, b1, err1 := safemarshal.ReadSlice1D(b0)
+	if err1 {
+		return nil, nil, true
+	}
+	if uint64(len(a1)) != 64 {
+		return nil, nil, true
+	}
+	return &sig{data: a1}, b1, false
+}
+func Mapstringuint32Encode(b0 []byte, o map[string]uint32) []byte {
+	var b = b0
+	var keys []string
+	for k := range o {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	b = marshal.WriteInt(b, uint64(len(keys)))
+	for _, k := range keys {
+		b = safemarshal.WriteString(b, k)
+		b = marshal.WriteInt32(b, o[k])
+	}
+	return b
+}
+func Mapstringuint32Decode(b0 []byte) (map[string]uint32, []byte, bool) {
+	length, b1, err1 := safemarshal.ReadInt(b0)
+	if err1 {
+		return nil, nil, true
+	}
+	var loopO = make(map[string]uint32)
+	var loopErr bool
+	var loopB = b1
+	var loopPrev string
+	for i := uint64(0); i < length; i++ {
+		a2, loopB1, err2 := safemarshal.ReadString(loopB)
+		if err2 {
+			loopErr = true
+			break
+		}
+		if i != 0 && a2 <= loopPrev {
+			loopErr = true
+			break
+		}
+		a3, loopB2, err3 := safemarshal.ReadInt32(loopB1)
+		if err3 {
+			loopErr = true
+			break
+		}
+		loopO[a2] = a3
+		loopPrev = a2
+		loopB = loopB2
+	}
+	if loopErr {
+		return nil, nil, true
+	}
+	return loopO, loopB, false
+}
@@ -3,6 +3,8 @@
 package serde
 
 import (
+	"slices"
+
 	"github.com/sanjit-bhat/pav/safemarshal"
 	"github.com/tchajed/marshal"
 )
@@ -41,3 +43,80 @@ func outerDecode(b0 []byte) (*outer, []byte, bool) {
 	}
 	return &outer{a1: a1, a2: a2, a3: a3}, b3, false
 }
+func innerSlice1DEncode(b0 []byte, o []*inner) []byte {
+	var b = b0
+	b = marshal.WriteInt(b, uint64(len(o)))
+	for _, e := range o {
+		b = innerEncode(b, e)
+	}
+	return b
+}
+func Mapuint64innerEncode(b0 []byte, o map[uint64]*inner) []byte {
+	var b = b0
+	var keys []uint64
+	for k := range o {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	b = marshal.WriteInt(b, uint64(len(keys)))
+	for _, k := range keys {
+		b = marshal.WriteInt(b, k)
+		b = innerEncode(b, o[k])
+	}
+	return b
+}
+func innerSlice1DDecode(b0 []byte) ([]*inner, []byte, bool) {
+	length, b1, err1 := safemarshal.ReadInt(b0)
+	if err1 {
+		return nil, nil, true
+	}
+	var loopO []*inner
+	var loopErr bool
+	var loopB = b1
+	for i := uint64(0); i < length; i++ {
+		a2, loopB1, err2 := innerDecode(loopB)
+		loopB = loopB1
+		if err2 {
+			loopErr = true
+			break
+		}
+		loopO = append(loopO, a2)
+	}
+	if loopErr {
+		return nil, nil, true
+	}
+	return loopO, loopB, false
+}
+func Mapuint64innerDecode(b0 []byte) (map[uint64]*inner, []byte, bool) {
+	length, b1, err1 := safemarshal.ReadInt(b0)
+	if err1 {
+		return nil, nil, true
+	}
+	var loopO = make(map[uint64]*inner)
+	var loopErr bool
+	var loopB = b1
+	var loopPrev uint64
+	for i := uint64(0); i < length; i++ {
+		a2, loopB1, err2 := safemarshal.ReadInt(loopB)
+		if err2 {
+			loopErr = true
+			break
+		}
+		if i != 0 && a2 <= loopPrev {
+			loopErr = true
+			break
+		}
+		a3, loopB2, err3 := innerDecode(loopB1)
+		if err3 {
+			loopErr = true
+			break
+		}
+		loopO[a2] = a3
+		loopPrev = a2
+		loopB = loopB2
+	}
+	if loopErr {
+		return nil, nil, true
+	}
+	return loopO, loopB, false
+}
@@ -0,0 +1,19 @@
+package serde
+
+type inner struct {
+	a1 uint64
+}
+
+type outer struct {
+	// serde: optional.
+	a1 *inner
+	a2 []byte
+	// serde: optional.
+	a3 *inner
+}
+
+// serde: union.
+type choice struct {
+	a1 *inner
+	a2 *outer
+}
@@ -0,0 +1,100 @@
+// Auto-generated from spec "github.com/sanjit-bhat/pav/serde/testdata/option/option.go"
+// using compiler "github.com/sanjit-bhat/pav/serde".
+package serde
+
+import (
+	"github.com/sanjit-bhat/pav/safemarshal"
+	"github.com/tchajed/marshal"
+)
+
+func innerEncode(b0 []byte, o *inner) []byte {
+	var b = b0
+	b = marshal.WriteInt(b, o.a1)
+	return b
+}
+func innerDecode(b0 []byte) (*inner, []byte, bool) {
+	a1, b1, err1 := safemarshal.ReadInt(b0)
+	if err1 {
+		return nil, nil, true
+	}
+	return &inner{a1: a1}, b1, false
+}
+func outerEncode(b0 []byte, o *outer) []byte {
+	var b = b0
+	b = marshal.WriteBool(b, o.a1 != nil)
+	if o.a1 != nil {
+		b = innerEncode(b, o.a1)
+	}
+	b = safemarshal.WriteSlice1D(b, o.a2)
+	b = marshal.WriteBool(b, o.a3 != nil)
+	if o.a3 != nil {
+		b = innerEncode(b, o.a3)
+	}
+	return b
+}
+func outerDecode(b0 []byte) (*outer, []byte, bool) {
+	var a1 *inner
+	isSet1, b1, err1 := safemarshal.ReadBool(b0)
+	if err1 {
+		return nil, nil, true
+	}
+	if isSet1 {
+		x, rem, err := innerDecode(b1)
+		if err {
+			return nil, nil, true
+		}
+		a1 = x
+		b1 = rem
+	}
+	a2, b2, err2 := safemarshal.ReadSlice1D(b1)
+	if err2 {
+		return nil, nil, true
+	}
+	var a3 *inner
+	isSet3, b3, err3 := safemarshal.ReadBool(b2)
+	if err3 {
+		return nil, nil, true
+	}
+	if isSet3 {
+		x, rem, err := innerDecode(b3)
+		if err {
+			return nil, nil, true
+		}
+		a3 = x
+		b3 = rem
+	}
+	return &outer{a1: a1, a2: a2, a3: a3}, b3, false
+}
+func choiceEncode(b0 []byte, o *choice) []byte {
+	var b = b0
+	if o.a1 != nil && o.a2 == nil {
+		b = safemarshal.WriteByte(b, 0)
+		return innerEncode(b, o.a1)
+	}
+	if o.a1 == nil && o.a2 != nil {
+		b = safemarshal.WriteByte(b, 1)
+		return outerEncode(b, o.a2)
+	}
+	panic("serde: choice must have exactly one variant")
+}
+func choiceDecode(b0 []byte) (*choice, []byte, bool) {
+	tag, b1, err1 := safemarshal.ReadByte(b0)
+	if err1 {
+		return nil, nil, true
+	}
+	if tag == 0 {
+		x, b2, err2 := innerDecode(b1)
+		if err2 {
+			return nil, nil, true
+		}
+		return &choice{a1: x}, b2, false
+	}
+	if tag == 1 {
+		x, b2, err2 := outerDecode(b1)
+		if err2 {
+			return nil, nil, true
+		}
+		return &choice{a2: x}, b2, false
+	}
+	return nil, nil, true
+}
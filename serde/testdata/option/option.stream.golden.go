@@ -0,0 +1,67 @@
+//go:build !goose
+
+// Auto-generated from spec "github.com/sanjit-bhat/pav/serde/testdata/option/option.go"
+// using compiler "github.com/sanjit-bhat/pav/serde".
+package serde
+
+import "github.com/sanjit-bhat/pav/safemarshal/stream"
+
+func innerDecodeStream(r *stream.Reader) (*inner, bool) {
+	a1, err1 := r.Int()
+	if err1 {
+		return nil, true
+	}
+	return &inner{a1: a1}, false
+}
+func outerDecodeStream(r *stream.Reader) (*outer, bool) {
+	var a1 *inner
+	isSet1, err1 := r.Bool()
+	if err1 {
+		return nil, true
+	}
+	if isSet1 {
+		x, err := innerDecodeStream(r)
+		if err {
+			return nil, true
+		}
+		a1 = x
+	}
+	a2, err2 := r.Slice1D()
+	if err2 {
+		return nil, true
+	}
+	var a3 *inner
+	isSet3, err3 := r.Bool()
+	if err3 {
+		return nil, true
+	}
+	if isSet3 {
+		x, err := innerDecodeStream(r)
+		if err {
+			return nil, true
+		}
+		a3 = x
+	}
+	return &outer{a1: a1, a2: a2, a3: a3}, false
+}
+func choiceDecodeStream(r *stream.Reader) (*choice, bool) {
+	tag, err1 := r.Byte()
+	if err1 {
+		return nil, true
+	}
+	if tag == 0 {
+		x, err2 := innerDecodeStream(r)
+		if err2 {
+			return nil, true
+		}
+		return &choice{a1: x}, false
+	}
+	if tag == 1 {
+		x, err2 := outerDecodeStream(r)
+		if err2 {
+			return nil, true
+		}
+		return &choice{a2: x}, false
+	}
+	return nil, true
+}
@@ -0,0 +1,200 @@
+// Auto-generated from spec "github.com/sanjit-bhat/pav/serde/testdata/option/option.go"
+// using compiler "github.com/sanjit-bhat/pav/serde".
+package serde
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"math/rand/v2"
+	"os"
+	"testing"
+)
+
+func FuzzInnerDecode(f *testing.F) {
+	rnd := rand.New(rand.NewPCG(0, 0))
+	for i := 0; i < 4; i++ {
+		f.Add(innerEncode(nil, randInner(rnd)))
+	}
+	f.Fuzz(func(t *testing.T, b []byte) {
+		x, _, err := innerDecode(b)
+		if err {
+			return
+		}
+		b0 := innerEncode(nil, x)
+		y, rem, err := innerDecode(b0)
+		if err || len(rem) != 0 {
+			t.Fatal()
+		}
+		if !bytes.Equal(b0, innerEncode(nil, y)) {
+			t.Fatal()
+		}
+	})
+}
+func TestInnerRoundTrip(t *testing.T) {
+	rnd := rand.New(rand.NewPCG(0, 0))
+	for i := 0; i < 100; i++ {
+		b := innerEncode(nil, randInner(rnd))
+		x, rem, err := innerDecode(b)
+		if err || len(rem) != 0 {
+			t.Fatal()
+		}
+		if !bytes.Equal(b, innerEncode(nil, x)) {
+			t.Fatal()
+		}
+	}
+}
+func FuzzOuterDecode(f *testing.F) {
+	rnd := rand.New(rand.NewPCG(0, 0))
+	for i := 0; i < 4; i++ {
+		f.Add(outerEncode(nil, randOuter(rnd)))
+	}
+	f.Fuzz(func(t *testing.T, b []byte) {
+		x, _, err := outerDecode(b)
+		if err {
+			return
+		}
+		b0 := outerEncode(nil, x)
+		y, rem, err := outerDecode(b0)
+		if err || len(rem) != 0 {
+			t.Fatal()
+		}
+		if !bytes.Equal(b0, outerEncode(nil, y)) {
+			t.Fatal()
+		}
+	})
+}
+func TestOuterRoundTrip(t *testing.T) {
+	rnd := rand.New(rand.NewPCG(0, 0))
+	for i := 0; i < 100; i++ {
+		b := outerEncode(nil, randOuter(rnd))
+		x, rem, err := outerDecode(b)
+		if err || len(rem) != 0 {
+			t.Fatal()
+		}
+		if !bytes.Equal(b, outerEncode(nil, x)) {
+			t.Fatal()
+		}
+	}
+}
+func FuzzChoiceDecode(f *testing.F) {
+	rnd := rand.New(rand.NewPCG(0, 0))
+	for i := 0; i < 4; i++ {
+		f.Add(choiceEncode(nil, randChoice(rnd)))
+	}
+	f.Fuzz(func(t *testing.T, b []byte) {
+		x, _, err := choiceDecode(b)
+		if err {
+			return
+		}
+		b0 := choiceEncode(nil, x)
+		y, rem, err := choiceDecode(b0)
+		if err || len(rem) != 0 {
+			t.Fatal()
+		}
+		if !bytes.Equal(b0, choiceEncode(nil, y)) {
+			t.Fatal()
+		}
+	})
+}
+func TestChoiceRoundTrip(t *testing.T) {
+	rnd := rand.New(rand.NewPCG(0, 0))
+	for i := 0; i < 100; i++ {
+		b := choiceEncode(nil, randChoice(rnd))
+		x, rem, err := choiceDecode(b)
+		if err || len(rem) != 0 {
+			t.Fatal()
+		}
+		if !bytes.Equal(b, choiceEncode(nil, x)) {
+			t.Fatal()
+		}
+	}
+}
+func randInner(rnd *rand.Rand) *inner {
+	o := &inner{}
+	o.a1 = rnd.Uint64()
+	return o
+}
+func randOuter(rnd *rand.Rand) *outer {
+	o := &outer{}
+	if rnd.IntN(2) == 0 {
+		o.a1 = randInner(rnd)
+	}
+	o.a2 = randBytes(rnd, rnd.IntN(4))
+	if rnd.IntN(2) == 0 {
+		o.a3 = randInner(rnd)
+	}
+	return o
+}
+func randChoice(rnd *rand.Rand) *choice {
+	o := &choice{}
+	tag := rnd.IntN(2)
+	if tag == 0 {
+		o.a1 = randInner(rnd)
+	}
+	if tag == 1 {
+		o.a2 = randOuter(rnd)
+	}
+	return o
+}
+func randBytes(rnd *rand.Rand, n int) []byte {
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = byte(rnd.Uint32())
+	}
+	return b
+}
+func vectorCodec(name string, b []byte) (enc []byte, err bool) {
+	switch name {
+	case "inner":
+		x, rem, errb := innerDecode(b)
+		if errb || len(rem) != 0 {
+			return nil, true
+		}
+		return innerEncode(nil, x), false
+	case "outer":
+		x, rem, errb := outerDecode(b)
+		if errb || len(rem) != 0 {
+			return nil, true
+		}
+		return outerEncode(nil, x), false
+	case "choice":
+		x, rem, errb := choiceDecode(b)
+		if errb || len(rem) != 0 {
+			return nil, true
+		}
+		return choiceEncode(nil, x), false
+	}
+	return nil, true
+}
+func TestVectors(t *testing.T) {
+	data, err := os.ReadFile("option.vectors.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var vs struct {
+		Vectors []struct {
+			Name, Struct, Hex string
+		}
+	}
+	err = json.Unmarshal(data, &vs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(vs.Vectors) == 0 {
+		t.Fatal("no vectors")
+	}
+	for _, v := range vs.Vectors {
+		b, err := hex.DecodeString(v.Hex)
+		if err != nil {
+			t.Fatal(err)
+		}
+		enc, errb := vectorCodec(v.Struct, b)
+		if errb {
+			t.Fatalf("%s: decode failed", v.Name)
+		}
+		if !bytes.Equal(b, enc) {
+			t.Fatalf("%s: encoding differs", v.Name)
+		}
+	}
+}
@@ -0,0 +1,5 @@
+package other
+
+type Elem struct {
+	A1 uint64
+}
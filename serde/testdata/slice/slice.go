@@ -0,0 +1,22 @@
+package serde
+
+import (
+	"github.com/sanjit-bhat/pav/serde/testdata/slice/other"
+)
+
+type elem struct {
+	a1 uint64
+}
+
+type args struct {
+	a1 []*elem
+	a2 [][]*elem
+	a3 [][][][]*elem
+	a4 []*other.Elem
+	a5 *other.Elem
+}
+
+// serde: no decode needed.
+type encOnly struct {
+	a1 [][]*elem
+}
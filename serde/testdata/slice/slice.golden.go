@@ -0,0 +1,209 @@
+// Auto-generated from spec "github.com/sanjit-bhat/pav/serde/testdata/slice/slice.go"
+// using compiler "github.com/sanjit-bhat/pav/serde".
+package serde
+
+import (
+	"github.com/sanjit-bhat/pav/safemarshal"
+	"github.com/sanjit-bhat/pav/serde/testdata/slice/other"
+	"github.com/tchajed/marshal"
+)
+
+func elemEncode(b0 []byte, o *elem) []byte {
+	var b = b0
+	b = marshal.WriteInt(b, o.a1)
+	return b
+}
+func elemDecode(b0 []byte) (*elem, []byte, bool) {
+	a1, b1, err1 := safemarshal.ReadInt(b0)
+	if err1 {
+		return nil, nil, true
+	}
+	return &elem{a1: a1}, b1, false
+}
+func argsEncode(b0 []byte, o *args) []byte {
+	var b = b0
+	b = elemSlice1DEncode(b, o.a1)
+	b = elemSlice2DEncode(b, o.a2)
+	b = elemSlice4DEncode(b, o.a3)
+	b = ElemSlice1DEncode(b, o.a4)
+	b = other.ElemEncode(b, o.a5)
+	return b
+}
+func argsDecode(b0 []byte) (*args, []byte, bool) {
+	a1, b1, err1 := elemSlice1DDecode(b0)
+	if err1 {
+		return nil, nil, true
+	}
+	a2, b2, err2 := elemSlice2DDecode(b1)
+	if err2 {
+		return nil, nil, true
+	}
+	a3, b3, err3 := elemSlice4DDecode(b2)
+	if err3 {
+		return nil, nil, true
+	}
+	a4, b4, err4 := ElemSlice1DDecode(b3)
+	if err4 {
+		return nil, nil, true
+	}
+	a5, b5, err5 := other.ElemDecode(b4)
+	if err5 {
+		return nil, nil, true
+	}
+	return &args{a1: a1, a2: a2, a3: a3, a4: a4, a5: a5}, b5, false
+}
+func encOnlyEncode(b0 []byte, o *encOnly) []byte {
+	var b = b0
+	b = elemSlice2DEncode(b, o.a1)
+	return b
+}
+func elemSlice1DEncode(b0 []byte, o []*elem) []byte {
+	var b = b0
+	b = marshal.WriteInt(b, uint64(len(o)))
+	for _, e := range o {
+		b = elemEncode(b, e)
+	}
+	return b
+}
+func elemSlice2DEncode(b0 []byte, o [][]*elem) []byte {
+	var b = b0
+	b = marshal.WriteInt(b, uint64(len(o)))
+	for _, e := range o {
+		b = elemSlice1DEncode(b, e)
+	}
+	return b
+}
+func elemSlice3DEncode(b0 []byte, o [][][]*elem) []byte {
+	var b = b0
+	b = marshal.WriteInt(b, uint64(len(o)))
+	for _, e := range o {
+		b = elemSlice2DEncode(b, e)
+	}
+	return b
+}
+func elemSlice4DEncode(b0 []byte, o [][][][]*elem) []byte {
+	var b = b0
+	b = marshal.WriteInt(b, uint64(len(o)))
+	for _, e := range o {
+		b = elemSlice3DEncode(b, e)
+	}
+	return b
+}
+func ElemSlice1DEncode(b0 []byte, o []*other.Elem) []byte {
+	var b = b0
+	b = marshal.WriteInt(b, uint64(len(o)))
+	for _, e := range o {
+		b = other.ElemEncode(b, e)
+	}
+	return b
+}
+func elemSlice1DDecode(b0 []byte) ([]*elem, []byte, bool) {
+	length, b1, err1 := safemarshal.ReadInt(b0)
+	if err1 {
+		return nil, nil, true
+	}
+	var loopO []*elem
+	var loopErr bool
+	var loopB = b1
+	for i := uint64(0); i < length; i++ {
+		a2, loopB1, err2 := elemDecode(loopB)
+		loopB = loopB1
+		if err2 {
+			loopErr = true
+			break
+		}
+		loopO = append(loopO, a2)
+	}
+	if loopErr {
+		return nil, nil, true
+	}
+	return loopO, loopB, false
+}
+func elemSlice2DDecode(b0 []byte) ([][]*elem, []byte, bool) {
+	length, b1, err1 := safemarshal.ReadInt(b0)
+	if err1 {
+		return nil, nil, true
+	}
+	var loopO [][]*elem
+	var loopErr bool
+	var loopB = b1
+	for i := uint64(0); i < length; i++ {
+		a2, loopB1, err2 := elemSlice1DDecode(loopB)
+		loopB = loopB1
+		if err2 {
+			loopErr = true
+			break
+		}
+		loopO = append(loopO, a2)
+	}
+	if loopErr {
+		return nil, nil, true
+	}
+	return loopO, loopB, false
+}
+func elemSlice3DDecode(b0 []byte) ([][][]*elem, []byte, bool) {
+	length, b1, err1 := safemarshal.ReadInt(b0)
+	if err1 {
+		return nil, nil, true
+	}
+	var loopO [][][]*elem
+	var loopErr bool
+	var loopB = b1
+	for i := uint64(0); i < length; i++ {
+		a2, loopB1, err2 := elemSlice2DDecode(loopB)
+		loopB = loopB1
+		if err2 {
+			loopErr = true
+			break
+		}
+		loopO = append(loopO, a2)
+	}
+	if loopErr {
+		return nil, nil, true
+	}
+	return loopO, loopB, false
+}
+func elemSlice4DDecode(b0 []byte) ([][][][]*elem, []byte, bool) {
+	length, b1, err1 := safemarshal.ReadInt(b0)
+	if err1 {
+		return nil, nil, true
+	}
+	var loopO [][][][]*elem
+	var loopErr bool
+	var loopB = b1
+	for i := uint64(0); i < length; i++ {
+		a2, loopB1, err2 := elemSlice3DDecode(loopB)
+		loopB = loopB1
+		if err2 {
+			loopErr = true
+			break
+		}
+		loopO = append(loopO, a2)
+	}
+	if loopErr {
+		return nil, nil, true
+	}
+	return loopO, loopB, false
+}
+func ElemSlice1DDecode(b0 []byte) ([]*other.Elem, []byte, bool) {
+	length, b1, err1 := safemarshal.ReadInt(b0)
+	if err1 {
+		return nil, nil, true
+	}
+	var loopO []*other.Elem
+	var loopErr bool
+	var loopB = b1
+	for i := uint64(0); i < length; i++ {
+		a2, loopB1, err2 := other.ElemDecode(loopB)
+		loopB = loopB1
+		if err2 {
+			loopErr = true
+			break
+		}
+		loopO = append(loopO, a2)
+	}
+	if loopErr {
+		return nil, nil, true
+	}
+	return loopO, loopB, false
+}
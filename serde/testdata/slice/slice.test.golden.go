@@ -0,0 +1,139 @@
+// Auto-generated from spec "github.com/sanjit-bhat/pav/serde/testdata/slice/slice.go"
+// using compiler "github.com/sanjit-bhat/pav/serde".
+package serde
+
+import (
+	"bytes"
+	"math/rand/v2"
+	"testing"
+
+	"github.com/sanjit-bhat/pav/serde/testdata/slice/other"
+)
+
+func FuzzElemDecode(f *testing.F) {
+	rnd := rand.New(rand.NewPCG(0, 0))
+	for i := 0; i < 4; i++ {
+		f.Add(elemEncode(nil, randElem(rnd)))
+	}
+	f.Fuzz(func(t *testing.T, b []byte) {
+		x, _, err := elemDecode(b)
+		if err {
+			return
+		}
+		b0 := elemEncode(nil, x)
+		y, rem, err := elemDecode(b0)
+		if err || len(rem) != 0 {
+			t.Fatal()
+		}
+		if !bytes.Equal(b0, elemEncode(nil, y)) {
+			t.Fatal()
+		}
+	})
+}
+func TestElemRoundTrip(t *testing.T) {
+	rnd := rand.New(rand.NewPCG(0, 0))
+	for i := 0; i < 100; i++ {
+		b := elemEncode(nil, randElem(rnd))
+		x, rem, err := elemDecode(b)
+		if err || len(rem) != 0 {
+			t.Fatal()
+		}
+		if !bytes.Equal(b, elemEncode(nil, x)) {
+			t.Fatal()
+		}
+	}
+}
+func FuzzArgsDecode(f *testing.F) {
+	rnd := rand.New(rand.NewPCG(0, 0))
+	for i := 0; i < 4; i++ {
+		f.Add(argsEncode(nil, randArgs(rnd)))
+	}
+	f.Fuzz(func(t *testing.T, b []byte) {
+		x, _, err := argsDecode(b)
+		if err {
+			return
+		}
+		b0 := argsEncode(nil, x)
+		y, rem, err := argsDecode(b0)
+		if err || len(rem) != 0 {
+			t.Fatal()
+		}
+		if !bytes.Equal(b0, argsEncode(nil, y)) {
+			t.Fatal()
+		}
+	})
+}
+func TestArgsRoundTrip(t *testing.T) {
+	rnd := rand.New(rand.NewPCG(0, 0))
+	for i := 0; i < 100; i++ {
+		b := argsEncode(nil, randArgs(rnd))
+		x, rem, err := argsDecode(b)
+		if err || len(rem) != 0 {
+			t.Fatal()
+		}
+		if !bytes.Equal(b, argsEncode(nil, x)) {
+			t.Fatal()
+		}
+	}
+}
+func randElem(rnd *rand.Rand) *elem {
+	o := &elem{}
+	o.a1 = rnd.Uint64()
+	return o
+}
+func randArgs(rnd *rand.Rand) *args {
+	o := &args{}
+	o.a1 = randSlelem(rnd, rnd.IntN(4))
+	o.a2 = randSlSlelem(rnd, rnd.IntN(4))
+	o.a3 = randSlSlSlSlelem(rnd, rnd.IntN(4))
+	o.a4 = randSlElem(rnd, rnd.IntN(4))
+	o.a5 = randOtherElem(rnd)
+	return o
+}
+func randSlelem(rnd *rand.Rand, n int) []*elem {
+	var x []*elem
+	for i := 0; i < n; i++ {
+		x = append(x, randElem(rnd))
+	}
+	return x
+}
+func randSlSlelem(rnd *rand.Rand, n int) [][]*elem {
+	var x [][]*elem
+	for i := 0; i < n; i++ {
+		x = append(x, randSlelem(rnd, rnd.IntN(4)))
+	}
+	return x
+}
+func randSlSlSlSlelem(rnd *rand.Rand, n int) [][][][]*elem {
+	var x [][][][]*elem
+	for i := 0; i < n; i++ {
+		x = append(x, randSlSlSlelem(rnd, rnd.IntN(4)))
+	}
+	return x
+}
+func randSlElem(rnd *rand.Rand, n int) []*other.Elem {
+	var x []*other.Elem
+	for i := 0; i < n; i++ {
+		x = append(x, randOtherElem(rnd))
+	}
+	return x
+}
+func randOtherElem(rnd *rand.Rand) *other.Elem {
+	o := &other.Elem{}
+	o.A1 = rnd.Uint64()
+	return o
+}
+func randSlSlSlelem(rnd *rand.Rand, n int) [][][]*elem {
+	var x [][][]*elem
+	for i := 0; i < n; i++ {
+		x = append(x, randSlSlelem(rnd, rnd.IntN(4)))
+	}
+	return x
+}
+func randBytes(rnd *rand.Rand, n int) []byte {
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = byte(rnd.Uint32())
+	}
+	return b
+}
@@ -0,0 +1,45 @@
+package streamed
+
+type entry struct {
+	// serde: invariant: const 1.
+	kind byte
+	// serde: len 4.
+	key  []byte
+	vals map[uint32][]byte
+}
+
+// serde: union.
+type choice struct {
+	e *entry
+	n *note
+}
+
+type note struct {
+	text string
+	pos  [2]uint16
+	// serde: maxlen 3.
+	tags []string
+}
+
+// serde: version 2.
+type log struct {
+	// serde: stream.
+	entries []*entry
+	// serde: optional.
+	last *note
+	// serde: since 2.
+	// serde: stream.
+	choices []*choice
+}
+
+type batch struct {
+	logs []*log
+	hash [4]byte
+	ids  [][]uint64
+}
+
+// shelf threads its logs' callbacks through.
+type shelf struct {
+	// serde: stream.
+	logs []*log
+}
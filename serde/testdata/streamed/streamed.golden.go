@@ -0,0 +1,446 @@
+// Auto-generated from spec "github.com/sanjit-bhat/pav/serde/testdata/streamed/streamed.go"
+// using compiler "github.com/sanjit-bhat/pav/serde".
+package streamed
+
+import (
+	"slices"
+
+	"github.com/sanjit-bhat/pav/safemarshal"
+	"github.com/tchajed/marshal"
+)
+
+func entryEncode(b0 []byte, o *entry) []byte {
+	var b = b0
+	b = safemarshal.WriteByte(b, 1)
+	b = safemarshal.WriteSlice1D(b, o.key)
+	b = Mapuint32SlbyteEncode(b, o.vals)
+	return b
+}
+func entryDecode(b0 []byte) (*entry, []byte, bool) {
+	b1, err1 := safemarshal.ReadConstByte(b0, 1)
+	if err1 {
+		return nil, nil, true
+	}
+	a2, b2, err2 := safemarshal.ReadSlice1D(b1)
+	if err2 {
+		return nil, nil, true
+	}
+	if uint64(len(a2)) != 4 {
+		return nil, nil, true
+	}
+	a3, b3, err3 := Mapuint32SlbyteDecode(b2)
+	if err3 {
+		return nil, nil, true
+	}
+	return &entry{kind: 1, key: a2, vals: a3}, b3, false
+}
+func choiceEncode(b0 []byte, o *choice) []byte {
+	var b = b0
+	if o.e != nil && o.n == nil {
+		b = safemarshal.WriteByte(b, 0)
+		return entryEncode(b, o.e)
+	}
+	if o.e == nil && o.n != nil {
+		b = safemarshal.WriteByte(b, 1)
+		return noteEncode(b, o.n)
+	}
+	panic("serde: choice must have exactly one variant")
+}
+func choiceDecode(b0 []byte) (*choice, []byte, bool) {
+	tag, b1, err1 := safemarshal.ReadByte(b0)
+	if err1 {
+		return nil, nil, true
+	}
+	if tag == 0 {
+		x, b2, err2 := entryDecode(b1)
+		if err2 {
+			return nil, nil, true
+		}
+		return &choice{e: x}, b2, false
+	}
+	if tag == 1 {
+		x, b2, err2 := noteDecode(b1)
+		if err2 {
+			return nil, nil, true
+		}
+		return &choice{n: x}, b2, false
+	}
+	return nil, nil, true
+}
+func noteEncode(b0 []byte, o *note) []byte {
+	var b = b0
+	b = safemarshal.WriteString(b, o.text)
+	b = Arr2uint16Encode(b, o.pos)
+	b = SlstringEncode(b, o.tags)
+	return b
+}
+func noteDecode(b0 []byte) (*note, []byte, bool) {
+	a1, b1, err1 := safemarshal.ReadString(b0)
+	if err1 {
+		return nil, nil, true
+	}
+	a2, b2, err2 := Arr2uint16Decode(b1)
+	if err2 {
+		return nil, nil, true
+	}
+	a3, b3, err3 := SlstringDecode(b2)
+	if err3 {
+		return nil, nil, true
+	}
+	if uint64(len(a3)) > 3 {
+		return nil, nil, true
+	}
+	return &note{text: a1, pos: a2, tags: a3}, b3, false
+}
+func logEncode(b0 []byte, o *log) []byte {
+	var b []byte
+	b = entrySlice1DEncode(b, o.entries)
+	b = marshal.WriteBool(b, o.last != nil)
+	if o.last != nil {
+		b = noteEncode(b, o.last)
+	}
+	b = choiceSlice1DEncode(b, o.choices)
+	return safemarshal.WriteSlice1D(marshal.WriteInt(b0, 2), b)
+}
+func logDecode(b0 []byte) (*log, []byte, bool) {
+	ver, bVer, errVer := safemarshal.ReadInt(b0)
+	if errVer {
+		return nil, nil, true
+	}
+	if ver == 0 {
+		return nil, nil, true
+	}
+	body, tail, errBody := safemarshal.ReadSlice1D(bVer)
+	if errBody {
+		return nil, nil, true
+	}
+	a1, b1, err1 := entrySlice1DDecode(body)
+	if err1 {
+		return nil, nil, true
+	}
+	var a2 *note
+	isSet2, b2, err2 := safemarshal.ReadBool(b1)
+	if err2 {
+		return nil, nil, true
+	}
+	if isSet2 {
+		x, rem, err := noteDecode(b2)
+		if err {
+			return nil, nil, true
+		}
+		a2 = x
+		b2 = rem
+	}
+	var a3 []*choice
+	var b3 = b2
+	if ver >= 2 {
+		a, b, err := choiceSlice1DDecode(b2)
+		if err {
+			return nil, nil, true
+		}
+		a3 = a
+		b3 = b
+	}
+	if ver <= 2 && len(b3) != 0 {
+		return nil, nil, true
+	}
+	return &log{entries: a1, last: a2, choices: a3}, tail, false
+}
+func batchEncode(b0 []byte, o *batch) []byte {
+	var b = b0
+	b = logSlice1DEncode(b, o.logs)
+	b = Arr4byteEncode(b, o.hash)
+	b = SlSluint64Encode(b, o.ids)
+	return b
+}
+func batchDecode(b0 []byte) (*batch, []byte, bool) {
+	a1, b1, err1 := logSlice1DDecode(b0)
+	if err1 {
+		return nil, nil, true
+	}
+	a2, b2, err2 := Arr4byteDecode(b1)
+	if err2 {
+		return nil, nil, true
+	}
+	a3, b3, err3 := SlSluint64Decode(b2)
+	if err3 {
+		return nil, nil, true
+	}
+	return &batch{logs: a1, hash: a2, ids: a3}, b3, false
+}
+func shelfEncode(b0 []byte, o *shelf) []byte {
+	var b = b0
+	b = logSlice1DEncode(b, o.logs)
+	return b
+}
+func shelfDecode(b0 []byte) (*shelf, []byte, bool) {
+	a1, b1, err1 := logSlice1DDecode(b0)
+	if err1 {
+		return nil, nil, true
+	}
+	return &shelf{logs: a1}, b1, false
+}
+func Mapuint32SlbyteEncode(b0 []byte, o map[uint32][]byte) []byte {
+	var b = b0
+	var keys []uint32
+	for k := range o {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	b = marshal.WriteInt(b, uint64(len(keys)))
+	for _, k := range keys {
+		b = marshal.WriteInt32(b, k)
+		b = safemarshal.WriteSlice1D(b, o[k])
+	}
+	return b
+}
+func Mapuint32SlbyteDecode(b0 []byte) (map[uint32][]byte, []byte, bool) {
+	length, b1, err1 := safemarshal.ReadInt(b0)
+	if err1 {
+		return nil, nil, true
+	}
+	var loopO = make(map[uint32][]byte)
+	var loopErr bool
+	var loopB = b1
+	var loopPrev uint32
+	for i := uint64(0); i < length; i++ {
+		a2, loopB1, err2 := safemarshal.ReadInt32(loopB)
+		if err2 {
+			loopErr = true
+			break
+		}
+		if i != 0 && a2 <= loopPrev {
+			loopErr = true
+			break
+		}
+		a3, loopB2, err3 := safemarshal.ReadSlice1D(loopB1)
+		if err3 {
+			loopErr = true
+			break
+		}
+		loopO[a2] = a3
+		loopPrev = a2
+		loopB = loopB2
+	}
+	if loopErr {
+		return nil, nil, true
+	}
+	return loopO, loopB, false
+}
+func Arr2uint16Encode(b0 []byte, o [2]uint16) []byte {
+	var b = b0
+	for _, e := range o {
+		b = safemarshal.WriteInt16(b, e)
+	}
+	return b
+}
+func SlstringEncode(b0 []byte, o []string) []byte {
+	var b = b0
+	b = marshal.WriteInt(b, uint64(len(o)))
+	for _, e := range o {
+		b = safemarshal.WriteString(b, e)
+	}
+	return b
+}
+func Arr2uint16Decode(b0 []byte) ([2]uint16, []byte, bool) {
+	var loopO [2]uint16
+	var loopErr bool
+	var loopB = b0
+	for i := uint64(0); i < 2; i++ {
+		a1, loopB1, err1 := safemarshal.ReadInt16(loopB)
+		loopB = loopB1
+		if err1 {
+			loopErr = true
+			break
+		}
+		loopO[i] = a1
+	}
+	if loopErr {
+		return loopO, nil, true
+	}
+	return loopO, loopB, false
+}
+func SlstringDecode(b0 []byte) ([]string, []byte, bool) {
+	length, b1, err1 := safemarshal.ReadInt(b0)
+	if err1 {
+		return nil, nil, true
+	}
+	var loopO []string
+	var loopErr bool
+	var loopB = b1
+	for i := uint64(0); i < length; i++ {
+		a2, loopB1, err2 := safemarshal.ReadString(loopB)
+		loopB = loopB1
+		if err2 {
+			loopErr = true
+			break
+		}
+		loopO = append(loopO, a2)
+	}
+	if loopErr {
+		return nil, nil, true
+	}
+	return loopO, loopB, false
+}
+func entrySlice1DEncode(b0 []byte, o []*entry) []byte {
+	var b = b0
+	b = marshal.WriteInt(b, uint64(len(o)))
+	for _, e := range o {
+		b = entryEncode(b, e)
+	}
+	return b
+}
+func choiceSlice1DEncode(b0 []byte, o []*choice) []byte {
+	var b = b0
+	b = marshal.WriteInt(b, uint64(len(o)))
+	for _, e := range o {
+		b = choiceEncode(b, e)
+	}
+	return b
+}
+func entrySlice1DDecode(b0 []byte) ([]*entry, []byte, bool) {
+	length, b1, err1 := safemarshal.ReadInt(b0)
+	if err1 {
+		return nil, nil, true
+	}
+	var loopO []*entry
+	var loopErr bool
+	var loopB = b1
+	for i := uint64(0); i < length; i++ {
+		a2, loopB1, err2 := entryDecode(loopB)
+		loopB = loopB1
+		if err2 {
+			loopErr = true
+			break
+		}
+		loopO = append(loopO, a2)
+	}
+	if loopErr {
+		return nil, nil, true
+	}
+	return loopO, loopB, false
+}
+func choiceSlice1DDecode(b0 []byte) ([]*choice, []byte, bool) {
+	length, b1, err1 := safemarshal.ReadInt(b0)
+	if err1 {
+		return nil, nil, true
+	}
+	var loopO []*choice
+	var loopErr bool
+	var loopB = b1
+	for i := uint64(0); i < length; i++ {
+		a2, loopB1, err2 := choiceDecode(loopB)
+		loopB = loopB1
+		if err2 {
+			loopErr = true
+			break
+		}
+		loopO = append(loopO, a2)
+	}
+	if loopErr {
+		return nil, nil, true
+	}
+	return loopO, loopB, false
+}
+func logSlice1DEncode(b0 []byte, o []*log) []byte {
+	var b = b0
+	b = marshal.WriteInt(b, uint64(len(o)))
+	for _, e := range o {
+		b = logEncode(b, e)
+	}
+	return b
+}
+func Arr4byteEncode(b0 []byte, o [4]byte) []byte {
+	return marshal.WriteBytes(b0, o[:])
+}
+func Sluint64Encode(b0 []byte, o []uint64) []byte {
+	var b = b0
+	b = marshal.WriteInt(b, uint64(len(o)))
+	for _, e := range o {
+		b = marshal.WriteInt(b, e)
+	}
+	return b
+}
+func SlSluint64Encode(b0 []byte, o [][]uint64) []byte {
+	var b = b0
+	b = marshal.WriteInt(b, uint64(len(o)))
+	for _, e := range o {
+		b = Sluint64Encode(b, e)
+	}
+	return b
+}
+func logSlice1DDecode(b0 []byte) ([]*log, []byte, bool) {
+	length, b1, err1 := safemarshal.ReadInt(b0)
+	if err1 {
+		return nil, nil, true
+	}
+	var loopO []*log
+	var loopErr bool
+	var loopB = b1
+	for i := uint64(0); i < length; i++ {
+		a2, loopB1, err2 := logDecode(loopB)
+		loopB = loopB1
+		if err2 {
+			loopErr = true
+			break
+		}
+		loopO = append(loopO, a2)
+	}
+	if loopErr {
+		return nil, nil, true
+	}
+	return loopO, loopB, false
+}
+func Arr4byteDecode(b0 []byte) ([4]byte, []byte, bool) {
+	var o [4]byte
+	a1, b1, err1 := safemarshal.ReadBytes(b0, 4)
+	if err1 {
+		return o, nil, true
+	}
+	copy(o[:], a1)
+	return o, b1, false
+}
+func Sluint64Decode(b0 []byte) ([]uint64, []byte, bool) {
+	length, b1, err1 := safemarshal.ReadInt(b0)
+	if err1 {
+		return nil, nil, true
+	}
+	var loopO []uint64
+	var loopErr bool
+	var loopB = b1
+	for i := uint64(0); i < length; i++ {
+		a2, loopB1, err2 := safemarshal.ReadInt(loopB)
+		loopB = loopB1
+		if err2 {
+			loopErr = true
+			break
+		}
+		loopO = append(loopO, a2)
+	}
+	if loopErr {
+		return nil, nil, true
+	}
+	return loopO, loopB, false
+}
+func SlSluint64Decode(b0 []byte) ([][]uint64, []byte, bool) {
+	length, b1, err1 := safemarshal.ReadInt(b0)
+	if err1 {
+		return nil, nil, true
+	}
+	var loopO [][]uint64
+	var loopErr bool
+	var loopB = b1
+	for i := uint64(0); i < length; i++ {
+		a2, loopB1, err2 := Sluint64Decode(loopB)
+		loopB = loopB1
+		if err2 {
+			loopErr = true
+			break
+		}
+		loopO = append(loopO, a2)
+	}
+	if loopErr {
+		return nil, nil, true
+	}
+	return loopO, loopB, false
+}
@@ -0,0 +1,280 @@
+//go:build !goose
+
+// Auto-generated from spec "github.com/sanjit-bhat/pav/serde/testdata/streamed/streamed.go"
+// using compiler "github.com/sanjit-bhat/pav/serde".
+package streamed
+
+import "github.com/sanjit-bhat/pav/safemarshal/stream"
+
+func entryDecodeStream(r *stream.Reader) (*entry, bool) {
+	if r.ConstByte(1) {
+		return nil, true
+	}
+	a2, err2 := r.Slice1D()
+	if err2 {
+		return nil, true
+	}
+	if uint64(len(a2)) != 4 {
+		return nil, true
+	}
+	a3, err3 := Mapuint32SlbyteDecodeStream(r)
+	if err3 {
+		return nil, true
+	}
+	return &entry{kind: 1, key: a2, vals: a3}, false
+}
+func choiceDecodeStream(r *stream.Reader) (*choice, bool) {
+	tag, err1 := r.Byte()
+	if err1 {
+		return nil, true
+	}
+	if tag == 0 {
+		x, err2 := entryDecodeStream(r)
+		if err2 {
+			return nil, true
+		}
+		return &choice{e: x}, false
+	}
+	if tag == 1 {
+		x, err2 := noteDecodeStream(r)
+		if err2 {
+			return nil, true
+		}
+		return &choice{n: x}, false
+	}
+	return nil, true
+}
+func noteDecodeStream(r *stream.Reader) (*note, bool) {
+	a1, err1 := r.String()
+	if err1 {
+		return nil, true
+	}
+	a2, err2 := Arr2uint16DecodeStream(r)
+	if err2 {
+		return nil, true
+	}
+	a3, err3 := SlstringDecodeStream(r)
+	if err3 {
+		return nil, true
+	}
+	if uint64(len(a3)) > 3 {
+		return nil, true
+	}
+	return &note{text: a1, pos: a2, tags: a3}, false
+}
+func logDecodeStream(r *stream.Reader, eachEntries func(*entry) bool, eachChoices func(*choice) bool) (*log, bool) {
+	ver, errVer := r.Int()
+	if errVer {
+		return nil, true
+	}
+	if ver == 0 {
+		return nil, true
+	}
+	length, errLen := r.Int()
+	if errLen {
+		return nil, true
+	}
+	body, errBody := r.Limit(length)
+	if errBody {
+		return nil, true
+	}
+	var a1 []*entry
+	length1, err1 := body.Count(17)
+	if err1 {
+		return nil, true
+	}
+	for i := uint64(0); i < length1; i++ {
+		x, err := entryDecodeStream(body)
+		if err {
+			return nil, true
+		}
+		if eachEntries == nil {
+			a1 = append(a1, x)
+			continue
+		}
+		if eachEntries(x) {
+			return nil, true
+		}
+	}
+	var a2 *note
+	isSet2, err2 := body.Bool()
+	if err2 {
+		return nil, true
+	}
+	if isSet2 {
+		x, err := noteDecodeStream(body)
+		if err {
+			return nil, true
+		}
+		a2 = x
+	}
+	var a3 []*choice
+	if ver >= 2 {
+		var a []*choice
+		length, err := body.Count(18)
+		if err {
+			return nil, true
+		}
+		for i := uint64(0); i < length; i++ {
+			x, err := choiceDecodeStream(body)
+			if err {
+				return nil, true
+			}
+			if eachChoices == nil {
+				a = append(a, x)
+				continue
+			}
+			if eachChoices(x) {
+				return nil, true
+			}
+		}
+		a3 = a
+	}
+	if ver <= 2 && body.Budget() != 0 {
+		return nil, true
+	}
+	if body.Discard() {
+		return nil, true
+	}
+	return &log{entries: a1, last: a2, choices: a3}, false
+}
+func batchDecodeStream(r *stream.Reader) (*batch, bool) {
+	a1, err1 := logSlice1DDecodeStream(r)
+	if err1 {
+		return nil, true
+	}
+	a2, err2 := Arr4byteDecodeStream(r)
+	if err2 {
+		return nil, true
+	}
+	a3, err3 := SlSluint64DecodeStream(r)
+	if err3 {
+		return nil, true
+	}
+	return &batch{logs: a1, hash: a2, ids: a3}, false
+}
+func shelfDecodeStream(r *stream.Reader, eachLogs func(*log) bool, eachLogsEntries func(*entry) bool, eachLogsChoices func(*choice) bool) (*shelf, bool) {
+	var a1 []*log
+	length1, err1 := r.Count(16)
+	if err1 {
+		return nil, true
+	}
+	for i := uint64(0); i < length1; i++ {
+		x, err := logDecodeStream(r, eachLogsEntries, eachLogsChoices)
+		if err {
+			return nil, true
+		}
+		if eachLogs == nil {
+			a1 = append(a1, x)
+			continue
+		}
+		if eachLogs(x) {
+			return nil, true
+		}
+	}
+	return &shelf{logs: a1}, false
+}
+func Mapuint32SlbyteDecodeStream(r *stream.Reader) (map[uint32][]byte, bool) {
+	length, err1 := r.Count(12)
+	if err1 {
+		return nil, true
+	}
+	var loopO = make(map[uint32][]byte)
+	var loopPrev uint32
+	for i := uint64(0); i < length; i++ {
+		a2, err2 := r.Int32()
+		if err2 {
+			return nil, true
+		}
+		if i != 0 && a2 <= loopPrev {
+			return nil, true
+		}
+		a3, err3 := r.Slice1D()
+		if err3 {
+			return nil, true
+		}
+		loopO[a2] = a3
+		loopPrev = a2
+	}
+	return loopO, false
+}
+func Arr2uint16DecodeStream(r *stream.Reader) ([2]uint16, bool) {
+	var loopO [2]uint16
+	for i := uint64(0); i < 2; i++ {
+		a1, err1 := r.Int16()
+		if err1 {
+			return loopO, true
+		}
+		loopO[i] = a1
+	}
+	return loopO, false
+}
+func SlstringDecodeStream(r *stream.Reader) ([]string, bool) {
+	length, err1 := r.Count(8)
+	if err1 {
+		return nil, true
+	}
+	var loopO []string
+	for i := uint64(0); i < length; i++ {
+		a2, err2 := r.String()
+		if err2 {
+			return nil, true
+		}
+		loopO = append(loopO, a2)
+	}
+	return loopO, false
+}
+func logSlice1DDecodeStream(r *stream.Reader) ([]*log, bool) {
+	length, err1 := r.Count(16)
+	if err1 {
+		return nil, true
+	}
+	var loopO []*log
+	for i := uint64(0); i < length; i++ {
+		a2, err2 := logDecodeStream(r, nil, nil)
+		if err2 {
+			return nil, true
+		}
+		loopO = append(loopO, a2)
+	}
+	return loopO, false
+}
+func Arr4byteDecodeStream(r *stream.Reader) ([4]byte, bool) {
+	var o [4]byte
+	a1, err1 := r.Bytes(4)
+	if err1 {
+		return o, true
+	}
+	copy(o[:], a1)
+	return o, false
+}
+func SlSluint64DecodeStream(r *stream.Reader) ([][]uint64, bool) {
+	length, err1 := r.Count(8)
+	if err1 {
+		return nil, true
+	}
+	var loopO [][]uint64
+	for i := uint64(0); i < length; i++ {
+		a2, err2 := Sluint64DecodeStream(r)
+		if err2 {
+			return nil, true
+		}
+		loopO = append(loopO, a2)
+	}
+	return loopO, false
+}
+func Sluint64DecodeStream(r *stream.Reader) ([]uint64, bool) {
+	length, err1 := r.Count(8)
+	if err1 {
+		return nil, true
+	}
+	var loopO []uint64
+	for i := uint64(0); i < length; i++ {
+		a2, err2 := r.Int()
+		if err2 {
+			return nil, true
+		}
+		loopO = append(loopO, a2)
+	}
+	return loopO, false
+}
@@ -0,0 +1,18 @@
+package strict
+
+// serde: strict.
+type sig struct {
+	// serde: invariant: const 2.
+	tag   byte
+	epoch uint64
+	// serde: len 32.
+	link []byte
+}
+
+// serde: version 2.
+// serde: strict.
+type payload struct {
+	attrs map[string]uint64
+	// serde: since 2.
+	note string
+}
@@ -0,0 +1,140 @@
+// Auto-generated from spec "github.com/sanjit-bhat/pav/serde/testdata/strict/strict.go"
+// using compiler "github.com/sanjit-bhat/pav/serde".
+package strict
+
+import (
+	"slices"
+
+	"github.com/sanjit-bhat/pav/safemarshal"
+	"github.com/tchajed/marshal"
+)
+
+func sigEncode(b0 []byte, o *sig) []byte {
+	var b = b0
+	b = safemarshal.WriteByte(b, 2)
+	b = marshal.WriteInt(b, o.epoch)
+	b = safemarshal.WriteSlice1D(b, o.link)
+	return b
+}
+func sigDecode(b0 []byte) (*sig, []byte, bool) {
+	b1, err1 := safemarshal.ReadConstByte(b0, 2)
+	if err1 {
+		return nil, nil, true
+	}
+	a2, b2, err2 := safemarshal.ReadInt(b1)
+	if err2 {
+		return nil, nil, true
+	}
+	a3, b3, err3 := safemarshal.ReadSlice1D(b2)
+	if err3 {
+		return nil, nil, true
+	}
+	if uint64(len(a3)) != 32 {
+		return nil, nil, true
+	}
+	return &sig{tag: 2, epoch: a2, link: a3}, b3, false
+}
+func sigDecodeStrict(b []byte) (*sig, bool) {
+	o, _, err := sigDecode(b)
+	if err {
+		return nil, true
+	}
+	if safemarshal.CheckCanonical(b, sigEncode(nil, o)) {
+		return nil, true
+	}
+	return o, false
+}
+func payloadEncode(b0 []byte, o *payload) []byte {
+	var b []byte
+	b = Mapstringuint64Encode(b, o.attrs)
+	b = safemarshal.WriteString(b, o.note)
+	return safemarshal.WriteSlice1D(marshal.WriteInt(b0, 2), b)
+}
+func payloadDecode(b0 []byte) (*payload, []byte, bool) {
+	ver, bVer, errVer := safemarshal.ReadInt(b0)
+	if errVer {
+		return nil, nil, true
+	}
+	if ver == 0 {
+		return nil, nil, true
+	}
+	body, tail, errBody := safemarshal.ReadSlice1D(bVer)
+	if errBody {
+		return nil, nil, true
+	}
+	a1, b1, err1 := Mapstringuint64Decode(body)
+	if err1 {
+		return nil, nil, true
+	}
+	var a2 string
+	var b2 = b1
+	if ver >= 2 {
+		a, b, err := safemarshal.ReadString(b1)
+		if err {
+			return nil, nil, true
+		}
+		a2 = a
+		b2 = b
+	}
+	if ver <= 2 && len(b2) != 0 {
+		return nil, nil, true
+	}
+	return &payload{attrs: a1, note: a2}, tail, false
+}
+func payloadDecodeStrict(b []byte) (*payload, bool) {
+	o, _, err := payloadDecode(b)
+	if err {
+		return nil, true
+	}
+	if safemarshal.CheckCanonical(b, payloadEncode(nil, o)) {
+		return nil, true
+	}
+	return o, false
+}
+func Mapstringuint64Encode(b0 []byte, o map[string]uint64) []byte {
+	var b = b0
+	var keys []string
+	for k := range o {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	b = marshal.WriteInt(b, uint64(len(keys)))
+	for _, k := range keys {
+		b = safemarshal.WriteString(b, k)
+		b = marshal.WriteInt(b, o[k])
+	}
+	return b
+}
+func Mapstringuint64Decode(b0 []byte) (map[string]uint64, []byte, bool) {
+	length, b1, err1 := safemarshal.ReadInt(b0)
+	if err1 {
+		return nil, nil, true
+	}
+	var loopO = make(map[string]uint64)
+	var loopErr bool
+	var loopB = b1
+	var loopPrev string
+	for i := uint64(0); i < length; i++ {
+		a2, loopB1, err2 := safemarshal.ReadString(loopB)
+		if err2 {
+			loopErr = true
+			break
+		}
+		if i != 0 && a2 <= loopPrev {
+			loopErr = true
+			break
+		}
+		a3, loopB2, err3 := safemarshal.ReadInt(loopB1)
+		if err3 {
+			loopErr = true
+			break
+		}
+		loopO[a2] = a3
+		loopPrev = a2
+		loopB = loopB2
+	}
+	if loopErr {
+		return nil, nil, true
+	}
+	return loopO, loopB, false
+}
@@ -0,0 +1,179 @@
+// Auto-generated from spec "github.com/sanjit-bhat/pav/serde/testdata/strict/strict.go"
+// using compiler "github.com/sanjit-bhat/pav/serde".
+package strict
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"math/rand/v2"
+	"os"
+	"testing"
+)
+
+func FuzzSigDecode(f *testing.F) {
+	rnd := rand.New(rand.NewPCG(0, 0))
+	for i := 0; i < 4; i++ {
+		f.Add(sigEncode(nil, randSig(rnd)))
+	}
+	f.Fuzz(func(t *testing.T, b []byte) {
+		x, _, err := sigDecode(b)
+		if err {
+			return
+		}
+		b0 := sigEncode(nil, x)
+		y, rem, err := sigDecode(b0)
+		if err || len(rem) != 0 {
+			t.Fatal()
+		}
+		if !bytes.Equal(b0, sigEncode(nil, y)) {
+			t.Fatal()
+		}
+	})
+}
+func TestSigRoundTrip(t *testing.T) {
+	rnd := rand.New(rand.NewPCG(0, 0))
+	for i := 0; i < 100; i++ {
+		b := sigEncode(nil, randSig(rnd))
+		x, rem, err := sigDecode(b)
+		if err || len(rem) != 0 {
+			t.Fatal()
+		}
+		if !bytes.Equal(b, sigEncode(nil, x)) {
+			t.Fatal()
+		}
+	}
+}
+func TestSigDecodeStrict(t *testing.T) {
+	rnd := rand.New(rand.NewPCG(0, 0))
+	for i := 0; i < 100; i++ {
+		b := sigEncode(nil, randSig(rnd))
+		if _, err := sigDecodeStrict(b); err {
+			t.Fatal()
+		}
+		if _, err := sigDecodeStrict(append(b, 0)); !err {
+			t.Fatal()
+		}
+	}
+}
+func FuzzPayloadDecode(f *testing.F) {
+	rnd := rand.New(rand.NewPCG(0, 0))
+	for i := 0; i < 4; i++ {
+		f.Add(payloadEncode(nil, randPayload(rnd)))
+	}
+	f.Fuzz(func(t *testing.T, b []byte) {
+		x, _, err := payloadDecode(b)
+		if err {
+			return
+		}
+		b0 := payloadEncode(nil, x)
+		y, rem, err := payloadDecode(b0)
+		if err || len(rem) != 0 {
+			t.Fatal()
+		}
+		if !bytes.Equal(b0, payloadEncode(nil, y)) {
+			t.Fatal()
+		}
+	})
+}
+func TestPayloadRoundTrip(t *testing.T) {
+	rnd := rand.New(rand.NewPCG(0, 0))
+	for i := 0; i < 100; i++ {
+		b := payloadEncode(nil, randPayload(rnd))
+		x, rem, err := payloadDecode(b)
+		if err || len(rem) != 0 {
+			t.Fatal()
+		}
+		if !bytes.Equal(b, payloadEncode(nil, x)) {
+			t.Fatal()
+		}
+	}
+}
+func TestPayloadDecodeStrict(t *testing.T) {
+	rnd := rand.New(rand.NewPCG(0, 0))
+	for i := 0; i < 100; i++ {
+		b := payloadEncode(nil, randPayload(rnd))
+		if _, err := payloadDecodeStrict(b); err {
+			t.Fatal()
+		}
+		if _, err := payloadDecodeStrict(append(b, 0)); !err {
+			t.Fatal()
+		}
+	}
+}
+func randSig(rnd *rand.Rand) *sig {
+	o := &sig{}
+	o.tag = 2
+	o.epoch = rnd.Uint64()
+	o.link = randBytes(rnd, 32)
+	return o
+}
+func randPayload(rnd *rand.Rand) *payload {
+	o := &payload{}
+	o.attrs = randMapstringuint64(rnd)
+	o.note = string(randBytes(rnd, rnd.IntN(4)))
+	return o
+}
+func randMapstringuint64(rnd *rand.Rand) map[string]uint64 {
+	x := make(map[string]uint64)
+	n := rnd.IntN(4)
+	for i := 0; i < n; i++ {
+		x[string(randBytes(rnd, rnd.IntN(4)))] = rnd.Uint64()
+	}
+	return x
+}
+func randBytes(rnd *rand.Rand, n int) []byte {
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = byte(rnd.Uint32())
+	}
+	return b
+}
+func vectorCodec(name string, b []byte) (enc []byte, err bool) {
+	switch name {
+	case "sig":
+		x, rem, errb := sigDecode(b)
+		if errb || len(rem) != 0 {
+			return nil, true
+		}
+		return sigEncode(nil, x), false
+	case "payload":
+		x, rem, errb := payloadDecode(b)
+		if errb || len(rem) != 0 {
+			return nil, true
+		}
+		return payloadEncode(nil, x), false
+	}
+	return nil, true
+}
+func TestVectors(t *testing.T) {
+	data, err := os.ReadFile("strict.vectors.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var vs struct {
+		Vectors []struct {
+			Name, Struct, Hex string
+		}
+	}
+	err = json.Unmarshal(data, &vs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(vs.Vectors) == 0 {
+		t.Fatal("no vectors")
+	}
+	for _, v := range vs.Vectors {
+		b, err := hex.DecodeString(v.Hex)
+		if err != nil {
+			t.Fatal(err)
+		}
+		enc, errb := vectorCodec(v.Struct, b)
+		if errb {
+			t.Fatalf("%s: decode failed", v.Name)
+		}
+		if !bytes.Equal(b, enc) {
+			t.Fatalf("%s: encoding differs", v.Name)
+		}
+	}
+}
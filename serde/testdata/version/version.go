@@ -0,0 +1,21 @@
+package serde
+
+type inner struct {
+	a1 uint64
+}
+
+// serde: version 3.
+type msg struct {
+	a1 uint64
+	// serde: len 2.
+	a2 []byte
+	// serde: since 2.
+	a3 *inner
+	// serde: since 3.
+	// serde: optional.
+	a4 *inner
+}
+
+// serde: version 1.
+type empty struct {
+}
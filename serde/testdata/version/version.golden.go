@@ -0,0 +1,110 @@
+// Auto-generated from spec "github.com/sanjit-bhat/pav/serde/testdata/version/version.go"
+// using compiler "github.com/sanjit-bhat/pav/serde".
+package serde
+
+import (
+	"github.com/sanjit-bhat/pav/safemarshal"
+	"github.com/tchajed/marshal"
+)
+
+func innerEncode(b0 []byte, o *inner) []byte {
+	var b = b0
+	b = marshal.WriteInt(b, o.a1)
+	return b
+}
+func innerDecode(b0 []byte) (*inner, []byte, bool) {
+	a1, b1, err1 := safemarshal.ReadInt(b0)
+	if err1 {
+		return nil, nil, true
+	}
+	return &inner{a1: a1}, b1, false
+}
+func msgEncode(b0 []byte, o *msg) []byte {
+	var b []byte
+	b = marshal.WriteInt(b, o.a1)
+	b = safemarshal.WriteSlice1D(b, o.a2)
+	b = innerEncode(b, o.a3)
+	b = marshal.WriteBool(b, o.a4 != nil)
+	if o.a4 != nil {
+		b = innerEncode(b, o.a4)
+	}
+	return safemarshal.WriteSlice1D(marshal.WriteInt(b0, 3), b)
+}
+func msgDecode(b0 []byte) (*msg, []byte, bool) {
+	ver, bVer, errVer := safemarshal.ReadInt(b0)
+	if errVer {
+		return nil, nil, true
+	}
+	if ver == 0 {
+		return nil, nil, true
+	}
+	body, tail, errBody := safemarshal.ReadSlice1D(bVer)
+	if errBody {
+		return nil, nil, true
+	}
+	a1, b1, err1 := safemarshal.ReadInt(body)
+	if err1 {
+		return nil, nil, true
+	}
+	a2, b2, err2 := safemarshal.ReadSlice1D(b1)
+	if err2 {
+		return nil, nil, true
+	}
+	if uint64(len(a2)) != 2 {
+		return nil, nil, true
+	}
+	var a3 *inner
+	var b3 = b2
+	if ver >= 2 {
+		a, b, err := innerDecode(b2)
+		if err {
+			return nil, nil, true
+		}
+		a3 = a
+		b3 = b
+	}
+	var a4 *inner
+	var b4 = b3
+	if ver >= 3 {
+		var a *inner
+		isSet, b, err := safemarshal.ReadBool(b3)
+		if err {
+			return nil, nil, true
+		}
+		if isSet {
+			x, rem, err := innerDecode(b)
+			if err {
+				return nil, nil, true
+			}
+			a = x
+			b = rem
+		}
+		a4 = a
+		b4 = b
+	}
+	if ver <= 3 && len(b4) != 0 {
+		return nil, nil, true
+	}
+	return &msg{a1: a1, a2: a2, a3: a3, a4: a4}, tail, false
+}
+func emptyEncode(b0 []byte, o *empty) []byte {
+	var b []byte
+	return safemarshal.WriteSlice1D(marshal.WriteInt(b0, 1), b)
+}
+func emptyDecode(b0 []byte) (*empty, []byte, bool) {
+	ver, bVer, errVer := safemarshal.ReadInt(b0)
+	if errVer {
+		return nil, nil, true
+	}
+	if ver == 0 {
+		return nil, nil, true
+	}
+	body, tail, errBody := safemarshal.ReadSlice1D(bVer)
+	if errBody {
+		return nil, nil, true
+	}
+	if ver <= 1 && len(body) != 0 {
+		return nil, nil, true
+	}
+	return &empty{}, tail, false
+}
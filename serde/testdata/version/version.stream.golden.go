@@ -0,0 +1,98 @@
+//go:build !goose
+
+// Auto-generated from spec "github.com/sanjit-bhat/pav/serde/testdata/version/version.go"
+// using compiler "github.com/sanjit-bhat/pav/serde".
+package serde
+
+import "github.com/sanjit-bhat/pav/safemarshal/stream"
+
+func innerDecodeStream(r *stream.Reader) (*inner, bool) {
+	a1, err1 := r.Int()
+	if err1 {
+		return nil, true
+	}
+	return &inner{a1: a1}, false
+}
+func msgDecodeStream(r *stream.Reader) (*msg, bool) {
+	ver, errVer := r.Int()
+	if errVer {
+		return nil, true
+	}
+	if ver == 0 {
+		return nil, true
+	}
+	length, errLen := r.Int()
+	if errLen {
+		return nil, true
+	}
+	body, errBody := r.Limit(length)
+	if errBody {
+		return nil, true
+	}
+	a1, err1 := body.Int()
+	if err1 {
+		return nil, true
+	}
+	a2, err2 := body.Slice1D()
+	if err2 {
+		return nil, true
+	}
+	if uint64(len(a2)) != 2 {
+		return nil, true
+	}
+	var a3 *inner
+	if ver >= 2 {
+		a, err := innerDecodeStream(body)
+		if err {
+			return nil, true
+		}
+		a3 = a
+	}
+	var a4 *inner
+	if ver >= 3 {
+		var a *inner
+		isSet, err := body.Bool()
+		if err {
+			return nil, true
+		}
+		if isSet {
+			x, err := innerDecodeStream(body)
+			if err {
+				return nil, true
+			}
+			a = x
+		}
+		a4 = a
+	}
+	if ver <= 3 && body.Budget() != 0 {
+		return nil, true
+	}
+	if body.Discard() {
+		return nil, true
+	}
+	return &msg{a1: a1, a2: a2, a3: a3, a4: a4}, false
+}
+func emptyDecodeStream(r *stream.Reader) (*empty, bool) {
+	ver, errVer := r.Int()
+	if errVer {
+		return nil, true
+	}
+	if ver == 0 {
+		return nil, true
+	}
+	length, errLen := r.Int()
+	if errLen {
+		return nil, true
+	}
+	body, errBody := r.Limit(length)
+	if errBody {
+		return nil, true
+	}
+	if ver <= 1 && body.Budget() != 0 {
+		return nil, true
+	}
+	if body.Discard() {
+		return nil, true
+	}
+	return &empty{}, false
+}
@@ -0,0 +1,192 @@
+// Auto-generated from spec "github.com/sanjit-bhat/pav/serde/testdata/version/version.go"
+// using compiler "github.com/sanjit-bhat/pav/serde".
+package serde
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"math/rand/v2"
+	"os"
+	"testing"
+)
+
+func FuzzInnerDecode(f *testing.F) {
+	rnd := rand.New(rand.NewPCG(0, 0))
+	for i := 0; i < 4; i++ {
+		f.Add(innerEncode(nil, randInner(rnd)))
+	}
+	f.Fuzz(func(t *testing.T, b []byte) {
+		x, _, err := innerDecode(b)
+		if err {
+			return
+		}
+		b0 := innerEncode(nil, x)
+		y, rem, err := innerDecode(b0)
+		if err || len(rem) != 0 {
+			t.Fatal()
+		}
+		if !bytes.Equal(b0, innerEncode(nil, y)) {
+			t.Fatal()
+		}
+	})
+}
+func TestInnerRoundTrip(t *testing.T) {
+	rnd := rand.New(rand.NewPCG(0, 0))
+	for i := 0; i < 100; i++ {
+		b := innerEncode(nil, randInner(rnd))
+		x, rem, err := innerDecode(b)
+		if err || len(rem) != 0 {
+			t.Fatal()
+		}
+		if !bytes.Equal(b, innerEncode(nil, x)) {
+			t.Fatal()
+		}
+	}
+}
+func FuzzMsgDecode(f *testing.F) {
+	rnd := rand.New(rand.NewPCG(0, 0))
+	for i := 0; i < 4; i++ {
+		f.Add(msgEncode(nil, randMsg(rnd)))
+	}
+	f.Fuzz(func(t *testing.T, b []byte) {
+		x, _, err := msgDecode(b)
+		if err {
+			return
+		}
+		b0 := msgEncode(nil, x)
+		y, rem, err := msgDecode(b0)
+		if err || len(rem) != 0 {
+			t.Fatal()
+		}
+		if !bytes.Equal(b0, msgEncode(nil, y)) {
+			t.Fatal()
+		}
+	})
+}
+func TestMsgRoundTrip(t *testing.T) {
+	rnd := rand.New(rand.NewPCG(0, 0))
+	for i := 0; i < 100; i++ {
+		b := msgEncode(nil, randMsg(rnd))
+		x, rem, err := msgDecode(b)
+		if err || len(rem) != 0 {
+			t.Fatal()
+		}
+		if !bytes.Equal(b, msgEncode(nil, x)) {
+			t.Fatal()
+		}
+	}
+}
+func FuzzEmptyDecode(f *testing.F) {
+	rnd := rand.New(rand.NewPCG(0, 0))
+	for i := 0; i < 4; i++ {
+		f.Add(emptyEncode(nil, randEmpty(rnd)))
+	}
+	f.Fuzz(func(t *testing.T, b []byte) {
+		x, _, err := emptyDecode(b)
+		if err {
+			return
+		}
+		b0 := emptyEncode(nil, x)
+		y, rem, err := emptyDecode(b0)
+		if err || len(rem) != 0 {
+			t.Fatal()
+		}
+		if !bytes.Equal(b0, emptyEncode(nil, y)) {
+			t.Fatal()
+		}
+	})
+}
+func TestEmptyRoundTrip(t *testing.T) {
+	rnd := rand.New(rand.NewPCG(0, 0))
+	for i := 0; i < 100; i++ {
+		b := emptyEncode(nil, randEmpty(rnd))
+		x, rem, err := emptyDecode(b)
+		if err || len(rem) != 0 {
+			t.Fatal()
+		}
+		if !bytes.Equal(b, emptyEncode(nil, x)) {
+			t.Fatal()
+		}
+	}
+}
+func randInner(rnd *rand.Rand) *inner {
+	o := &inner{}
+	o.a1 = rnd.Uint64()
+	return o
+}
+func randMsg(rnd *rand.Rand) *msg {
+	o := &msg{}
+	o.a1 = rnd.Uint64()
+	o.a2 = randBytes(rnd, 2)
+	o.a3 = randInner(rnd)
+	if rnd.IntN(2) == 0 {
+		o.a4 = randInner(rnd)
+	}
+	return o
+}
+func randEmpty(rnd *rand.Rand) *empty {
+	o := &empty{}
+	return o
+}
+func randBytes(rnd *rand.Rand, n int) []byte {
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = byte(rnd.Uint32())
+	}
+	return b
+}
+func vectorCodec(name string, b []byte) (enc []byte, err bool) {
+	switch name {
+	case "inner":
+		x, rem, errb := innerDecode(b)
+		if errb || len(rem) != 0 {
+			return nil, true
+		}
+		return innerEncode(nil, x), false
+	case "msg":
+		x, rem, errb := msgDecode(b)
+		if errb || len(rem) != 0 {
+			return nil, true
+		}
+		return msgEncode(nil, x), false
+	case "empty":
+		x, rem, errb := emptyDecode(b)
+		if errb || len(rem) != 0 {
+			return nil, true
+		}
+		return emptyEncode(nil, x), false
+	}
+	return nil, true
+}
+func TestVectors(t *testing.T) {
+	data, err := os.ReadFile("version.vectors.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var vs struct {
+		Vectors []struct {
+			Name, Struct, Hex string
+		}
+	}
+	err = json.Unmarshal(data, &vs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(vs.Vectors) == 0 {
+		t.Fatal("no vectors")
+	}
+	for _, v := range vs.Vectors {
+		b, err := hex.DecodeString(v.Hex)
+		if err != nil {
+			t.Fatal(err)
+		}
+		enc, errb := vectorCodec(v.Struct, b)
+		if errb {
+			t.Fatalf("%s: decode failed", v.Name)
+		}
+		if !bytes.Equal(b, enc) {
+			t.Fatalf("%s: encoding differs", v.Name)
+		}
+	}
+}
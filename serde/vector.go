@@ -0,0 +1,419 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"log"
+	"slices"
+	"strconv"
+)
+
+// test vectors have a sample value of each struct and its canonical encoding.
+// they're encoded from the schema, independent of the Go codecs,
+// so other languages can check against them.
+
+type vectors struct {
+	Package string    `json:"package"`
+	Vectors []*vector `json:"vectors"`
+}
+
+type vector struct {
+	// Name is the struct, with a suffix for variants.
+	Name   string `json:"name"`
+	Struct string `json:"struct"`
+	Value  any    `json:"value"`
+	Hex    string `json:"hex"`
+}
+
+// object is a JSON object that keeps its field order.
+type object []*objectField
+
+type objectField struct {
+	key string
+	val any
+}
+
+func (o object) MarshalJSON() ([]byte, error) {
+	b := []byte{'{'}
+	for i, f := range o {
+		if i != 0 {
+			b = append(b, ',')
+		}
+		b = strconv.AppendQuote(b, f.key)
+		b = append(b, ':')
+		val, err := json.Marshal(f.val)
+		if err != nil {
+			return nil, err
+		}
+		b = append(b, val...)
+	}
+	return append(b, '}'), nil
+}
+
+// vecGen makes deterministic sample values.
+type vecGen struct {
+	c *compiler
+	// n makes each sample value different.
+	n uint64
+}
+
+func (g *vecGen) next() uint64 {
+	g.n++
+	return g.n
+}
+
+// genVectors returns the JSON test vectors.
+func (c *compiler) genVectors() []byte {
+	vs := &vectors{Package: c.pkg.PkgPath}
+	add := func(name string, o types.Object, variant int, unset bool) {
+		g := &vecGen{c: c}
+		val, enc := g.genStruct(o.Type().(*types.Named), variant, unset)
+		vs.Vectors = append(vs.Vectors, &vector{Name: name, Struct: o.Name(), Value: val, Hex: hex.EncodeToString(enc)})
+	}
+	for _, o := range c.sts {
+		if enc, _ := c.shouldGen(o); !enc {
+			continue
+		}
+		st := o.Type().Underlying().(*types.Struct)
+		if c.isUnion(o) {
+			for i := 0; i < st.NumFields(); i++ {
+				add(o.Name()+"/"+st.Field(i).Name(), o, i, false)
+			}
+			continue
+		}
+		add(o.Name(), o, 0, false)
+		for i := 0; i < st.NumFields(); i++ {
+			if c.isOptional(st.Field(i).Pos()) {
+				add(o.Name()+"/unset", o, 0, true)
+				break
+			}
+		}
+	}
+	out, err := json.MarshalIndent(vs, "", "\t")
+	if err != nil {
+		log.Panic(err)
+	}
+	return append(out, '\n')
+}
+
+// genStruct samples a struct.
+// variant is the union variant, and unset leaves out optional fields.
+func (g *vecGen) genStruct(n *types.Named, variant int, unset bool) (object, []byte) {
+	c := g.c
+	o := n.Obj()
+	st := n.Underlying().(*types.Struct)
+	var val object
+	var enc []byte
+	if c.isUnion(o) {
+		checkUnion(o.Name(), st)
+		for i := 0; i < st.NumFields(); i++ {
+			field := st.Field(i)
+			if i != variant {
+				val = append(val, &objectField{field.Name(), nil})
+				continue
+			}
+			v, b := g.genType(field.Type(), -1)
+			val = append(val, &objectField{field.Name(), v})
+			enc = append([]byte{byte(i)}, b...)
+		}
+		return val, enc
+	}
+
+	ver := c.getVersion(o)
+	c.checkVersion(st, ver)
+	for i := 0; i < st.NumFields(); i++ {
+		field := st.Field(i)
+		v, b := g.genField(field, unset)
+		val = append(val, &objectField{field.Name(), v})
+		enc = append(enc, b...)
+	}
+	if ver != 0 {
+		enc = encSlice(binary.LittleEndian.AppendUint64(nil, ver), enc)
+	}
+	return val, enc
+}
+
+func (g *vecGen) genField(field *types.Var, unset bool) (any, []byte) {
+	c := g.c
+	if c.isOptional(field.Pos()) {
+		checkOptionalTy(field.Type())
+		if unset {
+			return nil, []byte{0}
+		}
+		v, b := g.genType(field.Type(), -1)
+		return v, append([]byte{1}, b...)
+	}
+	if isCst, cst := c.getConst(field.Pos()); isCst {
+		checkConstTy(field.Type())
+		return genConst(field.Type().Underlying().(*types.Basic), cst)
+	}
+	length := -1
+	if op, n := c.getLen(field.Pos()); op != token.ILLEGAL {
+		checkLenTy(field.Type())
+		x, _ := strconv.Atoi(n)
+		length = x
+		// keep maxlen samples small.
+		if op == token.GTR {
+			length = min(x, 2)
+		}
+	}
+	return g.genType(field.Type(), length)
+}
+
+// genType samples ty.
+// length is the len of slices and strings, or -1 for the default.
+func (g *vecGen) genType(ty types.Type, length int) (any, []byte) {
+	switch t := ty.Underlying().(type) {
+	case *types.Basic:
+		if t.Kind() == types.String {
+			if length == -1 {
+				length = 2
+			}
+			var s []byte
+			for i := 0; i < length; i++ {
+				s = append(s, 'a'+byte(g.next()%26))
+			}
+			return string(s), encSlice(nil, s)
+		}
+		return genBasic(t, g.next())
+	case *types.Pointer:
+		return g.genStruct(getStructTy(t), 0, false)
+	case *types.Slice:
+		if isByte(t.Elem()) {
+			if length == -1 {
+				length = 2
+			}
+			b := g.genBytes(length)
+			return hex.EncodeToString(b), encSlice(nil, b)
+		}
+		if length == -1 {
+			length = 1
+		}
+		var vals []any
+		enc := binary.LittleEndian.AppendUint64(nil, uint64(length))
+		for i := 0; i < length; i++ {
+			v, b := g.genType(t.Elem(), -1)
+			vals = append(vals, v)
+			enc = append(enc, b...)
+		}
+		return vals, enc
+	case *types.Array:
+		if isByte(t.Elem()) {
+			b := g.genBytes(int(t.Len()))
+			return hex.EncodeToString(b), b
+		}
+		var vals []any
+		var enc []byte
+		for i := int64(0); i < t.Len(); i++ {
+			v, b := g.genType(t.Elem(), -1)
+			vals = append(vals, v)
+			enc = append(enc, b...)
+		}
+		return vals, enc
+	case *types.Map:
+		checkMapKeyTy(t.Key())
+		type entry struct {
+			key, val any
+			keyEnc   []byte
+			valEnc   []byte
+		}
+		var ents []*entry
+		for i := 0; i < 2; i++ {
+			k, kb := g.genType(t.Key(), -1)
+			v, vb := g.genType(t.Elem(), -1)
+			ents = append(ents, &entry{k, v, kb, vb})
+		}
+		// canonical maps are sorted by key.
+		slices.SortFunc(ents, func(a, b *entry) int {
+			return compareKeys(a.key, b.key)
+		})
+		var vals []any
+		enc := binary.LittleEndian.AppendUint64(nil, uint64(len(ents)))
+		for _, e := range ents {
+			vals = append(vals, []any{e.key, e.val})
+			enc = append(enc, e.keyEnc...)
+			enc = append(enc, e.valEnc...)
+		}
+		return vals, enc
+	default:
+		log.Panic("unsupported type: ", ty)
+	}
+	return nil, nil
+}
+
+func (g *vecGen) genBytes(length int) []byte {
+	b := make([]byte, length)
+	for i := range b {
+		b[i] = byte(g.next())
+	}
+	return b
+}
+
+func isByte(ty types.Type) bool {
+	t, ok := ty.Underlying().(*types.Basic)
+	return ok && t.Kind() == types.Byte
+}
+
+func encSlice(b []byte, data []byte) []byte {
+	b = binary.LittleEndian.AppendUint64(b, uint64(len(data)))
+	return append(b, data...)
+}
+
+// genBasic samples a basic type, with x as the seed.
+func genBasic(t *types.Basic, x uint64) (any, []byte) {
+	_ = getBasicFunc(t)
+	le := binary.LittleEndian
+	switch t.Kind() {
+	case types.Bool:
+		return true, []byte{1}
+	case types.Byte:
+		return byte(x), []byte{byte(x)}
+	case types.Uint16:
+		return uint16(x), le.AppendUint16(nil, uint16(x))
+	case types.Uint32:
+		return uint32(x), le.AppendUint32(nil, uint32(x))
+	case types.Uint64:
+		return x, le.AppendUint64(nil, x)
+	case types.Int64:
+		// negative, to check two's complement.
+		return -int64(x), le.AppendUint64(nil, uint64(-int64(x)))
+	}
+	log.Panic("unsupported type: ", t)
+	return nil, nil
+}
+
+func genConst(t *types.Basic, cst string) (any, []byte) {
+	if t.Kind() == types.Bool {
+		if cst == "true" {
+			return true, []byte{1}
+		}
+		return false, []byte{0}
+	}
+	if t.Kind() == types.Int64 {
+		x, err := strconv.ParseInt(cst, 0, 64)
+		if err != nil {
+			log.Panic("bad const: ", cst)
+		}
+		return x, binary.LittleEndian.AppendUint64(nil, uint64(x))
+	}
+	x, err := strconv.ParseUint(cst, 0, 64)
+	if err != nil {
+		log.Panic("bad const: ", cst)
+	}
+	return genBasic(t, x)
+}
+
+// compareKeys compares two sampled map keys of the same type.
+func compareKeys(a, b any) int {
+	switch a := a.(type) {
+	case string:
+		return bytes.Compare([]byte(a), []byte(b.(string)))
+	case byte:
+		return int(a) - int(b.(byte))
+	case uint16:
+		return int(a) - int(b.(uint16))
+	case uint32:
+		return int(a) - int(b.(uint32))
+	case uint64:
+		if a < b.(uint64) {
+			return -1
+		}
+		return 1
+	case int64:
+		if a < b.(int64) {
+			return -1
+		}
+		return 1
+	}
+	log.Panicf("unsupported key: %v", a)
+	return 0
+}
+
+// genVectorTest makes vectorCodec, which decodes and re-encodes a
+// struct by name, and TestVectors, which runs it on the vectors in file.
+// encode-only structs have nothing to check, so they echo their input.
+func (c *compiler) genVectorTest(file string) []ast.Decl {
+	bytesTy := &ast.ArrayType{Elt: ident("byte")}
+	var cases []ast.Stmt
+	for _, o := range c.sts {
+		enc, dec := c.shouldGen(o)
+		if !enc {
+			continue
+		}
+		name := o.Name()
+		body := []ast.Stmt{ret(ident("b"), ident("false"))}
+		if dec {
+			body = []ast.Stmt{
+				define([]string{"x", "rem", "errb"}, call(ident(name+"Decode"), ident("b"))),
+				ifStmt(&ast.BinaryExpr{X: ident("errb"), Op: token.LOR, Y: hasLen("rem")},
+					ret(ident("nil"), ident("true"))),
+				ret(call(ident(name+"Encode"), ident("nil"), ident("x")), ident("false")),
+			}
+		}
+		cases = append(cases, &ast.CaseClause{List: []ast.Expr{strLit(name)}, Body: body})
+	}
+	codec := &ast.FuncDecl{
+		Name: ident("vectorCodec"),
+		Type: &ast.FuncType{
+			Params: &ast.FieldList{List: []*ast.Field{
+				{Names: []*ast.Ident{ident("name")}, Type: ident("string")},
+				{Names: []*ast.Ident{ident("b")}, Type: bytesTy},
+			}},
+			Results: &ast.FieldList{List: []*ast.Field{
+				{Names: []*ast.Ident{ident("enc")}, Type: bytesTy},
+				{Names: []*ast.Ident{ident("err")}, Type: ident("bool")},
+			}},
+		},
+		Body: block(
+			&ast.SwitchStmt{Tag: ident("name"), Body: block(cases...)},
+			ret(ident("nil"), ident("true")),
+		),
+	}
+
+	fatal := func(args ...ast.Expr) ast.Stmt {
+		return &ast.ExprStmt{X: call(sel("t", "Fatal"), args...)}
+	}
+	fatalf := func(msg string) ast.Stmt {
+		return &ast.ExprStmt{X: call(sel("t", "Fatalf"), strLit("%s: "+msg), sel("v", "Name"))}
+	}
+	isErr := &ast.BinaryExpr{X: ident("err"), Op: token.NEQ, Y: ident("nil")}
+	vecTy := &ast.StructType{Fields: &ast.FieldList{List: []*ast.Field{
+		{Names: []*ast.Ident{ident("Name"), ident("Struct"), ident("Hex")}, Type: ident("string")},
+	}}}
+	vecsTy := &ast.StructType{Fields: &ast.FieldList{List: []*ast.Field{
+		{Names: []*ast.Ident{ident("Vectors")}, Type: &ast.ArrayType{Elt: vecTy}},
+	}}}
+	loop := &ast.RangeStmt{Key: ident("_"), Value: ident("v"), Tok: token.DEFINE, X: sel("vs", "Vectors"), Body: block(
+		define([]string{"b", "err"}, call(sel("hex", "DecodeString"), sel("v", "Hex"))),
+		ifStmt(isErr, fatal(ident("err"))),
+		define([]string{"enc", "errb"}, call(ident("vectorCodec"), sel("v", "Struct"), ident("b"))),
+		ifStmt(ident("errb"), fatalf("decode failed")),
+		ifStmt(notEqual(ident("b"), ident("enc")), fatalf("encoding differs")),
+	)}
+	test := &ast.FuncDecl{
+		Name: ident("TestVectors"),
+		Type: &ast.FuncType{Params: &ast.FieldList{List: []*ast.Field{
+			{Names: []*ast.Ident{ident("t")}, Type: &ast.StarExpr{X: sel("testing", "T")}},
+		}}},
+		Body: block(
+			define([]string{"data", "err"}, call(sel("os", "ReadFile"), strLit(file))),
+			ifStmt(isErr, fatal(ident("err"))),
+			varDecl("vs", vecsTy, nil),
+			assign("err", call(sel("json", "Unmarshal"), ident("data"), &ast.UnaryExpr{Op: token.AND, X: ident("vs")})),
+			ifStmt(isErr, fatal(ident("err"))),
+			ifStmt(&ast.BinaryExpr{X: call(ident("len"), sel("vs", "Vectors")), Op: token.EQL, Y: intLit(0)},
+				fatal(strLit("no vectors"))),
+			loop,
+		),
+	}
+	return []ast.Decl{codec, test}
+}
+
+func strLit(s string) *ast.BasicLit {
+	return &ast.BasicLit{Kind: token.STRING, Value: strconv.Quote(s)}
+}
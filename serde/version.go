@@ -0,0 +1,110 @@
+package main
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"log"
+	"strconv"
+	"strings"
+)
+
+// versioned structs are encoded as a version, followed by a body
+// with the fields.
+// fields can be appended in later versions, and older decoders skip them.
+// the body is canonical for versions the decoder knows,
+// so it can't have trailing bytes.
+
+// getVersion returns the struct's version, or 0 if it's not versioned.
+func (c *compiler) getVersion(o types.Object) uint64 {
+	for _, comm := range c.getTypeDoc(o) {
+		text, found0 := strings.CutPrefix(comm.Text, "// serde: version ")
+		text, found1 := strings.CutSuffix(text, ".")
+		if found0 && found1 {
+			return parseVersion(text)
+		}
+	}
+	return 0
+}
+
+// getSince returns the version that added a field.
+func (c *compiler) getSince(pos token.Pos) uint64 {
+	for _, comm := range c.getFieldDoc(pos) {
+		text, found0 := strings.CutPrefix(comm.Text, "// serde: since ")
+		text, found1 := strings.CutSuffix(text, ".")
+		if found0 && found1 {
+			return parseVersion(text)
+		}
+	}
+	return 1
+}
+
+func parseVersion(text string) uint64 {
+	ver, err := strconv.ParseUint(text, 10, 64)
+	if err != nil || ver == 0 {
+		log.Panic("bad version: ", text)
+	}
+	return ver
+}
+
+// checkVersion panics if fields weren't appended in version order.
+func (c *compiler) checkVersion(st *types.Struct, ver uint64) {
+	prev := uint64(1)
+	for i := 0; i < st.NumFields(); i++ {
+		field := st.Field(i)
+		since := c.getSince(field.Pos())
+		if since == 1 {
+			continue
+		}
+		if ver == 0 {
+			log.Panic("since on unversioned field: ", field.Name())
+		}
+		if since < prev || since > ver {
+			log.Panic("out of order since: ", field.Name())
+		}
+		if isCst, _ := c.getConst(field.Pos()); isCst {
+			log.Panic("unsupported const since: ", field.Name())
+		}
+		prev = since
+	}
+}
+
+func genVersionEncStart() ast.Stmt {
+	return varDecl("b", &ast.ArrayType{Elt: ident("byte")}, nil)
+}
+
+func genVersionEncEnd(ver uint64) ast.Stmt {
+	b := call(sel("marshal", "WriteInt"), ident("b0"), intLit(int64(ver)))
+	return ret(call(sel("safemarshal", "WriteSlice1D"), b, ident("b")))
+}
+
+func genVersionDec() []ast.Stmt {
+	return []ast.Stmt{
+		define([]string{"ver", "bVer", "errVer"}, call(sel("safemarshal", "ReadInt"), ident("b0"))),
+		ifStmt(ident("errVer"), retErr(ident("nil"))),
+		ifStmt(&ast.BinaryExpr{X: ident("ver"), Op: token.EQL, Y: intLit(0)}, retErr(ident("nil"))),
+		define([]string{"body", "tail", "errBody"}, call(sel("safemarshal", "ReadSlice1D"), ident("bVer"))),
+		ifStmt(ident("errBody"), retErr(ident("nil"))),
+	}
+}
+
+// genSinceFieldDec decodes a field that older versions don't have.
+func (c *compiler) genSinceFieldDec(field *types.Var, fieldNum int, oldB string, since uint64) []ast.Stmt {
+	newX := "a" + strconv.Itoa(fieldNum+1)
+	newB := "b" + strconv.Itoa(fieldNum+1)
+	dec := c.genFieldDec(field, oldB, "")
+	dec = append(dec, assign(newX, ident("a")), assign(newB, ident("b")))
+	hasField := &ast.BinaryExpr{X: ident("ver"), Op: token.GEQ, Y: intLit(int64(since))}
+	return []ast.Stmt{
+		varDecl(newX, c.getTypeExpr(field.Type()), nil),
+		varDecl(newB, nil, ident(oldB)),
+		ifStmt(hasField, dec...),
+	}
+}
+
+// genTrailingCheck errors on trailing bytes in known versions.
+func genTrailingCheck(lastB string, ver uint64) ast.Stmt {
+	isKnown := &ast.BinaryExpr{X: ident("ver"), Op: token.LEQ, Y: intLit(int64(ver))}
+	hasTrailing := &ast.BinaryExpr{X: call(ident("len"), ident(lastB)), Op: token.NEQ, Y: intLit(0)}
+	return ifStmt(&ast.BinaryExpr{X: isKnown, Op: token.LAND, Y: hasTrailing}, retErr(ident("nil")))
+}
@@ -0,0 +1,150 @@
+package server
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sanjit-bhat/pav/ktcore"
+	"github.com/sanjit-bhat/pav/safemarshal"
+	"github.com/tchajed/marshal"
+)
+
+var updateCompat = flag.Bool("update", false, "add missing golden msgs for the current schema versions")
+
+// compatMsg is a versioned msg.
+// the goldens get re-encoded with the generated [vectorCodec].
+type compatMsg struct {
+	version uint64
+	// new returns a msg that sets all fields.
+	new func() []byte
+}
+
+// compatMsgs has the current version of each msg.
+// goldens are never regenerated or edited, and -update only adds
+// the missing golden for a new version.
+// when bumping a version, the old goldens check that new decoders
+// still accept msgs from old encoders.
+var compatMsgs = map[string]*compatMsg{
+	"HistoryReply": {
+		version: 2,
+		new: func() []byte {
+			memb := &ktcore.Memb{LabelProof: fill(1, 80), PkOpen: &ktcore.CommitOpen{Val: []byte{2}, Rand: []byte{3}}, MerkleProof: []byte{4}}
+			bound := &ktcore.NonMemb{LabelProof: fill(5, 80), MerkleProof: []byte{6}}
+			return HistoryReplyEncode(nil, &HistoryReply{ChainProof: []byte{7}, LinkSig: fill(8, 64), Hist: []*ktcore.Memb{memb}, Bound: bound, Epoch: 9})
+		},
+	},
+	"AuditReply": {
+		version: 1,
+		new: func() []byte {
+			upd := &ktcore.UpdateProof{MapLabel: fill(1, 32), MapVal: fill(2, 32), NonMembProof: []byte{3}}
+			p := &ktcore.AuditProof{Updates: []*ktcore.UpdateProof{upd}, LinkSig: fill(4, 64)}
+			return AuditReplyEncode(nil, &AuditReply{P: []*ktcore.AuditProof{p}})
+		},
+	},
+}
+
+// fill returns a msg field of n copies of x.
+func fill(x byte, n int) []byte {
+	return bytes.Repeat([]byte{x}, n)
+}
+
+func compatPath(name string, version uint64) string {
+	return filepath.Join("testdata", "compat", fmt.Sprintf("%s.v%d.hex", name, version))
+}
+
+func TestCompat(t *testing.T) {
+	for name, m := range compatMsgs {
+		if p := compatPath(name, m.version); *updateCompat {
+			if _, err := os.Stat(p); errors.Is(err, fs.ErrNotExist) {
+				if err = os.WriteFile(p, []byte(hex.EncodeToString(m.new())+"\n"), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+		}
+		for ver := uint64(1); ver <= m.version; ver++ {
+			data, err := os.ReadFile(compatPath(name, ver))
+			if err != nil {
+				t.Fatal(err)
+			}
+			b, err := hex.DecodeString(strings.TrimSpace(string(data)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			enc, errb := vectorCodec(name, b)
+			if errb {
+				t.Fatalf("%s v%d: decode failed", name, ver)
+			}
+			// the current version round-trips.
+			if ver == m.version && !bytes.Equal(b, enc) {
+				t.Fatalf("%s v%d: encoding changed", name, ver)
+			}
+			// older versions gain zeroed fields at the end.
+			if ver < m.version && !bytes.HasPrefix(compatBody(t, enc), compatBody(t, b)) {
+				t.Fatalf("%s v%d: old fields changed", name, ver)
+			}
+		}
+	}
+}
+
+// compatBody returns the fields of a versioned msg.
+func compatBody(t *testing.T, b []byte) []byte {
+	_, rem, errb := safemarshal.ReadInt(b)
+	if errb {
+		t.Fatal()
+	}
+	body, _, errb := safemarshal.ReadSlice1D(rem)
+	if errb {
+		t.Fatal()
+	}
+	return body
+}
+
+// TestCompatOld checks the v1 HistoryReply golden,
+// which predates Epoch.
+func TestCompatOld(t *testing.T) {
+	data, err := os.ReadFile(compatPath("HistoryReply", 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := hex.DecodeString(strings.TrimSpace(string(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, rem, errb := HistoryReplyDecode(b)
+	if errb || len(rem) != 0 {
+		t.Fatal()
+	}
+	if r.Epoch != 0 || !bytes.Equal(r.LinkSig, fill(8, 64)) || len(r.Hist) != 1 || r.Bound == nil {
+		t.Fatal()
+	}
+}
+
+// TestCompatNewer checks that decoders skip fields from newer versions.
+func TestCompatNewer(t *testing.T) {
+	m := compatMsgs["HistoryReply"]
+	body := compatBody(t, m.new())
+	// a newer encoder appends a field.
+	body = marshal.WriteInt(body, 9)
+	newer := safemarshal.WriteSlice1D(marshal.WriteInt(nil, m.version+1), body)
+	r, rem, errb := HistoryReplyDecode(newer)
+	if errb || len(rem) != 0 {
+		t.Fatal()
+	}
+	if !bytes.Equal(r.LinkSig, fill(8, 64)) {
+		t.Fatal()
+	}
+
+	// the known version can't have trailing bytes.
+	old := safemarshal.WriteSlice1D(marshal.WriteInt(nil, m.version), body)
+	if _, _, errb := HistoryReplyDecode(old); !errb {
+		t.Fatal()
+	}
+}
@@ -0,0 +1,170 @@
+//go:build !goose
+
+package server
+
+import (
+	"bytes"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/sanjit-bhat/pav/cryptoffi"
+	"github.com/sanjit-bhat/pav/hashchain/diskchain"
+	"github.com/sanjit-bhat/pav/merkle"
+	"github.com/sanjit-bhat/pav/merkle/diskstore"
+	"github.com/sanjit-bhat/pav/safemarshal"
+	"github.com/tchajed/marshal"
+)
+
+// Open restores the server in dir, or starts a new one there.
+// its identity comes from bundle and key, as in [NewFromSecrets].
+//
+// each epoch's dig goes in a [diskchain.Chain], with the epoch's
+// accepted puts as its aux record.
+// the merkle nodes of every epoch go in a [diskstore.Store],
+// so old epochs don't stay on the heap.
+// the chain head is synced before the epoch is served,
+// so a restart never loses a signed epoch.
+// Open replays the puts to rebuild the key directory,
+// and errors if it doesn't match the signed head.
+func Open(dir string, bundle, key []byte) (s *Server, sigPk cryptoffi.SigPublicKey, err bool) {
+	if s, sigPk, err = open(dir, bundle, key); err {
+		s = nil
+		return
+	}
+	go s.worker()
+	return
+}
+
+// nodeCacheSz is how many merkle nodes [Open] caches in memory.
+const nodeCacheSz = 1 << 16
+
+// open is [Open], without starting the worker.
+func open(dir string, bundle, key []byte) (s *Server, sigPk cryptoffi.SigPublicKey, err bool) {
+	s, sigPk, err = newFromSecrets(bundle, key)
+	if err {
+		return
+	}
+	// replay rebuilds the nodes, so they aren't durable state,
+	// and we start a new node log.
+	nodesPath := filepath.Join(dir, "nodes")
+	if err0 := os.Remove(nodesPath); err0 != nil && !errors.Is(err0, fs.ErrNotExist) {
+		err = true
+		return
+	}
+	st, err := diskstore.Open(nodesPath, nodeCacheSz)
+	if err {
+		return
+	}
+	s.keys.hidden = merkle.NewWithStore(s.hashSuite, st)
+	s.hist.maps[0] = s.keys.hidden.Snapshot()
+
+	c, head, err := diskchain.Open(filepath.Join(dir, "chain"), s.hashSuite, s.chainMode)
+	if err {
+		st.Close()
+		return
+	}
+	if head == nil {
+		// new dir. store epoch 0 from [newServer].
+		c.Append(s.hist.maps[0].Hash())
+		if err = c.SetHead(s.hist.audits[0].LinkSig); err {
+			c.Close()
+			st.Close()
+			return
+		}
+	} else if err = s.replay(c); err {
+		c.Close()
+		st.Close()
+		return
+	}
+
+	// the replayed chain should be the stored one.
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	lastSig := s.hist.audits[len(s.hist.audits)-1].LinkSig
+	if c.Len() != uint64(len(s.hist.audits)) ||
+		!bytes.Equal(c.Chain().Tail().Link(), s.hist.chain.Tail().Link()) {
+		c.Close()
+		st.Close()
+		err = true
+		return
+	}
+	if head != nil && !bytes.Equal(head.Sig, lastSig) {
+		c.Close()
+		st.Close()
+		err = true
+		return
+	}
+	s.hist.chain = c.Chain()
+	s.store = &diskStore{c: c}
+	return
+}
+
+// replay re-does the epochs after epoch 0.
+func (s *Server) replay(c *diskchain.Chain) (err bool) {
+	for ep := uint64(1); ep < c.Len(); ep++ {
+		aux, err0 := c.Aux(ep)
+		if err0 {
+			err = true
+			return
+		}
+		puts, err0 := decodePuts(aux)
+		if err0 {
+			err = true
+			return
+		}
+		batch := make([]*work, 0, len(puts))
+		for _, p := range puts {
+			batch = append(batch, s.newWork(p.Uid, p.Ver, p.Pk))
+		}
+		s.doWork(batch)
+		// doWork drops bad versions, which we never store.
+		if len(s.hist.audits[ep].Updates) != len(puts) {
+			err = true
+			return
+		}
+	}
+	return
+}
+
+// diskStore is an [epochStore] on a [diskchain.Chain].
+type diskStore struct {
+	c *diskchain.Chain
+}
+
+func (d *diskStore) Append(puts []*work, dig []byte) (link []byte) {
+	return d.c.AppendAux(dig, encodePuts(puts))
+}
+
+func (d *diskStore) SetHead(sig []byte) {
+	if d.c.SetHead(sig) {
+		panic("server: set head")
+	}
+}
+
+func encodePuts(puts []*work) []byte {
+	b := marshal.WriteInt(nil, uint64(len(puts)))
+	for _, w := range puts {
+		b = PutArgEncode(b, &PutArg{Uid: w.uid, Pk: w.pk, Ver: w.ver})
+	}
+	return b
+}
+
+func decodePuts(b []byte) (puts []*PutArg, err bool) {
+	n, b, err := safemarshal.ReadInt(b)
+	if err {
+		return
+	}
+	for i := uint64(0); i < n; i++ {
+		var p *PutArg
+		if p, b, err = PutArgDecode(b); err {
+			return
+		}
+		puts = append(puts, p)
+	}
+	if len(b) != 0 {
+		err = true
+	}
+	return
+}
@@ -0,0 +1,45 @@
+package server
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sanjit-bhat/pav/cryptoffi"
+	"github.com/sanjit-bhat/pav/hashchain"
+)
+
+func TestOpenTampered(t *testing.T) {
+	s, _ := New(cryptoffi.VrfSuiteTai, cryptoffi.HashSuiteSha256, hashchain.ModeLinear)
+	kek := cryptoffi.RandBytes(cryptoffi.KekLen)
+	bundle := s.ExportSecrets(kek)
+	dir := t.TempDir()
+
+	// without workers, the test drives the epochs.
+	s0, _, err := open(dir, bundle, kek)
+	if err {
+		t.Fatal()
+	}
+	s0.doWork([]*work{s0.newWork(0, 0, []byte{1})})
+	s1, _, err := open(dir, bundle, kek)
+	if err {
+		t.Fatal()
+	}
+	if len(s1.hist.audits) != 2 || len(s1.keys.plain[0]) != 1 {
+		t.Fatal()
+	}
+	// the replayed map is on disk.
+	if s1.keys.hidden.Ptr() == 0 || !bytes.Equal(s1.keys.hidden.Hash(), s0.keys.hidden.Hash()) {
+		t.Fatal()
+	}
+
+	// a changed pk no longer matches the signed head.
+	path := filepath.Join(dir, "chain.aux")
+	b, _ := os.ReadFile(path)
+	b[len(b)-9] ^= 1
+	os.WriteFile(path, b, 0o600)
+	if _, _, err = open(dir, bundle, kek); !err {
+		t.Fatal()
+	}
+}
@@ -2,7 +2,9 @@ package server
 
 import (
 	"github.com/sanjit-bhat/pav/advrpc"
+	"github.com/sanjit-bhat/pav/cryptoffi"
 	"github.com/sanjit-bhat/pav/ktcore"
+	"github.com/sanjit-bhat/pav/merkle"
 )
 
 const (
@@ -10,6 +12,9 @@ const (
 	PutRpc
 	HistoryRpc
 	AuditRpc
+	GetAtEpochRpc
+	SkipRpc
+	ShardRpc
 )
 
 func NewRpcServer(s *Server) *advrpc.Server {
@@ -31,12 +36,15 @@ func NewRpcServer(s *Server) *advrpc.Server {
 	h[HistoryRpc] = func(arg []byte, reply *[]byte) {
 		a, _, err := HistoryArgDecode(arg)
 		if err {
-			r := &HistoryReply{Err: true}
-			*reply = HistoryReplyEncode(*reply, r)
+			*reply = HistoryReplyEncode(*reply, newHistoryErr())
 			return
 		}
-		r0, r1, r2, r3, r4 := s.History(a.Uid, a.PrevEpoch, a.PrevVerLen)
-		r := &HistoryReply{ChainProof: r0, LinkSig: r1, Hist: r2, Bound: r3, Err: r4}
+		r0, r1, r2, r3, r4, r5 := s.History(a.Uid, a.PrevEpoch, a.PrevVerLen)
+		if r5 {
+			*reply = HistoryReplyEncode(*reply, newHistoryErr())
+			return
+		}
+		r := &HistoryReply{ChainProof: r0, LinkSig: r1, Epoch: r2, Hist: r3, Bound: r4}
 		*reply = HistoryReplyEncode(*reply, r)
 	}
 	h[AuditRpc] = func(arg []byte, reply *[]byte) {
@@ -50,6 +58,39 @@ func NewRpcServer(s *Server) *advrpc.Server {
 		r := &AuditReply{P: r0, Err: r1}
 		*reply = AuditReplyEncode(*reply, r)
 	}
+	h[GetAtEpochRpc] = func(arg []byte, reply *[]byte) {
+		a, _, err := GetAtEpochArgDecode(arg)
+		if err {
+			r := &GetAtEpochReply{Err: true}
+			*reply = GetAtEpochReplyEncode(*reply, r)
+			return
+		}
+		r0, r1, r2, r3 := s.GetAtEpoch(a.Uid, a.Epoch, a.KnownEpoch)
+		r := &GetAtEpochReply{InclProof: r0, Hist: r1, Bound: r2, Err: r3}
+		*reply = GetAtEpochReplyEncode(*reply, r)
+	}
+	h[SkipRpc] = func(arg []byte, reply *[]byte) {
+		a, _, err := SkipArgDecode(arg)
+		if err {
+			r := &SkipReply{Err: true}
+			*reply = SkipReplyEncode(*reply, r)
+			return
+		}
+		r0, r1 := s.Skip(a.PrevEpoch, a.Epoch)
+		r := &SkipReply{ChainProof: r0, Err: r1}
+		*reply = SkipReplyEncode(*reply, r)
+	}
+	h[ShardRpc] = func(arg []byte, reply *[]byte) {
+		a, _, err := ShardArgDecode(arg)
+		if err {
+			r := &ShardReply{Err: true}
+			*reply = ShardReplyEncode(*reply, r)
+			return
+		}
+		r0, r1, r2, r3, r4 := s.Shard(a.Epoch, a.Prefix, a.PrefixLen)
+		r := &ShardReply{PrevEntries: r0, PrevProof: r1, NextEntries: r2, NextProof: r3, Err: r4}
+		*reply = ShardReplyEncode(*reply, r)
+	}
 	return advrpc.NewServer(h)
 }
 
@@ -77,6 +118,12 @@ func CallPut(c *advrpc.Client, uid uint64, pk []byte, ver uint64) {
 	c.Call(PutRpc, ab, rb)
 }
 
+// newHistoryErr returns an err reply.
+// LinkSig has a fixed len, so it's zeroed instead of empty.
+func newHistoryErr() *HistoryReply {
+	return &HistoryReply{LinkSig: make([]byte, cryptoffi.SigLen), Err: true}
+}
+
 func CallHistory(c *advrpc.Client, uid, prevEpoch, prevVerLen uint64) (chainProof []byte, linkSig []byte, hist []*ktcore.Memb, bound *ktcore.NonMemb, err ktcore.Blame) {
 	a := &HistoryArg{Uid: uid, PrevEpoch: prevEpoch, PrevVerLen: prevVerLen}
 	ab := HistoryArgEncode(nil, a)
@@ -94,6 +141,11 @@ func CallHistory(c *advrpc.Client, uid, prevEpoch, prevVerLen uint64) (chainProo
 		err = ktcore.BlameServFull
 		return
 	}
+	// Bound is only optional for err replies.
+	if r.Bound == nil {
+		err = ktcore.BlameServFull
+		return
+	}
 	return r.ChainProof, r.LinkSig, r.Hist, r.Bound, ktcore.BlameNone
 }
 
@@ -116,3 +168,68 @@ func CallAudit(c *advrpc.Client, prevEpoch uint64) (p []*ktcore.AuditProof, err
 	}
 	return r.P, ktcore.BlameNone
 }
+
+func CallGetAtEpoch(c *advrpc.Client, uid, epoch, knownEpoch uint64) (inclProof []byte, hist []*ktcore.Memb, bound *ktcore.NonMemb, err ktcore.Blame) {
+	a := &GetAtEpochArg{Uid: uid, Epoch: epoch, KnownEpoch: knownEpoch}
+	ab := GetAtEpochArgEncode(nil, a)
+	rb := new([]byte)
+	if c.Call(GetAtEpochRpc, ab, rb) {
+		err = ktcore.BlameUnknown
+		return
+	}
+	r, _, errb := GetAtEpochReplyDecode(*rb)
+	if errb {
+		err = ktcore.BlameServFull
+		return
+	}
+	if r.Err {
+		err = ktcore.BlameServFull
+		return
+	}
+	// Bound is only optional for err replies.
+	if r.Bound == nil {
+		err = ktcore.BlameServFull
+		return
+	}
+	return r.InclProof, r.Hist, r.Bound, ktcore.BlameNone
+}
+
+func CallSkip(c *advrpc.Client, prevEpoch, epoch uint64) (chainProof []byte, err ktcore.Blame) {
+	a := &SkipArg{PrevEpoch: prevEpoch, Epoch: epoch}
+	ab := SkipArgEncode(nil, a)
+	rb := new([]byte)
+	if c.Call(SkipRpc, ab, rb) {
+		err = ktcore.BlameUnknown
+		return
+	}
+	r, _, errb := SkipReplyDecode(*rb)
+	if errb {
+		err = ktcore.BlameServFull
+		return
+	}
+	if r.Err {
+		err = ktcore.BlameServFull
+		return
+	}
+	return r.ChainProof, ktcore.BlameNone
+}
+
+func CallShard(c *advrpc.Client, epoch uint64, prefix []byte, prefixLen uint64) (prevEntries []*merkle.Entry, prevProof []byte, nextEntries []*merkle.Entry, nextProof []byte, err ktcore.Blame) {
+	a := &ShardArg{Epoch: epoch, Prefix: prefix, PrefixLen: prefixLen}
+	ab := ShardArgEncode(nil, a)
+	rb := new([]byte)
+	if c.Call(ShardRpc, ab, rb) {
+		err = ktcore.BlameUnknown
+		return
+	}
+	r, _, errb := ShardReplyDecode(*rb)
+	if errb {
+		err = ktcore.BlameServFull
+		return
+	}
+	if r.Err {
+		err = ktcore.BlameServFull
+		return
+	}
+	return r.PrevEntries, r.PrevProof, r.NextEntries, r.NextProof, ktcore.BlameNone
+}
@@ -0,0 +1,34 @@
+//go:build !goose
+
+package server
+
+import (
+	"github.com/sanjit-bhat/pav/advrpc"
+	"github.com/sanjit-bhat/pav/ktcore"
+	"github.com/sanjit-bhat/pav/safemarshal/stream"
+)
+
+// CallAuditStream is [CallAudit], except that it decodes the reply as
+// it arrives, passing each proof and update to the callbacks,
+// as in [AuditReplyDecodeStream].
+// budget bounds the reply len.
+func CallAuditStream(c *advrpc.Client, prevEpoch, budget uint64, eachP func(*ktcore.AuditProof) bool, eachPUpdates func(*ktcore.UpdateProof) bool) (err ktcore.Blame) {
+	a := &AuditArg{PrevEpoch: prevEpoch}
+	ab := AuditArgEncode(nil, a)
+	var errDec bool
+	errb := c.CallStream(AuditRpc, ab, budget, func(r *stream.Reader) bool {
+		rep, errb := AuditReplyDecodeStream(r, eachP, eachPUpdates)
+		if errb || rep.Err {
+			errDec = true
+			return true
+		}
+		return false
+	})
+	if errDec {
+		return ktcore.BlameServFull
+	}
+	if errb {
+		return ktcore.BlameUnknown
+	}
+	return ktcore.BlameNone
+}
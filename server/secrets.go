@@ -0,0 +1,154 @@
+package server
+
+import (
+	"github.com/goose-lang/std"
+	"github.com/sanjit-bhat/pav/cryptoffi"
+	"github.com/sanjit-bhat/pav/hashchain"
+)
+
+// KEK derivations for a [SecretBundle].
+const (
+	// KdfNone uses the key as a raw [cryptoffi.KekLen] KEK.
+	KdfNone byte = iota
+	// KdfArgon2id derives the KEK from a password,
+	// see [cryptoffi.KekFromPassword].
+	KdfArgon2id
+)
+
+// ExportSecrets seals the server identity under kek,
+// which should have length [cryptoffi.KekLen].
+// the plaintext secrets never leave memory.
+func (s *Server) ExportSecrets(kek []byte) (bundle []byte) {
+	std.Assert(uint64(len(kek)) == cryptoffi.KekLen)
+	return s.exportSecrets(KdfNone, nil, kek)
+}
+
+// ExportSecretsPassword is [Server.ExportSecrets],
+// with a KEK derived from password.
+func (s *Server) ExportSecretsPassword(password []byte) (bundle []byte) {
+	salt := cryptoffi.RandBytes(cryptoffi.KdfSaltLen)
+	kek := cryptoffi.KekFromPassword(password, salt)
+	return s.exportSecrets(KdfArgon2id, salt, kek)
+}
+
+func (s *Server) exportSecrets(kdf byte, salt, kek []byte) (bundle []byte) {
+	secs := &Secrets{
+		VrfSuite:  s.secs.vrf.Suite(),
+		HashSuite: s.hashSuite,
+		ChainMode: s.chainMode,
+		SigSk:     cryptoffi.SigPrivateKeyEncode(s.secs.sig),
+		VrfSk:     cryptoffi.VrfPrivateKeyEncode(s.secs.vrf),
+		Commit:    s.secs.commit,
+	}
+	pt := SecretsEncode(nil, secs)
+	b := &SecretBundle{Kdf: kdf, Salt: salt}
+	aad := SecretBundleEncode(nil, b)
+	b.Ct = cryptoffi.Seal(kek, aad, pt)
+	zero(pt)
+	zero(secs.SigSk)
+	zero(secs.VrfSk)
+	return SecretBundleEncode(nil, b)
+}
+
+// NewFromSecrets restores a server identity from a bundle made by
+// [Server.ExportSecrets] or [Server.ExportSecretsPassword].
+// key is the KEK or password used at export.
+// it errors if the bundle is malformed or the key is wrong.
+//
+// the restored server starts with an empty key directory.
+// clients that saw epochs from the old server will treat the
+// new epochs as equivocation, so only restore before going live,
+// or together with the directory state, as [Open] does.
+func NewFromSecrets(bundle, key []byte) (s *Server, sigPk cryptoffi.SigPublicKey, err bool) {
+	if s, sigPk, err = newFromSecrets(bundle, key); err {
+		return
+	}
+	go s.worker()
+	return
+}
+
+// newFromSecrets is [NewFromSecrets], without starting the worker.
+func newFromSecrets(bundle, key []byte) (s *Server, sigPk cryptoffi.SigPublicKey, err bool) {
+	b, rem, err := SecretBundleDecode(bundle)
+	if err {
+		return
+	}
+	if len(rem) != 0 {
+		err = true
+		return
+	}
+	var kek []byte
+	if b.Kdf == KdfNone {
+		if uint64(len(key)) != cryptoffi.KekLen {
+			err = true
+			return
+		}
+		kek = key
+	} else if b.Kdf == KdfArgon2id {
+		if uint64(len(b.Salt)) != cryptoffi.KdfSaltLen {
+			err = true
+			return
+		}
+		kek = cryptoffi.KekFromPassword(key, b.Salt)
+	} else {
+		err = true
+		return
+	}
+
+	aad := SecretBundleEncode(nil, &SecretBundle{Kdf: b.Kdf, Salt: b.Salt})
+	pt, err := cryptoffi.Open(kek, aad, b.Ct)
+	if err {
+		return
+	}
+	// the decoded secrets alias pt, so zero it only after importing them.
+	defer zero(pt)
+	secs0, rem, err := SecretsDecode(pt)
+	if err {
+		return
+	}
+	if len(rem) != 0 {
+		err = true
+		return
+	}
+	secs, err := importSecrets(secs0)
+	if err {
+		return
+	}
+	sigPk, sigSk, err := cryptoffi.SigPrivateKeyDecode(secs0.SigSk)
+	if err {
+		return
+	}
+	secs.sig = sigSk
+	s = newServer(secs0.HashSuite, secs0.ChainMode, secs)
+	return
+}
+
+// zero clears plaintext secrets once we're done with them.
+func zero(b []byte) {
+	for i := range b {
+		b[i] = 0
+	}
+}
+
+// importSecrets checks everything but the sig key.
+func importSecrets(secs0 *Secrets) (secs *secrets, err bool) {
+	if cryptoffi.CheckHashSuite(secs0.HashSuite) {
+		err = true
+		return
+	}
+	if hashchain.CheckMode(secs0.ChainMode) {
+		err = true
+		return
+	}
+	if uint64(len(secs0.Commit)) != cryptoffi.HashLen {
+		err = true
+		return
+	}
+	vrfSk, err := cryptoffi.VrfPrivateKeyDecode(secs0.VrfSuite, secs0.VrfSk)
+	if err {
+		return
+	}
+	// copy out of the plaintext, which gets zeroed.
+	secs = &secrets{vrf: vrfSk, commit: std.BytesClone(secs0.Commit)}
+	return
+}
@@ -0,0 +1,68 @@
+package server
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/sanjit-bhat/pav/cryptoffi"
+	"github.com/sanjit-bhat/pav/hashchain"
+)
+
+func TestSecrets(t *testing.T) {
+	s, sigPk := New(cryptoffi.VrfSuiteR255, cryptoffi.HashSuiteBlake3, hashchain.ModeLog)
+	_, vrf := s.Start()
+	kek := cryptoffi.RandBytes(cryptoffi.KekLen)
+	pw := []byte("pw")
+	bundles := [][]byte{s.ExportSecrets(kek), s.ExportSecretsPassword(pw)}
+	keys := [][]byte{kek, pw}
+
+	for i, bundle := range bundles {
+		// no plaintext secrets.
+		if bytes.Contains(bundle, s.secs.commit) {
+			t.Fatal()
+		}
+		if bytes.Contains(bundle, cryptoffi.SigPrivateKeyEncode(s.secs.sig)) {
+			t.Fatal()
+		}
+		if bytes.Contains(bundle, cryptoffi.VrfPrivateKeyEncode(s.secs.vrf)) {
+			t.Fatal()
+		}
+
+		// restored server has same identity.
+		s0, sigPk0, err := NewFromSecrets(bundle, keys[i])
+		if err {
+			t.Fatal()
+		}
+		if !bytes.Equal(sigPk, sigPk0) {
+			t.Fatal()
+		}
+		_, vrf0 := s0.Start()
+		if vrf.VrfSuite != vrf0.VrfSuite || vrf.HashSuite != vrf0.HashSuite || s.chainMode != s0.chainMode {
+			t.Fatal()
+		}
+		if !bytes.Equal(vrf.VrfPk, vrf0.VrfPk) || !bytes.Equal(vrf.VrfSig, vrf0.VrfSig) {
+			t.Fatal()
+		}
+		if !bytes.Equal(s.secs.commit, s0.secs.commit) {
+			t.Fatal()
+		}
+
+		// errors for wrong key or tampered bundle.
+		if _, _, err = NewFromSecrets(bundle, []byte("bad")); !err {
+			t.Fatal()
+		}
+		bundle0 := bytes.Clone(bundle)
+		bundle0[len(bundle0)-1] ^= 1
+		if _, _, err = NewFromSecrets(bundle0, keys[i]); !err {
+			t.Fatal()
+		}
+	}
+
+	// errors if kdf is swapped.
+	b, _, _ := SecretBundleDecode(bundles[0])
+	b.Kdf = KdfArgon2id
+	b.Salt = make([]byte, cryptoffi.KdfSaltLen)
+	if _, _, err := NewFromSecrets(SecretBundleEncode(nil, b), kek); !err {
+		t.Fatal()
+	}
+}
@@ -2,21 +2,57 @@ package server
 
 import (
 	"github.com/sanjit-bhat/pav/ktcore"
+	"github.com/sanjit-bhat/pav/merkle"
 )
 
 type StartChain struct {
+	// ChainMode is the [hashchain] mode.
+	// links from different modes never collide, so it doesn't need a sig.
+	ChainMode byte
 	// give [PrevLink] to prove that latest dig in hashchain.
 	PrevEpochLen uint64
-	PrevLink     []byte
-	ChainProof   []byte
-	LinkSig      []byte
+	// serde: len 32.
+	PrevLink []byte
+	// chain proofs have no maxlen, since [hashchain.ModeLinear] proofs
+	// grow with the epochs.
+	ChainProof []byte
+	// serde: len 64.
+	LinkSig []byte
 }
 
+// StartVrf has the server's static params, signed together in VrfSig.
 type StartVrf struct {
-	VrfPk  []byte
+	// VrfSuite is the [cryptoffi] VRF suite of VrfPk.
+	VrfSuite byte
+	// HashSuite is the [cryptoffi] hash suite of the directory.
+	HashSuite byte
+	VrfPk     []byte
+	// serde: len 64.
 	VrfSig []byte
 }
 
+// Secrets is the plaintext server identity.
+// it only lives in memory, and is sealed into a [SecretBundle] for storage.
+type Secrets struct {
+	VrfSuite  byte
+	HashSuite byte
+	ChainMode byte
+	SigSk     []byte
+	VrfSk     []byte
+	// serde: len 32.
+	Commit []byte
+}
+
+// SecretBundle is the encrypted-at-rest form of [Secrets].
+type SecretBundle struct {
+	// Kdf says how to get the KEK from the user's key.
+	Kdf byte
+	// Salt is only for [KdfArgon2id].
+	Salt []byte
+	// Ct seals [Secrets] under the KEK, with Kdf and Salt as aad.
+	Ct []byte
+}
+
 type StartReply struct {
 	Chain *StartChain
 	Vrf   *StartVrf
@@ -34,12 +70,34 @@ type HistoryArg struct {
 	PrevVerLen uint64
 }
 
+// serde: version 2.
 type HistoryReply struct {
 	ChainProof []byte
-	LinkSig    []byte
-	Hist       []*ktcore.Memb
-	Bound      *ktcore.NonMemb
-	Err        bool
+	// serde: len 64.
+	LinkSig []byte
+	Hist    []*ktcore.Memb
+	// serde: optional.
+	Bound *ktcore.NonMemb
+	Err   bool
+	// Epoch is the epoch of LinkSig.
+	// v1 replies don't have it, so clients get the epoch from ChainProof.
+	// serde: since 2.
+	Epoch uint64
+}
+
+type GetAtEpochArg struct {
+	Uid   uint64
+	Epoch uint64
+	// KnownEpoch is the caller's latest epoch, which [GetAtEpochReply.InclProof] ends at.
+	KnownEpoch uint64
+}
+
+type GetAtEpochReply struct {
+	InclProof []byte
+	Hist      []*ktcore.Memb
+	// serde: optional.
+	Bound *ktcore.NonMemb
+	Err   bool
 }
 
 type AuditArg struct {
@@ -47,6 +105,33 @@ type AuditArg struct {
 }
 
 type AuditReply struct {
+	// serde: stream.
 	P   []*ktcore.AuditProof
 	Err bool
 }
+
+type SkipArg struct {
+	PrevEpoch uint64
+	Epoch     uint64
+}
+
+type SkipReply struct {
+	ChainProof []byte
+	Err        bool
+}
+
+type ShardArg struct {
+	Epoch     uint64
+	Prefix    []byte
+	PrefixLen uint64
+}
+
+// ShardReply has the prefix sub-tree of the maps before and after
+// an epoch update, from [merkle.Map.ProvePrefix].
+type ShardReply struct {
+	PrevEntries []*merkle.Entry
+	PrevProof   []byte
+	NextEntries []*merkle.Entry
+	NextProof   []byte
+	Err         bool
+}
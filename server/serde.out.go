@@ -1,13 +1,17 @@
+// Auto-generated from spec "github.com/sanjit-bhat/pav/server/serde.go"
+// using compiler "github.com/sanjit-bhat/pav/serde".
 package server
 
 import (
 	"github.com/sanjit-bhat/pav/ktcore"
+	"github.com/sanjit-bhat/pav/merkle"
 	"github.com/sanjit-bhat/pav/safemarshal"
 	"github.com/tchajed/marshal"
 )
 
 func StartChainEncode(b0 []byte, o *StartChain) []byte {
 	var b = b0
+	b = safemarshal.WriteByte(b, o.ChainMode)
 	b = marshal.WriteInt(b, o.PrevEpochLen)
 	b = safemarshal.WriteSlice1D(b, o.PrevLink)
 	b = safemarshal.WriteSlice1D(b, o.ChainProof)
@@ -15,11 +19,11 @@ func StartChainEncode(b0 []byte, o *StartChain) []byte {
 	return b
 }
 func StartChainDecode(b0 []byte) (*StartChain, []byte, bool) {
-	a1, b1, err1 := safemarshal.ReadInt(b0)
+	a1, b1, err1 := safemarshal.ReadByte(b0)
 	if err1 {
 		return nil, nil, true
 	}
-	a2, b2, err2 := safemarshal.ReadSlice1D(b1)
+	a2, b2, err2 := safemarshal.ReadInt(b1)
 	if err2 {
 		return nil, nil, true
 	}
@@ -27,20 +31,101 @@ func StartChainDecode(b0 []byte) (*StartChain, []byte, bool) {
 	if err3 {
 		return nil, nil, true
 	}
+	if uint64(len(a3)) != 32 {
+		return nil, nil, true
+	}
 	a4, b4, err4 := safemarshal.ReadSlice1D(b3)
 	if err4 {
 		return nil, nil, true
 	}
-	return &StartChain{PrevEpochLen: a1, PrevLink: a2, ChainProof: a3, LinkSig: a4}, b4, false
+	a5, b5, err5 := safemarshal.ReadSlice1D(b4)
+	if err5 {
+		return nil, nil, true
+	}
+	if uint64(len(a5)) != 64 {
+		return nil, nil, true
+	}
+	return &StartChain{ChainMode: a1, PrevEpochLen: a2, PrevLink: a3, ChainProof: a4, LinkSig: a5}, b5, false
 }
 func StartVrfEncode(b0 []byte, o *StartVrf) []byte {
 	var b = b0
+	b = safemarshal.WriteByte(b, o.VrfSuite)
+	b = safemarshal.WriteByte(b, o.HashSuite)
 	b = safemarshal.WriteSlice1D(b, o.VrfPk)
 	b = safemarshal.WriteSlice1D(b, o.VrfSig)
 	return b
 }
 func StartVrfDecode(b0 []byte) (*StartVrf, []byte, bool) {
-	a1, b1, err1 := safemarshal.ReadSlice1D(b0)
+	a1, b1, err1 := safemarshal.ReadByte(b0)
+	if err1 {
+		return nil, nil, true
+	}
+	a2, b2, err2 := safemarshal.ReadByte(b1)
+	if err2 {
+		return nil, nil, true
+	}
+	a3, b3, err3 := safemarshal.ReadSlice1D(b2)
+	if err3 {
+		return nil, nil, true
+	}
+	a4, b4, err4 := safemarshal.ReadSlice1D(b3)
+	if err4 {
+		return nil, nil, true
+	}
+	if uint64(len(a4)) != 64 {
+		return nil, nil, true
+	}
+	return &StartVrf{VrfSuite: a1, HashSuite: a2, VrfPk: a3, VrfSig: a4}, b4, false
+}
+func SecretsEncode(b0 []byte, o *Secrets) []byte {
+	var b = b0
+	b = safemarshal.WriteByte(b, o.VrfSuite)
+	b = safemarshal.WriteByte(b, o.HashSuite)
+	b = safemarshal.WriteByte(b, o.ChainMode)
+	b = safemarshal.WriteSlice1D(b, o.SigSk)
+	b = safemarshal.WriteSlice1D(b, o.VrfSk)
+	b = safemarshal.WriteSlice1D(b, o.Commit)
+	return b
+}
+func SecretsDecode(b0 []byte) (*Secrets, []byte, bool) {
+	a1, b1, err1 := safemarshal.ReadByte(b0)
+	if err1 {
+		return nil, nil, true
+	}
+	a2, b2, err2 := safemarshal.ReadByte(b1)
+	if err2 {
+		return nil, nil, true
+	}
+	a3, b3, err3 := safemarshal.ReadByte(b2)
+	if err3 {
+		return nil, nil, true
+	}
+	a4, b4, err4 := safemarshal.ReadSlice1D(b3)
+	if err4 {
+		return nil, nil, true
+	}
+	a5, b5, err5 := safemarshal.ReadSlice1D(b4)
+	if err5 {
+		return nil, nil, true
+	}
+	a6, b6, err6 := safemarshal.ReadSlice1D(b5)
+	if err6 {
+		return nil, nil, true
+	}
+	if uint64(len(a6)) != 32 {
+		return nil, nil, true
+	}
+	return &Secrets{VrfSuite: a1, HashSuite: a2, ChainMode: a3, SigSk: a4, VrfSk: a5, Commit: a6}, b6, false
+}
+func SecretBundleEncode(b0 []byte, o *SecretBundle) []byte {
+	var b = b0
+	b = safemarshal.WriteByte(b, o.Kdf)
+	b = safemarshal.WriteSlice1D(b, o.Salt)
+	b = safemarshal.WriteSlice1D(b, o.Ct)
+	return b
+}
+func SecretBundleDecode(b0 []byte) (*SecretBundle, []byte, bool) {
+	a1, b1, err1 := safemarshal.ReadByte(b0)
 	if err1 {
 		return nil, nil, true
 	}
@@ -48,7 +133,11 @@ func StartVrfDecode(b0 []byte) (*StartVrf, []byte, bool) {
 	if err2 {
 		return nil, nil, true
 	}
-	return &StartVrf{VrfPk: a1, VrfSig: a2}, b2, false
+	a3, b3, err3 := safemarshal.ReadSlice1D(b2)
+	if err3 {
+		return nil, nil, true
+	}
+	return &SecretBundle{Kdf: a1, Salt: a2, Ct: a3}, b3, false
 }
 func StartReplyEncode(b0 []byte, o *StartReply) []byte {
 	var b = b0
@@ -112,16 +201,31 @@ func HistoryArgDecode(b0 []byte) (*HistoryArg, []byte, bool) {
 	return &HistoryArg{Uid: a1, PrevEpoch: a2, PrevVerLen: a3}, b3, false
 }
 func HistoryReplyEncode(b0 []byte, o *HistoryReply) []byte {
-	var b = b0
+	var b []byte
 	b = safemarshal.WriteSlice1D(b, o.ChainProof)
 	b = safemarshal.WriteSlice1D(b, o.LinkSig)
-	b = ktcore.MembSlice1DEncode(b, o.Hist)
-	b = ktcore.NonMembEncode(b, o.Bound)
+	b = MembSlice1DEncode(b, o.Hist)
+	b = marshal.WriteBool(b, o.Bound != nil)
+	if o.Bound != nil {
+		b = ktcore.NonMembEncode(b, o.Bound)
+	}
 	b = marshal.WriteBool(b, o.Err)
-	return b
+	b = marshal.WriteInt(b, o.Epoch)
+	return safemarshal.WriteSlice1D(marshal.WriteInt(b0, 2), b)
 }
 func HistoryReplyDecode(b0 []byte) (*HistoryReply, []byte, bool) {
-	a1, b1, err1 := safemarshal.ReadSlice1D(b0)
+	ver, bVer, errVer := safemarshal.ReadInt(b0)
+	if errVer {
+		return nil, nil, true
+	}
+	if ver == 0 {
+		return nil, nil, true
+	}
+	body, tail, errBody := safemarshal.ReadSlice1D(bVer)
+	if errBody {
+		return nil, nil, true
+	}
+	a1, b1, err1 := safemarshal.ReadSlice1D(body)
 	if err1 {
 		return nil, nil, true
 	}
@@ -129,19 +233,105 @@ func HistoryReplyDecode(b0 []byte) (*HistoryReply, []byte, bool) {
 	if err2 {
 		return nil, nil, true
 	}
-	a3, b3, err3 := ktcore.MembSlice1DDecode(b2)
+	if uint64(len(a2)) != 64 {
+		return nil, nil, true
+	}
+	a3, b3, err3 := MembSlice1DDecode(b2)
 	if err3 {
 		return nil, nil, true
 	}
-	a4, b4, err4 := ktcore.NonMembDecode(b3)
+	var a4 *ktcore.NonMemb
+	isSet4, b4, err4 := safemarshal.ReadBool(b3)
 	if err4 {
 		return nil, nil, true
 	}
+	if isSet4 {
+		x, rem, err := ktcore.NonMembDecode(b4)
+		if err {
+			return nil, nil, true
+		}
+		a4 = x
+		b4 = rem
+	}
 	a5, b5, err5 := safemarshal.ReadBool(b4)
 	if err5 {
 		return nil, nil, true
 	}
-	return &HistoryReply{ChainProof: a1, LinkSig: a2, Hist: a3, Bound: a4, Err: a5}, b5, false
+	var a6 uint64
+	var b6 = b5
+	if ver >= 2 {
+		a, b, err := safemarshal.ReadInt(b5)
+		if err {
+			return nil, nil, true
+		}
+		a6 = a
+		b6 = b
+	}
+	if ver <= 2 && len(b6) != 0 {
+		return nil, nil, true
+	}
+	return &HistoryReply{ChainProof: a1, LinkSig: a2, Hist: a3, Bound: a4, Err: a5, Epoch: a6}, tail, false
+}
+func GetAtEpochArgEncode(b0 []byte, o *GetAtEpochArg) []byte {
+	var b = b0
+	b = marshal.WriteInt(b, o.Uid)
+	b = marshal.WriteInt(b, o.Epoch)
+	b = marshal.WriteInt(b, o.KnownEpoch)
+	return b
+}
+func GetAtEpochArgDecode(b0 []byte) (*GetAtEpochArg, []byte, bool) {
+	a1, b1, err1 := safemarshal.ReadInt(b0)
+	if err1 {
+		return nil, nil, true
+	}
+	a2, b2, err2 := safemarshal.ReadInt(b1)
+	if err2 {
+		return nil, nil, true
+	}
+	a3, b3, err3 := safemarshal.ReadInt(b2)
+	if err3 {
+		return nil, nil, true
+	}
+	return &GetAtEpochArg{Uid: a1, Epoch: a2, KnownEpoch: a3}, b3, false
+}
+func GetAtEpochReplyEncode(b0 []byte, o *GetAtEpochReply) []byte {
+	var b = b0
+	b = safemarshal.WriteSlice1D(b, o.InclProof)
+	b = MembSlice1DEncode(b, o.Hist)
+	b = marshal.WriteBool(b, o.Bound != nil)
+	if o.Bound != nil {
+		b = ktcore.NonMembEncode(b, o.Bound)
+	}
+	b = marshal.WriteBool(b, o.Err)
+	return b
+}
+func GetAtEpochReplyDecode(b0 []byte) (*GetAtEpochReply, []byte, bool) {
+	a1, b1, err1 := safemarshal.ReadSlice1D(b0)
+	if err1 {
+		return nil, nil, true
+	}
+	a2, b2, err2 := MembSlice1DDecode(b1)
+	if err2 {
+		return nil, nil, true
+	}
+	var a3 *ktcore.NonMemb
+	isSet3, b3, err3 := safemarshal.ReadBool(b2)
+	if err3 {
+		return nil, nil, true
+	}
+	if isSet3 {
+		x, rem, err := ktcore.NonMembDecode(b3)
+		if err {
+			return nil, nil, true
+		}
+		a3 = x
+		b3 = rem
+	}
+	a4, b4, err4 := safemarshal.ReadBool(b3)
+	if err4 {
+		return nil, nil, true
+	}
+	return &GetAtEpochReply{InclProof: a1, Hist: a2, Bound: a3, Err: a4}, b4, false
 }
 func AuditArgEncode(b0 []byte, o *AuditArg) []byte {
 	var b = b0
@@ -157,12 +347,12 @@ func AuditArgDecode(b0 []byte) (*AuditArg, []byte, bool) {
 }
 func AuditReplyEncode(b0 []byte, o *AuditReply) []byte {
 	var b = b0
-	b = ktcore.AuditProofSlice1DEncode(b, o.P)
+	b = AuditProofSlice1DEncode(b, o.P)
 	b = marshal.WriteBool(b, o.Err)
 	return b
 }
 func AuditReplyDecode(b0 []byte) (*AuditReply, []byte, bool) {
-	a1, b1, err1 := ktcore.AuditProofSlice1DDecode(b0)
+	a1, b1, err1 := AuditProofSlice1DDecode(b0)
 	if err1 {
 		return nil, nil, true
 	}
@@ -172,3 +362,181 @@ func AuditReplyDecode(b0 []byte) (*AuditReply, []byte, bool) {
 	}
 	return &AuditReply{P: a1, Err: a2}, b2, false
 }
+func SkipArgEncode(b0 []byte, o *SkipArg) []byte {
+	var b = b0
+	b = marshal.WriteInt(b, o.PrevEpoch)
+	b = marshal.WriteInt(b, o.Epoch)
+	return b
+}
+func SkipArgDecode(b0 []byte) (*SkipArg, []byte, bool) {
+	a1, b1, err1 := safemarshal.ReadInt(b0)
+	if err1 {
+		return nil, nil, true
+	}
+	a2, b2, err2 := safemarshal.ReadInt(b1)
+	if err2 {
+		return nil, nil, true
+	}
+	return &SkipArg{PrevEpoch: a1, Epoch: a2}, b2, false
+}
+func SkipReplyEncode(b0 []byte, o *SkipReply) []byte {
+	var b = b0
+	b = safemarshal.WriteSlice1D(b, o.ChainProof)
+	b = marshal.WriteBool(b, o.Err)
+	return b
+}
+func SkipReplyDecode(b0 []byte) (*SkipReply, []byte, bool) {
+	a1, b1, err1 := safemarshal.ReadSlice1D(b0)
+	if err1 {
+		return nil, nil, true
+	}
+	a2, b2, err2 := safemarshal.ReadBool(b1)
+	if err2 {
+		return nil, nil, true
+	}
+	return &SkipReply{ChainProof: a1, Err: a2}, b2, false
+}
+func ShardArgEncode(b0 []byte, o *ShardArg) []byte {
+	var b = b0
+	b = marshal.WriteInt(b, o.Epoch)
+	b = safemarshal.WriteSlice1D(b, o.Prefix)
+	b = marshal.WriteInt(b, o.PrefixLen)
+	return b
+}
+func ShardArgDecode(b0 []byte) (*ShardArg, []byte, bool) {
+	a1, b1, err1 := safemarshal.ReadInt(b0)
+	if err1 {
+		return nil, nil, true
+	}
+	a2, b2, err2 := safemarshal.ReadSlice1D(b1)
+	if err2 {
+		return nil, nil, true
+	}
+	a3, b3, err3 := safemarshal.ReadInt(b2)
+	if err3 {
+		return nil, nil, true
+	}
+	return &ShardArg{Epoch: a1, Prefix: a2, PrefixLen: a3}, b3, false
+}
+func ShardReplyEncode(b0 []byte, o *ShardReply) []byte {
+	var b = b0
+	b = EntrySlice1DEncode(b, o.PrevEntries)
+	b = safemarshal.WriteSlice1D(b, o.PrevProof)
+	b = EntrySlice1DEncode(b, o.NextEntries)
+	b = safemarshal.WriteSlice1D(b, o.NextProof)
+	b = marshal.WriteBool(b, o.Err)
+	return b
+}
+func ShardReplyDecode(b0 []byte) (*ShardReply, []byte, bool) {
+	a1, b1, err1 := EntrySlice1DDecode(b0)
+	if err1 {
+		return nil, nil, true
+	}
+	a2, b2, err2 := safemarshal.ReadSlice1D(b1)
+	if err2 {
+		return nil, nil, true
+	}
+	a3, b3, err3 := EntrySlice1DDecode(b2)
+	if err3 {
+		return nil, nil, true
+	}
+	a4, b4, err4 := safemarshal.ReadSlice1D(b3)
+	if err4 {
+		return nil, nil, true
+	}
+	a5, b5, err5 := safemarshal.ReadBool(b4)
+	if err5 {
+		return nil, nil, true
+	}
+	return &ShardReply{PrevEntries: a1, PrevProof: a2, NextEntries: a3, NextProof: a4, Err: a5}, b5, false
+}
+func MembSlice1DEncode(b0 []byte, o []*ktcore.Memb) []byte {
+	var b = b0
+	b = marshal.WriteInt(b, uint64(len(o)))
+	for _, e := range o {
+		b = ktcore.MembEncode(b, e)
+	}
+	return b
+}
+func MembSlice1DDecode(b0 []byte) ([]*ktcore.Memb, []byte, bool) {
+	length, b1, err1 := safemarshal.ReadInt(b0)
+	if err1 {
+		return nil, nil, true
+	}
+	var loopO []*ktcore.Memb
+	var loopErr bool
+	var loopB = b1
+	for i := uint64(0); i < length; i++ {
+		a2, loopB1, err2 := ktcore.MembDecode(loopB)
+		loopB = loopB1
+		if err2 {
+			loopErr = true
+			break
+		}
+		loopO = append(loopO, a2)
+	}
+	if loopErr {
+		return nil, nil, true
+	}
+	return loopO, loopB, false
+}
+func AuditProofSlice1DEncode(b0 []byte, o []*ktcore.AuditProof) []byte {
+	var b = b0
+	b = marshal.WriteInt(b, uint64(len(o)))
+	for _, e := range o {
+		b = ktcore.AuditProofEncode(b, e)
+	}
+	return b
+}
+func AuditProofSlice1DDecode(b0 []byte) ([]*ktcore.AuditProof, []byte, bool) {
+	length, b1, err1 := safemarshal.ReadInt(b0)
+	if err1 {
+		return nil, nil, true
+	}
+	var loopO []*ktcore.AuditProof
+	var loopErr bool
+	var loopB = b1
+	for i := uint64(0); i < length; i++ {
+		a2, loopB1, err2 := ktcore.AuditProofDecode(loopB)
+		loopB = loopB1
+		if err2 {
+			loopErr = true
+			break
+		}
+		loopO = append(loopO, a2)
+	}
+	if loopErr {
+		return nil, nil, true
+	}
+	return loopO, loopB, false
+}
+func EntrySlice1DEncode(b0 []byte, o []*merkle.Entry) []byte {
+	var b = b0
+	b = marshal.WriteInt(b, uint64(len(o)))
+	for _, e := range o {
+		b = merkle.EntryEncode(b, e)
+	}
+	return b
+}
+func EntrySlice1DDecode(b0 []byte) ([]*merkle.Entry, []byte, bool) {
+	length, b1, err1 := safemarshal.ReadInt(b0)
+	if err1 {
+		return nil, nil, true
+	}
+	var loopO []*merkle.Entry
+	var loopErr bool
+	var loopB = b1
+	for i := uint64(0); i < length; i++ {
+		a2, loopB1, err2 := merkle.EntryDecode(loopB)
+		loopB = loopB1
+		if err2 {
+			loopErr = true
+			break
+		}
+		loopO = append(loopO, a2)
+	}
+	if loopErr {
+		return nil, nil, true
+	}
+	return loopO, loopB, false
+}
@@ -0,0 +1,871 @@
+// Auto-generated from spec "github.com/sanjit-bhat/pav/server/serde.go"
+// using compiler "github.com/sanjit-bhat/pav/serde".
+package server
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"math/rand/v2"
+	"os"
+	"testing"
+
+	"github.com/sanjit-bhat/pav/ktcore"
+	"github.com/sanjit-bhat/pav/merkle"
+)
+
+func FuzzStartChainDecode(f *testing.F) {
+	rnd := rand.New(rand.NewPCG(0, 0))
+	for i := 0; i < 4; i++ {
+		f.Add(StartChainEncode(nil, randStartChain(rnd)))
+	}
+	f.Fuzz(func(t *testing.T, b []byte) {
+		x, _, err := StartChainDecode(b)
+		if err {
+			return
+		}
+		b0 := StartChainEncode(nil, x)
+		y, rem, err := StartChainDecode(b0)
+		if err || len(rem) != 0 {
+			t.Fatal()
+		}
+		if !bytes.Equal(b0, StartChainEncode(nil, y)) {
+			t.Fatal()
+		}
+	})
+}
+func TestStartChainRoundTrip(t *testing.T) {
+	rnd := rand.New(rand.NewPCG(0, 0))
+	for i := 0; i < 100; i++ {
+		b := StartChainEncode(nil, randStartChain(rnd))
+		x, rem, err := StartChainDecode(b)
+		if err || len(rem) != 0 {
+			t.Fatal()
+		}
+		if !bytes.Equal(b, StartChainEncode(nil, x)) {
+			t.Fatal()
+		}
+	}
+}
+func FuzzStartVrfDecode(f *testing.F) {
+	rnd := rand.New(rand.NewPCG(0, 0))
+	for i := 0; i < 4; i++ {
+		f.Add(StartVrfEncode(nil, randStartVrf(rnd)))
+	}
+	f.Fuzz(func(t *testing.T, b []byte) {
+		x, _, err := StartVrfDecode(b)
+		if err {
+			return
+		}
+		b0 := StartVrfEncode(nil, x)
+		y, rem, err := StartVrfDecode(b0)
+		if err || len(rem) != 0 {
+			t.Fatal()
+		}
+		if !bytes.Equal(b0, StartVrfEncode(nil, y)) {
+			t.Fatal()
+		}
+	})
+}
+func TestStartVrfRoundTrip(t *testing.T) {
+	rnd := rand.New(rand.NewPCG(0, 0))
+	for i := 0; i < 100; i++ {
+		b := StartVrfEncode(nil, randStartVrf(rnd))
+		x, rem, err := StartVrfDecode(b)
+		if err || len(rem) != 0 {
+			t.Fatal()
+		}
+		if !bytes.Equal(b, StartVrfEncode(nil, x)) {
+			t.Fatal()
+		}
+	}
+}
+func FuzzSecretsDecode(f *testing.F) {
+	rnd := rand.New(rand.NewPCG(0, 0))
+	for i := 0; i < 4; i++ {
+		f.Add(SecretsEncode(nil, randSecrets(rnd)))
+	}
+	f.Fuzz(func(t *testing.T, b []byte) {
+		x, _, err := SecretsDecode(b)
+		if err {
+			return
+		}
+		b0 := SecretsEncode(nil, x)
+		y, rem, err := SecretsDecode(b0)
+		if err || len(rem) != 0 {
+			t.Fatal()
+		}
+		if !bytes.Equal(b0, SecretsEncode(nil, y)) {
+			t.Fatal()
+		}
+	})
+}
+func TestSecretsRoundTrip(t *testing.T) {
+	rnd := rand.New(rand.NewPCG(0, 0))
+	for i := 0; i < 100; i++ {
+		b := SecretsEncode(nil, randSecrets(rnd))
+		x, rem, err := SecretsDecode(b)
+		if err || len(rem) != 0 {
+			t.Fatal()
+		}
+		if !bytes.Equal(b, SecretsEncode(nil, x)) {
+			t.Fatal()
+		}
+	}
+}
+func FuzzSecretBundleDecode(f *testing.F) {
+	rnd := rand.New(rand.NewPCG(0, 0))
+	for i := 0; i < 4; i++ {
+		f.Add(SecretBundleEncode(nil, randSecretBundle(rnd)))
+	}
+	f.Fuzz(func(t *testing.T, b []byte) {
+		x, _, err := SecretBundleDecode(b)
+		if err {
+			return
+		}
+		b0 := SecretBundleEncode(nil, x)
+		y, rem, err := SecretBundleDecode(b0)
+		if err || len(rem) != 0 {
+			t.Fatal()
+		}
+		if !bytes.Equal(b0, SecretBundleEncode(nil, y)) {
+			t.Fatal()
+		}
+	})
+}
+func TestSecretBundleRoundTrip(t *testing.T) {
+	rnd := rand.New(rand.NewPCG(0, 0))
+	for i := 0; i < 100; i++ {
+		b := SecretBundleEncode(nil, randSecretBundle(rnd))
+		x, rem, err := SecretBundleDecode(b)
+		if err || len(rem) != 0 {
+			t.Fatal()
+		}
+		if !bytes.Equal(b, SecretBundleEncode(nil, x)) {
+			t.Fatal()
+		}
+	}
+}
+func FuzzStartReplyDecode(f *testing.F) {
+	rnd := rand.New(rand.NewPCG(0, 0))
+	for i := 0; i < 4; i++ {
+		f.Add(StartReplyEncode(nil, randStartReply(rnd)))
+	}
+	f.Fuzz(func(t *testing.T, b []byte) {
+		x, _, err := StartReplyDecode(b)
+		if err {
+			return
+		}
+		b0 := StartReplyEncode(nil, x)
+		y, rem, err := StartReplyDecode(b0)
+		if err || len(rem) != 0 {
+			t.Fatal()
+		}
+		if !bytes.Equal(b0, StartReplyEncode(nil, y)) {
+			t.Fatal()
+		}
+	})
+}
+func TestStartReplyRoundTrip(t *testing.T) {
+	rnd := rand.New(rand.NewPCG(0, 0))
+	for i := 0; i < 100; i++ {
+		b := StartReplyEncode(nil, randStartReply(rnd))
+		x, rem, err := StartReplyDecode(b)
+		if err || len(rem) != 0 {
+			t.Fatal()
+		}
+		if !bytes.Equal(b, StartReplyEncode(nil, x)) {
+			t.Fatal()
+		}
+	}
+}
+func FuzzPutArgDecode(f *testing.F) {
+	rnd := rand.New(rand.NewPCG(0, 0))
+	for i := 0; i < 4; i++ {
+		f.Add(PutArgEncode(nil, randPutArg(rnd)))
+	}
+	f.Fuzz(func(t *testing.T, b []byte) {
+		x, _, err := PutArgDecode(b)
+		if err {
+			return
+		}
+		b0 := PutArgEncode(nil, x)
+		y, rem, err := PutArgDecode(b0)
+		if err || len(rem) != 0 {
+			t.Fatal()
+		}
+		if !bytes.Equal(b0, PutArgEncode(nil, y)) {
+			t.Fatal()
+		}
+	})
+}
+func TestPutArgRoundTrip(t *testing.T) {
+	rnd := rand.New(rand.NewPCG(0, 0))
+	for i := 0; i < 100; i++ {
+		b := PutArgEncode(nil, randPutArg(rnd))
+		x, rem, err := PutArgDecode(b)
+		if err || len(rem) != 0 {
+			t.Fatal()
+		}
+		if !bytes.Equal(b, PutArgEncode(nil, x)) {
+			t.Fatal()
+		}
+	}
+}
+func FuzzHistoryArgDecode(f *testing.F) {
+	rnd := rand.New(rand.NewPCG(0, 0))
+	for i := 0; i < 4; i++ {
+		f.Add(HistoryArgEncode(nil, randHistoryArg(rnd)))
+	}
+	f.Fuzz(func(t *testing.T, b []byte) {
+		x, _, err := HistoryArgDecode(b)
+		if err {
+			return
+		}
+		b0 := HistoryArgEncode(nil, x)
+		y, rem, err := HistoryArgDecode(b0)
+		if err || len(rem) != 0 {
+			t.Fatal()
+		}
+		if !bytes.Equal(b0, HistoryArgEncode(nil, y)) {
+			t.Fatal()
+		}
+	})
+}
+func TestHistoryArgRoundTrip(t *testing.T) {
+	rnd := rand.New(rand.NewPCG(0, 0))
+	for i := 0; i < 100; i++ {
+		b := HistoryArgEncode(nil, randHistoryArg(rnd))
+		x, rem, err := HistoryArgDecode(b)
+		if err || len(rem) != 0 {
+			t.Fatal()
+		}
+		if !bytes.Equal(b, HistoryArgEncode(nil, x)) {
+			t.Fatal()
+		}
+	}
+}
+func FuzzHistoryReplyDecode(f *testing.F) {
+	rnd := rand.New(rand.NewPCG(0, 0))
+	for i := 0; i < 4; i++ {
+		f.Add(HistoryReplyEncode(nil, randHistoryReply(rnd)))
+	}
+	f.Fuzz(func(t *testing.T, b []byte) {
+		x, _, err := HistoryReplyDecode(b)
+		if err {
+			return
+		}
+		b0 := HistoryReplyEncode(nil, x)
+		y, rem, err := HistoryReplyDecode(b0)
+		if err || len(rem) != 0 {
+			t.Fatal()
+		}
+		if !bytes.Equal(b0, HistoryReplyEncode(nil, y)) {
+			t.Fatal()
+		}
+	})
+}
+func TestHistoryReplyRoundTrip(t *testing.T) {
+	rnd := rand.New(rand.NewPCG(0, 0))
+	for i := 0; i < 100; i++ {
+		b := HistoryReplyEncode(nil, randHistoryReply(rnd))
+		x, rem, err := HistoryReplyDecode(b)
+		if err || len(rem) != 0 {
+			t.Fatal()
+		}
+		if !bytes.Equal(b, HistoryReplyEncode(nil, x)) {
+			t.Fatal()
+		}
+	}
+}
+func FuzzGetAtEpochArgDecode(f *testing.F) {
+	rnd := rand.New(rand.NewPCG(0, 0))
+	for i := 0; i < 4; i++ {
+		f.Add(GetAtEpochArgEncode(nil, randGetAtEpochArg(rnd)))
+	}
+	f.Fuzz(func(t *testing.T, b []byte) {
+		x, _, err := GetAtEpochArgDecode(b)
+		if err {
+			return
+		}
+		b0 := GetAtEpochArgEncode(nil, x)
+		y, rem, err := GetAtEpochArgDecode(b0)
+		if err || len(rem) != 0 {
+			t.Fatal()
+		}
+		if !bytes.Equal(b0, GetAtEpochArgEncode(nil, y)) {
+			t.Fatal()
+		}
+	})
+}
+func TestGetAtEpochArgRoundTrip(t *testing.T) {
+	rnd := rand.New(rand.NewPCG(0, 0))
+	for i := 0; i < 100; i++ {
+		b := GetAtEpochArgEncode(nil, randGetAtEpochArg(rnd))
+		x, rem, err := GetAtEpochArgDecode(b)
+		if err || len(rem) != 0 {
+			t.Fatal()
+		}
+		if !bytes.Equal(b, GetAtEpochArgEncode(nil, x)) {
+			t.Fatal()
+		}
+	}
+}
+func FuzzGetAtEpochReplyDecode(f *testing.F) {
+	rnd := rand.New(rand.NewPCG(0, 0))
+	for i := 0; i < 4; i++ {
+		f.Add(GetAtEpochReplyEncode(nil, randGetAtEpochReply(rnd)))
+	}
+	f.Fuzz(func(t *testing.T, b []byte) {
+		x, _, err := GetAtEpochReplyDecode(b)
+		if err {
+			return
+		}
+		b0 := GetAtEpochReplyEncode(nil, x)
+		y, rem, err := GetAtEpochReplyDecode(b0)
+		if err || len(rem) != 0 {
+			t.Fatal()
+		}
+		if !bytes.Equal(b0, GetAtEpochReplyEncode(nil, y)) {
+			t.Fatal()
+		}
+	})
+}
+func TestGetAtEpochReplyRoundTrip(t *testing.T) {
+	rnd := rand.New(rand.NewPCG(0, 0))
+	for i := 0; i < 100; i++ {
+		b := GetAtEpochReplyEncode(nil, randGetAtEpochReply(rnd))
+		x, rem, err := GetAtEpochReplyDecode(b)
+		if err || len(rem) != 0 {
+			t.Fatal()
+		}
+		if !bytes.Equal(b, GetAtEpochReplyEncode(nil, x)) {
+			t.Fatal()
+		}
+	}
+}
+func FuzzAuditArgDecode(f *testing.F) {
+	rnd := rand.New(rand.NewPCG(0, 0))
+	for i := 0; i < 4; i++ {
+		f.Add(AuditArgEncode(nil, randAuditArg(rnd)))
+	}
+	f.Fuzz(func(t *testing.T, b []byte) {
+		x, _, err := AuditArgDecode(b)
+		if err {
+			return
+		}
+		b0 := AuditArgEncode(nil, x)
+		y, rem, err := AuditArgDecode(b0)
+		if err || len(rem) != 0 {
+			t.Fatal()
+		}
+		if !bytes.Equal(b0, AuditArgEncode(nil, y)) {
+			t.Fatal()
+		}
+	})
+}
+func TestAuditArgRoundTrip(t *testing.T) {
+	rnd := rand.New(rand.NewPCG(0, 0))
+	for i := 0; i < 100; i++ {
+		b := AuditArgEncode(nil, randAuditArg(rnd))
+		x, rem, err := AuditArgDecode(b)
+		if err || len(rem) != 0 {
+			t.Fatal()
+		}
+		if !bytes.Equal(b, AuditArgEncode(nil, x)) {
+			t.Fatal()
+		}
+	}
+}
+func FuzzAuditReplyDecode(f *testing.F) {
+	rnd := rand.New(rand.NewPCG(0, 0))
+	for i := 0; i < 4; i++ {
+		f.Add(AuditReplyEncode(nil, randAuditReply(rnd)))
+	}
+	f.Fuzz(func(t *testing.T, b []byte) {
+		x, _, err := AuditReplyDecode(b)
+		if err {
+			return
+		}
+		b0 := AuditReplyEncode(nil, x)
+		y, rem, err := AuditReplyDecode(b0)
+		if err || len(rem) != 0 {
+			t.Fatal()
+		}
+		if !bytes.Equal(b0, AuditReplyEncode(nil, y)) {
+			t.Fatal()
+		}
+	})
+}
+func TestAuditReplyRoundTrip(t *testing.T) {
+	rnd := rand.New(rand.NewPCG(0, 0))
+	for i := 0; i < 100; i++ {
+		b := AuditReplyEncode(nil, randAuditReply(rnd))
+		x, rem, err := AuditReplyDecode(b)
+		if err || len(rem) != 0 {
+			t.Fatal()
+		}
+		if !bytes.Equal(b, AuditReplyEncode(nil, x)) {
+			t.Fatal()
+		}
+	}
+}
+func FuzzSkipArgDecode(f *testing.F) {
+	rnd := rand.New(rand.NewPCG(0, 0))
+	for i := 0; i < 4; i++ {
+		f.Add(SkipArgEncode(nil, randSkipArg(rnd)))
+	}
+	f.Fuzz(func(t *testing.T, b []byte) {
+		x, _, err := SkipArgDecode(b)
+		if err {
+			return
+		}
+		b0 := SkipArgEncode(nil, x)
+		y, rem, err := SkipArgDecode(b0)
+		if err || len(rem) != 0 {
+			t.Fatal()
+		}
+		if !bytes.Equal(b0, SkipArgEncode(nil, y)) {
+			t.Fatal()
+		}
+	})
+}
+func TestSkipArgRoundTrip(t *testing.T) {
+	rnd := rand.New(rand.NewPCG(0, 0))
+	for i := 0; i < 100; i++ {
+		b := SkipArgEncode(nil, randSkipArg(rnd))
+		x, rem, err := SkipArgDecode(b)
+		if err || len(rem) != 0 {
+			t.Fatal()
+		}
+		if !bytes.Equal(b, SkipArgEncode(nil, x)) {
+			t.Fatal()
+		}
+	}
+}
+func FuzzSkipReplyDecode(f *testing.F) {
+	rnd := rand.New(rand.NewPCG(0, 0))
+	for i := 0; i < 4; i++ {
+		f.Add(SkipReplyEncode(nil, randSkipReply(rnd)))
+	}
+	f.Fuzz(func(t *testing.T, b []byte) {
+		x, _, err := SkipReplyDecode(b)
+		if err {
+			return
+		}
+		b0 := SkipReplyEncode(nil, x)
+		y, rem, err := SkipReplyDecode(b0)
+		if err || len(rem) != 0 {
+			t.Fatal()
+		}
+		if !bytes.Equal(b0, SkipReplyEncode(nil, y)) {
+			t.Fatal()
+		}
+	})
+}
+func TestSkipReplyRoundTrip(t *testing.T) {
+	rnd := rand.New(rand.NewPCG(0, 0))
+	for i := 0; i < 100; i++ {
+		b := SkipReplyEncode(nil, randSkipReply(rnd))
+		x, rem, err := SkipReplyDecode(b)
+		if err || len(rem) != 0 {
+			t.Fatal()
+		}
+		if !bytes.Equal(b, SkipReplyEncode(nil, x)) {
+			t.Fatal()
+		}
+	}
+}
+func FuzzShardArgDecode(f *testing.F) {
+	rnd := rand.New(rand.NewPCG(0, 0))
+	for i := 0; i < 4; i++ {
+		f.Add(ShardArgEncode(nil, randShardArg(rnd)))
+	}
+	f.Fuzz(func(t *testing.T, b []byte) {
+		x, _, err := ShardArgDecode(b)
+		if err {
+			return
+		}
+		b0 := ShardArgEncode(nil, x)
+		y, rem, err := ShardArgDecode(b0)
+		if err || len(rem) != 0 {
+			t.Fatal()
+		}
+		if !bytes.Equal(b0, ShardArgEncode(nil, y)) {
+			t.Fatal()
+		}
+	})
+}
+func TestShardArgRoundTrip(t *testing.T) {
+	rnd := rand.New(rand.NewPCG(0, 0))
+	for i := 0; i < 100; i++ {
+		b := ShardArgEncode(nil, randShardArg(rnd))
+		x, rem, err := ShardArgDecode(b)
+		if err || len(rem) != 0 {
+			t.Fatal()
+		}
+		if !bytes.Equal(b, ShardArgEncode(nil, x)) {
+			t.Fatal()
+		}
+	}
+}
+func FuzzShardReplyDecode(f *testing.F) {
+	rnd := rand.New(rand.NewPCG(0, 0))
+	for i := 0; i < 4; i++ {
+		f.Add(ShardReplyEncode(nil, randShardReply(rnd)))
+	}
+	f.Fuzz(func(t *testing.T, b []byte) {
+		x, _, err := ShardReplyDecode(b)
+		if err {
+			return
+		}
+		b0 := ShardReplyEncode(nil, x)
+		y, rem, err := ShardReplyDecode(b0)
+		if err || len(rem) != 0 {
+			t.Fatal()
+		}
+		if !bytes.Equal(b0, ShardReplyEncode(nil, y)) {
+			t.Fatal()
+		}
+	})
+}
+func TestShardReplyRoundTrip(t *testing.T) {
+	rnd := rand.New(rand.NewPCG(0, 0))
+	for i := 0; i < 100; i++ {
+		b := ShardReplyEncode(nil, randShardReply(rnd))
+		x, rem, err := ShardReplyDecode(b)
+		if err || len(rem) != 0 {
+			t.Fatal()
+		}
+		if !bytes.Equal(b, ShardReplyEncode(nil, x)) {
+			t.Fatal()
+		}
+	}
+}
+func randStartChain(rnd *rand.Rand) *StartChain {
+	o := &StartChain{}
+	o.ChainMode = byte(rnd.Uint32())
+	o.PrevEpochLen = rnd.Uint64()
+	o.PrevLink = randBytes(rnd, 32)
+	o.ChainProof = randBytes(rnd, rnd.IntN(4))
+	o.LinkSig = randBytes(rnd, 64)
+	return o
+}
+func randStartVrf(rnd *rand.Rand) *StartVrf {
+	o := &StartVrf{}
+	o.VrfSuite = byte(rnd.Uint32())
+	o.HashSuite = byte(rnd.Uint32())
+	o.VrfPk = randBytes(rnd, rnd.IntN(4))
+	o.VrfSig = randBytes(rnd, 64)
+	return o
+}
+func randSecrets(rnd *rand.Rand) *Secrets {
+	o := &Secrets{}
+	o.VrfSuite = byte(rnd.Uint32())
+	o.HashSuite = byte(rnd.Uint32())
+	o.ChainMode = byte(rnd.Uint32())
+	o.SigSk = randBytes(rnd, rnd.IntN(4))
+	o.VrfSk = randBytes(rnd, rnd.IntN(4))
+	o.Commit = randBytes(rnd, 32)
+	return o
+}
+func randSecretBundle(rnd *rand.Rand) *SecretBundle {
+	o := &SecretBundle{}
+	o.Kdf = byte(rnd.Uint32())
+	o.Salt = randBytes(rnd, rnd.IntN(4))
+	o.Ct = randBytes(rnd, rnd.IntN(4))
+	return o
+}
+func randStartReply(rnd *rand.Rand) *StartReply {
+	o := &StartReply{}
+	o.Chain = randStartChain(rnd)
+	o.Vrf = randStartVrf(rnd)
+	return o
+}
+func randPutArg(rnd *rand.Rand) *PutArg {
+	o := &PutArg{}
+	o.Uid = rnd.Uint64()
+	o.Pk = randBytes(rnd, rnd.IntN(4))
+	o.Ver = rnd.Uint64()
+	return o
+}
+func randHistoryArg(rnd *rand.Rand) *HistoryArg {
+	o := &HistoryArg{}
+	o.Uid = rnd.Uint64()
+	o.PrevEpoch = rnd.Uint64()
+	o.PrevVerLen = rnd.Uint64()
+	return o
+}
+func randHistoryReply(rnd *rand.Rand) *HistoryReply {
+	o := &HistoryReply{}
+	o.ChainProof = randBytes(rnd, rnd.IntN(4))
+	o.LinkSig = randBytes(rnd, 64)
+	o.Hist = randSlMemb(rnd, rnd.IntN(4))
+	if rnd.IntN(2) == 0 {
+		o.Bound = randKtcoreNonMemb(rnd)
+	}
+	o.Err = rnd.IntN(2) == 0
+	o.Epoch = rnd.Uint64()
+	return o
+}
+func randGetAtEpochArg(rnd *rand.Rand) *GetAtEpochArg {
+	o := &GetAtEpochArg{}
+	o.Uid = rnd.Uint64()
+	o.Epoch = rnd.Uint64()
+	o.KnownEpoch = rnd.Uint64()
+	return o
+}
+func randGetAtEpochReply(rnd *rand.Rand) *GetAtEpochReply {
+	o := &GetAtEpochReply{}
+	o.InclProof = randBytes(rnd, rnd.IntN(4))
+	o.Hist = randSlMemb(rnd, rnd.IntN(4))
+	if rnd.IntN(2) == 0 {
+		o.Bound = randKtcoreNonMemb(rnd)
+	}
+	o.Err = rnd.IntN(2) == 0
+	return o
+}
+func randAuditArg(rnd *rand.Rand) *AuditArg {
+	o := &AuditArg{}
+	o.PrevEpoch = rnd.Uint64()
+	return o
+}
+func randAuditReply(rnd *rand.Rand) *AuditReply {
+	o := &AuditReply{}
+	o.P = randSlAuditProof(rnd, rnd.IntN(4))
+	o.Err = rnd.IntN(2) == 0
+	return o
+}
+func randSkipArg(rnd *rand.Rand) *SkipArg {
+	o := &SkipArg{}
+	o.PrevEpoch = rnd.Uint64()
+	o.Epoch = rnd.Uint64()
+	return o
+}
+func randSkipReply(rnd *rand.Rand) *SkipReply {
+	o := &SkipReply{}
+	o.ChainProof = randBytes(rnd, rnd.IntN(4))
+	o.Err = rnd.IntN(2) == 0
+	return o
+}
+func randShardArg(rnd *rand.Rand) *ShardArg {
+	o := &ShardArg{}
+	o.Epoch = rnd.Uint64()
+	o.Prefix = randBytes(rnd, rnd.IntN(4))
+	o.PrefixLen = rnd.Uint64()
+	return o
+}
+func randShardReply(rnd *rand.Rand) *ShardReply {
+	o := &ShardReply{}
+	o.PrevEntries = randSlEntry(rnd, rnd.IntN(4))
+	o.PrevProof = randBytes(rnd, rnd.IntN(4))
+	o.NextEntries = randSlEntry(rnd, rnd.IntN(4))
+	o.NextProof = randBytes(rnd, rnd.IntN(4))
+	o.Err = rnd.IntN(2) == 0
+	return o
+}
+func randSlMemb(rnd *rand.Rand, n int) []*ktcore.Memb {
+	var x []*ktcore.Memb
+	for i := 0; i < n; i++ {
+		x = append(x, randKtcoreMemb(rnd))
+	}
+	return x
+}
+func randKtcoreNonMemb(rnd *rand.Rand) *ktcore.NonMemb {
+	o := &ktcore.NonMemb{}
+	o.LabelProof = randBytes(rnd, 80)
+	o.MerkleProof = randBytes(rnd, rnd.IntN(8281+1))
+	return o
+}
+func randSlAuditProof(rnd *rand.Rand, n int) []*ktcore.AuditProof {
+	var x []*ktcore.AuditProof
+	for i := 0; i < n; i++ {
+		x = append(x, randKtcoreAuditProof(rnd))
+	}
+	return x
+}
+func randSlEntry(rnd *rand.Rand, n int) []*merkle.Entry {
+	var x []*merkle.Entry
+	for i := 0; i < n; i++ {
+		x = append(x, randMerkleEntry(rnd))
+	}
+	return x
+}
+func randKtcoreMemb(rnd *rand.Rand) *ktcore.Memb {
+	o := &ktcore.Memb{}
+	o.LabelProof = randBytes(rnd, 80)
+	o.PkOpen = randKtcoreCommitOpen(rnd)
+	o.MerkleProof = randBytes(rnd, rnd.IntN(8281+1))
+	return o
+}
+func randKtcoreAuditProof(rnd *rand.Rand) *ktcore.AuditProof {
+	o := &ktcore.AuditProof{}
+	o.Updates = randSlUpdateProof(rnd, rnd.IntN(4))
+	o.LinkSig = randBytes(rnd, 64)
+	return o
+}
+func randMerkleEntry(rnd *rand.Rand) *merkle.Entry {
+	o := &merkle.Entry{}
+	o.Label = randBytes(rnd, rnd.IntN(4))
+	o.Val = randBytes(rnd, rnd.IntN(4))
+	return o
+}
+func randKtcoreCommitOpen(rnd *rand.Rand) *ktcore.CommitOpen {
+	o := &ktcore.CommitOpen{}
+	o.Val = randBytes(rnd, rnd.IntN(4))
+	o.Rand = randBytes(rnd, rnd.IntN(4))
+	return o
+}
+func randSlUpdateProof(rnd *rand.Rand, n int) []*ktcore.UpdateProof {
+	var x []*ktcore.UpdateProof
+	for i := 0; i < n; i++ {
+		x = append(x, randKtcoreUpdateProof(rnd))
+	}
+	return x
+}
+func randKtcoreUpdateProof(rnd *rand.Rand) *ktcore.UpdateProof {
+	o := &ktcore.UpdateProof{}
+	o.MapLabel = randBytes(rnd, 32)
+	o.MapVal = randBytes(rnd, 32)
+	o.NonMembProof = randBytes(rnd, rnd.IntN(8281+1))
+	return o
+}
+func randBytes(rnd *rand.Rand, n int) []byte {
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = byte(rnd.Uint32())
+	}
+	return b
+}
+func vectorCodec(name string, b []byte) (enc []byte, err bool) {
+	switch name {
+	case "StartChain":
+		x, rem, errb := StartChainDecode(b)
+		if errb || len(rem) != 0 {
+			return nil, true
+		}
+		return StartChainEncode(nil, x), false
+	case "StartVrf":
+		x, rem, errb := StartVrfDecode(b)
+		if errb || len(rem) != 0 {
+			return nil, true
+		}
+		return StartVrfEncode(nil, x), false
+	case "Secrets":
+		x, rem, errb := SecretsDecode(b)
+		if errb || len(rem) != 0 {
+			return nil, true
+		}
+		return SecretsEncode(nil, x), false
+	case "SecretBundle":
+		x, rem, errb := SecretBundleDecode(b)
+		if errb || len(rem) != 0 {
+			return nil, true
+		}
+		return SecretBundleEncode(nil, x), false
+	case "StartReply":
+		x, rem, errb := StartReplyDecode(b)
+		if errb || len(rem) != 0 {
+			return nil, true
+		}
+		return StartReplyEncode(nil, x), false
+	case "PutArg":
+		x, rem, errb := PutArgDecode(b)
+		if errb || len(rem) != 0 {
+			return nil, true
+		}
+		return PutArgEncode(nil, x), false
+	case "HistoryArg":
+		x, rem, errb := HistoryArgDecode(b)
+		if errb || len(rem) != 0 {
+			return nil, true
+		}
+		return HistoryArgEncode(nil, x), false
+	case "HistoryReply":
+		x, rem, errb := HistoryReplyDecode(b)
+		if errb || len(rem) != 0 {
+			return nil, true
+		}
+		return HistoryReplyEncode(nil, x), false
+	case "GetAtEpochArg":
+		x, rem, errb := GetAtEpochArgDecode(b)
+		if errb || len(rem) != 0 {
+			return nil, true
+		}
+		return GetAtEpochArgEncode(nil, x), false
+	case "GetAtEpochReply":
+		x, rem, errb := GetAtEpochReplyDecode(b)
+		if errb || len(rem) != 0 {
+			return nil, true
+		}
+		return GetAtEpochReplyEncode(nil, x), false
+	case "AuditArg":
+		x, rem, errb := AuditArgDecode(b)
+		if errb || len(rem) != 0 {
+			return nil, true
+		}
+		return AuditArgEncode(nil, x), false
+	case "AuditReply":
+		x, rem, errb := AuditReplyDecode(b)
+		if errb || len(rem) != 0 {
+			return nil, true
+		}
+		return AuditReplyEncode(nil, x), false
+	case "SkipArg":
+		x, rem, errb := SkipArgDecode(b)
+		if errb || len(rem) != 0 {
+			return nil, true
+		}
+		return SkipArgEncode(nil, x), false
+	case "SkipReply":
+		x, rem, errb := SkipReplyDecode(b)
+		if errb || len(rem) != 0 {
+			return nil, true
+		}
+		return SkipReplyEncode(nil, x), false
+	case "ShardArg":
+		x, rem, errb := ShardArgDecode(b)
+		if errb || len(rem) != 0 {
+			return nil, true
+		}
+		return ShardArgEncode(nil, x), false
+	case "ShardReply":
+		x, rem, errb := ShardReplyDecode(b)
+		if errb || len(rem) != 0 {
+			return nil, true
+		}
+		return ShardReplyEncode(nil, x), false
+	}
+	return nil, true
+}
+func TestVectors(t *testing.T) {
+	data, err := os.ReadFile("serde.vectors.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var vs struct {
+		Vectors []struct {
+			Name, Struct, Hex string
+		}
+	}
+	err = json.Unmarshal(data, &vs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(vs.Vectors) == 0 {
+		t.Fatal("no vectors")
+	}
+	for _, v := range vs.Vectors {
+		b, err := hex.DecodeString(v.Hex)
+		if err != nil {
+			t.Fatal(err)
+		}
+		enc, errb := vectorCodec(v.Struct, b)
+		if errb {
+			t.Fatalf("%s: decode failed", v.Name)
+		}
+		if !bytes.Equal(b, enc) {
+			t.Fatalf("%s: encoding differs", v.Name)
+		}
+	}
+}
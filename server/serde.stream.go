@@ -0,0 +1,376 @@
+//go:build !goose
+
+// Auto-generated from spec "github.com/sanjit-bhat/pav/server/serde.go"
+// using compiler "github.com/sanjit-bhat/pav/serde".
+package server
+
+import (
+	"github.com/sanjit-bhat/pav/ktcore"
+	"github.com/sanjit-bhat/pav/merkle"
+	"github.com/sanjit-bhat/pav/safemarshal/stream"
+)
+
+func StartChainDecodeStream(r *stream.Reader) (*StartChain, bool) {
+	a1, err1 := r.Byte()
+	if err1 {
+		return nil, true
+	}
+	a2, err2 := r.Int()
+	if err2 {
+		return nil, true
+	}
+	a3, err3 := r.Slice1D()
+	if err3 {
+		return nil, true
+	}
+	if uint64(len(a3)) != 32 {
+		return nil, true
+	}
+	a4, err4 := r.Slice1D()
+	if err4 {
+		return nil, true
+	}
+	a5, err5 := r.Slice1D()
+	if err5 {
+		return nil, true
+	}
+	if uint64(len(a5)) != 64 {
+		return nil, true
+	}
+	return &StartChain{ChainMode: a1, PrevEpochLen: a2, PrevLink: a3, ChainProof: a4, LinkSig: a5}, false
+}
+func StartVrfDecodeStream(r *stream.Reader) (*StartVrf, bool) {
+	a1, err1 := r.Byte()
+	if err1 {
+		return nil, true
+	}
+	a2, err2 := r.Byte()
+	if err2 {
+		return nil, true
+	}
+	a3, err3 := r.Slice1D()
+	if err3 {
+		return nil, true
+	}
+	a4, err4 := r.Slice1D()
+	if err4 {
+		return nil, true
+	}
+	if uint64(len(a4)) != 64 {
+		return nil, true
+	}
+	return &StartVrf{VrfSuite: a1, HashSuite: a2, VrfPk: a3, VrfSig: a4}, false
+}
+func SecretsDecodeStream(r *stream.Reader) (*Secrets, bool) {
+	a1, err1 := r.Byte()
+	if err1 {
+		return nil, true
+	}
+	a2, err2 := r.Byte()
+	if err2 {
+		return nil, true
+	}
+	a3, err3 := r.Byte()
+	if err3 {
+		return nil, true
+	}
+	a4, err4 := r.Slice1D()
+	if err4 {
+		return nil, true
+	}
+	a5, err5 := r.Slice1D()
+	if err5 {
+		return nil, true
+	}
+	a6, err6 := r.Slice1D()
+	if err6 {
+		return nil, true
+	}
+	if uint64(len(a6)) != 32 {
+		return nil, true
+	}
+	return &Secrets{VrfSuite: a1, HashSuite: a2, ChainMode: a3, SigSk: a4, VrfSk: a5, Commit: a6}, false
+}
+func SecretBundleDecodeStream(r *stream.Reader) (*SecretBundle, bool) {
+	a1, err1 := r.Byte()
+	if err1 {
+		return nil, true
+	}
+	a2, err2 := r.Slice1D()
+	if err2 {
+		return nil, true
+	}
+	a3, err3 := r.Slice1D()
+	if err3 {
+		return nil, true
+	}
+	return &SecretBundle{Kdf: a1, Salt: a2, Ct: a3}, false
+}
+func StartReplyDecodeStream(r *stream.Reader) (*StartReply, bool) {
+	a1, err1 := StartChainDecodeStream(r)
+	if err1 {
+		return nil, true
+	}
+	a2, err2 := StartVrfDecodeStream(r)
+	if err2 {
+		return nil, true
+	}
+	return &StartReply{Chain: a1, Vrf: a2}, false
+}
+func PutArgDecodeStream(r *stream.Reader) (*PutArg, bool) {
+	a1, err1 := r.Int()
+	if err1 {
+		return nil, true
+	}
+	a2, err2 := r.Slice1D()
+	if err2 {
+		return nil, true
+	}
+	a3, err3 := r.Int()
+	if err3 {
+		return nil, true
+	}
+	return &PutArg{Uid: a1, Pk: a2, Ver: a3}, false
+}
+func HistoryArgDecodeStream(r *stream.Reader) (*HistoryArg, bool) {
+	a1, err1 := r.Int()
+	if err1 {
+		return nil, true
+	}
+	a2, err2 := r.Int()
+	if err2 {
+		return nil, true
+	}
+	a3, err3 := r.Int()
+	if err3 {
+		return nil, true
+	}
+	return &HistoryArg{Uid: a1, PrevEpoch: a2, PrevVerLen: a3}, false
+}
+func HistoryReplyDecodeStream(r *stream.Reader) (*HistoryReply, bool) {
+	ver, errVer := r.Int()
+	if errVer {
+		return nil, true
+	}
+	if ver == 0 {
+		return nil, true
+	}
+	length, errLen := r.Int()
+	if errLen {
+		return nil, true
+	}
+	body, errBody := r.Limit(length)
+	if errBody {
+		return nil, true
+	}
+	a1, err1 := body.Slice1D()
+	if err1 {
+		return nil, true
+	}
+	a2, err2 := body.Slice1D()
+	if err2 {
+		return nil, true
+	}
+	if uint64(len(a2)) != 64 {
+		return nil, true
+	}
+	a3, err3 := MembSlice1DDecodeStream(body)
+	if err3 {
+		return nil, true
+	}
+	var a4 *ktcore.NonMemb
+	isSet4, err4 := body.Bool()
+	if err4 {
+		return nil, true
+	}
+	if isSet4 {
+		x, err := ktcore.NonMembDecodeStream(body)
+		if err {
+			return nil, true
+		}
+		a4 = x
+	}
+	a5, err5 := body.Bool()
+	if err5 {
+		return nil, true
+	}
+	var a6 uint64
+	if ver >= 2 {
+		a, err := body.Int()
+		if err {
+			return nil, true
+		}
+		a6 = a
+	}
+	if ver <= 2 && body.Budget() != 0 {
+		return nil, true
+	}
+	if body.Discard() {
+		return nil, true
+	}
+	return &HistoryReply{ChainProof: a1, LinkSig: a2, Hist: a3, Bound: a4, Err: a5, Epoch: a6}, false
+}
+func GetAtEpochArgDecodeStream(r *stream.Reader) (*GetAtEpochArg, bool) {
+	a1, err1 := r.Int()
+	if err1 {
+		return nil, true
+	}
+	a2, err2 := r.Int()
+	if err2 {
+		return nil, true
+	}
+	a3, err3 := r.Int()
+	if err3 {
+		return nil, true
+	}
+	return &GetAtEpochArg{Uid: a1, Epoch: a2, KnownEpoch: a3}, false
+}
+func GetAtEpochReplyDecodeStream(r *stream.Reader) (*GetAtEpochReply, bool) {
+	a1, err1 := r.Slice1D()
+	if err1 {
+		return nil, true
+	}
+	a2, err2 := MembSlice1DDecodeStream(r)
+	if err2 {
+		return nil, true
+	}
+	var a3 *ktcore.NonMemb
+	isSet3, err3 := r.Bool()
+	if err3 {
+		return nil, true
+	}
+	if isSet3 {
+		x, err := ktcore.NonMembDecodeStream(r)
+		if err {
+			return nil, true
+		}
+		a3 = x
+	}
+	a4, err4 := r.Bool()
+	if err4 {
+		return nil, true
+	}
+	return &GetAtEpochReply{InclProof: a1, Hist: a2, Bound: a3, Err: a4}, false
+}
+func AuditArgDecodeStream(r *stream.Reader) (*AuditArg, bool) {
+	a1, err1 := r.Int()
+	if err1 {
+		return nil, true
+	}
+	return &AuditArg{PrevEpoch: a1}, false
+}
+func AuditReplyDecodeStream(r *stream.Reader, eachP func(*ktcore.AuditProof) bool, eachPUpdates func(*ktcore.UpdateProof) bool) (*AuditReply, bool) {
+	var a1 []*ktcore.AuditProof
+	length1, err1 := r.Count(16)
+	if err1 {
+		return nil, true
+	}
+	for i := uint64(0); i < length1; i++ {
+		x, err := ktcore.AuditProofDecodeStream(r, eachPUpdates)
+		if err {
+			return nil, true
+		}
+		if eachP == nil {
+			a1 = append(a1, x)
+			continue
+		}
+		if eachP(x) {
+			return nil, true
+		}
+	}
+	a2, err2 := r.Bool()
+	if err2 {
+		return nil, true
+	}
+	return &AuditReply{P: a1, Err: a2}, false
+}
+func SkipArgDecodeStream(r *stream.Reader) (*SkipArg, bool) {
+	a1, err1 := r.Int()
+	if err1 {
+		return nil, true
+	}
+	a2, err2 := r.Int()
+	if err2 {
+		return nil, true
+	}
+	return &SkipArg{PrevEpoch: a1, Epoch: a2}, false
+}
+func SkipReplyDecodeStream(r *stream.Reader) (*SkipReply, bool) {
+	a1, err1 := r.Slice1D()
+	if err1 {
+		return nil, true
+	}
+	a2, err2 := r.Bool()
+	if err2 {
+		return nil, true
+	}
+	return &SkipReply{ChainProof: a1, Err: a2}, false
+}
+func ShardArgDecodeStream(r *stream.Reader) (*ShardArg, bool) {
+	a1, err1 := r.Int()
+	if err1 {
+		return nil, true
+	}
+	a2, err2 := r.Slice1D()
+	if err2 {
+		return nil, true
+	}
+	a3, err3 := r.Int()
+	if err3 {
+		return nil, true
+	}
+	return &ShardArg{Epoch: a1, Prefix: a2, PrefixLen: a3}, false
+}
+func ShardReplyDecodeStream(r *stream.Reader) (*ShardReply, bool) {
+	a1, err1 := EntrySlice1DDecodeStream(r)
+	if err1 {
+		return nil, true
+	}
+	a2, err2 := r.Slice1D()
+	if err2 {
+		return nil, true
+	}
+	a3, err3 := EntrySlice1DDecodeStream(r)
+	if err3 {
+		return nil, true
+	}
+	a4, err4 := r.Slice1D()
+	if err4 {
+		return nil, true
+	}
+	a5, err5 := r.Bool()
+	if err5 {
+		return nil, true
+	}
+	return &ShardReply{PrevEntries: a1, PrevProof: a2, NextEntries: a3, NextProof: a4, Err: a5}, false
+}
+func MembSlice1DDecodeStream(r *stream.Reader) ([]*ktcore.Memb, bool) {
+	length, err1 := r.Count(32)
+	if err1 {
+		return nil, true
+	}
+	var loopO []*ktcore.Memb
+	for i := uint64(0); i < length; i++ {
+		a2, err2 := ktcore.MembDecodeStream(r)
+		if err2 {
+			return nil, true
+		}
+		loopO = append(loopO, a2)
+	}
+	return loopO, false
+}
+func EntrySlice1DDecodeStream(r *stream.Reader) ([]*merkle.Entry, bool) {
+	length, err1 := r.Count(16)
+	if err1 {
+		return nil, true
+	}
+	var loopO []*merkle.Entry
+	for i := uint64(0); i < length; i++ {
+		a2, err2 := merkle.EntryDecodeStream(r)
+		if err2 {
+			return nil, true
+		}
+		loopO = append(loopO, a2)
+	}
+	return loopO, false
+}
@@ -0,0 +1,82 @@
+package server
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/sanjit-bhat/pav/ktcore"
+	"github.com/sanjit-bhat/pav/safemarshal/stream"
+	"github.com/tchajed/marshal"
+)
+
+func TestMembSlice1DDecode(t *testing.T) {
+	b := marshal.WriteInt(nil, 0x8000000000000000)
+	_, _, err := MembSlice1DDecode(b)
+	if !err {
+		t.Errorf("should have errored")
+	}
+}
+
+func TestHistoryReplyErr(t *testing.T) {
+	// err replies have no bound.
+	b := HistoryReplyEncode(nil, newHistoryErr())
+	r, _, err := HistoryReplyDecode(b)
+	if err {
+		t.Fatal()
+	}
+	if !r.Err || r.Bound != nil {
+		t.Fatal()
+	}
+}
+
+func TestAuditReplyStream(t *testing.T) {
+	var ps []*ktcore.AuditProof
+	for i := 0; i < 3; i++ {
+		upd := &ktcore.UpdateProof{MapLabel: bytes.Repeat([]byte{byte(i)}, 32), MapVal: make([]byte, 32), NonMembProof: []byte{3}}
+		ps = append(ps, &ktcore.AuditProof{Updates: []*ktcore.UpdateProof{upd}, LinkSig: make([]byte, 64)})
+	}
+	b := AuditReplyEncode(nil, &AuditReply{P: ps})
+
+	// each proof comes out as it's decoded.
+	var got []*ktcore.AuditProof
+	r, err := AuditReplyDecodeStream(stream.NewReader(bytes.NewReader(b), uint64(len(b))), func(p *ktcore.AuditProof) bool {
+		got = append(got, p)
+		return false
+	}, nil)
+	if err || r.P != nil || len(got) != 3 {
+		t.Fatal()
+	}
+	r.P = got
+	if !bytes.Equal(b, AuditReplyEncode(nil, r)) {
+		t.Fatal()
+	}
+
+	// so does each update inside a proof.
+	var gotUpds []*ktcore.UpdateProof
+	r, err = AuditReplyDecodeStream(stream.NewReader(bytes.NewReader(b), uint64(len(b))), nil, func(u *ktcore.UpdateProof) bool {
+		gotUpds = append(gotUpds, u)
+		return false
+	})
+	if err || len(r.P) != 3 || len(gotUpds) != 3 {
+		t.Fatal()
+	}
+	for i, p := range r.P {
+		if p.Updates != nil {
+			t.Fatal()
+		}
+		p.Updates = gotUpds[i : i+1]
+	}
+	if !bytes.Equal(b, AuditReplyEncode(nil, r)) {
+		t.Fatal()
+	}
+
+	// the budget bounds the msg.
+	if _, err := AuditReplyDecodeStream(stream.NewReader(bytes.NewReader(b), uint64(len(b)-1)), nil, nil); !err {
+		t.Fatal()
+	}
+	// counts that can't fit fail early.
+	b = marshal.WriteInt(nil, 1<<40)
+	if _, err := AuditReplyDecodeStream(stream.NewReader(bytes.NewReader(b), 1<<30), nil, nil); !err {
+		t.Fatal()
+	}
+}
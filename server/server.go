@@ -14,17 +14,36 @@ import (
 // performance params.
 var (
 	// EpochTime roughly matches AKD.
+	// it's the default for new servers, see [Server.SetEpochTime].
 	EpochTime = time.Second
 )
 
 type Server struct {
-	secs *secrets
+	// hashSuite is the [cryptoffi] hash suite.
+	// it's fixed at creation, so reads don't need mu.
+	hashSuite byte
+	// chainMode is the [hashchain] mode, also fixed at creation.
+	chainMode byte
+	secs      *secrets
 	// workQ for batching puts into one epoch update.
 	workQ chan *work
+	// epochTime is how long the worker batches puts, under mu.
+	epochTime time.Duration
 
 	mu   *sync.RWMutex
 	keys *keyStore
 	hist *history
+	// store is nil for in-memory servers.
+	store epochStore
+}
+
+// epochStore persists epochs, e.g., for [Open].
+// it's trusted, and should panic if it can't do an op.
+type epochStore interface {
+	// Append durably adds an epoch's accepted puts and dig to the chain.
+	Append(puts []*work, dig []byte) (link []byte)
+	// SetHead durably records the sig on the last link.
+	SetHead(sig []byte)
 }
 
 type secrets struct {
@@ -39,6 +58,8 @@ type keyStore struct {
 	hidden *merkle.Map
 	// plain stores plaintext mappings from uid to pks.
 	plain map[uint64][][]byte
+	// eps stores the epoch in which each of a uid's pks was added.
+	eps map[uint64][]uint64
 }
 
 type history struct {
@@ -47,7 +68,11 @@ type history struct {
 	// audits has auditing info for all epochs.
 	// for epoch 0, the UpdateProof is invalid (there is no prior epoch),
 	// but [Server.Audit] will never return it.
-	audits   []*ktcore.AuditProof
+	audits []*ktcore.AuditProof
+	// maps has a snapshot of the merkle map at each epoch.
+	// snapshots share most nodes, so each epoch costs about as much
+	// as its updates.
+	maps     []*merkle.Map
 	vrfPkSig []byte
 }
 
@@ -56,26 +81,30 @@ type history struct {
 func (s *Server) Start() (chain *StartChain, vrf *StartVrf) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
-	predLen := uint64(len(s.hist.audits)) - 1
-	predLink, proof := s.hist.chain.Bootstrap()
-	lastSig := s.hist.audits[predLen].LinkSig
+	predLen, predLink, proof := s.hist.chain.Bootstrap()
+	lastSig := s.hist.audits[len(s.hist.audits)-1].LinkSig
 	pk := s.secs.vrf.PublicKey()
-	chain = &StartChain{PrevEpochLen: predLen, PrevLink: predLink, ChainProof: proof, LinkSig: lastSig}
-	vrf = &StartVrf{VrfPk: pk, VrfSig: s.hist.vrfPkSig}
+	chain = &StartChain{ChainMode: s.chainMode, PrevEpochLen: predLen, PrevLink: predLink, ChainProof: proof, LinkSig: lastSig}
+	vrf = &StartVrf{VrfSuite: s.secs.vrf.Suite(), HashSuite: s.hashSuite, VrfPk: pk, VrfSig: s.hist.vrfPkSig}
 	return
 }
 
 // Put queues pk (at the specified version) for insertion.
 func (s *Server) Put(uid uint64, ver uint64, pk []byte) {
+	s.workQ <- s.newWork(uid, ver, pk)
+}
+
+func (s *Server) newWork(uid uint64, ver uint64, pk []byte) *work {
 	label := ktcore.EvalMapLabel(s.secs.vrf, uid, ver)
-	rand := ktcore.GetCommitRand(s.secs.commit, label)
-	val := ktcore.GetMapVal(pk, rand)
-	s.workQ <- &work{uid: uid, ver: ver, pk: pk, mapLabel: label, mapVal: val}
+	rand := ktcore.GetCommitRand(s.hashSuite, s.secs.commit, label)
+	val := ktcore.GetMapVal(s.hashSuite, pk, rand)
+	return &work{uid: uid, ver: ver, pk: pk, mapLabel: label, mapVal: val}
 }
 
 // History gives key history for uid, excluding first prevVerLen versions.
 // the caller already saw prevEpoch.
-func (s *Server) History(uid, prevEpoch, prevVerLen uint64) (chainProof, linkSig []byte, hist []*ktcore.Memb, bound *ktcore.NonMemb, err bool) {
+// epoch is the latest epoch, which linkSig signs.
+func (s *Server) History(uid, prevEpoch, prevVerLen uint64) (chainProof, linkSig []byte, epoch uint64, hist []*ktcore.Memb, bound *ktcore.NonMemb, err bool) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 	numEps := uint64(len(s.hist.audits))
@@ -90,9 +119,74 @@ func (s *Server) History(uid, prevEpoch, prevVerLen uint64) (chainProof, linkSig
 	}
 
 	chainProof = s.hist.chain.Prove(prevEpoch + 1)
-	linkSig = s.hist.audits[len(s.hist.audits)-1].LinkSig
-	hist = s.getHist(uid, prevVerLen)
-	bound = s.getBound(uid, numVers)
+	epoch = numEps - 1
+	linkSig = s.hist.audits[epoch].LinkSig
+	hist = s.getHist(s.keys.hidden, uid, prevVerLen, numVers)
+	bound = s.getBound(s.keys.hidden, uid, numVers)
+	return
+}
+
+// GetAtEpoch gives uid's key history as of epoch.
+// the caller already saw knownEpoch, and inclProof links
+// epoch's dig to knownEpoch's link.
+func (s *Server) GetAtEpoch(uid, epoch, knownEpoch uint64) (inclProof []byte, hist []*ktcore.Memb, bound *ktcore.NonMemb, err bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	numEps := uint64(len(s.hist.audits))
+	if knownEpoch >= numEps {
+		err = true
+		return
+	}
+	if epoch > knownEpoch {
+		err = true
+		return
+	}
+
+	m := s.hist.maps[epoch]
+	numVers := s.getNumVers(uid, epoch)
+	inclProof = s.hist.chain.ProveIncl(epoch, knownEpoch+1)
+	hist = s.getHist(m, uid, 0, numVers)
+	bound = s.getBound(m, uid, numVers)
+	return
+}
+
+// Skip proves the chain from prevEpoch to epoch, e.g., for a light client
+// to skip to an auditor checkpoint at epoch.
+// unlike [Server.History], it doesn't sign the link at epoch,
+// since the checkpoint already has a sig.
+func (s *Server) Skip(prevEpoch, epoch uint64) (chainProof []byte, err bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	numEps := uint64(len(s.hist.audits))
+	if epoch >= numEps {
+		err = true
+		return
+	}
+	if prevEpoch > epoch {
+		err = true
+		return
+	}
+	chainProof = s.hist.chain.ProveSkip(prevEpoch+1, epoch+1)
+	return
+}
+
+// Shard proves the entries under prefix in the maps before and after
+// epoch's update, so that auditors can split up a large update.
+// it errors if args out of bounds.
+func (s *Server) Shard(epoch uint64, prefix []byte, prefixLen uint64) (prevEntries []*merkle.Entry, prevProof []byte, nextEntries []*merkle.Entry, nextProof []byte, err bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	numEps := uint64(len(s.hist.audits))
+	if epoch == 0 || epoch >= numEps {
+		err = true
+		return
+	}
+	if prefixLen > cryptoffi.HashLen*8 || prefixLen > uint64(len(prefix))*8 {
+		err = true
+		return
+	}
+	prevEntries, prevProof = s.hist.maps[epoch-1].ProvePrefix(prefix, prefixLen)
+	nextEntries, nextProof = s.hist.maps[epoch].ProvePrefix(prefix, prefixLen)
 	return
 }
 
@@ -121,32 +215,40 @@ type work struct {
 }
 
 func (s *Server) worker() {
-	// our merkle tree only supports one latest view, so merkle updates
-	// must be sync'd with epoch releases. we batch updates for perf.
+	// merkle updates are only visible in the next epoch's snapshot.
+	// we batch updates for perf.
 	for {
-		w := s.getWork()
-		// empty batches are safe, but for perf, skip them.
-		if len(w) == 0 {
-			continue
-		}
-		s.doWork(w)
+		s.doWork(s.getWork())
 	}
 }
 
-func New() (*Server, cryptoffi.SigPublicKey) {
-	mu := new(sync.RWMutex)
-	vrfSk := cryptoffi.VrfGenerateKey()
+// New creates a server whose VRF uses vrfSuite, e.g., [cryptoffi.VrfSuiteTai],
+// and whose merkle map, hashchain, and commitments use hashSuite,
+// e.g., [cryptoffi.HashSuiteSha256].
+// the hashchain has chainMode, e.g., [hashchain.ModeLog].
+func New(vrfSuite, hashSuite, chainMode byte) (*Server, cryptoffi.SigPublicKey) {
+	vrfSk := cryptoffi.VrfGenerateKey(vrfSuite)
 	sigPk, sigSk := cryptoffi.SigGenerateKey()
-	vrfSig := ktcore.SignVrf(sigSk, vrfSk.PublicKey())
 	commitSec := cryptoffi.RandBytes(cryptoffi.HashLen)
 	secs := &secrets{sig: sigSk, vrf: vrfSk, commit: commitSec}
-	hidden := &merkle.Map{}
+	s := newServer(hashSuite, chainMode, secs)
+	go s.worker()
+	return s, sigPk
+}
+
+// newServer makes a server with an empty key directory.
+// the caller starts its worker.
+func newServer(hashSuite, chainMode byte, secs *secrets) *Server {
+	mu := new(sync.RWMutex)
+	vrfSig := ktcore.SignVrf(secs.sig, secs.vrf.Suite(), hashSuite, secs.vrf.PublicKey())
+	hidden := merkle.New(hashSuite)
 	plain := make(map[uint64][][]byte)
-	keys := &keyStore{hidden: hidden, plain: plain}
-	chain := hashchain.New()
+	eps := make(map[uint64][]uint64)
+	keys := &keyStore{hidden: hidden, plain: plain, eps: eps}
+	chain := hashchain.NewWithMode(hashSuite, chainMode)
 	hist := &history{chain: chain, vrfPkSig: vrfSig}
 	wq := make(chan *work)
-	s := &Server{mu: mu, secs: secs, keys: keys, hist: hist, workQ: wq}
+	s := &Server{hashSuite: hashSuite, chainMode: chainMode, mu: mu, epochTime: EpochTime, secs: secs, keys: keys, hist: hist, workQ: wq}
 
 	// commit empty map as epoch 0 to always have some epoch
 	// against which we can respond to requests.
@@ -154,15 +256,27 @@ func New() (*Server, cryptoffi.SigPublicKey) {
 	link := chain.Append(dig)
 	linkSig := ktcore.SignLink(s.secs.sig, 0, link)
 	s.hist.audits = append(s.hist.audits, &ktcore.AuditProof{LinkSig: linkSig})
+	s.hist.maps = append(s.hist.maps, keys.hidden.Snapshot())
+	return s
+}
 
-	go s.worker()
-	return s, sigPk
+// SetEpochTime sets how long the server batches puts into an epoch.
+// it applies from the next epoch.
+func (s *Server) SetEpochTime(d time.Duration) {
+	s.mu.Lock()
+	s.epochTime = d
+	s.mu.Unlock()
 }
 
+// getWork waits for a put, and then batches puts for the epoch time.
 func (s *Server) getWork() (work []*work) {
-	timer := time.NewTimer(EpochTime)
+	work = append(work, <-s.workQ)
+	s.mu.RLock()
+	d := s.epochTime
+	s.mu.RUnlock()
+	timer := time.NewTimer(d)
 	// don't care about upper-bounding batch size.
-	// so aggregate as much work as we can within [EpochTime].
+	// so aggregate as much work as we can within the epoch time.
 	for {
 		select {
 		case <-timer.C:
@@ -173,41 +287,75 @@ func (s *Server) getWork() (work []*work) {
 	}
 }
 
-func (s *Server) doWork(work []*work) {
+func (s *Server) doWork(batch []*work) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	upd := make([]*ktcore.UpdateProof, 0, len(work))
-	for _, w := range work {
+	epoch := uint64(len(s.hist.audits))
+	entries := make([]*merkle.Entry, 0, len(batch))
+	puts := make([]*work, 0, len(batch))
+	for _, w := range batch {
 		// check: for each uid, maintain contiguous seq of versions.
 		nextVer := uint64(len(s.keys.plain[w.uid]))
 		if w.ver != nextVer {
 			continue
 		}
-
-		// update.
-		proof := s.keys.hidden.Put(w.mapLabel, w.mapVal)
 		s.keys.plain[w.uid] = append(s.keys.plain[w.uid], w.pk)
-		info := &ktcore.UpdateProof{MapLabel: w.mapLabel, MapVal: w.mapVal, NonMembProof: proof}
+		s.keys.eps[w.uid] = append(s.keys.eps[w.uid], epoch)
+		entries = append(entries, &merkle.Entry{Label: w.mapLabel, Val: w.mapVal})
+		puts = append(puts, w)
+	}
+
+	// update. proofs follow the batch's path order.
+	sorted, proofs := s.keys.hidden.PutBatch(entries)
+	upd := make([]*ktcore.UpdateProof, 0, len(sorted))
+	for i, e := range sorted {
+		info := &ktcore.UpdateProof{MapLabel: e.Label, MapVal: e.Val, NonMembProof: proofs[i]}
 		upd = append(upd, info)
 	}
 
 	dig := s.keys.hidden.Hash()
-	link := s.hist.chain.Append(dig)
-	epoch := uint64(len(s.hist.audits))
+	var link []byte
+	if s.store == nil {
+		link = s.hist.chain.Append(dig)
+	} else {
+		link = s.store.Append(puts, dig)
+	}
 	sig := ktcore.SignLink(s.secs.sig, epoch, link)
+	// persist before anyone can see the epoch.
+	if s.store != nil {
+		s.store.SetHead(sig)
+	}
 	s.hist.audits = append(s.hist.audits, &ktcore.AuditProof{Updates: upd, LinkSig: sig})
+	s.hist.maps = append(s.hist.maps, s.keys.hidden.Snapshot())
+}
+
+// getNumVers returns the number of uid versions as of epoch.
+// it binary searches the version epochs, without any VRF evals.
+func (s *Server) getNumVers(uid, epoch uint64) (numVers uint64) {
+	// versions are added in order, so epoch has some prefix of them.
+	eps := s.keys.eps[uid]
+	hi := uint64(len(eps))
+	for numVers < hi {
+		mid := numVers + (hi-numVers)/2
+		if eps[mid] <= epoch {
+			numVers = mid + 1
+		} else {
+			hi = mid
+		}
+	}
+	return
 }
 
-// getHist returns a history of membership proofs for all post-prefix versions.
-func (s *Server) getHist(uid, prefixLen uint64) (hist []*ktcore.Memb) {
+// getHist returns a history of membership proofs in m
+// for versions in [prefixLen, numVers).
+func (s *Server) getHist(m *merkle.Map, uid, prefixLen, numVers uint64) (hist []*ktcore.Memb) {
 	pks := s.keys.plain[uid]
-	numVers := uint64(len(pks))
 	hist = make([]*ktcore.Memb, 0, numVers-prefixLen)
 	for ver := prefixLen; ver < numVers; ver++ {
 		label, labelProof := ktcore.ProveMapLabel(s.secs.vrf, uid, ver)
-		inMap, _, mapProof := s.keys.hidden.Prove(label)
+		inMap, _, mapProof := m.Prove(label)
 		std.Assert(inMap)
-		rand := ktcore.GetCommitRand(s.secs.commit, label)
+		rand := ktcore.GetCommitRand(s.hashSuite, s.secs.commit, label)
 		open := &ktcore.CommitOpen{Val: pks[ver], Rand: rand}
 		memb := &ktcore.Memb{LabelProof: labelProof, PkOpen: open, MerkleProof: mapProof}
 		hist = append(hist, memb)
@@ -215,10 +363,10 @@ func (s *Server) getHist(uid, prefixLen uint64) (hist []*ktcore.Memb) {
 	return
 }
 
-// getBound returns a non-membership proof for the boundary version.
-func (s *Server) getBound(uid, numVers uint64) (bound *ktcore.NonMemb) {
+// getBound returns a non-membership proof in m for the boundary version.
+func (s *Server) getBound(m *merkle.Map, uid, numVers uint64) (bound *ktcore.NonMemb) {
 	label, labelProof := ktcore.ProveMapLabel(s.secs.vrf, uid, numVers)
-	inMap, _, mapProof := s.keys.hidden.Prove(label)
+	inMap, _, mapProof := m.Prove(label)
 	std.Assert(!inMap)
 	bound = &ktcore.NonMemb{LabelProof: labelProof, MerkleProof: mapProof}
 	return